@@ -0,0 +1,51 @@
+package bootstrap
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-service/internal/saga/application"
+)
+
+type LambdaHandler interface {
+	Handle(ctx context.Context, sqsEvent events.SQSEvent) error
+}
+
+type ScheduledLambdaHandler interface {
+	Handle(ctx context.Context, event events.CloudWatchEvent) error
+}
+
+type PaymentStarter interface {
+	Handle(ctx context.Context, req application.StartRequest) error
+}
+
+// App holds the inbound entry points of the payment service sharing the same adapters
+type App struct {
+	Handler          LambdaHandler
+	ScheduledHandler ScheduledLambdaHandler
+	Starter          PaymentStarter
+}
+
+func BuildApp() App {
+	sagaRepo := provideRepository()
+	eventBus := provideEventBus()
+	deadlines := provideScheduler()
+	clock := provideClock()
+
+	useCase := provideUseCase(sagaRepo, eventBus, deadlines, clock)
+	expirePayments := provideExpirePayments(sagaRepo, eventBus, deadlines, clock, useCase)
+
+	return App{
+		Handler:          provideHandler(useCase),
+		ScheduledHandler: provideScheduledHandler(expirePayments),
+		Starter:          provideStartPayment(sagaRepo, eventBus),
+	}
+}
+
+func BuildHandler() LambdaHandler {
+	return BuildApp().Handler
+}
+
+func BuildScheduledHandler() ScheduledLambdaHandler {
+	return BuildApp().ScheduledHandler
+}
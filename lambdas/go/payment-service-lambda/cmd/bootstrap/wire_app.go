@@ -0,0 +1,38 @@
+package bootstrap
+
+import (
+	"github.com/payment-service/internal/saga/application"
+	"github.com/payment-service/internal/saga/application/ports"
+	"github.com/payment-service/internal/saga/infra/handler"
+)
+
+func provideUseCase(
+	repo ports.SagaRepository,
+	bus ports.EventBusProcessor,
+	scheduler ports.DeadlineScheduler,
+	clock ports.Clock,
+) *application.UseCaseHandler {
+	return application.NewProcessEventUseCaseHandler(repo, bus, scheduler, clock, application.DefaultTimeoutPolicy())
+}
+
+func provideStartPayment(repo ports.SagaRepository, bus ports.EventBusProcessor) *application.StartPaymentUseCaseHandler {
+	return application.NewStartPaymentUseCaseHandler(repo, bus)
+}
+
+func provideExpirePayments(
+	repo ports.SagaRepository,
+	bus ports.EventBusProcessor,
+	scheduler ports.DeadlineScheduler,
+	clock ports.Clock,
+	sagaEvents *application.UseCaseHandler,
+) *application.ExpirePaymentsUseCaseHandler {
+	return application.NewExpirePaymentsUseCaseHandler(repo, bus, scheduler, clock, application.DefaultTimeoutPolicy(), sagaEvents)
+}
+
+func provideHandler(useCase *application.UseCaseHandler) *handler.SQSHandler {
+	return handler.NewSQSHandler(useCase)
+}
+
+func provideScheduledHandler(useCase *application.ExpirePaymentsUseCaseHandler) *handler.ScheduledHandler {
+	return handler.NewScheduledHandler(useCase)
+}
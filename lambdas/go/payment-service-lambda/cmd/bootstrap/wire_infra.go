@@ -0,0 +1,26 @@
+package bootstrap
+
+import (
+	"github.com/payment-service/internal/saga/infra/bus"
+	"github.com/payment-service/internal/saga/infra/repository"
+	"github.com/payment-service/internal/saga/infra/scheduler"
+)
+
+func provideRepository() *repository.InMemorySagaRepository {
+	// in a real case, we would instance the real client here
+	return repository.NewInMemorySagaRepository()
+}
+
+func provideEventBus() *bus.ConsoleEventBus {
+	// in a real case, we would instance the real client here
+	return bus.NewConsoleEventBus()
+}
+
+func provideScheduler() *scheduler.InMemoryDeadlineScheduler {
+	// in a real case, we would instance the real client here (e.g. a DynamoDB table indexed by due date)
+	return scheduler.NewInMemoryDeadlineScheduler()
+}
+
+func provideClock() scheduler.SystemClock {
+	return scheduler.SystemClock{}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-service/cmd/bootstrap"
+	"github.com/payment-service/internal/saga/application"
+	"github.com/payment-service/internal/saga/domain"
+	_events "github.com/payment-service/internal/saga/domain/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLambdaHandler_EndToEnd drives a whole saga through the in-memory adapters
+func TestLambdaHandler_EndToEnd(t *testing.T) {
+	// --- 1. Arrange ---
+	app := bootstrap.BuildApp()
+
+	err := app.Starter.Handle(context.Background(), application.StartRequest{
+		PaymentID:     "pay-123",
+		UserID:        "user-123",
+		Amount:        25.50,
+		CorrelationID: "test-correlation-id-123",
+	})
+	require.NoError(t, err)
+
+	// --- 2. Act ---
+	// The wallet debits the balance and then the provider confirms the payment.
+	for _, eventType := range []domain.Event{domain.BalanceDebitedEventName, domain.ProviderPaymentSuccessEventName} {
+		err = app.Handler.Handle(context.Background(), sqsEvent(t, eventType))
+
+		// --- 3. Assert ---
+		assert.NoError(t, err)
+	}
+}
+
+func sqsEvent(t *testing.T, eventType domain.Event) events.SQSEvent {
+	t.Helper()
+
+	body, err := json.Marshal(_events.SagaEvent{
+		Header:  _events.EventHeader{CorrelationID: "test-correlation-id-123", EventType: string(eventType)},
+		Payload: _events.SagaEventPayload{PaymentID: "pay-123", UserID: "user-123"},
+	})
+	require.NoError(t, err)
+
+	return events.SQSEvent{Records: []events.SQSMessage{{MessageId: "test-message-id", Body: string(body)}}}
+}
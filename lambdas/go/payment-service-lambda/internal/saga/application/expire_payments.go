@@ -0,0 +1,138 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/payment-service/internal/saga/application/ports"
+	"github.com/payment-service/internal/saga/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type (
+	// TimeoutPolicy decides how long a payment may wait for the provider. Once ProviderTimeout
+	// elapses the provider is queried QueryAttempts times, QueryInterval apart, before compensating
+	TimeoutPolicy struct {
+		ProviderTimeout time.Duration
+		QueryInterval   time.Duration
+		QueryAttempts   int
+	}
+
+	SagaEventHandler interface {
+		Handle(ctx context.Context, req EventRequest) error
+	}
+
+	ExpirePaymentsUseCaseHandler struct {
+		sagaRepo       ports.SagaRepository
+		eventProcessor ports.EventBusProcessor
+		scheduler      ports.DeadlineScheduler
+		clock          ports.Clock
+		policy         TimeoutPolicy
+		sagaEvents     SagaEventHandler
+	}
+)
+
+func DefaultTimeoutPolicy() TimeoutPolicy {
+	return TimeoutPolicy{
+		ProviderTimeout: 5 * time.Minute,
+		QueryInterval:   time.Minute,
+		QueryAttempts:   1,
+	}
+}
+
+// Handle fires every deadline that is due: stuck payments are first queried to the provider
+// and compensated once the queries are exhausted
+func (h *ExpirePaymentsUseCaseHandler) Handle(ctx context.Context) error {
+	ctx, span := otel.Tracer("payment-service.application").Start(ctx, "UseCase.ExpirePayments")
+	defer span.End()
+
+	now := h.clock.Now()
+
+	deadlines, err := h.scheduler.Due(ctx, now)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to get due deadlines")
+		slog.ErrorContext(ctx, "error getting due deadlines", "error", err)
+		return err
+	}
+
+	var errs []error
+	for _, deadline := range deadlines {
+		if err = h.expire(ctx, deadline, now); err != nil {
+			slog.ErrorContext(ctx, "error expiring payment", "paymentId", deadline.PaymentID, "error", err)
+			errs = append(errs, err)
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to expire payments")
+	}
+
+	return err
+}
+
+func (h *ExpirePaymentsUseCaseHandler) expire(ctx context.Context, deadline domain.Deadline, now time.Time) error {
+	saga, err := h.sagaRepo.Get(ctx, deadline.PaymentID)
+	if err != nil {
+		return domain.NewGetSagaError(string(deadline.PaymentID), err)
+	}
+
+	// a timed out saga keeps its deadline until its compensation is published, handling the
+	// timeout again publishes it
+	timedOut := saga.State == domain.StateFailed && saga.LastEvent == domain.PaymentTimedOutEventName
+	if saga.State != domain.StateAwaitingProvider && !timedOut {
+		slog.InfoContext(ctx, "discarding stale deadline", "paymentId", saga.PaymentID, "state", saga.State)
+		return h.scheduler.Cancel(ctx, saga.PaymentID)
+	}
+
+	if !timedOut && deadline.Attempt < h.policy.QueryAttempts {
+		slog.WarnContext(ctx, "payment deadline passed, querying provider", "paymentId", saga.PaymentID, "attempt", deadline.Attempt+1)
+
+		if err = h.eventProcessor.Publish(ctx, toPaymentEventRequest(saga, domain.QueryProviderPaymentEventName)); err != nil {
+			return domain.NewPublishMessageError(string(saga.PaymentID), err)
+		}
+
+		next := domain.Deadline{PaymentID: saga.PaymentID, DueAt: now.Add(h.policy.QueryInterval), Attempt: deadline.Attempt + 1}
+		if err = h.scheduler.Schedule(ctx, next); err != nil {
+			return domain.NewScheduleDeadlineError(string(saga.PaymentID), err)
+		}
+
+		return nil
+	}
+
+	slog.WarnContext(ctx, "payment deadline passed, compensating user", "paymentId", saga.PaymentID)
+
+	err = h.sagaEvents.Handle(ctx, EventRequest{
+		PaymentID:     saga.PaymentID,
+		Event:         domain.PaymentTimedOutEventName,
+		CorrelationID: saga.CorrelationID,
+	})
+	if errors.Is(err, domain.ErrInvalidTransition) {
+		// the provider answered between reading the saga and compensating it
+		return h.scheduler.Cancel(ctx, saga.PaymentID)
+	}
+
+	return err
+}
+
+func NewExpirePaymentsUseCaseHandler(
+	repo ports.SagaRepository,
+	bus ports.EventBusProcessor,
+	scheduler ports.DeadlineScheduler,
+	clock ports.Clock,
+	policy TimeoutPolicy,
+	sagaEvents SagaEventHandler,
+) *ExpirePaymentsUseCaseHandler {
+	return &ExpirePaymentsUseCaseHandler{
+		sagaRepo:       repo,
+		eventProcessor: bus,
+		scheduler:      scheduler,
+		clock:          clock,
+		policy:         policy,
+		sagaEvents:     sagaEvents,
+	}
+}
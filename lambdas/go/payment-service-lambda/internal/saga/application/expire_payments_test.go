@@ -0,0 +1,201 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/payment-service/internal/saga/application"
+	"github.com/payment-service/internal/saga/application/ports"
+	"github.com/payment-service/internal/saga/application/ports/mocks"
+	"github.com/payment-service/internal/saga/domain"
+	"github.com/payment-service/internal/saga/infra/repository"
+	"github.com/payment-service/internal/saga/infra/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpirePaymentsUseCaseHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should do nothing before the deadline", testExpire_NotDue)
+	t.Run("should query provider when the deadline passes", testExpire_QueryProvider)
+	t.Run("should compensate user once provider queries are exhausted", testExpire_Compensate)
+	t.Run("should discard deadline of a saga that already finished", testExpire_StaleDeadline)
+	t.Run("should publish the compensation again when it failed", testExpire_CompensationRetried)
+}
+
+func testExpire_NotDue(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	env := newExpireEnv(t)
+	env.debit(t, "pay-1")
+	env.clock.Advance(policy.ProviderTimeout - time.Second)
+
+	// WHEN
+	err := env.useCase.Handle(context.Background())
+
+	// THEN
+	assert.NoError(t, err)
+	env.bus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func testExpire_QueryProvider(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	env := newExpireEnv(t)
+	env.debit(t, "pay-1")
+	env.clock.Advance(policy.ProviderTimeout)
+
+	env.bus.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(r ports.PaymentEventRequest) bool {
+		return r.EventName == domain.QueryProviderPaymentEventName && r.PaymentID == "pay-1"
+	})).Return(nil).Once()
+
+	// WHEN
+	err := env.useCase.Handle(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+
+	due, err := env.scheduler.Due(context.Background(), env.clock.Now().Add(policy.QueryInterval))
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Deadline{{PaymentID: "pay-1", DueAt: env.clock.Now().Add(policy.QueryInterval), Attempt: 1}}, due)
+
+	saga, err := env.repo.Get(context.Background(), "pay-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateAwaitingProvider, saga.State)
+}
+
+func testExpire_Compensate(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	env := newExpireEnv(t)
+	env.debit(t, "pay-1")
+
+	var published []domain.Event
+	env.bus.EXPECT().Publish(mock.Anything, mock.Anything).Run(func(_ context.Context, r ports.PaymentEventRequest) {
+		published = append(published, r.EventName)
+	}).Return(nil).Times(3)
+
+	env.clock.Advance(policy.ProviderTimeout)
+	require.NoError(t, env.useCase.Handle(context.Background()))
+	env.clock.Advance(policy.QueryInterval)
+
+	// WHEN
+	err := env.useCase.Handle(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Event{
+		domain.QueryProviderPaymentEventName,
+		domain.ReembolsarUsuarioEventName,
+		domain.PaymentFailedEventName,
+	}, published)
+
+	saga, err := env.repo.Get(context.Background(), "pay-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateFailed, saga.State)
+	assert.Equal(t, string(domain.PaymentTimedOutEventName), saga.FailureReason)
+
+	due, err := env.scheduler.Due(context.Background(), env.clock.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+func testExpire_StaleDeadline(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	env := newExpireEnv(t)
+	env.debit(t, "pay-1")
+
+	saga, err := env.repo.Get(context.Background(), "pay-1")
+	require.NoError(t, err)
+	saga.State = domain.StateCompleted
+	require.NoError(t, env.repo.Update(context.Background(), saga))
+
+	env.clock.Advance(policy.ProviderTimeout)
+
+	// WHEN
+	err = env.useCase.Handle(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+	env.bus.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+
+	due, err := env.scheduler.Due(context.Background(), env.clock.Now())
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+func testExpire_CompensationRetried(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	env := newExpireEnv(t)
+	env.debit(t, "pay-1")
+
+	var published []domain.Event
+	env.bus.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("eventbridge is down")).Once()
+	env.bus.EXPECT().Publish(mock.Anything, mock.Anything).Run(func(_ context.Context, r ports.PaymentEventRequest) {
+		published = append(published, r.EventName)
+	}).Return(nil).Twice()
+
+	env.clock.Advance(policy.ProviderTimeout)
+	env.useCase = application.NewExpirePaymentsUseCaseHandler(env.repo, env.bus, env.scheduler, env.clock,
+		application.TimeoutPolicy{ProviderTimeout: policy.ProviderTimeout}, env.events)
+	require.Error(t, env.useCase.Handle(context.Background()))
+
+	// WHEN
+	err := env.useCase.Handle(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Event{domain.ReembolsarUsuarioEventName, domain.PaymentFailedEventName}, published)
+
+	due, err := env.scheduler.Due(context.Background(), env.clock.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+// --- Helper Functions ---
+
+type movingClock struct{ now time.Time }
+
+func (c *movingClock) Now() time.Time          { return c.now }
+func (c *movingClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+type expireEnv struct {
+	repo      *repository.InMemorySagaRepository
+	scheduler *scheduler.InMemoryDeadlineScheduler
+	bus       *mocks.MockEventBusProcessor
+	clock     *movingClock
+	events    *application.UseCaseHandler
+	useCase   *application.ExpirePaymentsUseCaseHandler
+}
+
+func newExpireEnv(t *testing.T) *expireEnv {
+	env := &expireEnv{
+		repo:      repository.NewInMemorySagaRepository(),
+		scheduler: scheduler.NewInMemoryDeadlineScheduler(),
+		bus:       mocks.NewMockEventBusProcessor(t),
+		clock:     &movingClock{now: now},
+	}
+	env.events = application.NewProcessEventUseCaseHandler(env.repo, env.bus, env.scheduler, env.clock, policy)
+	env.useCase = application.NewExpirePaymentsUseCaseHandler(env.repo, env.bus, env.scheduler, env.clock, policy, env.events)
+
+	return env
+}
+
+// debit leaves the saga of paymentID waiting for the provider
+func (e *expireEnv) debit(t *testing.T, paymentID domain.PaymentID) {
+	t.Helper()
+
+	require.NoError(t, e.repo.Create(context.Background(), domain.NewSaga(paymentID, "user-1", 30, "corr-1")))
+	require.NoError(t, e.events.Handle(context.Background(), application.EventRequest{PaymentID: paymentID, Event: domain.BalanceDebitedEventName}))
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/payment-service/internal/saga/domain"
+)
+
+// DeadlineScheduler keeps at most one deadline per payment; scheduling again replaces it
+type DeadlineScheduler interface {
+	Schedule(context.Context, domain.Deadline) error
+	Cancel(context.Context, domain.PaymentID) error
+	Due(context.Context, time.Time) ([]domain.Deadline, error)
+}
+
+type Clock interface {
+	Now() time.Time
+}
@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/payment-service/internal/saga/domain"
+)
+
+type PaymentEventRequest struct {
+	PaymentID     domain.PaymentID
+	UserID        domain.UserID
+	Amount        domain.Amount
+	Reason        string
+	EventName     domain.Event
+	CorrelationID string
+}
+
+type EventBusProcessor interface {
+	Publish(context.Context, PaymentEventRequest) error
+}
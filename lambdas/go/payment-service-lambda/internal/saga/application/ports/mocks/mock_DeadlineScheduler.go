@@ -0,0 +1,222 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/payment-service/internal/saga/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockDeadlineScheduler creates a new instance of MockDeadlineScheduler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDeadlineScheduler(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDeadlineScheduler {
+	mock := &MockDeadlineScheduler{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockDeadlineScheduler is an autogenerated mock type for the DeadlineScheduler type
+type MockDeadlineScheduler struct {
+	mock.Mock
+}
+
+type MockDeadlineScheduler_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDeadlineScheduler) EXPECT() *MockDeadlineScheduler_Expecter {
+	return &MockDeadlineScheduler_Expecter{mock: &_m.Mock}
+}
+
+// Cancel provides a mock function for the type MockDeadlineScheduler
+func (_mock *MockDeadlineScheduler) Cancel(context1 context.Context, paymentID domain.PaymentID) error {
+	ret := _mock.Called(context1, paymentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Cancel")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.PaymentID) error); ok {
+		r0 = returnFunc(context1, paymentID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockDeadlineScheduler_Cancel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Cancel'
+type MockDeadlineScheduler_Cancel_Call struct {
+	*mock.Call
+}
+
+// Cancel is a helper method to define mock.On call
+//   - context1 context.Context
+//   - paymentID domain.PaymentID
+func (_e *MockDeadlineScheduler_Expecter) Cancel(context1 interface{}, paymentID interface{}) *MockDeadlineScheduler_Cancel_Call {
+	return &MockDeadlineScheduler_Cancel_Call{Call: _e.mock.On("Cancel", context1, paymentID)}
+}
+
+func (_c *MockDeadlineScheduler_Cancel_Call) Run(run func(context1 context.Context, paymentID domain.PaymentID)) *MockDeadlineScheduler_Cancel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.PaymentID
+		if args[1] != nil {
+			arg1 = args[1].(domain.PaymentID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockDeadlineScheduler_Cancel_Call) Return(err error) *MockDeadlineScheduler_Cancel_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockDeadlineScheduler_Cancel_Call) RunAndReturn(run func(context1 context.Context, paymentID domain.PaymentID) error) *MockDeadlineScheduler_Cancel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Due provides a mock function for the type MockDeadlineScheduler
+func (_mock *MockDeadlineScheduler) Due(context1 context.Context, time1 time.Time) ([]domain.Deadline, error) {
+	ret := _mock.Called(context1, time1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Due")
+	}
+
+	var r0 []domain.Deadline
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) ([]domain.Deadline, error)); ok {
+		return returnFunc(context1, time1)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) []domain.Deadline); ok {
+		r0 = returnFunc(context1, time1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Deadline)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = returnFunc(context1, time1)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockDeadlineScheduler_Due_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Due'
+type MockDeadlineScheduler_Due_Call struct {
+	*mock.Call
+}
+
+// Due is a helper method to define mock.On call
+//   - context1 context.Context
+//   - time1 time.Time
+func (_e *MockDeadlineScheduler_Expecter) Due(context1 interface{}, time1 interface{}) *MockDeadlineScheduler_Due_Call {
+	return &MockDeadlineScheduler_Due_Call{Call: _e.mock.On("Due", context1, time1)}
+}
+
+func (_c *MockDeadlineScheduler_Due_Call) Run(run func(context1 context.Context, time1 time.Time)) *MockDeadlineScheduler_Due_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockDeadlineScheduler_Due_Call) Return(deadlines []domain.Deadline, err error) *MockDeadlineScheduler_Due_Call {
+	_c.Call.Return(deadlines, err)
+	return _c
+}
+
+func (_c *MockDeadlineScheduler_Due_Call) RunAndReturn(run func(context1 context.Context, time1 time.Time) ([]domain.Deadline, error)) *MockDeadlineScheduler_Due_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Schedule provides a mock function for the type MockDeadlineScheduler
+func (_mock *MockDeadlineScheduler) Schedule(context1 context.Context, deadline domain.Deadline) error {
+	ret := _mock.Called(context1, deadline)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Schedule")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Deadline) error); ok {
+		r0 = returnFunc(context1, deadline)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockDeadlineScheduler_Schedule_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Schedule'
+type MockDeadlineScheduler_Schedule_Call struct {
+	*mock.Call
+}
+
+// Schedule is a helper method to define mock.On call
+//   - context1 context.Context
+//   - deadline domain.Deadline
+func (_e *MockDeadlineScheduler_Expecter) Schedule(context1 interface{}, deadline interface{}) *MockDeadlineScheduler_Schedule_Call {
+	return &MockDeadlineScheduler_Schedule_Call{Call: _e.mock.On("Schedule", context1, deadline)}
+}
+
+func (_c *MockDeadlineScheduler_Schedule_Call) Run(run func(context1 context.Context, deadline domain.Deadline)) *MockDeadlineScheduler_Schedule_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.Deadline
+		if args[1] != nil {
+			arg1 = args[1].(domain.Deadline)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockDeadlineScheduler_Schedule_Call) Return(err error) *MockDeadlineScheduler_Schedule_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockDeadlineScheduler_Schedule_Call) RunAndReturn(run func(context1 context.Context, deadline domain.Deadline) error) *MockDeadlineScheduler_Schedule_Call {
+	_c.Call.Return(run)
+	return _c
+}
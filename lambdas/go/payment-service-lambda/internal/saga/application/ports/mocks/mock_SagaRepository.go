@@ -0,0 +1,219 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/payment-service/internal/saga/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockSagaRepository creates a new instance of MockSagaRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockSagaRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockSagaRepository {
+	mock := &MockSagaRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockSagaRepository is an autogenerated mock type for the SagaRepository type
+type MockSagaRepository struct {
+	mock.Mock
+}
+
+type MockSagaRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockSagaRepository) EXPECT() *MockSagaRepository_Expecter {
+	return &MockSagaRepository_Expecter{mock: &_m.Mock}
+}
+
+// Create provides a mock function for the type MockSagaRepository
+func (_mock *MockSagaRepository) Create(context1 context.Context, saga domain.Saga) error {
+	ret := _mock.Called(context1, saga)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Saga) error); ok {
+		r0 = returnFunc(context1, saga)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSagaRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockSagaRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - context1 context.Context
+//   - saga domain.Saga
+func (_e *MockSagaRepository_Expecter) Create(context1 interface{}, saga interface{}) *MockSagaRepository_Create_Call {
+	return &MockSagaRepository_Create_Call{Call: _e.mock.On("Create", context1, saga)}
+}
+
+func (_c *MockSagaRepository_Create_Call) Run(run func(context1 context.Context, saga domain.Saga)) *MockSagaRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.Saga
+		if args[1] != nil {
+			arg1 = args[1].(domain.Saga)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSagaRepository_Create_Call) Return(err error) *MockSagaRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSagaRepository_Create_Call) RunAndReturn(run func(context1 context.Context, saga domain.Saga) error) *MockSagaRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function for the type MockSagaRepository
+func (_mock *MockSagaRepository) Get(context1 context.Context, paymentID domain.PaymentID) (domain.Saga, error) {
+	ret := _mock.Called(context1, paymentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 domain.Saga
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.PaymentID) (domain.Saga, error)); ok {
+		return returnFunc(context1, paymentID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.PaymentID) domain.Saga); ok {
+		r0 = returnFunc(context1, paymentID)
+	} else {
+		r0 = ret.Get(0).(domain.Saga)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, domain.PaymentID) error); ok {
+		r1 = returnFunc(context1, paymentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockSagaRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockSagaRepository_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - context1 context.Context
+//   - paymentID domain.PaymentID
+func (_e *MockSagaRepository_Expecter) Get(context1 interface{}, paymentID interface{}) *MockSagaRepository_Get_Call {
+	return &MockSagaRepository_Get_Call{Call: _e.mock.On("Get", context1, paymentID)}
+}
+
+func (_c *MockSagaRepository_Get_Call) Run(run func(context1 context.Context, paymentID domain.PaymentID)) *MockSagaRepository_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.PaymentID
+		if args[1] != nil {
+			arg1 = args[1].(domain.PaymentID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSagaRepository_Get_Call) Return(saga domain.Saga, err error) *MockSagaRepository_Get_Call {
+	_c.Call.Return(saga, err)
+	return _c
+}
+
+func (_c *MockSagaRepository_Get_Call) RunAndReturn(run func(context1 context.Context, paymentID domain.PaymentID) (domain.Saga, error)) *MockSagaRepository_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function for the type MockSagaRepository
+func (_mock *MockSagaRepository) Update(context1 context.Context, saga domain.Saga) error {
+	ret := _mock.Called(context1, saga)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Saga) error); ok {
+		r0 = returnFunc(context1, saga)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockSagaRepository_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockSagaRepository_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - context1 context.Context
+//   - saga domain.Saga
+func (_e *MockSagaRepository_Expecter) Update(context1 interface{}, saga interface{}) *MockSagaRepository_Update_Call {
+	return &MockSagaRepository_Update_Call{Call: _e.mock.On("Update", context1, saga)}
+}
+
+func (_c *MockSagaRepository_Update_Call) Run(run func(context1 context.Context, saga domain.Saga)) *MockSagaRepository_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.Saga
+		if args[1] != nil {
+			arg1 = args[1].(domain.Saga)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockSagaRepository_Update_Call) Return(err error) *MockSagaRepository_Update_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockSagaRepository_Update_Call) RunAndReturn(run func(context1 context.Context, saga domain.Saga) error) *MockSagaRepository_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/payment-service/internal/saga/domain"
+)
+
+type SagaRepository interface {
+	Create(context.Context, domain.Saga) error
+	Get(context.Context, domain.PaymentID) (domain.Saga, error)
+	Update(context.Context, domain.Saga) error
+}
@@ -0,0 +1,169 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/payment-service/internal/saga/application/ports"
+	"github.com/payment-service/internal/saga/domain"
+	"github.com/payment-service/internal/saga/infra/repository"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const maxRetries = 3
+
+type (
+	EventRequest struct {
+		PaymentID     domain.PaymentID
+		Event         domain.Event
+		CorrelationID string
+	}
+
+	UseCaseHandler struct {
+		sagaRepo       ports.SagaRepository
+		eventProcessor ports.EventBusProcessor
+		scheduler      ports.DeadlineScheduler
+		clock          ports.Clock
+		policy         TimeoutPolicy
+	}
+)
+
+// Handle moves the saga of the payment to its next state and emits the resulting events. The
+// saga is saved before the events are published, so the redelivery of an event already applied
+// publishes the events of its transition again instead of being rejected
+func (h *UseCaseHandler) Handle(ctx context.Context, req EventRequest) error {
+	tracer := otel.Tracer("payment-service.application")
+	ctx, span := tracer.Start(ctx, "UseCase.HandleSagaEvent")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("payment.id", string(req.PaymentID)),
+		attribute.String("saga.event", string(req.Event)),
+	)
+
+	slog.InfoContext(ctx, "Handling saga event", "paymentId", req.PaymentID, "event", req.Event)
+
+	var err error
+	var saga domain.Saga
+	var emit []domain.Event
+
+	for i := 0; i < maxRetries; i++ {
+		readCtx, readSpan := tracer.Start(ctx, "Repository.Get")
+		saga, err = h.sagaRepo.Get(readCtx, req.PaymentID)
+		readSpan.End()
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Failed to get saga")
+			slog.ErrorContext(ctx, "error getting saga", "paymentId", req.PaymentID, "error", err)
+			return domain.NewGetSagaError(string(req.PaymentID), err)
+		}
+
+		from := saga.State
+		if emitted, ok := saga.Redelivered(req.Event); ok {
+			slog.WarnContext(ctx, "event already applied to the saga, publishing its events again", "paymentId", req.PaymentID, "state", saga.State, "event", req.Event)
+			return h.publish(ctx, saga, emitted)
+		}
+		if emit, err = saga.Apply(req.Event); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Invalid transition")
+			slog.WarnContext(ctx, "saga rejected event", "paymentId", req.PaymentID, "state", from, "event", req.Event)
+			return err
+		}
+
+		// the deadline is recorded before the transition is saved so a retried message never
+		// leaves an in-flight payment without one; stale deadlines are ignored when they fire
+		if saga.State == domain.StateAwaitingProvider {
+			deadline := domain.Deadline{PaymentID: saga.PaymentID, DueAt: h.clock.Now().Add(h.policy.ProviderTimeout)}
+			if err = h.scheduler.Schedule(ctx, deadline); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, "Failed to schedule deadline")
+				slog.ErrorContext(ctx, "error scheduling payment deadline", "paymentId", req.PaymentID, "error", err)
+				return domain.NewScheduleDeadlineError(string(req.PaymentID), err)
+			}
+		}
+
+		updateCtx, updateSpan := tracer.Start(ctx, "Repository.Update")
+		err = h.sagaRepo.Update(updateCtx, saga)
+		updateSpan.End()
+
+		if err == nil {
+			slog.InfoContext(ctx, "Saga transitioned", "paymentId", req.PaymentID, "from", from, "to", saga.State)
+			break
+		}
+
+		if errors.Is(err, repository.ErrVersionMismatch) {
+			slog.WarnContext(ctx, "version mismatch detected, retrying transition", "attempt", i+1, "paymentId", req.PaymentID)
+			continue
+		}
+
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Unrecoverable repository error")
+		slog.ErrorContext(ctx, "unrecoverable repository error on update", "error", err, "paymentId", req.PaymentID)
+		return domain.NewSaveSagaError(string(req.PaymentID), err)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Transition failed after max retries")
+		slog.ErrorContext(ctx, "transition failed after max retries", "error", err, "paymentId", req.PaymentID)
+		return domain.NewMaxRetriesError(string(req.PaymentID), err)
+	}
+
+	return h.publish(ctx, saga, emit)
+}
+
+// publish emits the events of the transition into the current state. The deadline of a finished
+// saga is cancelled only once they are all published, so a timed out payment keeps its deadline
+// until its compensation is sent
+func (h *UseCaseHandler) publish(ctx context.Context, saga domain.Saga, emit []domain.Event) error {
+	span := trace.SpanFromContext(ctx)
+
+	for _, event := range emit {
+		if err := h.eventProcessor.Publish(ctx, toPaymentEventRequest(saga, event)); err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "Publish event failed")
+			slog.ErrorContext(ctx, "error publishing event after saga transition", "event", event, "error", err)
+			return domain.NewPublishMessageError(string(saga.PaymentID), err)
+		}
+	}
+
+	if saga.IsTerminal() {
+		if err := h.scheduler.Cancel(ctx, saga.PaymentID); err != nil {
+			slog.WarnContext(ctx, "error cancelling payment deadline", "paymentId", saga.PaymentID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+func toPaymentEventRequest(saga domain.Saga, event domain.Event) ports.PaymentEventRequest {
+	return ports.PaymentEventRequest{
+		PaymentID:     saga.PaymentID,
+		UserID:        saga.UserID,
+		Amount:        saga.Amount,
+		Reason:        saga.FailureReason,
+		EventName:     event,
+		CorrelationID: saga.CorrelationID,
+	}
+}
+
+func NewProcessEventUseCaseHandler(
+	repo ports.SagaRepository,
+	bus ports.EventBusProcessor,
+	scheduler ports.DeadlineScheduler,
+	clock ports.Clock,
+	policy TimeoutPolicy,
+) *UseCaseHandler {
+	return &UseCaseHandler{
+		sagaRepo:       repo,
+		eventProcessor: bus,
+		scheduler:      scheduler,
+		clock:          clock,
+		policy:         policy,
+	}
+}
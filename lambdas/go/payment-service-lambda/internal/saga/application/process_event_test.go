@@ -0,0 +1,348 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/payment-service/internal/saga/application"
+	"github.com/payment-service/internal/saga/application/ports"
+	"github.com/payment-service/internal/saga/application/ports/mocks"
+	"github.com/payment-service/internal/saga/domain"
+	"github.com/payment-service/internal/saga/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseCaseHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should transition saga without emitting on balance debited", testUseCase_BalanceDebited)
+	t.Run("should emit refund and payment failed on provider failure", testUseCase_ProviderFailed)
+	t.Run("should reject invalid transition without saving", testUseCase_InvalidTransition)
+	t.Run("should return error when repository fails to get saga", testUseCase_RepositoryGetError)
+	t.Run("should succeed after one retry on version mismatch", testUseCase_OptimisticLockingRetrySuccess)
+	t.Run("should fail after max retries on version mismatch", testUseCase_OptimisticLockingMaxRetries)
+	t.Run("should return error when event bus fails to publish", testUseCase_EventBusError)
+	t.Run("should publish again the events of a redelivered event", testUseCase_RedeliveredEvent)
+	t.Run("should not save transition when deadline cannot be scheduled", testUseCase_ScheduleError)
+}
+
+func TestStartPaymentUseCaseHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should create saga and emit payment init", testStartPayment_Success)
+	t.Run("should return error when saga cannot be created", testStartPayment_CreateError)
+}
+
+func testUseCase_BalanceDebited(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockSagaRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	schedulerMock := mocks.NewMockDeadlineScheduler(t)
+	saga := domain.NewSaga("pay-1", "user-1", 30, "corr-1")
+	req := application.EventRequest{PaymentID: "pay-1", Event: domain.BalanceDebitedEventName, CorrelationID: "corr-1"}
+
+	repoMock.EXPECT().Get(mock.Anything, req.PaymentID).Return(saga, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, mock.MatchedBy(func(s domain.Saga) bool {
+		return s.State == domain.StateAwaitingProvider && s.Version == 1
+	})).Return(nil).Once()
+	schedulerMock.EXPECT().Schedule(mock.Anything, domain.Deadline{PaymentID: "pay-1", DueAt: now.Add(policy.ProviderTimeout)}).Return(nil).Once()
+
+	useCase := newUseCase(repoMock, busMock, schedulerMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+	busMock.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func testUseCase_ProviderFailed(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockSagaRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	schedulerMock := mocks.NewMockDeadlineScheduler(t)
+	saga := domain.NewSaga("pay-1", "user-1", 30, "corr-1")
+	saga.State = domain.StateAwaitingProvider
+	req := application.EventRequest{PaymentID: "pay-1", Event: domain.ProviderPaymentFailedEventName, CorrelationID: "corr-1"}
+
+	var published []domain.Event
+	repoMock.EXPECT().Get(mock.Anything, req.PaymentID).Return(saga, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(nil).Once()
+	schedulerMock.EXPECT().Cancel(mock.Anything, req.PaymentID).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(r ports.PaymentEventRequest) bool {
+		return r.PaymentID == "pay-1" && r.UserID == "user-1" && r.Amount == 30 && r.CorrelationID == "corr-1"
+	})).Run(func(_ context.Context, r ports.PaymentEventRequest) {
+		published = append(published, r.EventName)
+	}).Return(nil).Twice()
+
+	useCase := newUseCase(repoMock, busMock, schedulerMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []domain.Event{domain.ReembolsarUsuarioEventName, domain.PaymentFailedEventName}, published)
+}
+
+func testUseCase_InvalidTransition(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockSagaRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	schedulerMock := mocks.NewMockDeadlineScheduler(t)
+	saga := domain.NewSaga("pay-1", "user-1", 30, "corr-1")
+	saga.State = domain.StateCompleted
+	req := application.EventRequest{PaymentID: "pay-1", Event: domain.ProviderPaymentFailedEventName}
+
+	repoMock.EXPECT().Get(mock.Anything, req.PaymentID).Return(saga, nil).Once()
+
+	useCase := newUseCase(repoMock, busMock, schedulerMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.ErrorIs(t, err, domain.ErrInvalidTransition)
+
+	var domainErr *domain.Error
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "4001", domainErr.Code)
+
+	repoMock.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	busMock.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func testUseCase_RepositoryGetError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockSagaRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	schedulerMock := mocks.NewMockDeadlineScheduler(t)
+	req := application.EventRequest{PaymentID: "pay-1", Event: domain.BalanceDebitedEventName}
+
+	repoMock.EXPECT().Get(mock.Anything, req.PaymentID).Return(domain.Saga{}, errors.New("dynamo is down")).Once()
+
+	useCase := newUseCase(repoMock, busMock, schedulerMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "get saga error")
+}
+
+func testUseCase_OptimisticLockingRetrySuccess(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockSagaRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	schedulerMock := mocks.NewMockDeadlineScheduler(t)
+	req := application.EventRequest{PaymentID: "pay-1", Event: domain.ProviderPaymentSuccessEventName}
+
+	sagaV1 := domain.NewSaga("pay-1", "user-1", 30, "corr-1")
+	sagaV1.State = domain.StateAwaitingProvider
+	sagaV2 := sagaV1
+	sagaV2.Version = 2
+
+	repoMock.EXPECT().Get(mock.Anything, req.PaymentID).Return(sagaV1, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(repository.ErrVersionMismatch).Once()
+	repoMock.EXPECT().Get(mock.Anything, req.PaymentID).Return(sagaV2, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, mock.MatchedBy(func(s domain.Saga) bool {
+		return s.Version == 2 && s.State == domain.StateCompleted
+	})).Return(nil).Once()
+	schedulerMock.EXPECT().Cancel(mock.Anything, req.PaymentID).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+
+	useCase := newUseCase(repoMock, busMock, schedulerMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testUseCase_OptimisticLockingMaxRetries(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockSagaRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	schedulerMock := mocks.NewMockDeadlineScheduler(t)
+	req := application.EventRequest{PaymentID: "pay-1", Event: domain.BalanceDebitedEventName}
+	saga := domain.NewSaga("pay-1", "user-1", 30, "corr-1")
+
+	repoMock.EXPECT().Get(mock.Anything, req.PaymentID).Return(saga, nil).Times(3)
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(repository.ErrVersionMismatch).Times(3)
+	schedulerMock.EXPECT().Schedule(mock.Anything, mock.Anything).Return(nil).Times(3)
+
+	useCase := newUseCase(repoMock, busMock, schedulerMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	var domainErr *domain.Error
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "4002", domainErr.Code)
+}
+
+func testUseCase_EventBusError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockSagaRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	schedulerMock := mocks.NewMockDeadlineScheduler(t)
+	req := application.EventRequest{PaymentID: "pay-1", Event: domain.InsufficientBalanceEventName}
+	saga := domain.NewSaga("pay-1", "user-1", 30, "corr-1")
+
+	repoMock.EXPECT().Get(mock.Anything, req.PaymentID).Return(saga, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("eventbridge is down")).Once()
+
+	useCase := newUseCase(repoMock, busMock, schedulerMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	var domainErr *domain.Error
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "5003", domainErr.Code)
+	schedulerMock.AssertNotCalled(t, "Cancel", mock.Anything, mock.Anything)
+}
+
+func testUseCase_RedeliveredEvent(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemorySagaRepository()
+	busMock := mocks.NewMockEventBusProcessor(t)
+	schedulerMock := mocks.NewMockDeadlineScheduler(t)
+	req := application.EventRequest{PaymentID: "pay-1", Event: domain.ProviderPaymentFailedEventName, CorrelationID: "corr-1"}
+	saga := domain.NewSaga("pay-1", "user-1", 30, "corr-1")
+	saga.State = domain.StateAwaitingProvider
+	require.NoError(t, repo.Create(context.Background(), saga))
+
+	var published []domain.Event
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("eventbridge is down")).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, r ports.PaymentEventRequest) error {
+		published = append(published, r.EventName)
+		return nil
+	}).Twice()
+	schedulerMock.EXPECT().Cancel(mock.Anything, req.PaymentID).Return(nil).Once()
+
+	useCase := newUseCase(repo, busMock, schedulerMock)
+	require.Error(t, useCase.Handle(context.Background(), req))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Event{domain.ReembolsarUsuarioEventName, domain.PaymentFailedEventName}, published)
+	stored, err := repo.Get(context.Background(), "pay-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.StateFailed, stored.State)
+}
+
+func testUseCase_ScheduleError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockSagaRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	schedulerMock := mocks.NewMockDeadlineScheduler(t)
+	req := application.EventRequest{PaymentID: "pay-1", Event: domain.BalanceDebitedEventName}
+	saga := domain.NewSaga("pay-1", "user-1", 30, "corr-1")
+
+	repoMock.EXPECT().Get(mock.Anything, req.PaymentID).Return(saga, nil).Once()
+	schedulerMock.EXPECT().Schedule(mock.Anything, mock.Anything).Return(errors.New("scheduler is down")).Once()
+
+	useCase := newUseCase(repoMock, busMock, schedulerMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	var domainErr *domain.Error
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "5004", domainErr.Code)
+	repoMock.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func testStartPayment_Success(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockSagaRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.StartRequest{PaymentID: "pay-1", UserID: "user-1", Amount: 30, CorrelationID: "corr-1"}
+
+	repoMock.EXPECT().Create(mock.Anything, mock.MatchedBy(func(s domain.Saga) bool {
+		return s.PaymentID == "pay-1" && s.State == domain.StateStarted && s.Version == 1
+	})).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(r ports.PaymentEventRequest) bool {
+		return r.EventName == domain.PaymentInitEventName && r.Amount == 30
+	})).Return(nil).Once()
+
+	useCase := application.NewStartPaymentUseCaseHandler(repoMock, busMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testStartPayment_CreateError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockSagaRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.StartRequest{PaymentID: "pay-1", UserID: "user-1", Amount: 30, CorrelationID: "corr-1"}
+
+	repoMock.EXPECT().Create(mock.Anything, mock.Anything).Return(repository.ErrSagaAlreadyExists).Once()
+
+	useCase := application.NewStartPaymentUseCaseHandler(repoMock, busMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	var domainErr *domain.Error
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "5002", domainErr.Code)
+	busMock.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+// --- Helper Functions ---
+
+var (
+	now    = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	policy = application.TimeoutPolicy{ProviderTimeout: 5 * time.Minute, QueryInterval: time.Minute, QueryAttempts: 1}
+)
+
+type fixedClock struct{ now time.Time }
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func newUseCase(repo ports.SagaRepository, bus ports.EventBusProcessor, scheduler ports.DeadlineScheduler) *application.UseCaseHandler {
+	return application.NewProcessEventUseCaseHandler(repo, bus, scheduler, fixedClock{now: now}, policy)
+}
@@ -0,0 +1,63 @@
+package application
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/payment-service/internal/saga/application/ports"
+	"github.com/payment-service/internal/saga/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type (
+	StartRequest struct {
+		PaymentID     domain.PaymentID
+		UserID        domain.UserID
+		Amount        domain.Amount
+		CorrelationID string
+	}
+
+	StartPaymentUseCaseHandler struct {
+		sagaRepo       ports.SagaRepository
+		eventProcessor ports.EventBusProcessor
+	}
+)
+
+// Handle persists a new saga and emits PaymentInit to start it
+func (h *StartPaymentUseCaseHandler) Handle(ctx context.Context, req StartRequest) error {
+	ctx, span := otel.Tracer("payment-service.application").Start(ctx, "UseCase.StartPayment")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("payment.id", string(req.PaymentID)),
+		attribute.String("user.id", string(req.UserID)),
+	)
+
+	saga := domain.NewSaga(req.PaymentID, req.UserID, req.Amount, req.CorrelationID)
+
+	if err := h.sagaRepo.Create(ctx, saga); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Failed to create saga")
+		slog.ErrorContext(ctx, "error creating saga", "paymentId", req.PaymentID, "error", err)
+		return domain.NewSaveSagaError(string(req.PaymentID), err)
+	}
+
+	if err := h.eventProcessor.Publish(ctx, toPaymentEventRequest(saga, domain.PaymentInitEventName)); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Publish event failed")
+		slog.ErrorContext(ctx, "error publishing payment init", "paymentId", req.PaymentID, "error", err)
+		return domain.NewPublishMessageError(string(req.PaymentID), err)
+	}
+
+	slog.InfoContext(ctx, "Payment saga started", "paymentId", req.PaymentID)
+	return nil
+}
+
+func NewStartPaymentUseCaseHandler(repo ports.SagaRepository, bus ports.EventBusProcessor) *StartPaymentUseCaseHandler {
+	return &StartPaymentUseCaseHandler{
+		sagaRepo:       repo,
+		eventProcessor: bus,
+	}
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+// Deadline is the moment after which an in-flight payment is considered stuck.
+// Attempt counts how many times the provider has already been queried for it
+type Deadline struct {
+	PaymentID PaymentID
+	DueAt     time.Time
+	Attempt   int
+}
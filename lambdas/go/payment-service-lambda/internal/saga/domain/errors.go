@@ -0,0 +1,76 @@
+package domain
+
+import "errors"
+
+var (
+	ErrInvalidTransition = errors.New("invalid saga transition")
+	// ErrSagaNotFound is returned by the repositories for a payment without saga
+	ErrSagaNotFound = errors.New("saga not found")
+)
+
+type Error struct {
+	Message  string
+	Code     string
+	Cause    error
+	Metadata map[string]any
+}
+
+func (e *Error) Error() string { return e.Message }
+func (e *Error) Unwrap() error { return e.Cause }
+
+func NewInvalidTransitionError(id, state, event string) error {
+	return &Error{
+		Message: "invalid saga transition error",
+		Code:    "4001",
+		Cause:   ErrInvalidTransition,
+		Metadata: map[string]any{
+			"id":    id,
+			"state": state,
+			"event": event},
+	}
+}
+
+func NewMaxRetriesError(id string, e error) error {
+	return &Error{
+		Message:  "max retries exceeded error",
+		Code:     "4002",
+		Cause:    e,
+		Metadata: map[string]any{"id": id},
+	}
+}
+
+func NewGetSagaError(id string, e error) error {
+	return &Error{
+		Message:  "get saga error",
+		Code:     "5001",
+		Cause:    e,
+		Metadata: map[string]any{"id": id},
+	}
+}
+
+func NewSaveSagaError(id string, e error) error {
+	return &Error{
+		Message:  "save saga error",
+		Code:     "5002",
+		Cause:    e,
+		Metadata: map[string]any{"id": id},
+	}
+}
+
+func NewPublishMessageError(id string, e error) error {
+	return &Error{
+		Message:  "publish message error",
+		Code:     "5003",
+		Cause:    e,
+		Metadata: map[string]any{"id": id},
+	}
+}
+
+func NewScheduleDeadlineError(id string, e error) error {
+	return &Error{
+		Message:  "schedule deadline error",
+		Code:     "5004",
+		Cause:    e,
+		Metadata: map[string]any{"id": id},
+	}
+}
@@ -0,0 +1,17 @@
+package events
+
+import "github.com/payment-service/internal/saga/domain"
+
+// PaymentEventPayload is the payload of every event emitted by the payment service.
+// It keeps the snake_case naming of the PaymentInit contract consumed by the wallet
+type PaymentEventPayload struct {
+	PaymentID domain.PaymentID `json:"payment_id"`
+	UserID    domain.UserID    `json:"user_id"`
+	Amount    domain.Amount    `json:"amount"`
+	Reason    string           `json:"reason,omitempty"`
+}
+
+type PaymentEvent struct {
+	Header  EventHeader         `json:"header"`
+	Payload PaymentEventPayload `json:"payload"`
+}
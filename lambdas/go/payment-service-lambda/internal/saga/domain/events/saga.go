@@ -0,0 +1,28 @@
+package events
+
+import (
+	"time"
+
+	"github.com/payment-service/internal/saga/domain"
+)
+
+type EventHeader struct {
+	EventID       string    `json:"event_id"`
+	CorrelationID string    `json:"correlation_id"`
+	EventType     string    `json:"event_type"`
+	Timestamp     time.Time `json:"timestamp"`
+	Version       string    `json:"version"`
+}
+
+// SagaEventPayload is the payload shared by the events the saga reacts to.
+// Fields follow the camelCase naming of the wallet and provider gateway events
+type SagaEventPayload struct {
+	PaymentID domain.PaymentID `json:"paymentId"`
+	UserID    domain.UserID    `json:"userId"`
+	Reason    string           `json:"reason,omitempty"`
+}
+
+type SagaEvent struct {
+	Header  EventHeader      `json:"header"`
+	Payload SagaEventPayload `json:"payload"`
+}
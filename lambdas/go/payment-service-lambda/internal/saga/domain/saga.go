@@ -0,0 +1,103 @@
+package domain
+
+var (
+	PaymentInitEventName            Event = "PaymentInit"
+	BalanceDebitedEventName         Event = "BalanceDebited"
+	InsufficientBalanceEventName    Event = "InsufficientBalance"
+	ProviderPaymentSuccessEventName Event = "ProviderPaymentSuccess"
+	ProviderPaymentFailedEventName  Event = "ProviderPaymentFailed"
+	ReembolsarUsuarioEventName      Event = "ReembolsarUsuario"
+	PaymentCompletedEventName       Event = "PaymentCompleted"
+	PaymentFailedEventName          Event = "PaymentFailed"
+	PaymentTimedOutEventName        Event = "PaymentTimedOut"
+	QueryProviderPaymentEventName   Event = "QueryProviderPayment"
+)
+
+const (
+	StateStarted          State = "STARTED"
+	StateAwaitingProvider State = "AWAITING_PROVIDER"
+	StateCompleted        State = "COMPLETED"
+	StateFailed           State = "FAILED"
+)
+
+type (
+	Event     string
+	State     string
+	PaymentID string
+	UserID    string
+	Amount    float64
+)
+
+type transition struct {
+	next State
+	emit []Event
+}
+
+// transitions is the saga state machine: for every state, the events it accepts,
+// the state it moves to and the commands or terminal events to emit
+var transitions = map[State]map[Event]transition{
+	StateStarted: {
+		BalanceDebitedEventName:      {next: StateAwaitingProvider},
+		InsufficientBalanceEventName: {next: StateFailed, emit: []Event{PaymentFailedEventName}},
+	},
+	StateAwaitingProvider: {
+		ProviderPaymentSuccessEventName: {next: StateCompleted, emit: []Event{PaymentCompletedEventName}},
+		ProviderPaymentFailedEventName:  {next: StateFailed, emit: []Event{ReembolsarUsuarioEventName, PaymentFailedEventName}},
+		PaymentTimedOutEventName:        {next: StateFailed, emit: []Event{ReembolsarUsuarioEventName, PaymentFailedEventName}},
+	},
+}
+
+// Saga is the state of a payment. LastEvent is the event that moved it to State and Emitted the
+// events that transition emitted, kept so a redelivery of LastEvent can publish them again
+type Saga struct {
+	PaymentID     PaymentID
+	UserID        UserID
+	Amount        Amount
+	CorrelationID string
+	State         State
+	FailureReason string
+	LastEvent     Event
+	Emitted       []Event
+	Version       int
+}
+
+// Apply moves the saga to the state triggered by event and returns the events to emit
+func (s *Saga) Apply(event Event) ([]Event, error) {
+	t, ok := transitions[s.State][event]
+	if !ok {
+		return nil, NewInvalidTransitionError(string(s.PaymentID), string(s.State), string(event))
+	}
+
+	s.State = t.next
+	s.LastEvent = event
+	s.Emitted = t.emit
+	if t.next == StateFailed {
+		s.FailureReason = string(event)
+	}
+
+	return t.emit, nil
+}
+
+// Redelivered reports whether event is the one already applied to reach the current state, and
+// returns the events its transition emitted
+func (s *Saga) Redelivered(event Event) ([]Event, bool) {
+	if s.LastEvent == "" || s.LastEvent != event {
+		return nil, false
+	}
+	return s.Emitted, true
+}
+
+func (s *Saga) IsTerminal() bool {
+	return s.State == StateCompleted || s.State == StateFailed
+}
+
+func NewSaga(paymentID PaymentID, userID UserID, amount Amount, correlationID string) Saga {
+	return Saga{
+		PaymentID:     paymentID,
+		UserID:        userID,
+		Amount:        amount,
+		CorrelationID: correlationID,
+		State:         StateStarted,
+		Version:       1,
+	}
+}
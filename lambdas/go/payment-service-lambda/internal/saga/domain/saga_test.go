@@ -0,0 +1,75 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/payment-service/internal/saga/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaga_Apply(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		state    domain.State
+		event    domain.Event
+		next     domain.State
+		emit     []domain.Event
+		rejected bool
+	}{
+		{name: "balance debited waits for provider", state: domain.StateStarted, event: domain.BalanceDebitedEventName, next: domain.StateAwaitingProvider},
+		{name: "insufficient balance fails payment", state: domain.StateStarted, event: domain.InsufficientBalanceEventName, next: domain.StateFailed, emit: []domain.Event{domain.PaymentFailedEventName}},
+		{name: "provider success completes payment", state: domain.StateAwaitingProvider, event: domain.ProviderPaymentSuccessEventName, next: domain.StateCompleted, emit: []domain.Event{domain.PaymentCompletedEventName}},
+		{name: "provider failure refunds user", state: domain.StateAwaitingProvider, event: domain.ProviderPaymentFailedEventName, next: domain.StateFailed, emit: []domain.Event{domain.ReembolsarUsuarioEventName, domain.PaymentFailedEventName}},
+		{name: "timed out payment refunds user", state: domain.StateAwaitingProvider, event: domain.PaymentTimedOutEventName, next: domain.StateFailed, emit: []domain.Event{domain.ReembolsarUsuarioEventName, domain.PaymentFailedEventName}},
+		{name: "provider result before debit is rejected", state: domain.StateStarted, event: domain.ProviderPaymentSuccessEventName, rejected: true},
+		{name: "duplicated balance debited is rejected", state: domain.StateAwaitingProvider, event: domain.BalanceDebitedEventName, rejected: true},
+		{name: "completed saga rejects every event", state: domain.StateCompleted, event: domain.ProviderPaymentFailedEventName, rejected: true},
+		{name: "failed saga rejects every event", state: domain.StateFailed, event: domain.ProviderPaymentSuccessEventName, rejected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			saga := domain.NewSaga("pay-1", "user-1", 10, "corr-1")
+			saga.State = tt.state
+
+			// WHEN
+			emit, err := saga.Apply(tt.event)
+
+			// THEN
+			if tt.rejected {
+				assert.True(t, errors.Is(err, domain.ErrInvalidTransition))
+				assert.Equal(t, tt.state, saga.State)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.next, saga.State)
+			assert.Equal(t, tt.emit, emit)
+		})
+	}
+}
+
+func TestSaga_Redelivered(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	saga := domain.NewSaga("pay-1", "user-1", 10, "corr-1")
+	saga.State = domain.StateAwaitingProvider
+	_, err := saga.Apply(domain.ProviderPaymentFailedEventName)
+	assert.NoError(t, err)
+
+	// WHEN
+	emitted, redelivered := saga.Redelivered(domain.ProviderPaymentFailedEventName)
+	_, other := saga.Redelivered(domain.ProviderPaymentSuccessEventName)
+
+	// THEN
+	assert.True(t, redelivered)
+	assert.Equal(t, []domain.Event{domain.ReembolsarUsuarioEventName, domain.PaymentFailedEventName}, emitted)
+	assert.False(t, other)
+}
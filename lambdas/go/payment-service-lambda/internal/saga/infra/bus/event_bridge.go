@@ -0,0 +1,53 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/payment-service/internal/saga/application/ports"
+	"github.com/payment-service/internal/saga/domain/events"
+)
+
+// ConsoleEventBus is a mock implementation of port EventBusProcessor.
+// Simulates event publishing by printing in console. The events carry neither tenant, source nor
+// signature, a wallet verifying them needs another producer
+type ConsoleEventBus struct{}
+
+func (b *ConsoleEventBus) Publish(ctx context.Context, req ports.PaymentEventRequest) error {
+	event := events.PaymentEvent{
+		Header: events.EventHeader{
+			EventID:       uuid.NewString(),
+			EventType:     string(req.EventName),
+			Timestamp:     time.Now().UTC(),
+			Version:       "1",
+			CorrelationID: req.CorrelationID,
+		},
+		Payload: events.PaymentEventPayload{
+			PaymentID: req.PaymentID,
+			UserID:    req.UserID,
+			Amount:    req.Amount,
+			Reason:    req.Reason,
+		},
+	}
+
+	eventJSON, err := json.MarshalIndent(event, "", "  ")
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal event to JSON", "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "--- EVENT PUBLISHED ---", "event", string(eventJSON))
+
+	// real implementation would be like:
+	// _, err := b.eventBridgeClient.PutEvents(...)
+	// return err
+
+	return nil
+}
+
+func NewConsoleEventBus() *ConsoleEventBus {
+	return &ConsoleEventBus{}
+}
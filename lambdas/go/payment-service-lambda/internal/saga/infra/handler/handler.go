@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-service/internal/saga/application"
+	"github.com/payment-service/internal/saga/domain"
+	events2 "github.com/payment-service/internal/saga/domain/events"
+)
+
+var ErrValidation = errors.New("event validation failed")
+
+// sagaEvents are the event types the orchestrator subscribes to
+var sagaEvents = map[domain.Event]bool{
+	domain.BalanceDebitedEventName:         true,
+	domain.InsufficientBalanceEventName:    true,
+	domain.ProviderPaymentSuccessEventName: true,
+	domain.ProviderPaymentFailedEventName:  true,
+}
+
+type UseCase interface {
+	Handle(ctx context.Context, req application.EventRequest) error
+}
+
+type SQSHandler struct {
+	useCase UseCase
+}
+
+func (h *SQSHandler) Handle(ctx context.Context, sqsEvent events.SQSEvent) error {
+	for _, message := range sqsEvent.Records {
+		if err := h.processMessage(ctx, message); err != nil {
+			slog.ErrorContext(
+				ctx,
+				"error processing message, batch will be retried",
+				"messageId", message.MessageId,
+				"error", err,
+			)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *SQSHandler) processMessage(ctx context.Context, message events.SQSMessage) error {
+	slog.InfoContext(ctx, "Processing SQS message", "messageId", message.MessageId)
+
+	var event events2.SagaEvent
+	if err := json.Unmarshal([]byte(message.Body), &event); err != nil {
+		slog.ErrorContext(ctx, "failed to unmarshal message body", "error", err, "body", message.Body)
+		return err
+	}
+
+	logger := slog.With("correlationId", event.Header.CorrelationID)
+
+	if err := h.validate(event); err != nil {
+		logger.ErrorContext(ctx, "event validation failed", "error", err)
+		return nil
+	}
+
+	err := h.useCase.Handle(ctx, toUseCaseRequest(event))
+	if errors.Is(err, domain.ErrInvalidTransition) {
+		// retrying would never succeed: out of order events are dropped, a duplicate of the event
+		// that moved the saga to its state was already published again by the use case
+		logger.WarnContext(ctx, "invalid saga transition, message discarded", "error", err, "eventType", event.Header.EventType)
+		return nil
+	}
+	if errors.Is(err, domain.ErrSagaNotFound) {
+		// a stray or late event of a payment this service never started, no redelivery will find it
+		logger.WarnContext(ctx, "saga not found, message discarded", "error", err, "eventType", event.Header.EventType)
+		return nil
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "use case failed to handle request", "error", err)
+		return err
+	}
+
+	logger.InfoContext(ctx, "Successfully processed message", "messageId", message.MessageId)
+	return nil
+}
+
+func (h *SQSHandler) validate(event events2.SagaEvent) error {
+	if event.Header.CorrelationID == "" {
+		return errors.Join(ErrValidation, errors.New("correlation_id is missing"))
+	}
+	if !sagaEvents[domain.Event(event.Header.EventType)] {
+		return errors.Join(ErrValidation, errors.New("event_type is not supported"))
+	}
+	if event.Payload.PaymentID == "" {
+		return errors.Join(ErrValidation, errors.New("paymentId is missing"))
+	}
+
+	return nil
+}
+
+func toUseCaseRequest(event events2.SagaEvent) application.EventRequest {
+	return application.EventRequest{
+		PaymentID:     event.Payload.PaymentID,
+		Event:         domain.Event(event.Header.EventType),
+		CorrelationID: event.Header.CorrelationID,
+	}
+}
+
+func NewSQSHandler(uc UseCase) *SQSHandler {
+	return &SQSHandler{useCase: uc}
+}
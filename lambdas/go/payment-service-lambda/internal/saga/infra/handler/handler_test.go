@@ -0,0 +1,166 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-service/internal/saga/application"
+	"github.com/payment-service/internal/saga/domain"
+	_events "github.com/payment-service/internal/saga/domain/events"
+	"github.com/payment-service/internal/saga/infra/handler"
+	"github.com/payment-service/internal/saga/infra/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSQSHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should process message successfully", testHandlerSuccessfully)
+	t.Run("should return error when message body is invalid json", testHandlerUnmarshalError)
+	t.Run("should not return error when event type is not supported", testHandlerUnsupportedEvent)
+	t.Run("should not return error when transition is invalid", testHandlerInvalidTransition)
+	t.Run("should not return error when the payment has no saga", testHandlerSagaNotFound)
+	t.Run("should return error when use case fails", testHandlerUseCaseError)
+}
+
+func testHandlerSuccessfully(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	useCaseRequest := application.EventRequest{
+		PaymentID:     "pay-1",
+		Event:         domain.BalanceDebitedEventName,
+		CorrelationID: "corr-id-abc",
+	}
+	sqsEvent := createSQSEvent(t, useCaseRequest.Event, useCaseRequest.PaymentID, useCaseRequest.CorrelationID)
+
+	useCaseMock.EXPECT().Handle(mock.Anything, useCaseRequest).Return(nil).Once()
+
+	h := handler.NewSQSHandler(useCaseMock)
+
+	// WHEN
+	err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testHandlerUnmarshalError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	sqsEvent := events.SQSEvent{
+		Records: []events.SQSMessage{{Body: "this is not json"}},
+	}
+	h := handler.NewSQSHandler(useCaseMock)
+
+	// WHEN
+	err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.Error(t, err)
+}
+
+func testHandlerUnsupportedEvent(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	sqsEvent := createSQSEvent(t, domain.PaymentCompletedEventName, "pay-1", "corr-id-abc")
+	h := handler.NewSQSHandler(useCaseMock)
+
+	// WHEN
+	err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	useCaseMock.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
+}
+
+func testHandlerInvalidTransition(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	sqsEvent := createSQSEvent(t, domain.ProviderPaymentSuccessEventName, "pay-1", "corr-id-abc")
+
+	useCaseMock.EXPECT().Handle(mock.Anything, mock.Anything).
+		Return(domain.NewInvalidTransitionError("pay-1", "COMPLETED", "ProviderPaymentSuccess")).Once()
+
+	h := handler.NewSQSHandler(useCaseMock)
+
+	// WHEN
+	err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testHandlerSagaNotFound(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	sqsEvent := createSQSEvent(t, domain.BalanceDebitedEventName, "pay-unknown", "corr-id-abc")
+
+	useCaseMock.EXPECT().Handle(mock.Anything, mock.Anything).
+		Return(domain.NewGetSagaError("pay-unknown", domain.ErrSagaNotFound)).Once()
+
+	h := handler.NewSQSHandler(useCaseMock)
+
+	// WHEN
+	err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testHandlerUseCaseError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	expectedError := errors.New("something went wrong in the use case")
+	sqsEvent := createSQSEvent(t, domain.ProviderPaymentFailedEventName, "pay-1", "corr-id-abc")
+
+	useCaseMock.EXPECT().Handle(mock.Anything, mock.Anything).Return(expectedError).Once()
+
+	h := handler.NewSQSHandler(useCaseMock)
+
+	// WHEN
+	err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.Equal(t, expectedError, err)
+}
+
+// --- Helper Functions ---
+
+func createSQSEvent(t *testing.T, eventType domain.Event, paymentID domain.PaymentID, corrID string) events.SQSEvent {
+	t.Helper()
+
+	event := _events.SagaEvent{
+		Header:  _events.EventHeader{CorrelationID: corrID, EventType: string(eventType)},
+		Payload: _events.SagaEventPayload{PaymentID: paymentID, UserID: "user-123"},
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+
+	return events.SQSEvent{
+		Records: []events.SQSMessage{
+			{
+				MessageId: "test-message-id",
+				Body:      string(body),
+			},
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type TimeoutUseCase interface {
+	Handle(ctx context.Context) error
+}
+
+// ScheduledHandler is triggered by an EventBridge schedule to fire the payment deadlines that are due
+type ScheduledHandler struct {
+	useCase TimeoutUseCase
+}
+
+func (h *ScheduledHandler) Handle(ctx context.Context, event events.CloudWatchEvent) error {
+	slog.InfoContext(ctx, "Expiring stuck payments", "scheduledEventId", event.ID)
+
+	if err := h.useCase.Handle(ctx); err != nil {
+		slog.ErrorContext(ctx, "error expiring stuck payments, pending deadlines will be retried on next run", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+func NewScheduledHandler(uc TimeoutUseCase) *ScheduledHandler {
+	return &ScheduledHandler{useCase: uc}
+}
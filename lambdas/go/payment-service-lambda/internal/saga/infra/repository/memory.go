@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/payment-service/internal/saga/domain"
+)
+
+var (
+	ErrSagaNotFound      = domain.ErrSagaNotFound
+	ErrSagaAlreadyExists = errors.New("saga already exists")
+	ErrVersionMismatch   = errors.New("optimistic lock failed: version mismatch")
+)
+
+type InMemorySagaRepository struct {
+	mu    sync.Mutex
+	sagas map[domain.PaymentID]domain.Saga
+}
+
+func (r *InMemorySagaRepository) Create(_ context.Context, saga domain.Saga) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.sagas[saga.PaymentID]; ok {
+		return ErrSagaAlreadyExists
+	}
+
+	r.sagas[saga.PaymentID] = saga
+	return nil
+}
+
+func (r *InMemorySagaRepository) Get(_ context.Context, paymentID domain.PaymentID) (domain.Saga, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	saga, ok := r.sagas[paymentID]
+	if !ok {
+		return domain.Saga{}, ErrSagaNotFound
+	}
+
+	return saga, nil
+}
+
+func (r *InMemorySagaRepository) Update(_ context.Context, sagaToUpdate domain.Saga) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	currentSaga, ok := r.sagas[sagaToUpdate.PaymentID]
+	if !ok {
+		return ErrSagaNotFound
+	}
+
+	// Optimistic Blocking
+	if currentSaga.Version != sagaToUpdate.Version {
+		return ErrVersionMismatch
+	}
+
+	sagaToUpdate.Version++
+	r.sagas[sagaToUpdate.PaymentID] = sagaToUpdate
+
+	return nil
+}
+
+func NewInMemorySagaRepository() *InMemorySagaRepository {
+	return &InMemorySagaRepository{sagas: make(map[domain.PaymentID]domain.Saga)}
+}
@@ -0,0 +1,7 @@
+package scheduler
+
+import "time"
+
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now().UTC() }
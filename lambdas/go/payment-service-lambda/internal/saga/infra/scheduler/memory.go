@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/payment-service/internal/saga/domain"
+)
+
+type InMemoryDeadlineScheduler struct {
+	mu        sync.Mutex
+	deadlines map[domain.PaymentID]domain.Deadline
+}
+
+func (s *InMemoryDeadlineScheduler) Schedule(_ context.Context, deadline domain.Deadline) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deadlines[deadline.PaymentID] = deadline
+	return nil
+}
+
+func (s *InMemoryDeadlineScheduler) Cancel(_ context.Context, paymentID domain.PaymentID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.deadlines, paymentID)
+	return nil
+}
+
+// Due returns the deadlines due at now, oldest first. They stay scheduled until cancelled or replaced
+func (s *InMemoryDeadlineScheduler) Due(_ context.Context, now time.Time) ([]domain.Deadline, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []domain.Deadline
+	for _, deadline := range s.deadlines {
+		if !deadline.DueAt.After(now) {
+			due = append(due, deadline)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool { return due[i].DueAt.Before(due[j].DueAt) })
+	return due, nil
+}
+
+func NewInMemoryDeadlineScheduler() *InMemoryDeadlineScheduler {
+	return &InMemoryDeadlineScheduler{deadlines: make(map[domain.PaymentID]domain.Deadline)}
+}
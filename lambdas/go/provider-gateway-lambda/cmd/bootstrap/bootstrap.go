@@ -0,0 +1,23 @@
+package bootstrap
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+)
+
+type LambdaHandler interface {
+	Handle(ctx context.Context, sqsEvent events.SQSEvent) error
+}
+
+func BuildHandler() LambdaHandler {
+	paymentProvider := provideProvider()
+	eventBus := provideEventBus()
+
+	useCase := provideUseCase(paymentProvider, eventBus)
+	queryUseCase := provideQueryUseCase(paymentProvider, eventBus)
+
+	handler := provideHandler(useCase, queryUseCase)
+
+	return handler
+}
@@ -0,0 +1,19 @@
+package bootstrap
+
+import (
+	"github.com/provider-gateway/internal/charge/application"
+	"github.com/provider-gateway/internal/charge/application/ports"
+	"github.com/provider-gateway/internal/charge/infra/handler"
+)
+
+func provideUseCase(provider ports.PaymentProvider, bus ports.EventBusProcessor) *application.UseCaseHandler {
+	return application.NewChargePaymentUseCaseHandler(provider, bus)
+}
+
+func provideQueryUseCase(provider ports.PaymentProvider, bus ports.EventBusProcessor) *application.QueryUseCaseHandler {
+	return application.NewQueryPaymentUseCaseHandler(provider, bus)
+}
+
+func provideHandler(useCase *application.UseCaseHandler, query *application.QueryUseCaseHandler) *handler.SQSHandler {
+	return handler.NewSQSHandler(useCase, query)
+}
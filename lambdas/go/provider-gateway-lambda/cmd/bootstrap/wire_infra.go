@@ -0,0 +1,29 @@
+package bootstrap
+
+import (
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/provider-gateway/internal/charge/application/ports"
+	"github.com/provider-gateway/internal/charge/infra/breaker"
+	"github.com/provider-gateway/internal/charge/infra/bus"
+	"github.com/provider-gateway/internal/charge/infra/provider"
+)
+
+const providerTimeout = 3 * time.Second
+
+func provideProvider() ports.PaymentProvider {
+	var next ports.PaymentProvider = provider.NewApprovingPaymentProvider()
+	if url := os.Getenv("PROVIDER_BASE_URL"); url != "" {
+		next = provider.NewHTTPPaymentProvider(url, &http.Client{}, providerTimeout)
+	}
+
+	// the breaker lives as long as the lambda container, shared by every invocation
+	return breaker.NewPaymentProvider(next, breaker.NewCircuitBreaker(breaker.DefaultSettings(), time.Now))
+}
+
+func provideEventBus() *bus.ConsoleEventBus {
+	// in a real case, we would instance the real client here
+	return bus.NewConsoleEventBus()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/provider-gateway/cmd/bootstrap"
+	_events "github.com/provider-gateway/internal/charge/domain/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLambdaHandler_EndToEnd charges a debited payment through the in-memory adapters
+func TestLambdaHandler_EndToEnd(t *testing.T) {
+	// --- 1. Arrange ---
+	handler := bootstrap.BuildHandler()
+
+	body, err := json.Marshal(_events.BalanceDebitedEvent{
+		Header: _events.EventHeader{CorrelationID: "test-correlation-id-123", EventType: "BalanceDebited"},
+		Payload: _events.BalanceDebitedPayload{
+			PaymentID:     "pay-123",
+			UserID:        "user-123",
+			AmountDebited: 25.50,
+			AmountLeft:    74.50,
+		},
+	})
+	require.NoError(t, err)
+
+	sqsEvent := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "test-message-id", Body: string(body)}}}
+
+	// --- 2. Act ---
+	err = handler.Handle(context.Background(), sqsEvent)
+
+	// --- 3. Assert ---
+	assert.NoError(t, err)
+}
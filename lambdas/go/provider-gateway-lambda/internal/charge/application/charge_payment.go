@@ -0,0 +1,99 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/provider-gateway/internal/charge/application/ports"
+	"github.com/provider-gateway/internal/charge/domain"
+	"github.com/provider-gateway/internal/charge/infra/breaker"
+	"github.com/provider-gateway/internal/charge/infra/provider"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type (
+	Request struct {
+		PaymentID     domain.PaymentID
+		UserID        domain.UserID
+		Amount        domain.Amount
+		Currency      domain.Currency
+		CorrelationID string
+	}
+
+	UseCaseHandler struct {
+		provider       ports.PaymentProvider
+		eventProcessor ports.EventBusProcessor
+	}
+)
+
+// Handle charges the payment with the external provider and publishes its outcome.
+// Provider failures, including an open circuit, end in ProviderPaymentFailed so the saga can compensate
+func (h *UseCaseHandler) Handle(ctx context.Context, req Request) error {
+	tracer := otel.Tracer("provider-gateway.application")
+	ctx, span := tracer.Start(ctx, "UseCase.HandleCharge")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("payment.id", string(req.PaymentID)),
+		attribute.Float64("charge.amount", float64(req.Amount)),
+	)
+
+	slog.InfoContext(ctx, "Handling charge request", "paymentId", req.PaymentID)
+
+	chargeCtx, chargeSpan := tracer.Start(ctx, "Provider.Charge")
+	result, err := h.provider.Charge(chargeCtx, domain.Payment{PaymentID: req.PaymentID, UserID: req.UserID, Amount: req.Amount, Currency: req.Currency})
+	chargeSpan.End()
+
+	event := toProviderResultRequest(req, result, err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Provider charge failed")
+		slog.WarnContext(ctx, "provider charge failed", "paymentId", req.PaymentID, "reason", event.Reason, "error", err)
+	}
+
+	if err = h.eventProcessor.Publish(ctx, event); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Publish event failed")
+		slog.ErrorContext(ctx, "error publishing provider result", "paymentId", req.PaymentID, "error", err)
+		return domain.NewPublishMessageError(string(req.PaymentID), err)
+	}
+
+	slog.InfoContext(ctx, "Finished charge request", "paymentId", req.PaymentID, "event", event.EventName)
+	return nil
+}
+
+func toProviderResultRequest(req Request, result domain.ChargeResult, err error) ports.ProviderResultRequest {
+	event := ports.ProviderResultRequest{
+		PaymentID:     req.PaymentID,
+		UserID:        req.UserID,
+		Amount:        req.Amount,
+		EventName:     domain.ProviderPaymentFailedEventName,
+		CorrelationID: req.CorrelationID,
+	}
+
+	switch {
+	case errors.Is(err, breaker.ErrCircuitOpen):
+		event.Reason = domain.ReasonProviderUnavailable
+	case errors.Is(err, provider.ErrProviderTimeout):
+		event.Reason = domain.ReasonProviderTimeout
+	case err != nil:
+		event.Reason = domain.ReasonProviderError
+	case !result.Approved:
+		event.Reason = domain.ReasonDeclined
+	default:
+		event.EventName = domain.ProviderPaymentSuccessEventName
+		event.ProviderTransactionID = result.ProviderTransactionID
+	}
+
+	return event
+}
+
+func NewChargePaymentUseCaseHandler(p ports.PaymentProvider, bus ports.EventBusProcessor) *UseCaseHandler {
+	return &UseCaseHandler{
+		provider:       p,
+		eventProcessor: bus,
+	}
+}
@@ -0,0 +1,118 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/provider-gateway/internal/charge/application"
+	"github.com/provider-gateway/internal/charge/application/ports"
+	"github.com/provider-gateway/internal/charge/application/ports/mocks"
+	"github.com/provider-gateway/internal/charge/domain"
+	"github.com/provider-gateway/internal/charge/infra/breaker"
+	"github.com/provider-gateway/internal/charge/infra/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUseCaseHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should publish provider payment success when charge is approved", testUseCase_Approved)
+	t.Run("should publish provider payment failed when charge is declined", testUseCase_Declined)
+	t.Run("should publish provider payment failed when circuit is open", testUseCase_CircuitOpen)
+	t.Run("should publish provider payment failed when provider times out", testUseCase_Timeout)
+	t.Run("should return error when event bus fails to publish", testUseCase_EventBusError)
+}
+
+func testUseCase_Approved(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	providerMock := mocks.NewMockPaymentProvider(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 30, CorrelationID: "corr-1"}
+
+	providerMock.EXPECT().Charge(mock.Anything, domain.Payment{PaymentID: "pay-1", UserID: "user-1", Amount: 30}).
+		Return(domain.ChargeResult{Approved: true, ProviderTransactionID: "tx-1"}, nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, ports.ProviderResultRequest{
+		PaymentID:             "pay-1",
+		UserID:                "user-1",
+		Amount:                30,
+		ProviderTransactionID: "tx-1",
+		EventName:             domain.ProviderPaymentSuccessEventName,
+		CorrelationID:         "corr-1",
+	}).Return(nil).Once()
+
+	useCase := application.NewChargePaymentUseCaseHandler(providerMock, busMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testUseCase_Declined(t *testing.T) {
+	t.Parallel()
+
+	assertFailedWithReason(t, domain.ChargeResult{DeclineReason: "limit exceeded"}, nil, domain.ReasonDeclined)
+}
+
+func testUseCase_CircuitOpen(t *testing.T) {
+	t.Parallel()
+
+	assertFailedWithReason(t, domain.ChargeResult{}, breaker.ErrCircuitOpen, domain.ReasonProviderUnavailable)
+}
+
+func testUseCase_Timeout(t *testing.T) {
+	t.Parallel()
+
+	assertFailedWithReason(t, domain.ChargeResult{}, errors.Join(provider.ErrProviderTimeout, context.DeadlineExceeded), domain.ReasonProviderTimeout)
+}
+
+func testUseCase_EventBusError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	providerMock := mocks.NewMockPaymentProvider(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 30, CorrelationID: "corr-1"}
+
+	providerMock.EXPECT().Charge(mock.Anything, mock.Anything).Return(domain.ChargeResult{Approved: true}, nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("eventbridge is down")).Once()
+
+	useCase := application.NewChargePaymentUseCaseHandler(providerMock, busMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	var domainErr *domain.Error
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "5003", domainErr.Code)
+}
+
+// --- Helper Functions ---
+
+func assertFailedWithReason(t *testing.T, result domain.ChargeResult, chargeErr error, reason string) {
+	t.Helper()
+
+	// GIVEN
+	providerMock := mocks.NewMockPaymentProvider(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 30, CorrelationID: "corr-1"}
+
+	providerMock.EXPECT().Charge(mock.Anything, mock.Anything).Return(result, chargeErr).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(r ports.ProviderResultRequest) bool {
+		return r.EventName == domain.ProviderPaymentFailedEventName && r.Reason == reason && r.PaymentID == "pay-1"
+	})).Return(nil).Once()
+
+	useCase := application.NewChargePaymentUseCaseHandler(providerMock, busMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/provider-gateway/internal/charge/domain"
+)
+
+type ProviderResultRequest struct {
+	PaymentID             domain.PaymentID
+	UserID                domain.UserID
+	Amount                domain.Amount
+	ProviderTransactionID string
+	Reason                string
+	EventName             domain.Event
+	CorrelationID         string
+}
+
+type EventBusProcessor interface {
+	Publish(context.Context, ProviderResultRequest) error
+}
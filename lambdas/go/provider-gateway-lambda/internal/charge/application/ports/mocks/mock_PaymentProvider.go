@@ -0,0 +1,171 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/provider-gateway/internal/charge/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockPaymentProvider creates a new instance of MockPaymentProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockPaymentProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockPaymentProvider {
+	mock := &MockPaymentProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockPaymentProvider is an autogenerated mock type for the PaymentProvider type
+type MockPaymentProvider struct {
+	mock.Mock
+}
+
+type MockPaymentProvider_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockPaymentProvider) EXPECT() *MockPaymentProvider_Expecter {
+	return &MockPaymentProvider_Expecter{mock: &_m.Mock}
+}
+
+// Charge provides a mock function for the type MockPaymentProvider
+func (_mock *MockPaymentProvider) Charge(context1 context.Context, payment domain.Payment) (domain.ChargeResult, error) {
+	ret := _mock.Called(context1, payment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Charge")
+	}
+
+	var r0 domain.ChargeResult
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Payment) (domain.ChargeResult, error)); ok {
+		return returnFunc(context1, payment)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Payment) domain.ChargeResult); ok {
+		r0 = returnFunc(context1, payment)
+	} else {
+		r0 = ret.Get(0).(domain.ChargeResult)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, domain.Payment) error); ok {
+		r1 = returnFunc(context1, payment)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPaymentProvider_Charge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Charge'
+type MockPaymentProvider_Charge_Call struct {
+	*mock.Call
+}
+
+// Charge is a helper method to define mock.On call
+//   - context1 context.Context
+//   - payment domain.Payment
+func (_e *MockPaymentProvider_Expecter) Charge(context1 interface{}, payment interface{}) *MockPaymentProvider_Charge_Call {
+	return &MockPaymentProvider_Charge_Call{Call: _e.mock.On("Charge", context1, payment)}
+}
+
+func (_c *MockPaymentProvider_Charge_Call) Run(run func(context1 context.Context, payment domain.Payment)) *MockPaymentProvider_Charge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.Payment
+		if args[1] != nil {
+			arg1 = args[1].(domain.Payment)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPaymentProvider_Charge_Call) Return(chargeResult domain.ChargeResult, err error) *MockPaymentProvider_Charge_Call {
+	_c.Call.Return(chargeResult, err)
+	return _c
+}
+
+func (_c *MockPaymentProvider_Charge_Call) RunAndReturn(run func(context1 context.Context, payment domain.Payment) (domain.ChargeResult, error)) *MockPaymentProvider_Charge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Status provides a mock function for the type MockPaymentProvider
+func (_mock *MockPaymentProvider) Status(context1 context.Context, paymentID domain.PaymentID) (domain.ChargeResult, error) {
+	ret := _mock.Called(context1, paymentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Status")
+	}
+
+	var r0 domain.ChargeResult
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.PaymentID) (domain.ChargeResult, error)); ok {
+		return returnFunc(context1, paymentID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.PaymentID) domain.ChargeResult); ok {
+		r0 = returnFunc(context1, paymentID)
+	} else {
+		r0 = ret.Get(0).(domain.ChargeResult)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, domain.PaymentID) error); ok {
+		r1 = returnFunc(context1, paymentID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockPaymentProvider_Status_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Status'
+type MockPaymentProvider_Status_Call struct {
+	*mock.Call
+}
+
+// Status is a helper method to define mock.On call
+//   - context1 context.Context
+//   - paymentID domain.PaymentID
+func (_e *MockPaymentProvider_Expecter) Status(context1 interface{}, paymentID interface{}) *MockPaymentProvider_Status_Call {
+	return &MockPaymentProvider_Status_Call{Call: _e.mock.On("Status", context1, paymentID)}
+}
+
+func (_c *MockPaymentProvider_Status_Call) Run(run func(context1 context.Context, paymentID domain.PaymentID)) *MockPaymentProvider_Status_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.PaymentID
+		if args[1] != nil {
+			arg1 = args[1].(domain.PaymentID)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockPaymentProvider_Status_Call) Return(chargeResult domain.ChargeResult, err error) *MockPaymentProvider_Status_Call {
+	_c.Call.Return(chargeResult, err)
+	return _c
+}
+
+func (_c *MockPaymentProvider_Status_Call) RunAndReturn(run func(context1 context.Context, paymentID domain.PaymentID) (domain.ChargeResult, error)) *MockPaymentProvider_Status_Call {
+	_c.Call.Return(run)
+	return _c
+}
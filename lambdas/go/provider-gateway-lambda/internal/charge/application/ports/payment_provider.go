@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/provider-gateway/internal/charge/domain"
+)
+
+// PaymentProvider is the external payment provider. A declined charge is a result,
+// only technical failures are returned as errors. Status answers the result of a previous
+// charge, or domain.ErrChargeNotFound when the provider never received it
+type PaymentProvider interface {
+	Charge(context.Context, domain.Payment) (domain.ChargeResult, error)
+	Status(context.Context, domain.PaymentID) (domain.ChargeResult, error)
+}
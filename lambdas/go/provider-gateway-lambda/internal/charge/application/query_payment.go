@@ -0,0 +1,67 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/provider-gateway/internal/charge/application/ports"
+	"github.com/provider-gateway/internal/charge/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type QueryUseCaseHandler struct {
+	provider       ports.PaymentProvider
+	eventProcessor ports.EventBusProcessor
+}
+
+// Handle asks the provider for the outcome of a payment whose result never reached the saga and
+// publishes it again. A charge unknown to the provider ends in ProviderPaymentFailed. When the
+// provider cannot answer nothing is published, the saga compensates once its next deadline passes
+func (h *QueryUseCaseHandler) Handle(ctx context.Context, req Request) error {
+	tracer := otel.Tracer("provider-gateway.application")
+	ctx, span := tracer.Start(ctx, "UseCase.HandleQuery")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("payment.id", string(req.PaymentID)))
+
+	slog.InfoContext(ctx, "Handling provider payment query", "paymentId", req.PaymentID)
+
+	statusCtx, statusSpan := tracer.Start(ctx, "Provider.Status")
+	result, err := h.provider.Status(statusCtx, req.PaymentID)
+	statusSpan.End()
+
+	var event ports.ProviderResultRequest
+	switch {
+	case errors.Is(err, domain.ErrChargeNotFound):
+		event = toProviderResultRequest(req, result, nil)
+		event.EventName = domain.ProviderPaymentFailedEventName
+		event.Reason = domain.ReasonNotCharged
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Provider status failed")
+		slog.WarnContext(ctx, "provider status failed, leaving the payment to its deadline", "paymentId", req.PaymentID, "error", err)
+		return nil
+	default:
+		event = toProviderResultRequest(req, result, nil)
+	}
+
+	if err = h.eventProcessor.Publish(ctx, event); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Publish event failed")
+		slog.ErrorContext(ctx, "error publishing provider result", "paymentId", req.PaymentID, "error", err)
+		return domain.NewPublishMessageError(string(req.PaymentID), err)
+	}
+
+	slog.InfoContext(ctx, "Finished provider payment query", "paymentId", req.PaymentID, "event", event.EventName)
+	return nil
+}
+
+func NewQueryPaymentUseCaseHandler(p ports.PaymentProvider, bus ports.EventBusProcessor) *QueryUseCaseHandler {
+	return &QueryUseCaseHandler{
+		provider:       p,
+		eventProcessor: bus,
+	}
+}
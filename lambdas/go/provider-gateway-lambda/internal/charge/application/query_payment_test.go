@@ -0,0 +1,129 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/provider-gateway/internal/charge/application"
+	"github.com/provider-gateway/internal/charge/application/ports"
+	"github.com/provider-gateway/internal/charge/application/ports/mocks"
+	"github.com/provider-gateway/internal/charge/domain"
+	"github.com/provider-gateway/internal/charge/infra/breaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestQueryUseCaseHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should publish provider payment success when the charge was approved", testQuery_Approved)
+	t.Run("should publish provider payment failed when the charge was declined", testQuery_Declined)
+	t.Run("should publish provider payment failed when the provider never got the charge", testQuery_NotCharged)
+	t.Run("should publish nothing when the provider cannot answer", testQuery_ProviderUnavailable)
+	t.Run("should return error when event bus fails to publish", testQuery_EventBusError)
+}
+
+func testQuery_Approved(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	providerMock := mocks.NewMockPaymentProvider(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+
+	providerMock.EXPECT().Status(mock.Anything, domain.PaymentID("pay-1")).
+		Return(domain.ChargeResult{Approved: true, ProviderTransactionID: "tx-1"}, nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, ports.ProviderResultRequest{
+		PaymentID:             "pay-1",
+		UserID:                "user-1",
+		Amount:                30,
+		ProviderTransactionID: "tx-1",
+		EventName:             domain.ProviderPaymentSuccessEventName,
+		CorrelationID:         "corr-1",
+	}).Return(nil).Once()
+
+	useCase := application.NewQueryPaymentUseCaseHandler(providerMock, busMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), queryRequest)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testQuery_Declined(t *testing.T) {
+	t.Parallel()
+
+	assertQueryFailedWithReason(t, domain.ChargeResult{DeclineReason: "limit exceeded"}, nil, domain.ReasonDeclined)
+}
+
+func testQuery_NotCharged(t *testing.T) {
+	t.Parallel()
+
+	assertQueryFailedWithReason(t, domain.ChargeResult{}, domain.ErrChargeNotFound, domain.ReasonNotCharged)
+}
+
+func testQuery_ProviderUnavailable(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	providerMock := mocks.NewMockPaymentProvider(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+
+	providerMock.EXPECT().Status(mock.Anything, mock.Anything).Return(domain.ChargeResult{}, breaker.ErrCircuitOpen).Once()
+
+	useCase := application.NewQueryPaymentUseCaseHandler(providerMock, busMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), queryRequest)
+
+	// THEN
+	assert.NoError(t, err)
+	busMock.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func testQuery_EventBusError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	providerMock := mocks.NewMockPaymentProvider(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+
+	providerMock.EXPECT().Status(mock.Anything, mock.Anything).Return(domain.ChargeResult{Approved: true}, nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("eventbridge is down")).Once()
+
+	useCase := application.NewQueryPaymentUseCaseHandler(providerMock, busMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), queryRequest)
+
+	// THEN
+	var domainErr *domain.Error
+	assert.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "5003", domainErr.Code)
+}
+
+// --- Helper Functions ---
+
+var queryRequest = application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 30, CorrelationID: "corr-1"}
+
+func assertQueryFailedWithReason(t *testing.T, result domain.ChargeResult, statusErr error, reason string) {
+	t.Helper()
+
+	// GIVEN
+	providerMock := mocks.NewMockPaymentProvider(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+
+	providerMock.EXPECT().Status(mock.Anything, mock.Anything).Return(result, statusErr).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(r ports.ProviderResultRequest) bool {
+		return r.EventName == domain.ProviderPaymentFailedEventName && r.Reason == reason && r.PaymentID == "pay-1"
+	})).Return(nil).Once()
+
+	useCase := application.NewQueryPaymentUseCaseHandler(providerMock, busMock)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), queryRequest)
+
+	// THEN
+	assert.NoError(t, err)
+}
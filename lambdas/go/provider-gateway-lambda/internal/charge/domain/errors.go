@@ -0,0 +1,25 @@
+package domain
+
+import "errors"
+
+// ErrChargeNotFound is answered by the provider when it has no charge for the payment
+var ErrChargeNotFound = errors.New("charge not found")
+
+type Error struct {
+	Message  string
+	Code     string
+	Cause    error
+	Metadata map[string]any
+}
+
+func (e *Error) Error() string { return e.Message }
+func (e *Error) Unwrap() error { return e.Cause }
+
+func NewPublishMessageError(id string, e error) error {
+	return &Error{
+		Message:  "publish message error",
+		Code:     "5003",
+		Cause:    e,
+		Metadata: map[string]any{"id": id},
+	}
+}
@@ -0,0 +1,33 @@
+package events
+
+import (
+	"time"
+
+	"github.com/provider-gateway/internal/charge/domain"
+)
+
+type EventHeader struct {
+	EventID       string    `json:"event_id"`
+	CorrelationID string    `json:"correlation_id"`
+	EventType     string    `json:"event_type"`
+	Timestamp     time.Time `json:"timestamp"`
+	Version       string    `json:"version"`
+}
+
+// BalanceDebitedPayload mirrors the event published by the wallet service. AmountDebited includes
+// the fee the wallet service keeps, Principal is the payment alone. A payment sent in another
+// currency than the wallet one carries it as sent in OriginalAmount and OriginalCurrency
+type BalanceDebitedPayload struct {
+	PaymentID        domain.PaymentID `json:"paymentId"`
+	UserID           domain.UserID    `json:"userId"`
+	AmountDebited    domain.Amount    `json:"amountDebited"`
+	Principal        domain.Amount    `json:"principal"`
+	AmountLeft       domain.Amount    `json:"amountLeft"`
+	OriginalAmount   domain.Amount    `json:"originalAmount,omitempty"`
+	OriginalCurrency domain.Currency  `json:"originalCurrency,omitempty"`
+}
+
+type BalanceDebitedEvent struct {
+	Header  EventHeader           `json:"header"`
+	Payload BalanceDebitedPayload `json:"payload"`
+}
@@ -0,0 +1,16 @@
+package events
+
+import "github.com/provider-gateway/internal/charge/domain"
+
+type ProviderPaymentPayload struct {
+	PaymentID             domain.PaymentID `json:"paymentId"`
+	UserID                domain.UserID    `json:"userId"`
+	Amount                domain.Amount    `json:"amount"`
+	ProviderTransactionID string           `json:"providerTransactionId,omitempty"`
+	Reason                string           `json:"reason,omitempty"`
+}
+
+type ProviderPaymentEvent struct {
+	Header  EventHeader            `json:"header"`
+	Payload ProviderPaymentPayload `json:"payload"`
+}
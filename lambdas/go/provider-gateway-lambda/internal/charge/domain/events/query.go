@@ -0,0 +1,16 @@
+package events
+
+import "github.com/provider-gateway/internal/charge/domain"
+
+// QueryProviderPaymentPayload mirrors the command published by the payment service
+// when a payment waits for the provider past its deadline
+type QueryProviderPaymentPayload struct {
+	PaymentID domain.PaymentID `json:"payment_id"`
+	UserID    domain.UserID    `json:"user_id"`
+	Amount    domain.Amount    `json:"amount"`
+}
+
+type QueryProviderPaymentEvent struct {
+	Header  EventHeader                 `json:"header"`
+	Payload QueryProviderPaymentPayload `json:"payload"`
+}
@@ -0,0 +1,39 @@
+package domain
+
+var (
+	ProviderPaymentSuccessEventName Event = "ProviderPaymentSuccess"
+	ProviderPaymentFailedEventName  Event = "ProviderPaymentFailed"
+
+	QueryProviderPaymentEventName Event = "QueryProviderPayment"
+)
+
+const (
+	ReasonDeclined            = "declined"
+	ReasonProviderUnavailable = "provider_unavailable"
+	ReasonProviderTimeout     = "provider_timeout"
+	ReasonProviderError       = "provider_error"
+	ReasonNotCharged          = "not_charged"
+)
+
+type (
+	Event     string
+	PaymentID string
+	UserID    string
+	Amount    float64
+	// Currency is an ISO 4217 code, empty for the currency of the wallets
+	Currency string
+)
+
+type Payment struct {
+	PaymentID PaymentID
+	UserID    UserID
+	Amount    Amount
+	Currency  Currency
+}
+
+// ChargeResult is the answer of the provider for a charge it was able to process
+type ChargeResult struct {
+	Approved              bool
+	ProviderTransactionID string
+	DeclineReason         string
+}
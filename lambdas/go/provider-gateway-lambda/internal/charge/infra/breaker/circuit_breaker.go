@@ -0,0 +1,171 @@
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half-open"
+)
+
+type Settings struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before letting probes through
+	OpenTimeout time.Duration
+	// HalfOpenMaxCalls is the number of concurrent probes allowed while half-open
+	HalfOpenMaxCalls int
+	// SuccessThreshold is the number of consecutive successful probes that closes the circuit
+	SuccessThreshold int
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenMaxCalls: 1,
+		SuccessThreshold: 1,
+	}
+}
+
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	settings  Settings
+	state     State
+	failures  int
+	successes int
+	inFlight  int
+	openedAt  time.Time
+	now       func() time.Time
+	// generation changes with every state transition, so calls admitted in a previous state
+	// do not count towards the current one
+	generation uint64
+}
+
+// Execute runs fn unless the circuit is open, in which case ErrCircuitOpen is returned without calling it
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	generation, err := cb.before()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	cb.after(generation, err == nil)
+
+	return err
+}
+
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.refresh()
+	return cb.state
+}
+
+// before admits a call and returns the generation it was admitted in
+func (cb *CircuitBreaker) before() (uint64, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.refresh()
+
+	switch cb.state {
+	case StateOpen:
+		return 0, ErrCircuitOpen
+	case StateHalfOpen:
+		if cb.inFlight >= cb.settings.HalfOpenMaxCalls {
+			return 0, ErrCircuitOpen
+		}
+		cb.inFlight++
+	}
+
+	return cb.generation, nil
+}
+
+// after records the result of a call. Results of calls admitted before the last transition are
+// stale: a call started while closed must not count as a probe nor release a probe slot
+func (cb *CircuitBreaker) after(generation uint64, success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation != cb.generation {
+		return
+	}
+
+	switch cb.state {
+	case StateClosed:
+		if success {
+			cb.failures = 0
+			return
+		}
+		cb.failures++
+		if cb.failures >= cb.settings.FailureThreshold {
+			cb.open()
+		}
+	case StateHalfOpen:
+		cb.inFlight--
+		if !success {
+			cb.open()
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.settings.SuccessThreshold {
+			cb.close()
+		}
+	}
+}
+
+// refresh moves an open circuit to half-open once its timeout has elapsed
+func (cb *CircuitBreaker) refresh() {
+	if cb.state == StateOpen && cb.now().Sub(cb.openedAt) >= cb.settings.OpenTimeout {
+		cb.state = StateHalfOpen
+		cb.successes = 0
+		cb.inFlight = 0
+		cb.generation++
+	}
+}
+
+func (cb *CircuitBreaker) open() {
+	cb.state = StateOpen
+	cb.openedAt = cb.now()
+	cb.failures = 0
+	cb.successes = 0
+	cb.generation++
+}
+
+func (cb *CircuitBreaker) close() {
+	cb.state = StateClosed
+	cb.failures = 0
+	cb.successes = 0
+	cb.generation++
+}
+
+func NewCircuitBreaker(settings Settings, now func() time.Time) *CircuitBreaker {
+	defaults := DefaultSettings()
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = defaults.FailureThreshold
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = defaults.OpenTimeout
+	}
+	if settings.HalfOpenMaxCalls <= 0 {
+		settings.HalfOpenMaxCalls = defaults.HalfOpenMaxCalls
+	}
+	if settings.SuccessThreshold <= 0 {
+		settings.SuccessThreshold = defaults.SuccessThreshold
+	}
+	if now == nil {
+		now = time.Now
+	}
+
+	return &CircuitBreaker{settings: settings, state: StateClosed, now: now}
+}
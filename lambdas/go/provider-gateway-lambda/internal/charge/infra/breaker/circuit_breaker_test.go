@@ -0,0 +1,222 @@
+package breaker_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/provider-gateway/internal/charge/infra/breaker"
+	"github.com/stretchr/testify/assert"
+)
+
+var errBoom = errors.New("boom")
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should open after consecutive failures", testBreakerOpens)
+	t.Run("should reset failure count after a success", testBreakerResetsOnSuccess)
+	t.Run("should short-circuit while open", testBreakerShortCircuits)
+	t.Run("should close after a successful probe in half-open", testBreakerHalfOpenSuccess)
+	t.Run("should reopen after a failed probe in half-open", testBreakerHalfOpenFailure)
+	t.Run("should limit concurrent probes in half-open", testBreakerHalfOpenMaxCalls)
+	t.Run("should ignore calls admitted before the circuit went half-open", testBreakerStaleResult)
+}
+
+func testBreakerOpens(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cb := breaker.NewCircuitBreaker(breaker.Settings{FailureThreshold: 3, OpenTimeout: time.Minute}, newClock().Now)
+
+	// WHEN
+	for i := 0; i < 3; i++ {
+		_ = cb.Execute(fail)
+	}
+
+	// THEN
+	assert.Equal(t, breaker.StateOpen, cb.State())
+}
+
+func testBreakerResetsOnSuccess(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cb := breaker.NewCircuitBreaker(breaker.Settings{FailureThreshold: 3, OpenTimeout: time.Minute}, newClock().Now)
+
+	// WHEN
+	_ = cb.Execute(fail)
+	_ = cb.Execute(fail)
+	_ = cb.Execute(succeed)
+	_ = cb.Execute(fail)
+	_ = cb.Execute(fail)
+
+	// THEN
+	assert.Equal(t, breaker.StateClosed, cb.State())
+}
+
+func testBreakerShortCircuits(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	cb := breaker.NewCircuitBreaker(breaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute}, newClock().Now)
+	_ = cb.Execute(fail)
+
+	called := false
+
+	// WHEN
+	err := cb.Execute(func() error {
+		called = true
+		return nil
+	})
+
+	// THEN
+	assert.ErrorIs(t, err, breaker.ErrCircuitOpen)
+	assert.False(t, called)
+}
+
+func testBreakerHalfOpenSuccess(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	cb := breaker.NewCircuitBreaker(breaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute, SuccessThreshold: 2}, clock.Now)
+	_ = cb.Execute(fail)
+	clock.Advance(time.Minute)
+
+	// WHEN
+	assert.Equal(t, breaker.StateHalfOpen, cb.State())
+	assert.NoError(t, cb.Execute(succeed))
+	assert.Equal(t, breaker.StateHalfOpen, cb.State())
+	assert.NoError(t, cb.Execute(succeed))
+
+	// THEN
+	assert.Equal(t, breaker.StateClosed, cb.State())
+}
+
+func testBreakerHalfOpenFailure(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	cb := breaker.NewCircuitBreaker(breaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute}, clock.Now)
+	_ = cb.Execute(fail)
+	clock.Advance(time.Minute)
+
+	// WHEN
+	err := cb.Execute(fail)
+
+	// THEN
+	assert.ErrorIs(t, err, errBoom)
+	assert.Equal(t, breaker.StateOpen, cb.State())
+	assert.ErrorIs(t, cb.Execute(succeed), breaker.ErrCircuitOpen)
+}
+
+func testBreakerHalfOpenMaxCalls(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	cb := breaker.NewCircuitBreaker(breaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenMaxCalls: 1}, clock.Now)
+	_ = cb.Execute(fail)
+	clock.Advance(time.Minute)
+
+	probing := make(chan struct{})
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = cb.Execute(func() error {
+			close(probing)
+			<-release
+			return nil
+		})
+	}()
+	<-probing
+
+	// WHEN
+	err := cb.Execute(succeed)
+
+	// THEN
+	assert.ErrorIs(t, err, breaker.ErrCircuitOpen)
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, breaker.StateClosed, cb.State())
+}
+
+func testBreakerStaleResult(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	cb := breaker.NewCircuitBreaker(breaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenMaxCalls: 1}, clock.Now)
+
+	releaseStale := blockedCall(cb, nil)
+	_ = cb.Execute(fail)
+	clock.Advance(time.Minute)
+	releaseProbe := blockedCall(cb, errBoom)
+
+	// WHEN
+	releaseStale()
+
+	// THEN
+	assert.Equal(t, breaker.StateHalfOpen, cb.State())
+	assert.ErrorIs(t, cb.Execute(succeed), breaker.ErrCircuitOpen)
+
+	releaseProbe()
+	assert.Equal(t, breaker.StateOpen, cb.State())
+}
+
+// --- Helper Functions ---
+
+func fail() error    { return errBoom }
+func succeed() error { return nil }
+
+// blockedCall starts a call returning result and waits until it is running. The returned
+// release lets it finish and waits for its result to be recorded
+func blockedCall(cb *breaker.CircuitBreaker, result error) func() {
+	running := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = cb.Execute(func() error {
+			close(running)
+			<-release
+			return result
+		})
+	}()
+	<-running
+
+	return func() {
+		close(release)
+		<-done
+	}
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,56 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/provider-gateway/internal/charge/application/ports"
+	"github.com/provider-gateway/internal/charge/domain"
+)
+
+// PaymentProvider decorates a ports.PaymentProvider with a circuit breaker.
+// Declined charges are valid answers and do not count as failures
+type PaymentProvider struct {
+	next    ports.PaymentProvider
+	breaker *CircuitBreaker
+}
+
+func (p *PaymentProvider) Charge(ctx context.Context, payment domain.Payment) (domain.ChargeResult, error) {
+	var result domain.ChargeResult
+
+	err := p.breaker.Execute(func() error {
+		var err error
+		result, err = p.next.Charge(ctx, payment)
+		return err
+	})
+
+	return result, err
+}
+
+// Status is guarded by the same circuit as Charge. A charge the provider does not know
+// is a valid answer and does not count as a failure
+func (p *PaymentProvider) Status(ctx context.Context, paymentID domain.PaymentID) (domain.ChargeResult, error) {
+	var (
+		result   domain.ChargeResult
+		notFound bool
+	)
+
+	err := p.breaker.Execute(func() error {
+		var err error
+		result, err = p.next.Status(ctx, paymentID)
+		if errors.Is(err, domain.ErrChargeNotFound) {
+			notFound = true
+			return nil
+		}
+		return err
+	})
+	if notFound {
+		return domain.ChargeResult{}, domain.ErrChargeNotFound
+	}
+
+	return result, err
+}
+
+func NewPaymentProvider(next ports.PaymentProvider, cb *CircuitBreaker) *PaymentProvider {
+	return &PaymentProvider{next: next, breaker: cb}
+}
@@ -0,0 +1,53 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/provider-gateway/internal/charge/application/ports"
+	"github.com/provider-gateway/internal/charge/domain/events"
+)
+
+// ConsoleEventBus is a mock implementation of port EventBusProcessor.
+// Simulates event publishing by printing in console
+type ConsoleEventBus struct{}
+
+func (b *ConsoleEventBus) Publish(ctx context.Context, req ports.ProviderResultRequest) error {
+	event := events.ProviderPaymentEvent{
+		Header: events.EventHeader{
+			EventID:       uuid.NewString(),
+			EventType:     string(req.EventName),
+			Timestamp:     time.Now().UTC(),
+			Version:       "1",
+			CorrelationID: req.CorrelationID,
+		},
+		Payload: events.ProviderPaymentPayload{
+			PaymentID:             req.PaymentID,
+			UserID:                req.UserID,
+			Amount:                req.Amount,
+			ProviderTransactionID: req.ProviderTransactionID,
+			Reason:                req.Reason,
+		},
+	}
+
+	eventJSON, err := json.MarshalIndent(event, "", "  ")
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal event to JSON", "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "--- EVENT PUBLISHED ---", "event", string(eventJSON))
+
+	// real implementation would be like:
+	// _, err := b.eventBridgeClient.PutEvents(...)
+	// return err
+
+	return nil
+}
+
+func NewConsoleEventBus() *ConsoleEventBus {
+	return &ConsoleEventBus{}
+}
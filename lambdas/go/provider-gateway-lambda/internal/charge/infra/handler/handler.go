@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/provider-gateway/internal/charge/application"
+	"github.com/provider-gateway/internal/charge/domain"
+	events2 "github.com/provider-gateway/internal/charge/domain/events"
+)
+
+var ErrValidation = errors.New("event validation failed")
+
+type UseCase interface {
+	Handle(ctx context.Context, req application.Request) error
+}
+
+// QueryUseCase answers the QueryProviderPayment commands of the payment service
+type QueryUseCase interface {
+	Handle(ctx context.Context, req application.Request) error
+}
+
+type SQSHandler struct {
+	useCase      UseCase
+	queryUseCase QueryUseCase
+}
+
+func (h *SQSHandler) Handle(ctx context.Context, sqsEvent events.SQSEvent) error {
+	for _, message := range sqsEvent.Records {
+		if err := h.processMessage(ctx, message); err != nil {
+			slog.ErrorContext(
+				ctx,
+				"error processing message, batch will be retried",
+				"messageId", message.MessageId,
+				"error", err,
+			)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (h *SQSHandler) processMessage(ctx context.Context, message events.SQSMessage) error {
+	slog.InfoContext(ctx, "Processing SQS message", "messageId", message.MessageId)
+
+	var envelope struct {
+		Header events2.EventHeader `json:"header"`
+	}
+	if err := json.Unmarshal([]byte(message.Body), &envelope); err != nil {
+		slog.ErrorContext(ctx, "failed to unmarshal message body", "error", err, "body", message.Body)
+		return err
+	}
+
+	if domain.Event(envelope.Header.EventType) == domain.QueryProviderPaymentEventName {
+		return h.processQuery(ctx, message)
+	}
+
+	var event events2.BalanceDebitedEvent
+	if err := json.Unmarshal([]byte(message.Body), &event); err != nil {
+		slog.ErrorContext(ctx, "failed to unmarshal message body", "error", err, "body", message.Body)
+		return err
+	}
+
+	logger := slog.With("correlationId", event.Header.CorrelationID)
+
+	if err := h.validate(event); err != nil {
+		logger.ErrorContext(ctx, "event validation failed", "error", err)
+		return nil
+	}
+
+	if err := h.useCase.Handle(ctx, toUseCaseRequest(event)); err != nil {
+		logger.ErrorContext(ctx, "use case failed to handle request", "error", err)
+		return err
+	}
+
+	logger.InfoContext(ctx, "Successfully processed message", "messageId", message.MessageId)
+	return nil
+}
+
+func (h *SQSHandler) processQuery(ctx context.Context, message events.SQSMessage) error {
+	var event events2.QueryProviderPaymentEvent
+	if err := json.Unmarshal([]byte(message.Body), &event); err != nil {
+		slog.ErrorContext(ctx, "failed to unmarshal message body", "error", err, "body", message.Body)
+		return err
+	}
+
+	logger := slog.With("correlationId", event.Header.CorrelationID)
+
+	if event.Header.CorrelationID == "" || event.Payload.PaymentID == "" {
+		logger.ErrorContext(ctx, "event validation failed", "error", errors.Join(ErrValidation, errors.New("correlation_id or payment_id is missing")))
+		return nil
+	}
+
+	req := application.Request{
+		PaymentID:     event.Payload.PaymentID,
+		UserID:        event.Payload.UserID,
+		Amount:        event.Payload.Amount,
+		CorrelationID: event.Header.CorrelationID,
+	}
+	if err := h.queryUseCase.Handle(ctx, req); err != nil {
+		logger.ErrorContext(ctx, "query use case failed to handle request", "error", err)
+		return err
+	}
+
+	logger.InfoContext(ctx, "Successfully processed message", "messageId", message.MessageId)
+	return nil
+}
+
+func (h *SQSHandler) validate(event events2.BalanceDebitedEvent) error {
+	if event.Header.CorrelationID == "" {
+		return errors.Join(ErrValidation, errors.New("correlation_id is missing"))
+	}
+	if event.Payload.PaymentID == "" {
+		return errors.Join(ErrValidation, errors.New("paymentId is missing"))
+	}
+	if event.Payload.AmountDebited <= 0 {
+		return errors.Join(ErrValidation, errors.New("amountDebited must be positive"))
+	}
+
+	return nil
+}
+
+// toUseCaseRequest charges the payment as the user sent it: the fee debited on top stays with the
+// wallet service, and a converted payment is charged in its original currency. Events of wallet
+// services predating the principal only carry AmountDebited
+func toUseCaseRequest(event events2.BalanceDebitedEvent) application.Request {
+	req := application.Request{
+		PaymentID:     event.Payload.PaymentID,
+		UserID:        event.Payload.UserID,
+		Amount:        event.Payload.Principal,
+		CorrelationID: event.Header.CorrelationID,
+	}
+	switch {
+	case event.Payload.OriginalCurrency != "":
+		req.Amount = event.Payload.OriginalAmount
+		req.Currency = event.Payload.OriginalCurrency
+	case event.Payload.Principal == 0:
+		req.Amount = event.Payload.AmountDebited
+	}
+
+	return req
+}
+
+func NewSQSHandler(uc UseCase, query QueryUseCase) *SQSHandler {
+	return &SQSHandler{useCase: uc, queryUseCase: query}
+}
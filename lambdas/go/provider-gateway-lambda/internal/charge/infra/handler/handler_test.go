@@ -0,0 +1,224 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/provider-gateway/internal/charge/application"
+	"github.com/provider-gateway/internal/charge/domain"
+	_events "github.com/provider-gateway/internal/charge/domain/events"
+	"github.com/provider-gateway/internal/charge/infra/handler"
+	"github.com/provider-gateway/internal/charge/infra/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQSHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should process message successfully", testHandlerSuccessfully)
+	t.Run("should return error when message body is invalid json", testHandlerUnmarshalError)
+	t.Run("should not return error when event validation fails", testHandlerValidationError)
+	t.Run("should return error when use case fails", testHandlerUseCaseError)
+	t.Run("should route provider payment queries to the query use case", testHandlerQuery)
+	t.Run("should charge the payment without the fee and in its original currency", testHandlerChargedAmount)
+}
+
+func testHandlerSuccessfully(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	useCaseRequest := application.Request{
+		PaymentID:     "pay-1",
+		UserID:        "user-123",
+		Amount:        50.5,
+		CorrelationID: "corr-id-abc",
+	}
+	sqsEvent := createSQSEvent(t, useCaseRequest.PaymentID, useCaseRequest.Amount, useCaseRequest.CorrelationID)
+
+	useCaseMock.EXPECT().Handle(mock.Anything, useCaseRequest).Return(nil).Once()
+
+	h := handler.NewSQSHandler(useCaseMock, mocks.NewMockQueryUseCase(t))
+
+	// WHEN
+	err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testHandlerUnmarshalError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	sqsEvent := events.SQSEvent{
+		Records: []events.SQSMessage{{Body: "this is not json"}},
+	}
+	h := handler.NewSQSHandler(useCaseMock, mocks.NewMockQueryUseCase(t))
+
+	// WHEN
+	err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.Error(t, err)
+}
+
+func testHandlerValidationError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	sqsEvent := createSQSEvent(t, "", 50.5, "corr-id-abc")
+	h := handler.NewSQSHandler(useCaseMock, mocks.NewMockQueryUseCase(t))
+
+	// WHEN
+	err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	useCaseMock.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
+}
+
+func testHandlerUseCaseError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	expectedError := errors.New("something went wrong in the use case")
+	sqsEvent := createSQSEvent(t, "pay-1", 50.5, "corr-id-abc")
+
+	useCaseMock.EXPECT().Handle(mock.Anything, mock.Anything).Return(expectedError).Once()
+
+	h := handler.NewSQSHandler(useCaseMock, mocks.NewMockQueryUseCase(t))
+
+	// WHEN
+	err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.Equal(t, expectedError, err)
+}
+
+func testHandlerQuery(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	queryMock := mocks.NewMockQueryUseCase(t)
+
+	body, err := json.Marshal(_events.QueryProviderPaymentEvent{
+		Header:  _events.EventHeader{CorrelationID: "corr-id-abc", EventType: string(domain.QueryProviderPaymentEventName)},
+		Payload: _events.QueryProviderPaymentPayload{PaymentID: "pay-1", UserID: "user-123", Amount: 50.5},
+	})
+	require.NoError(t, err)
+
+	queryMock.EXPECT().Handle(mock.Anything, application.Request{
+		PaymentID:     "pay-1",
+		UserID:        "user-123",
+		Amount:        50.5,
+		CorrelationID: "corr-id-abc",
+	}).Return(nil).Once()
+
+	h := handler.NewSQSHandler(useCaseMock, queryMock)
+
+	// WHEN
+	err = h.Handle(context.Background(), events.SQSEvent{Records: []events.SQSMessage{{MessageId: "msg-1", Body: string(body)}}})
+
+	// THEN
+	assert.NoError(t, err)
+	useCaseMock.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
+}
+
+func testHandlerChargedAmount(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		payload  _events.BalanceDebitedPayload
+		amount   domain.Amount
+		currency domain.Currency
+	}{
+		"fee debited on top": {
+			payload: _events.BalanceDebitedPayload{AmountDebited: 52, Principal: 50},
+			amount:  50,
+		},
+		"converted payment": {
+			payload:  _events.BalanceDebitedPayload{AmountDebited: 46.5, Principal: 45.5, OriginalAmount: 50, OriginalCurrency: "USD"},
+			amount:   50,
+			currency: "USD",
+		},
+		"event without principal": {
+			payload: _events.BalanceDebitedPayload{AmountDebited: 50.5},
+			amount:  50.5,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			useCaseMock := mocks.NewMockUseCase(t)
+			tc.payload.PaymentID = "pay-1"
+			tc.payload.UserID = "user-123"
+			sqsEvent := sqsEventOf(t, _events.BalanceDebitedEvent{
+				Header:  _events.EventHeader{CorrelationID: "corr-id-abc", EventType: "BalanceDebited"},
+				Payload: tc.payload,
+			})
+
+			useCaseMock.EXPECT().Handle(mock.Anything, application.Request{
+				PaymentID:     "pay-1",
+				UserID:        "user-123",
+				Amount:        tc.amount,
+				Currency:      tc.currency,
+				CorrelationID: "corr-id-abc",
+			}).Return(nil).Once()
+
+			h := handler.NewSQSHandler(useCaseMock, mocks.NewMockQueryUseCase(t))
+
+			// WHEN
+			err := h.Handle(context.Background(), sqsEvent)
+
+			// THEN
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// --- Helper Functions ---
+
+func createSQSEvent(t *testing.T, paymentID domain.PaymentID, amount domain.Amount, corrID string) events.SQSEvent {
+	t.Helper()
+
+	return sqsEventOf(t, _events.BalanceDebitedEvent{
+		Header: _events.EventHeader{CorrelationID: corrID, EventType: "BalanceDebited"},
+		Payload: _events.BalanceDebitedPayload{
+			PaymentID:     paymentID,
+			UserID:        "user-123",
+			AmountDebited: amount,
+			AmountLeft:    10,
+		},
+	})
+}
+
+func sqsEventOf(t *testing.T, event any) events.SQSEvent {
+	t.Helper()
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+
+	return events.SQSEvent{
+		Records: []events.SQSMessage{
+			{
+				MessageId: "test-message-id",
+				Body:      string(body),
+			},
+		},
+	}
+}
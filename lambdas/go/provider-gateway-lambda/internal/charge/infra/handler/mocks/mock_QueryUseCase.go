@@ -0,0 +1,96 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/provider-gateway/internal/charge/application"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockQueryUseCase creates a new instance of MockQueryUseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockQueryUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockQueryUseCase {
+	mock := &MockQueryUseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockQueryUseCase is an autogenerated mock type for the QueryUseCase type
+type MockQueryUseCase struct {
+	mock.Mock
+}
+
+type MockQueryUseCase_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockQueryUseCase) EXPECT() *MockQueryUseCase_Expecter {
+	return &MockQueryUseCase_Expecter{mock: &_m.Mock}
+}
+
+// Handle provides a mock function for the type MockQueryUseCase
+func (_mock *MockQueryUseCase) Handle(ctx context.Context, req application.Request) error {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Handle")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, application.Request) error); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockQueryUseCase_Handle_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Handle'
+type MockQueryUseCase_Handle_Call struct {
+	*mock.Call
+}
+
+// Handle is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req application.Request
+func (_e *MockQueryUseCase_Expecter) Handle(ctx interface{}, req interface{}) *MockQueryUseCase_Handle_Call {
+	return &MockQueryUseCase_Handle_Call{Call: _e.mock.On("Handle", ctx, req)}
+}
+
+func (_c *MockQueryUseCase_Handle_Call) Run(run func(ctx context.Context, req application.Request)) *MockQueryUseCase_Handle_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 application.Request
+		if args[1] != nil {
+			arg1 = args[1].(application.Request)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockQueryUseCase_Handle_Call) Return(err error) *MockQueryUseCase_Handle_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockQueryUseCase_Handle_Call) RunAndReturn(run func(ctx context.Context, req application.Request) error) *MockQueryUseCase_Handle_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/provider-gateway/internal/charge/domain"
+)
+
+const (
+	StatusApproved = "approved"
+	StatusDeclined = "declined"
+)
+
+var (
+	ErrProviderTimeout = errors.New("payment provider timed out")
+	ErrProviderFailure = errors.New("payment provider failure")
+)
+
+// ChargeRequest is the body sent to the provider charges endpoint
+type ChargeRequest struct {
+	PaymentID domain.PaymentID `json:"payment_id"`
+	UserID    domain.UserID    `json:"user_id"`
+	Amount    domain.Amount    `json:"amount"`
+	Currency  domain.Currency  `json:"currency,omitempty"`
+}
+
+// ChargeResponse is the body answered by the provider charges endpoint
+type ChargeResponse struct {
+	Status        string `json:"status"`
+	TransactionID string `json:"transaction_id,omitempty"`
+	DeclineReason string `json:"decline_reason,omitempty"`
+}
+
+// HTTPPaymentProvider calls the external provider over HTTP, bounding every call with a timeout
+type HTTPPaymentProvider struct {
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+}
+
+func (p *HTTPPaymentProvider) Charge(ctx context.Context, payment domain.Payment) (domain.ChargeResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	body, err := json.Marshal(ChargeRequest{PaymentID: payment.PaymentID, UserID: payment.UserID, Amount: payment.Amount, Currency: payment.Currency})
+	if err != nil {
+		return domain.ChargeResult{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/charges", bytes.NewReader(body))
+	if err != nil {
+		return domain.ChargeResult{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", string(payment.PaymentID))
+
+	resp, err := p.client.Do(req)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return domain.ChargeResult{}, errors.Join(ErrProviderTimeout, err)
+	}
+	if err != nil {
+		return domain.ChargeResult{}, errors.Join(ErrProviderFailure, err)
+	}
+	defer resp.Body.Close()
+
+	return toChargeResult(resp)
+}
+
+// Status asks the provider for the charge of paymentID, sent earlier with it as idempotency key
+func (p *HTTPPaymentProvider) Status(ctx context.Context, paymentID domain.PaymentID) (domain.ChargeResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/charges/"+url.PathEscape(string(paymentID)), nil)
+	if err != nil {
+		return domain.ChargeResult{}, err
+	}
+
+	resp, err := p.client.Do(req)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return domain.ChargeResult{}, errors.Join(ErrProviderTimeout, err)
+	}
+	if err != nil {
+		return domain.ChargeResult{}, errors.Join(ErrProviderFailure, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return domain.ChargeResult{}, domain.ErrChargeNotFound
+	}
+
+	return toChargeResult(resp)
+}
+
+func toChargeResult(resp *http.Response) (domain.ChargeResult, error) {
+	if resp.StatusCode != http.StatusOK {
+		return domain.ChargeResult{}, fmt.Errorf("%w: unexpected status %d", ErrProviderFailure, resp.StatusCode)
+	}
+
+	var chargeResp ChargeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chargeResp); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return domain.ChargeResult{}, errors.Join(ErrProviderTimeout, err)
+		}
+		return domain.ChargeResult{}, errors.Join(ErrProviderFailure, err)
+	}
+
+	switch chargeResp.Status {
+	case StatusApproved:
+		return domain.ChargeResult{Approved: true, ProviderTransactionID: chargeResp.TransactionID}, nil
+	case StatusDeclined:
+		return domain.ChargeResult{DeclineReason: chargeResp.DeclineReason}, nil
+	default:
+		return domain.ChargeResult{}, fmt.Errorf("%w: unknown charge status %q", ErrProviderFailure, chargeResp.Status)
+	}
+}
+
+func NewHTTPPaymentProvider(baseURL string, client *http.Client, timeout time.Duration) *HTTPPaymentProvider {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &HTTPPaymentProvider{baseURL: baseURL, client: client, timeout: timeout}
+}
@@ -0,0 +1,150 @@
+package provider_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/provider-gateway/internal/charge/domain"
+	"github.com/provider-gateway/internal/charge/infra/breaker"
+	"github.com/provider-gateway/internal/charge/infra/provider"
+	"github.com/provider-gateway/internal/charge/infra/provider/providertest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var payment = domain.Payment{PaymentID: "pay-1", UserID: "user-1", Amount: 50}
+
+func TestHTTPPaymentProvider(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return approved charge", testProviderApproved)
+	t.Run("should return declined charge without error", testProviderDeclined)
+	t.Run("should return timeout error when provider is slow", testProviderTimeout)
+	t.Run("should return failure error when provider answers 5xx", testProviderFailure)
+	t.Run("should stop calling provider once the circuit opens", testProviderCircuitOpens)
+	t.Run("should return status of a previous charge", testProviderStatus)
+	t.Run("should return not found status without opening the circuit", testProviderStatusNotFound)
+}
+
+func testProviderApproved(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	fake := providertest.NewFakeProvider()
+	defer fake.Close()
+	p := provider.NewHTTPPaymentProvider(fake.URL(), nil, time.Second)
+
+	// WHEN
+	result, err := p.Charge(context.Background(), payment)
+
+	// THEN
+	require.NoError(t, err)
+	assert.True(t, result.Approved)
+	assert.Equal(t, "tx-pay-1", result.ProviderTransactionID)
+}
+
+func testProviderDeclined(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	fake := providertest.NewFakeProvider(providertest.WithDeclineAbove(10))
+	defer fake.Close()
+	p := provider.NewHTTPPaymentProvider(fake.URL(), nil, time.Second)
+
+	// WHEN
+	result, err := p.Charge(context.Background(), payment)
+
+	// THEN
+	require.NoError(t, err)
+	assert.False(t, result.Approved)
+	assert.Equal(t, "limit exceeded", result.DeclineReason)
+}
+
+func testProviderTimeout(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	fake := providertest.NewFakeProvider(providertest.WithLatency(time.Second))
+	defer fake.Close()
+	p := provider.NewHTTPPaymentProvider(fake.URL(), nil, 20*time.Millisecond)
+
+	// WHEN
+	_, err := p.Charge(context.Background(), payment)
+
+	// THEN
+	assert.ErrorIs(t, err, provider.ErrProviderTimeout)
+}
+
+func testProviderFailure(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	fake := providertest.NewFakeProvider(providertest.WithErrorRate(1))
+	defer fake.Close()
+	p := provider.NewHTTPPaymentProvider(fake.URL(), nil, time.Second)
+
+	// WHEN
+	_, err := p.Charge(context.Background(), payment)
+
+	// THEN
+	assert.ErrorIs(t, err, provider.ErrProviderFailure)
+}
+
+func testProviderCircuitOpens(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	fake := providertest.NewFakeProvider(providertest.WithErrorRate(1))
+	defer fake.Close()
+	cb := breaker.NewCircuitBreaker(breaker.Settings{FailureThreshold: 3, OpenTimeout: time.Minute}, time.Now)
+	p := breaker.NewPaymentProvider(provider.NewHTTPPaymentProvider(fake.URL(), nil, time.Second), cb)
+
+	// WHEN
+	var err error
+	for i := 0; i < 10; i++ {
+		_, err = p.Charge(context.Background(), payment)
+	}
+
+	// THEN
+	assert.ErrorIs(t, err, breaker.ErrCircuitOpen)
+	assert.Equal(t, 3, fake.Calls())
+	assert.Equal(t, breaker.StateOpen, cb.State())
+}
+
+func testProviderStatus(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	fake := providertest.NewFakeProvider()
+	defer fake.Close()
+	p := provider.NewHTTPPaymentProvider(fake.URL(), nil, time.Second)
+
+	_, err := p.Charge(context.Background(), payment)
+	require.NoError(t, err)
+
+	// WHEN
+	result, err := p.Status(context.Background(), payment.PaymentID)
+
+	// THEN
+	require.NoError(t, err)
+	assert.True(t, result.Approved)
+	assert.Equal(t, "tx-pay-1", result.ProviderTransactionID)
+}
+
+func testProviderStatusNotFound(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	fake := providertest.NewFakeProvider()
+	defer fake.Close()
+	cb := breaker.NewCircuitBreaker(breaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute}, time.Now)
+	p := breaker.NewPaymentProvider(provider.NewHTTPPaymentProvider(fake.URL(), nil, time.Second), cb)
+
+	// WHEN
+	_, err := p.Status(context.Background(), payment.PaymentID)
+
+	// THEN
+	assert.ErrorIs(t, err, domain.ErrChargeNotFound)
+	assert.Equal(t, breaker.StateClosed, cb.State())
+}
@@ -0,0 +1,145 @@
+package providertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/provider-gateway/internal/charge/domain"
+	"github.com/provider-gateway/internal/charge/infra/provider"
+)
+
+// FakeProvider is an httptest server speaking the provider protocol. Latency, error rate
+// and declines can be tuned at any time to simulate a degraded provider
+type FakeProvider struct {
+	server *httptest.Server
+
+	mu           sync.Mutex
+	latency      time.Duration
+	errorRate    float64
+	declineAbove domain.Amount
+	rnd          *rand.Rand
+	calls        int
+	charges      map[domain.PaymentID]provider.ChargeResponse
+}
+
+type Option func(*FakeProvider)
+
+// WithLatency delays every answer by d
+func WithLatency(d time.Duration) Option {
+	return func(f *FakeProvider) { f.latency = d }
+}
+
+// WithErrorRate answers 503 for the given fraction of calls, between 0 and 1
+func WithErrorRate(rate float64) Option {
+	return func(f *FakeProvider) { f.errorRate = rate }
+}
+
+// WithDeclineAbove declines every charge with an amount greater than limit
+func WithDeclineAbove(limit domain.Amount) Option {
+	return func(f *FakeProvider) { f.declineAbove = limit }
+}
+
+// WithSeed makes the simulated errors reproducible
+func WithSeed(seed int64) Option {
+	return func(f *FakeProvider) { f.rnd = rand.New(rand.NewSource(seed)) }
+}
+
+func (f *FakeProvider) URL() string { return f.server.URL }
+
+func (f *FakeProvider) Close() { f.server.Close() }
+
+func (f *FakeProvider) SetLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.latency = d
+}
+
+func (f *FakeProvider) SetErrorRate(rate float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.errorRate = rate
+}
+
+// Calls returns the number of charges that reached the provider
+func (f *FakeProvider) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls
+}
+
+func (f *FakeProvider) charge(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	f.calls++
+	latency := f.latency
+	fail := f.errorRate > 0 && f.rnd.Float64() < f.errorRate
+	declineAbove := f.declineAbove
+	f.mu.Unlock()
+
+	var req provider.ChargeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fail {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	resp := provider.ChargeResponse{Status: provider.StatusApproved, TransactionID: fmt.Sprintf("tx-%s", req.PaymentID)}
+	if declineAbove > 0 && req.Amount > declineAbove {
+		resp = provider.ChargeResponse{Status: provider.StatusDeclined, DeclineReason: "limit exceeded"}
+	}
+
+	f.mu.Lock()
+	f.charges[req.PaymentID] = resp
+	f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// status answers the charge recorded for the payment, or 404 when it never reached the provider
+func (f *FakeProvider) status(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	resp, ok := f.charges[domain.PaymentID(r.PathValue("paymentID"))]
+	f.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func NewFakeProvider(opts ...Option) *FakeProvider {
+	f := &FakeProvider{rnd: rand.New(rand.NewSource(1)), charges: make(map[domain.PaymentID]provider.ChargeResponse)}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /charges", f.charge)
+	mux.HandleFunc("GET /charges/{paymentID}", f.status)
+	f.server = httptest.NewServer(mux)
+
+	return f
+}
@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/provider-gateway/internal/charge/domain"
+)
+
+// ApprovingPaymentProvider is a mock implementation of port PaymentProvider.
+// Approves every charge without calling any external service
+type ApprovingPaymentProvider struct{}
+
+func (p *ApprovingPaymentProvider) Charge(ctx context.Context, payment domain.Payment) (domain.ChargeResult, error) {
+	slog.InfoContext(ctx, "--- CHARGE APPROVED ---", "paymentId", payment.PaymentID, "amount", payment.Amount)
+
+	// real implementation would be like:
+	// return p.providerClient.Charge(...)
+
+	return domain.ChargeResult{Approved: true, ProviderTransactionID: uuid.NewString()}, nil
+}
+
+// Status reports every charge as approved, as Charge would have answered
+func (p *ApprovingPaymentProvider) Status(ctx context.Context, paymentID domain.PaymentID) (domain.ChargeResult, error) {
+	slog.InfoContext(ctx, "--- CHARGE STATUS APPROVED ---", "paymentId", paymentID)
+
+	return domain.ChargeResult{Approved: true, ProviderTransactionID: uuid.NewString()}, nil
+}
+
+func NewApprovingPaymentProvider() *ApprovingPaymentProvider {
+	return &ApprovingPaymentProvider{}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/payment-processor/internal/debit/infra/audit"
+)
+
+// auditverify checks the hash chain of an audit trail. Exits with 1 when the trail was tampered
+// with or does not end at the expected head, and with 2 when it can't be read
+func main() {
+	path := flag.String("log", "", "JSONL audit trail written by the lambda")
+	expected := flag.String("head", "", "hash of the last entry recorded elsewhere, detects removed tail entries")
+	flag.Parse()
+
+	f, err := os.Open(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open audit trail: %v\n", err)
+		os.Exit(2)
+	}
+	defer f.Close()
+
+	os.Exit(run(f, os.Stdout, *expected))
+}
+
+func run(r io.Reader, out io.Writer, expected string) int {
+	head, err := audit.Verify(r)
+	if errors.Is(err, audit.ErrTampered) {
+		fmt.Fprintf(out, "FAIL %v (%d entries verified before it)\n", err, head.Sequence)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(out, "failed to read audit trail: %v\n", err)
+		return 2
+	}
+
+	if expected != "" && expected != head.Hash {
+		fmt.Fprintf(out, "FAIL trail ends at entry %d with hash %s, expected %s\n", head.Sequence, head.Hash, expected)
+		return 1
+	}
+
+	fmt.Fprintf(out, "OK   %d entries, head %s\n", head.Sequence, head.Hash)
+	return 0
+}
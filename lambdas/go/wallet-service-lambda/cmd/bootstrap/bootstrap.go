@@ -2,21 +2,194 @@ package bootstrap
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-processor/internal/config"
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/audit"
+	"github.com/payment-processor/internal/debit/infra/fees"
+	"github.com/payment-processor/internal/debit/infra/fx"
+	"github.com/payment-processor/internal/debit/infra/handler"
+	"github.com/payment-processor/internal/debit/infra/signing"
+	"github.com/payment-processor/internal/debit/infra/tenants"
 )
 
 type LambdaHandler interface {
-	Handle(ctx context.Context, sqsEvent events.SQSEvent) error
+	Handle(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error)
 }
 
-func BuildHandler() LambdaHandler {
-	walletRepo := provideRepository()
-	eventBus := provideEventBus()
+type SchedulerHandler interface {
+	Handle(ctx context.Context, event events.CloudWatchEvent) error
+}
+
+// BuildHandler wires the handler from the configuration, adapters passed as options win over
+// the ones selected by the configuration
+func BuildHandler(opts ...Option) (LambdaHandler, error) {
+	a := newAdapters(opts)
+
+	useCase, err := a.useCase()
+	if err != nil {
+		return nil, err
+	}
+
+	verifier, err := a.verifier()
+	if err != nil {
+		return nil, err
+	}
+
+	handler := provideHandler(useCase, a.config.Handler, a.tenants, verifier)
+
+	if a.recorder != nil {
+		return a.recorder.Handler(handler), nil
+	}
+
+	return handler, nil
+}
+
+// BuildScheduler wires the handler of the scheduled debits with the same adapters as
+// BuildHandler, the mandates are seeded from the configured file
+func BuildScheduler(opts ...Option) (SchedulerHandler, error) {
+	mandates, err := BuildMandates(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return handler.NewSchedulerHandler(mandates), nil
+}
+
+// BuildMandates wires the use case creating, cancelling and debiting the mandates, for the
+// entry points other than the schedule
+func BuildMandates(opts ...Option) (*application.MandateUseCase, error) {
+	a := newAdapters(opts)
+
+	useCase, err := a.useCase()
+	if err != nil {
+		return nil, err
+	}
+
+	history, ok := a.transactions.(ports.TransactionHistory)
+	if !ok {
+		return nil, errors.New("the scheduler needs a transaction repository able to read the history")
+	}
+	if a.mandates == nil {
+		mandates, err := provideMandateRepository(a.config.Mandates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load mandates: %w", err)
+		}
+		a.mandates = mandates
+	}
+
+	return application.NewMandateUseCase(a.mandates, useCase, history,
+		application.WithDefaultMandateRetry(a.config.Mandates.Retry()),
+	), nil
+}
+
+func newAdapters(opts []Option) *adapters {
+	a := &adapters{config: config.Default()}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// useCase validates the configuration and fills every adapter not passed as option
+func (a *adapters) useCase() (*application.UseCaseHandler, error) {
+	if err := a.config.Validate(); err != nil {
+		return nil, err
+	}
+
+	if a.walletRepo == nil {
+		repo, err := provideRepository(a.config.Repository)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build wallet repository: %w", err)
+		}
+		a.walletRepo = repo
+	}
+	if a.eventBus == nil {
+		a.eventBus = provideEventBus(a.config.EventBus)
+	}
+
+	if a.transactions == nil {
+		transactions, err := provideTransactionRepository(a.config.TransactionsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open transactions: %w", err)
+		}
+		a.transactions = transactions
+	}
+	if a.auditTrail == nil && a.config.AuditLogPath != "" {
+		trail, err := audit.OpenFile(a.config.AuditLogPath)
+		if err != nil {
+			return nil, err
+		}
+		a.auditTrail = trail
+	}
+	if a.fees == nil && a.config.Fees.SchedulePath != "" {
+		schedule, err := fees.LoadScheduleFile(a.config.Fees.SchedulePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fee schedule: %w", err)
+		}
+		a.fees = &schedule
+	}
+	if a.rates == nil && a.config.FX.RatesPath != "" {
+		provider, err := fx.LoadRatesFile(a.config.FX.RatesPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load exchange rates: %w", err)
+		}
+		a.rates = provider
+	}
+
+	if a.tenants == nil && a.config.TenantsPath != "" {
+		registry, err := tenants.LoadRegistryFile(a.config.TenantsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tenants: %w", err)
+		}
+		a.tenants = registry
+	}
+	if a.rates == nil && hasFallbacks(a.tenants) {
+		return nil, fmt.Errorf("%s: required by the fallback currencies of the tenants", config.EnvFXRatesPath)
+	}
 
-	useCase := provideUseCase(walletRepo, eventBus)
+	if a.recorder != nil {
+		a.walletRepo = a.recorder.Repository(a.walletRepo)
+		a.eventBus = a.recorder.EventBus(a.eventBus)
+	}
 
-	handler := provideHandler(useCase)
+	a.walletRepo = provideResilientRepository(a.walletRepo)
+	a.eventBus = provideResilientEventBus(a.eventBus)
+
+	return provideUseCase(a.walletRepo, a.eventBus, a.transactions, a.auditTrail, a.fees, a.rates, a.tenants, a.config), nil
+}
+
+// verifier authenticates the events when signing keys are configured, nil otherwise. The keys are
+// read once here so a missing or malformed file fails the cold start instead of every event
+func (a *adapters) verifier() (*signing.Verifier, error) {
+	if a.keys == nil && a.config.Signing.KeysPath != "" {
+		a.keys = signing.NewFileKeyProvider(a.config.Signing.KeysPath, a.config.Signing.KeysRefresh)
+	}
+	if a.keys == nil {
+		return nil, nil
+	}
+	if _, err := a.keys.Keys(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	return signing.NewVerifier(a.keys, signing.WithSources(a.config.Signing.Allowlist())), nil
+}
+
+func hasFallbacks(registry domain.Tenants) bool {
+	for _, tenant := range registry {
+		if len(tenant.FallbackCurrencies) > 0 {
+			return true
+		}
+	}
+	return false
+}
 
-	return handler
+// BuildRepository opens the wallet repository selected by the configuration, for tools that work
+// on the stored wallets without handling events
+func BuildRepository(cfg config.Repository) (ports.WalletRepository, error) {
+	return provideRepository(cfg)
 }
@@ -0,0 +1,34 @@
+package bootstrap
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// tenantHandler tags every record logged with a context scoped to a tenant with its id
+type tenantHandler struct {
+	slog.Handler
+}
+
+// NewLogHandler wraps next so the records of a tenant carry a tenantId attribute
+func NewLogHandler(next slog.Handler) slog.Handler {
+	return tenantHandler{Handler: next}
+}
+
+func (h tenantHandler) Handle(ctx context.Context, record slog.Record) error {
+	if tenant := domain.TenantFrom(ctx); tenant != "" {
+		record = record.Clone()
+		record.AddAttrs(slog.String("tenantId", string(tenant)))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h tenantHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return tenantHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h tenantHandler) WithGroup(name string) slog.Handler {
+	return tenantHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,82 @@
+package bootstrap
+
+import (
+	"github.com/payment-processor/internal/config"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/recorder"
+	"github.com/payment-processor/internal/debit/infra/signing"
+)
+
+// Option overrides one of the adapters wired by BuildHandler and BuildScheduler
+type Option func(*adapters)
+
+type adapters struct {
+	config       config.Config
+	walletRepo   ports.WalletRepository
+	eventBus     ports.EventBusProcessor
+	recorder     *recorder.Recorder
+	auditTrail   ports.AuditTrail
+	transactions ports.TransactionRepository
+	fees         *domain.FeeSchedule
+	rates        ports.RateProvider
+	mandates     ports.MandateRepository
+	tenants      domain.Tenants
+	keys         signing.KeyProvider
+}
+
+// WithConfig replaces the default configuration, it is validated by BuildHandler
+func WithConfig(cfg config.Config) Option {
+	return func(a *adapters) { a.config = cfg }
+}
+
+// WithRepository replaces the default wallet repository
+func WithRepository(repo ports.WalletRepository) Option {
+	return func(a *adapters) { a.walletRepo = repo }
+}
+
+// WithEventBus replaces the default event bus
+func WithEventBus(bus ports.EventBusProcessor) Option {
+	return func(a *adapters) { a.eventBus = bus }
+}
+
+// WithRecorder captures every invocation, repository access and published event
+func WithRecorder(rec *recorder.Recorder) Option {
+	return func(a *adapters) { a.recorder = rec }
+}
+
+// WithAuditTrail replaces the audit trail opened from the configuration
+func WithAuditTrail(trail ports.AuditTrail) Option {
+	return func(a *adapters) { a.auditTrail = trail }
+}
+
+// WithTransactionRepository replaces the in-memory transaction history
+func WithTransactionRepository(repo ports.TransactionRepository) Option {
+	return func(a *adapters) { a.transactions = repo }
+}
+
+// WithFeeSchedule replaces the fee schedule loaded from the configuration
+func WithFeeSchedule(schedule domain.FeeSchedule) Option {
+	return func(a *adapters) { a.fees = &schedule }
+}
+
+// WithRateProvider replaces the static rates loaded from the configuration
+func WithRateProvider(provider ports.RateProvider) Option {
+	return func(a *adapters) { a.rates = provider }
+}
+
+// WithMandateRepository replaces the mandates loaded from the configuration
+func WithMandateRepository(repo ports.MandateRepository) Option {
+	return func(a *adapters) { a.mandates = repo }
+}
+
+// WithTenants replaces the tenant registry loaded from the configuration
+func WithTenants(tenants domain.Tenants) Option {
+	return func(a *adapters) { a.tenants = tenants }
+}
+
+// WithKeyProvider replaces the signing keys read from the configured file, enabling the
+// verification of the events
+func WithKeyProvider(keys signing.KeyProvider) Option {
+	return func(a *adapters) { a.keys = keys }
+}
@@ -4,22 +4,41 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/payment-processor/internal/config"
 	"go.opentelemetry.io/contrib/propagators/aws/xray"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
 )
 
-func newTracerProvider() (*sdktrace.TracerProvider, error) {
-	tp := sdktrace.NewTracerProvider(
+func newTracerProvider(cfg config.Telemetry) (*sdktrace.TracerProvider, error) {
+	opts := []sdktrace.TracerProviderOption{
 		sdktrace.WithIDGenerator(xray.NewIDGenerator()),
-	)
-	return tp, nil
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName))),
+	}
+
+	if cfg.TracesExporter == config.ExporterStdout {
+		exporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+
+	return sdktrace.NewTracerProvider(opts...), nil
 }
 
-func InitTracing(ctx context.Context) trace.TracerProvider {
-	tp, err := newTracerProvider()
+func InitTracing(ctx context.Context, cfg config.Telemetry) trace.TracerProvider {
+	if cfg.TracesExporter == config.ExporterNone {
+		slog.InfoContext(ctx, "tracing disabled")
+		return noop.NewTracerProvider()
+	}
+
+	tp, err := newTracerProvider(cfg)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to initialize tracer provider", "error", err)
 		return noop.NewTracerProvider()
@@ -28,6 +47,6 @@ func InitTracing(ctx context.Context) trace.TracerProvider {
 	otel.SetTracerProvider(tp)
 	otel.SetTextMapPropagator(xray.Propagator{})
 
-	slog.InfoContext(ctx, "X-Ray tracer provider initialized")
+	slog.InfoContext(ctx, "tracer provider initialized", "exporter", cfg.TracesExporter)
 	return tp
 }
@@ -1,15 +1,64 @@
 package bootstrap
 
 import (
+	"github.com/payment-processor/internal/config"
 	"github.com/payment-processor/internal/debit/application"
 	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/retry"
+	"github.com/payment-processor/internal/debit/domain"
 	"github.com/payment-processor/internal/debit/infra/handler"
+	"github.com/payment-processor/internal/debit/infra/signing"
 )
 
-func provideUseCase(repo ports.WalletRepository, bus ports.EventBusProcessor) *application.UseCaseHandler {
-	return application.NewDebitBalanceUseCaseHandler(repo, bus)
+// provideUseCase signs the audit records with the service name, credits the fees to the
+// configured revenue account, converts the payments into the currency of the debited balance,
+// falling back to the configured currencies, and hosts the tenants of the registry
+func provideUseCase(
+	repo ports.WalletRepository,
+	bus ports.EventBusProcessor,
+	transactions ports.TransactionRepository,
+	trail ports.AuditTrail,
+	schedule *domain.FeeSchedule,
+	rates ports.RateProvider,
+	tenants domain.Tenants,
+	cfg config.Config,
+) *application.UseCaseHandler {
+	opts := []application.Option{
+		application.WithRetryPolicy(retry.NewPolicy(cfg.Retry)),
+		application.WithTransactionRepository(transactions),
+		application.WithWalletCurrency(domain.Currency(cfg.FX.WalletCurrency)),
+		application.WithTenants(tenants),
+	}
+	if trail != nil {
+		opts = append(opts, application.WithAuditTrail(trail, cfg.Telemetry.ServiceName))
+	}
+	if schedule != nil {
+		opts = append(opts, application.WithFeeSchedule(*schedule, domain.UserID(cfg.Fees.RevenueAccount)))
+	}
+	if rates != nil {
+		opts = append(opts,
+			application.WithExchangeRates(rates, cfg.FX.Policy()),
+			application.WithCurrencyFallback(cfg.FX.Fallbacks()...),
+		)
+	}
+
+	return application.NewDebitBalanceUseCaseHandler(repo, bus, opts...)
 }
 
-func provideHandler(useCase *application.UseCaseHandler) *handler.SQSHandler {
-	return handler.NewSQSHandler(useCase)
+// provideHandler verifies the events with verifier when set
+func provideHandler(useCase *application.UseCaseHandler, cfg config.Handler, tenants domain.Tenants, verifier *signing.Verifier) *handler.SQSHandler {
+	opts := []handler.Option{
+		handler.WithTenants(tenants),
+		handler.WithWorkers(cfg.Workers),
+		handler.WithRecordTimeout(cfg.RecordTimeout),
+		handler.WithSafetyMargin(cfg.SafetyMargin),
+	}
+	if cfg.Ordering == config.OrderingPerUser {
+		opts = append(opts, handler.WithOrdering(handler.OrderingPerUser))
+	}
+	if verifier != nil {
+		opts = append(opts, handler.WithVerifier(verifier))
+	}
+
+	return handler.NewSQSHandler(useCase, opts...)
 }
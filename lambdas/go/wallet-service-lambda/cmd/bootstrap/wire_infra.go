@@ -1,16 +1,144 @@
 package bootstrap
 
 import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"github.com/payment-processor/internal/config"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
 	"github.com/payment-processor/internal/debit/infra/bus"
 	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/payment-processor/internal/debit/infra/resilience"
+
+	// embedded driver for the sql repository
+	_ "modernc.org/sqlite"
 )
 
-func provideRepository() *repository.InMemoryWalletRepository {
-	// in a real case, we would instance the real client here
-	return repository.NewInMemoryWalletRepository()
+// provideRepository selects the repository adapter, the kind is already validated by the config.
+// The table name is meant for the real client
+func provideRepository(cfg config.Repository) (ports.WalletRepository, error) {
+	slog.Info("wallet repository selected", "kind", cfg.Kind, "table", cfg.TableName, "seed", cfg.SeedPath)
+	switch cfg.Kind {
+	case config.RepositoryFile:
+		return provideFileRepository(cfg)
+	case config.RepositorySQL:
+		return provideSQLRepository(cfg)
+	default:
+		if cfg.SeedPath != "" {
+			return repository.NewInMemoryWalletRepositoryFromFile(cfg.SeedPath)
+		}
+		return repository.NewInMemoryWalletRepository(), nil
+	}
+}
+
+// provideFileRepository seeds the store only on its first start, later starts keep the persisted state
+func provideFileRepository(cfg config.Repository) (*repository.FileWalletRepository, error) {
+	repo, err := repository.NewFileWalletRepository(cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.SeedPath != "" && len(repo.Wallets()) == 0 {
+		wallets, err := repository.LoadWalletsFile(cfg.SeedPath)
+		if err == nil {
+			err = repo.Seed(wallets...)
+		}
+		if err != nil {
+			repo.Close()
+			return nil, err
+		}
+	}
+
+	return repo, nil
+}
+
+// provideTransactionRepository appends the history to path, without it the history only lives as
+// long as the container
+func provideTransactionRepository(path string) (ports.TransactionRepository, error) {
+	if path == "" {
+		return repository.NewInMemoryTransactionRepository(), nil
+	}
+	return repository.OpenFileTransactionRepository(path)
+}
+
+func provideEventBus(cfg config.EventBus) ports.EventBusProcessor {
+	slog.Info("event bus selected", "kind", cfg.Kind, "bus", cfg.Name)
+	switch cfg.Kind {
+	case config.EventBusMemory:
+		return bus.NewInMemoryEventBus()
+	default:
+		return bus.NewConsoleEventBus()
+	}
+}
+
+// provideSQLRepository migrates the schema on cold start. Only the embedded sqlite driver is linked,
+// other drivers must be registered by a blank import
+func provideSQLRepository(cfg config.Repository) (*repository.SQLWalletRepository, error) {
+	db, err := sql.Open(cfg.SQLDriver, cfg.SQLDSN)
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []repository.SQLOption
+	if cfg.SQLDriver == "postgres" || cfg.SQLDriver == "pgx" {
+		opts = append(opts, repository.WithDialect(repository.DialectDollar))
+	}
+	repo := repository.NewSQLWalletRepository(db, opts...)
+
+	ctx := context.Background()
+	err = repo.Migrate(ctx)
+	if err == nil && cfg.SeedPath != "" {
+		err = seedSQLRepository(ctx, repo, cfg.SeedPath)
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+func seedSQLRepository(ctx context.Context, repo *repository.SQLWalletRepository, path string) error {
+	existing, err := repo.Wallets(ctx)
+	if err != nil || len(existing) > 0 {
+		return err
+	}
+
+	wallets, err := repository.LoadWalletsFile(path)
+	if err != nil {
+		return err
+	}
+
+	return repo.Seed(ctx, wallets...)
+}
+
+func provideResilientRepository(repo ports.WalletRepository) *resilience.WalletRepository {
+	policy := resilience.NewPolicy("wallet-repository", resilience.DefaultSettings(), resilience.IsRepositoryFailure, time.Now)
+	return resilience.NewWalletRepository(repo, policy)
 }
 
-func provideEventBus() *bus.ConsoleEventBus {
-	// in a real case, we would instance the real client here
-	return bus.NewConsoleEventBus()
+func provideResilientEventBus(eventBus ports.EventBusProcessor) *resilience.EventBus {
+	policy := resilience.NewPolicy("event-bus", resilience.DefaultSettings(), nil, time.Now)
+	return resilience.NewEventBus(eventBus, policy)
+}
+
+// provideMandateRepository seeds the mandates from the configured file, without it the scheduler
+// starts with none. The state file keeps the changes across restarts, without it they only live
+// as long as the container
+func provideMandateRepository(cfg config.Mandates) (ports.MandateRepository, error) {
+	var seed []domain.Mandate
+	if cfg.Path != "" {
+		var err error
+		if seed, err = repository.LoadMandatesFile(cfg.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.StatePath != "" {
+		return repository.OpenFileMandateRepository(cfg.StatePath, seed...)
+	}
+	return repository.NewInMemoryMandateRepositoryWith(seed...), nil
 }
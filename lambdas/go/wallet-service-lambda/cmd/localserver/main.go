@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/payment-processor/cmd/bootstrap"
+	"github.com/payment-processor/internal/config"
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/infra/bus"
+	"github.com/payment-processor/internal/debit/infra/repository"
+)
+
+// localserver hosts the lambda handler behind a local HTTP endpoint so scenarios
+// can be driven with curl without AWS
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	seed := flag.String("seed", "", "optional JSON or CSV file with the initial wallets")
+	snapshot := flag.String("snapshot", "", "optional file where the final wallet state is dumped on shutdown")
+	transactionsPath := flag.String("transactions", "", "optional JSONL file where the recorded transactions are dumped on shutdown")
+	flag.Parse()
+
+	cfg, err := config.FromEnv()
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	logger := slog.New(bootstrap.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: cfg.LogLevel})))
+	slog.SetDefault(logger)
+
+	repo := repository.NewInMemoryWalletRepository()
+	if *seed != "" {
+		repo, err = repository.NewInMemoryWalletRepositoryFromFile(*seed)
+		if err != nil {
+			slog.Error("failed to load seed file", "path", *seed, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	eventBus := bus.NewInMemoryEventBus()
+	transactions := repository.NewInMemoryTransactionRepository()
+	handler, err := bootstrap.BuildHandler(
+		bootstrap.WithConfig(cfg),
+		bootstrap.WithRepository(repo),
+		bootstrap.WithEventBus(eventBus),
+		bootstrap.WithTransactionRepository(transactions),
+	)
+	if err != nil {
+		slog.Error("failed to build handler", "error", err)
+		os.Exit(1)
+	}
+	mandates, err := bootstrap.BuildMandates(
+		bootstrap.WithConfig(cfg),
+		bootstrap.WithRepository(repo),
+		bootstrap.WithEventBus(eventBus),
+		bootstrap.WithTransactionRepository(transactions),
+	)
+	if err != nil {
+		slog.Error("failed to build mandates", "error", err)
+		os.Exit(1)
+	}
+	srv := newServer(handler, repo, eventBus, application.NewTransactionHistoryUseCase(transactions), mandates)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{Addr: *addr, Handler: srv.routes()}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("local server listening", "addr", *addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("local server stopped", "error", err)
+		os.Exit(1)
+	}
+
+	if *snapshot != "" {
+		if err := repo.WriteSnapshotFile(*snapshot); err != nil {
+			slog.Error("failed to write snapshot", "path", *snapshot, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("snapshot written", "path", *snapshot)
+	}
+
+	if *transactionsPath != "" {
+		if err := transactions.WriteTransactionsFile(*transactionsPath); err != nil {
+			slog.Error("failed to write transactions", "path", *transactionsPath, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("transactions written", "path", *transactionsPath)
+	}
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/uuid"
+	"github.com/payment-processor/cmd/bootstrap"
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/bus"
+	"github.com/payment-processor/internal/debit/infra/repository"
+)
+
+// tenantHeader scopes the wallet and transaction queries to a tenant, the empty one when missing
+const tenantHeader = "X-Tenant-Id"
+
+type walletDTO struct {
+	TenantID domain.TenantID                   `json:"tenant_id,omitempty"`
+	UserID   domain.UserID                     `json:"user_id"`
+	Amount   domain.Amount                     `json:"amount"`
+	Balances map[domain.Currency]domain.Amount `json:"balances,omitempty"`
+	Version  int                               `json:"version"`
+}
+
+func toWalletDTO(wallet domain.Wallet) walletDTO {
+	return walletDTO{TenantID: wallet.TenantID, UserID: wallet.UserID, Amount: wallet.Amount, Balances: wallet.Balances, Version: wallet.Version}
+}
+
+type transactionDTO struct {
+	ID            string                 `json:"id"`
+	PaymentID     string                 `json:"payment_id"`
+	CorrelationID string                 `json:"correlation_id"`
+	Type          domain.TransactionType `json:"type"`
+	Amount        domain.Amount          `json:"amount"`
+	Currency      domain.Currency        `json:"currency,omitempty"`
+	BalanceAfter  domain.Amount          `json:"balance_after"`
+	Timestamp     time.Time              `json:"timestamp"`
+}
+
+type historyDTO struct {
+	Transactions []transactionDTO `json:"transactions"`
+	NextCursor   string           `json:"next_cursor,omitempty"`
+}
+
+func toHistoryDTO(page ports.TransactionPage) historyDTO {
+	dto := historyDTO{Transactions: make([]transactionDTO, 0, len(page.Transactions)), NextCursor: page.NextCursor}
+	for _, tx := range page.Transactions {
+		dto.Transactions = append(dto.Transactions, transactionDTO{
+			ID:            tx.ID,
+			PaymentID:     tx.PaymentID,
+			CorrelationID: tx.CorrelationID,
+			Type:          tx.Type,
+			Amount:        tx.Amount,
+			Currency:      tx.Currency,
+			BalanceAfter:  tx.BalanceAfter,
+			Timestamp:     tx.Timestamp,
+		})
+	}
+	return dto
+}
+
+// mandateDTO holds the terms of a mandate to create and, once cancelled, where its debits stopped
+type mandateDTO struct {
+	ID               string               `json:"id"`
+	TenantID         domain.TenantID      `json:"tenant_id,omitempty"`
+	UserID           domain.UserID        `json:"user_id"`
+	Amount           domain.Amount        `json:"amount"`
+	Currency         domain.Currency      `json:"currency,omitempty"`
+	MerchantCategory string               `json:"merchant_category,omitempty"`
+	Cadence          domain.Cadence       `json:"cadence"`
+	Start            time.Time            `json:"start"`
+	End              time.Time            `json:"end,omitzero"`
+	MaxAmount        domain.Amount        `json:"max_amount,omitempty"`
+	RetryAttempts    int                  `json:"retry_attempts,omitempty"`
+	RetryInterval    string               `json:"retry_interval,omitempty"`
+	Status           domain.MandateStatus `json:"status,omitempty"`
+	CancelledAt      time.Time            `json:"cancelled_at,omitzero"`
+	Cycle            int                  `json:"cycle"`
+	Total            domain.Amount        `json:"total"`
+}
+
+func toMandateDTO(mandate domain.Mandate) mandateDTO {
+	dto := mandateDTO{
+		ID:               mandate.ID,
+		TenantID:         mandate.TenantID,
+		UserID:           mandate.UserID,
+		Amount:           mandate.Amount,
+		Currency:         mandate.Currency,
+		MerchantCategory: mandate.MerchantCategory,
+		Cadence:          mandate.Cadence,
+		Start:            mandate.Start,
+		End:              mandate.End,
+		MaxAmount:        mandate.MaxAmount,
+		RetryAttempts:    mandate.Retry.Attempts,
+		Status:           mandate.Status,
+		CancelledAt:      mandate.CancelledAt,
+		Cycle:            mandate.Cycle,
+		Total:            mandate.Total,
+	}
+	if mandate.Retry.Interval > 0 {
+		dto.RetryInterval = mandate.Retry.Interval.String()
+	}
+	return dto
+}
+
+func (dto mandateDTO) toDomain() (domain.Mandate, error) {
+	mandate := domain.Mandate{
+		ID:               dto.ID,
+		UserID:           dto.UserID,
+		Amount:           dto.Amount,
+		Currency:         dto.Currency,
+		MerchantCategory: dto.MerchantCategory,
+		Cadence:          dto.Cadence,
+		Start:            dto.Start,
+		End:              dto.End,
+		MaxAmount:        dto.MaxAmount,
+		Retry:            domain.MandateRetry{Attempts: dto.RetryAttempts},
+	}
+	if dto.RetryInterval != "" {
+		interval, err := time.ParseDuration(dto.RetryInterval)
+		if err != nil {
+			return mandate, fmt.Errorf("retry_interval: %w", err)
+		}
+		mandate.Retry.Interval = interval
+	}
+	return mandate, nil
+}
+
+type cycleDTO struct {
+	MandateID string                   `json:"mandate_id"`
+	Cycle     int                      `json:"cycle"`
+	PaymentID string                   `json:"payment_id"`
+	Outcome   application.CycleOutcome `json:"outcome"`
+	Error     string                   `json:"error,omitempty"`
+}
+
+type schedulerReportDTO struct {
+	Mandates int        `json:"mandates"`
+	Cycles   []cycleDTO `json:"cycles"`
+}
+
+func toSchedulerReportDTO(report application.SchedulerReport) schedulerReportDTO {
+	dto := schedulerReportDTO{Mandates: report.Mandates, Cycles: make([]cycleDTO, 0, len(report.Cycles))}
+	for _, cycle := range report.Cycles {
+		c := cycleDTO{MandateID: cycle.MandateID, Cycle: cycle.Cycle, PaymentID: cycle.PaymentID, Outcome: cycle.Outcome}
+		if cycle.Err != nil {
+			c.Error = cycle.Err.Error()
+		}
+		dto.Cycles = append(dto.Cycles, c)
+	}
+	return dto
+}
+
+type server struct {
+	handler  bootstrap.LambdaHandler
+	repo     *repository.InMemoryWalletRepository
+	bus      *bus.InMemoryEventBus
+	history  *application.TransactionHistoryUseCase
+	mandates *application.MandateUseCase
+	now      func() time.Time
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /invoke", s.invoke)
+	mux.HandleFunc("GET /wallets", s.listWallets)
+	mux.HandleFunc("GET /wallets/{userID}", s.getWallet)
+	mux.HandleFunc("GET /wallets/{userID}/transactions", s.listTransactions)
+	mux.HandleFunc("GET /events", s.listEvents)
+	mux.HandleFunc("DELETE /events", s.resetEvents)
+	mux.HandleFunc("GET /snapshot", s.snapshot)
+	mux.HandleFunc("POST /mandates", s.createMandate)
+	mux.HandleFunc("DELETE /mandates/{id}", s.cancelMandate)
+	mux.HandleFunc("POST /mandates/run", s.runMandates)
+
+	return withTenant(mux)
+}
+
+// withTenant scopes the context of the request to the tenant of its header
+func withTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := domain.WithTenant(r.Context(), domain.TenantID(r.Header.Get(tenantHeader)))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// invoke accepts either a full SQSEvent or a raw PaymentInitEvent, which is wrapped
+// in a single record batch before reaching the handler
+func (s *server) invoke(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	sqsEvent, err := toSQSEvent(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	response, err := s.handler.Handle(r.Context(), sqsEvent)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	failed := len(response.BatchItemFailures)
+	status := http.StatusOK
+	if failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+
+	writeJSON(w, status, map[string]any{
+		"processed":         len(sqsEvent.Records) - failed,
+		"batchItemFailures": response.BatchItemFailures,
+	})
+}
+
+func (s *server) listWallets(w http.ResponseWriter, _ *http.Request) {
+	wallets := s.repo.Wallets()
+
+	dtos := make([]walletDTO, 0, len(wallets))
+	for _, wallet := range wallets {
+		dtos = append(dtos, toWalletDTO(wallet))
+	}
+
+	writeJSON(w, http.StatusOK, dtos)
+}
+
+func (s *server) getWallet(w http.ResponseWriter, r *http.Request) {
+	wallet, err := s.repo.Get(r.Context(), domain.UserID(r.PathValue("userID")))
+	if errors.Is(err, repository.ErrWalletNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toWalletDTO(wallet))
+}
+
+// listTransactions accepts from and to as RFC 3339, type repeated or comma separated, limit and
+// the cursor returned by the previous page
+func (s *server) listTransactions(w http.ResponseWriter, r *http.Request) {
+	query, err := toTransactionQuery(domain.UserID(r.PathValue("userID")), r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := s.history.History(r.Context(), query)
+	if errors.Is(err, application.ErrInvalidQuery) || errors.Is(err, repository.ErrInvalidCursor) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toHistoryDTO(page))
+}
+
+func toTransactionQuery(userID domain.UserID, values url.Values) (ports.TransactionQuery, error) {
+	query := ports.TransactionQuery{UserID: userID, Cursor: values.Get("cursor")}
+
+	var err error
+	if v := values.Get("from"); v != "" {
+		if query.From, err = time.Parse(time.RFC3339, v); err != nil {
+			return query, fmt.Errorf("from: %w", err)
+		}
+	}
+	if v := values.Get("to"); v != "" {
+		if query.To, err = time.Parse(time.RFC3339, v); err != nil {
+			return query, fmt.Errorf("to: %w", err)
+		}
+	}
+	if v := values.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil {
+			return query, fmt.Errorf("limit: %w", err)
+		}
+	}
+	for _, v := range values["type"] {
+		for _, t := range strings.Split(v, ",") {
+			query.Types = append(query.Types, domain.TransactionType(strings.TrimSpace(t)))
+		}
+	}
+
+	return query, nil
+}
+
+func (s *server) listEvents(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.bus.Events())
+}
+
+func (s *server) resetEvents(w http.ResponseWriter, _ *http.Request) {
+	s.bus.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// snapshot dumps the full repository state, the response can be used as a seed file
+func (s *server) snapshot(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.repo.Snapshot())
+}
+
+// createMandate stores an active mandate in the tenant of the header, its first cycle is debited
+// by the first run at or after its start
+func (s *server) createMandate(w http.ResponseWriter, r *http.Request) {
+	var dto mandateDTO
+	if err := json.NewDecoder(r.Body).Decode(&dto); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	mandate, err := dto.toDomain()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = s.mandates.Create(r.Context(), mandate)
+	if errors.Is(err, application.ErrInvalidMandate) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if errors.Is(err, repository.ErrMandateExists) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, map[string]string{"id": mandate.ID})
+}
+
+func (s *server) cancelMandate(w http.ResponseWriter, r *http.Request) {
+	mandate, err := s.mandates.Cancel(r.Context(), r.PathValue("id"))
+	var domainErr *domain.Error
+	if errors.Is(err, repository.ErrMandateNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	if errors.As(err, &domainErr) {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toMandateDTO(mandate))
+}
+
+// runMandates debits the cycles of every tenant due at the RFC 3339 at parameter, now when missing,
+// as a tick of the schedule would
+func (s *server) runMandates(w http.ResponseWriter, r *http.Request) {
+	at := s.now()
+	if v := r.URL.Query().Get("at"); v != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339, v); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("at: %w", err))
+			return
+		}
+	}
+
+	report, err := s.mandates.Run(r.Context(), at.UTC())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toSchedulerReportDTO(report))
+}
+
+func toSQSEvent(body []byte) (events.SQSEvent, error) {
+	var probe struct {
+		Records json.RawMessage `json:"Records"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return events.SQSEvent{}, err
+	}
+
+	if probe.Records != nil {
+		var sqsEvent events.SQSEvent
+		err := json.Unmarshal(body, &sqsEvent)
+		return sqsEvent, err
+	}
+
+	return events.SQSEvent{
+		Records: []events.SQSMessage{{MessageId: uuid.NewString(), Body: string(body)}},
+	}, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func newServer(
+	handler bootstrap.LambdaHandler,
+	repo *repository.InMemoryWalletRepository,
+	eventBus *bus.InMemoryEventBus,
+	history *application.TransactionHistoryUseCase,
+	mandates *application.MandateUseCase,
+) *server {
+	return &server{handler: handler, repo: repo, bus: eventBus, history: history, mandates: mandates, now: time.Now}
+}
@@ -0,0 +1,325 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-processor/cmd/bootstrap"
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/domain"
+	_events "github.com/payment-processor/internal/debit/domain/events"
+	"github.com/payment-processor/internal/debit/infra/bus"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalServer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should debit wallet from a raw payment init event", testServerRawEvent)
+	t.Run("should debit wallet from a full sqs event", testServerSQSEvent)
+	t.Run("should return unprocessable entity when the debit fails", testServerDebitError)
+	t.Run("should return not found for unknown wallet", testServerWalletNotFound)
+	t.Run("should read the wallet of the tenant of the header", testServerTenantWallet)
+	t.Run("should reset captured events", testServerResetEvents)
+	t.Run("should dump the repository state as a snapshot", testServerSnapshot)
+	t.Run("should page through the transaction history", testServerTransactions)
+	t.Run("should reject an invalid history query", testServerTransactionsInvalidQuery)
+	t.Run("should debit a created mandate on every run once", testServerMandates)
+	t.Run("should stop debiting a cancelled mandate", testServerCancelMandate)
+	t.Run("should reject an invalid mandate", testServerInvalidMandate)
+}
+
+func testServerRawEvent(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	body := paymentInitBody(t, "user-1", 40)
+
+	// WHEN
+	rec := doRequest(srv, http.MethodPost, "/invoke", body)
+
+	// THEN
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	wallet := decode[walletDTO](t, doRequest(srv, http.MethodGet, "/wallets/user-1", ""))
+	assert.Equal(t, domain.Amount(60), wallet.Amount)
+	assert.Equal(t, 2, wallet.Version)
+
+	published := decode[[]_events.BalanceDebitedEvent](t, doRequest(srv, http.MethodGet, "/events", ""))
+	require.Len(t, published, 1)
+	assert.Equal(t, domain.Amount(40), published[0].Payload.AmountDebited)
+	assert.Equal(t, domain.Amount(60), published[0].Payload.AmountLeft)
+}
+
+func testServerSQSEvent(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	sqsEvent := events.SQSEvent{Records: []events.SQSMessage{
+		{MessageId: "msg-1", Body: paymentInitBody(t, "user-1", 10)},
+		{MessageId: "msg-2", Body: paymentInitBody(t, "user-1", 15)},
+	}}
+	body, err := json.Marshal(sqsEvent)
+	require.NoError(t, err)
+
+	// WHEN
+	rec := doRequest(srv, http.MethodPost, "/invoke", string(body))
+
+	// THEN
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	wallets := decode[[]walletDTO](t, doRequest(srv, http.MethodGet, "/wallets", ""))
+	require.Len(t, wallets, 1)
+	assert.Equal(t, domain.Amount(75), wallets[0].Amount)
+}
+
+func testServerDebitError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t, domain.Wallet{UserID: "user-1", Amount: 5, Version: 1})
+
+	// WHEN
+	rec := doRequest(srv, http.MethodPost, "/invoke", paymentInitBody(t, "user-1", 40))
+
+	// THEN
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	result := decode[struct {
+		Processed         int                          `json:"processed"`
+		BatchItemFailures []events.SQSBatchItemFailure `json:"batchItemFailures"`
+	}](t, rec)
+	assert.Zero(t, result.Processed)
+	assert.Len(t, result.BatchItemFailures, 1)
+}
+
+func testServerWalletNotFound(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t)
+
+	// WHEN
+	rec := doRequest(srv, http.MethodGet, "/wallets/unknown", "")
+
+	// THEN
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func testServerTenantWallet(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t, domain.Wallet{TenantID: "acme", UserID: "user-1", Amount: 100, Version: 1})
+	req := httptest.NewRequest(http.MethodGet, "/wallets/user-1", nil)
+	req.Header.Set("X-Tenant-Id", "acme")
+
+	// WHEN
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	missing := doRequest(srv, http.MethodGet, "/wallets/user-1", "")
+
+	// THEN
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, domain.TenantID("acme"), decode[walletDTO](t, rec).TenantID)
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+}
+
+func testServerResetEvents(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	doRequest(srv, http.MethodPost, "/invoke", paymentInitBody(t, "user-1", 40))
+
+	// WHEN
+	rec := doRequest(srv, http.MethodDelete, "/events", "")
+
+	// THEN
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	published := decode[[]_events.BalanceDebitedEvent](t, doRequest(srv, http.MethodGet, "/events", ""))
+	assert.Empty(t, published)
+}
+
+func testServerSnapshot(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t,
+		domain.Wallet{UserID: "user-2", Amount: 50, Version: 1},
+		domain.Wallet{UserID: "user-1", Amount: 100, Version: 1},
+	)
+	doRequest(srv, http.MethodPost, "/invoke", paymentInitBody(t, "user-1", 30))
+
+	// WHEN
+	rec := doRequest(srv, http.MethodGet, "/snapshot", "")
+
+	// THEN
+	assert.Equal(t, http.StatusOK, rec.Code)
+	snapshot := decode[repository.Snapshot](t, rec)
+	assert.Equal(t, []repository.WalletRecord{
+		{UserID: "user-1", Amount: 70, Version: 2},
+		{UserID: "user-2", Amount: 50, Version: 1},
+	}, snapshot.Wallets)
+}
+
+func testServerTransactions(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	for _, amount := range []domain.Amount{10, 20, 30} {
+		doRequest(srv, http.MethodPost, "/invoke", paymentInitBody(t, "user-1", amount))
+	}
+
+	// WHEN
+	first := decode[historyDTO](t, doRequest(srv, http.MethodGet, "/wallets/user-1/transactions?limit=2&type=debit", ""))
+	second := decode[historyDTO](t, doRequest(srv, http.MethodGet, "/wallets/user-1/transactions?limit=2&cursor="+first.NextCursor, ""))
+
+	// THEN
+	require.Len(t, first.Transactions, 2)
+	assert.Equal(t, domain.Amount(30), first.Transactions[0].Amount)
+	assert.Equal(t, domain.Amount(40), first.Transactions[0].BalanceAfter)
+	assert.Equal(t, domain.TransactionDebit, first.Transactions[0].Type)
+	assert.Equal(t, "corr-id", first.Transactions[0].CorrelationID)
+	require.Len(t, second.Transactions, 1)
+	assert.Equal(t, domain.Amount(90), second.Transactions[0].BalanceAfter)
+	assert.Empty(t, second.NextCursor)
+}
+
+func testServerTransactionsInvalidQuery(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t)
+
+	// WHEN
+	badDate := doRequest(srv, http.MethodGet, "/wallets/user-1/transactions?from=yesterday", "")
+	badType := doRequest(srv, http.MethodGet, "/wallets/user-1/transactions?type=refund", "")
+	badCursor := doRequest(srv, http.MethodGet, "/wallets/user-1/transactions?cursor=abc", "")
+
+	// THEN
+	assert.Equal(t, http.StatusBadRequest, badDate.Code)
+	assert.Equal(t, http.StatusBadRequest, badType.Code)
+	assert.Equal(t, http.StatusBadRequest, badCursor.Code)
+}
+
+func testServerMandates(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	created := doRequest(srv, http.MethodPost, "/mandates", mandateBody)
+
+	// WHEN
+	first := decode[schedulerReportDTO](t, doRequest(srv, http.MethodPost, "/mandates/run?at=2026-02-01T08:00:00Z", ""))
+	again := decode[schedulerReportDTO](t, doRequest(srv, http.MethodPost, "/mandates/run?at=2026-02-01T08:00:00Z", ""))
+
+	// THEN
+	assert.Equal(t, http.StatusCreated, created.Code)
+	assert.Equal(t, []cycleDTO{
+		{MandateID: "sub-1", Cycle: 0, PaymentID: "mandate:sub-1:0", Outcome: application.CycleDebited},
+		{MandateID: "sub-1", Cycle: 1, PaymentID: "mandate:sub-1:1", Outcome: application.CycleDebited},
+	}, first.Cycles)
+	assert.Empty(t, again.Cycles)
+	wallet := decode[walletDTO](t, doRequest(srv, http.MethodGet, "/wallets/user-1", ""))
+	assert.Equal(t, domain.Amount(80), wallet.Amount)
+}
+
+func testServerCancelMandate(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	require.Equal(t, http.StatusCreated, doRequest(srv, http.MethodPost, "/mandates", mandateBody).Code)
+
+	// WHEN
+	cancelled := doRequest(srv, http.MethodDelete, "/mandates/sub-1", "")
+	again := doRequest(srv, http.MethodDelete, "/mandates/sub-1", "")
+	unknown := doRequest(srv, http.MethodDelete, "/mandates/sub-9", "")
+	report := decode[schedulerReportDTO](t, doRequest(srv, http.MethodPost, "/mandates/run?at=2026-02-01T08:00:00Z", ""))
+
+	// THEN
+	assert.Equal(t, http.StatusOK, cancelled.Code)
+	assert.Equal(t, domain.MandateCancelled, decode[mandateDTO](t, cancelled).Status)
+	assert.Equal(t, http.StatusConflict, again.Code)
+	assert.Equal(t, http.StatusNotFound, unknown.Code)
+	assert.Empty(t, report.Cycles)
+}
+
+func testServerInvalidMandate(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	srv := newTestServer(t)
+
+	// WHEN
+	invalid := doRequest(srv, http.MethodPost, "/mandates", `{"id":"sub-1","user_id":"user-1","amount":-1,"cadence":"monthly","start":"2026-01-01T08:00:00Z"}`)
+	badInterval := doRequest(srv, http.MethodPost, "/mandates", `{"id":"sub-1","user_id":"user-1","amount":10,"cadence":"monthly","start":"2026-01-01T08:00:00Z","retry_interval":"soon"}`)
+	badAt := doRequest(srv, http.MethodPost, "/mandates/run?at=tomorrow", "")
+
+	// THEN
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.Equal(t, http.StatusBadRequest, badInterval.Code)
+	assert.Equal(t, http.StatusBadRequest, badAt.Code)
+}
+
+// --- Helper Functions ---
+
+const mandateBody = `{"id":"sub-1","user_id":"user-1","amount":10,"cadence":"monthly","start":"2026-01-01T08:00:00Z"}`
+
+func newTestServer(t *testing.T, wallets ...domain.Wallet) http.Handler {
+	t.Helper()
+
+	repo := repository.NewInMemoryWalletRepositoryWith(wallets...)
+	eventBus := bus.NewInMemoryEventBus()
+	transactions := repository.NewInMemoryTransactionRepository()
+	handler, err := bootstrap.BuildHandler(
+		bootstrap.WithRepository(repo),
+		bootstrap.WithEventBus(eventBus),
+		bootstrap.WithTransactionRepository(transactions),
+	)
+	require.NoError(t, err)
+	mandates, err := bootstrap.BuildMandates(
+		bootstrap.WithRepository(repo),
+		bootstrap.WithEventBus(eventBus),
+		bootstrap.WithTransactionRepository(transactions),
+		bootstrap.WithMandateRepository(repository.NewInMemoryMandateRepository()),
+	)
+	require.NoError(t, err)
+
+	return newServer(handler, repo, eventBus, application.NewTransactionHistoryUseCase(transactions), mandates).routes()
+}
+
+func doRequest(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func paymentInitBody(t *testing.T, userID domain.UserID, amount domain.Amount) string {
+	t.Helper()
+
+	body, err := json.Marshal(_events.PaymentInitEvent{
+		Header:  _events.EventHeader{CorrelationID: "corr-id"},
+		Payload: _events.PaymentInitPayload{UserID: userID, Amount: amount},
+	})
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+func decode[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+
+	var v T
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &v))
+	return v
+}
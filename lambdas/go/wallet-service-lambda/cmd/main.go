@@ -7,17 +7,25 @@ import (
 
 	"github.com/aws/aws-lambda-go/lambda"
 	"github.com/payment-processor/cmd/bootstrap"
+	"github.com/payment-processor/internal/config"
+	"github.com/payment-processor/internal/debit/infra/recorder"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-lambda-go/otellambda"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
-	slog.SetDefault(logger)
-
 	ctx := context.Background()
 
-	tp := bootstrap.InitTracing(ctx)
+	cfg, err := config.FromEnv()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	logger := slog.New(bootstrap.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: cfg.LogLevel})))
+	slog.SetDefault(logger)
+
+	tp := bootstrap.InitTracing(ctx, cfg.Telemetry)
 
 	if sdkTracerProvider, ok := tp.(*sdktrace.TracerProvider); ok {
 		defer func() {
@@ -27,7 +35,22 @@ func main() {
 		}()
 	}
 
-	handler := bootstrap.BuildHandler()
+	opts := []bootstrap.Option{bootstrap.WithConfig(cfg)}
+	if path := cfg.CapturePath; path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to open capture file, recording disabled", "path", path, "error", err)
+		} else {
+			defer f.Close()
+			opts = append(opts, bootstrap.WithRecorder(recorder.NewRecorder(f)))
+		}
+	}
+
+	handler, err := bootstrap.BuildHandler(opts...)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build handler", "error", err)
+		os.Exit(1)
+	}
 
 	lambda.Start(otellambda.InstrumentHandler(handler.Handle))
 }
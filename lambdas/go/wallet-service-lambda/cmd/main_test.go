@@ -2,12 +2,22 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/payment-processor/cmd/bootstrap"
+	"github.com/payment-processor/internal/config"
+	"github.com/payment-processor/internal/debit/domain"
 	_events "github.com/payment-processor/internal/debit/domain/events"
+	"github.com/payment-processor/internal/debit/infra/bus"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/payment-processor/internal/debit/infra/signing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -19,7 +29,8 @@ func TestLambdaHandler_EndToEnd(t *testing.T) {
 
 	// Construimos nuestro handler con todas sus dependencias (mocks)
 	// exactamente como lo haría el main.go real.
-	handler := bootstrap.BuildHandler()
+	handler, err := bootstrap.BuildHandler()
+	require.NoError(t, err)
 
 	// Creamos el evento de entrada que simula lo que llegaría en un mensaje de SQS.
 	inputEvent := _events.PaymentInitEvent{
@@ -50,10 +61,155 @@ func TestLambdaHandler_EndToEnd(t *testing.T) {
 
 	// Invocamos el handler con nuestro evento simulado.
 	// Esto ejecutará todo el flujo: handler -> use case -> repository -> bus.
-	err = handler.Handle(context.Background(), sqsEvent)
+	response, err := handler.Handle(context.Background(), sqsEvent)
 
 	// --- 3. Aserción ---
 
-	// Verificamos que el handler no haya devuelto ningún error.
+	// Verificamos que el handler no haya devuelto ningún error ni mensajes fallidos.
 	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+}
+
+// TestSchedulerHandler_EndToEnd cobra un mandato mensual con el scheduler construido como en cmd/scheduler
+func TestSchedulerHandler_EndToEnd(t *testing.T) {
+	// --- 1. Preparación ---
+
+	// El mandato empezó dos meses antes del tick, así que hay tres ciclos pendientes de cobrar.
+	start := time.Date(2026, 1, 15, 8, 0, 0, 0, time.UTC)
+	wallets := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-123", Amount: 100, Version: 1})
+	mandates := repository.NewInMemoryMandateRepositoryWith(domain.Mandate{
+		ID:      "sub-1",
+		UserID:  "user-123",
+		Amount:  10,
+		Cadence: domain.CadenceMonthly,
+		Start:   start,
+		Status:  domain.MandateActive,
+		Version: 1,
+	})
+
+	handler, err := bootstrap.BuildScheduler(
+		bootstrap.WithRepository(wallets),
+		bootstrap.WithMandateRepository(mandates),
+	)
+	require.NoError(t, err)
+
+	// --- 2. Actuación  ---
+
+	// Dos ejecuciones del mismo tick no cobran dos veces los mismos ciclos.
+	tick := events.CloudWatchEvent{ID: "tick-1", Time: start.AddDate(0, 2, 0)}
+	require.NoError(t, handler.Handle(context.Background(), tick))
+	require.NoError(t, handler.Handle(context.Background(), tick))
+
+	// --- 3. Aserción ---
+
+	wallet, err := wallets.Get(context.Background(), "user-123")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(70), wallet.Amount)
+}
+
+// TestLambdaHandler_MultiTenant debita la billetera del tenant del evento y descarta los eventos de tenants desconocidos
+func TestLambdaHandler_MultiTenant(t *testing.T) {
+	// --- 1. Preparación ---
+
+	// El mismo usuario existe en dos tenants con saldos distintos
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{TenantID: "acme", UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{TenantID: "globex", UserID: "user-1", Amount: 50, Version: 1},
+	)
+	eventBus := bus.NewInMemoryEventBus()
+	handler, err := bootstrap.BuildHandler(
+		bootstrap.WithRepository(repo),
+		bootstrap.WithEventBus(eventBus),
+		bootstrap.WithTenants(domain.Tenants{
+			"acme":   {ID: "acme", MaxDebit: 500},
+			"globex": {ID: "globex"},
+		}),
+	)
+	require.NoError(t, err)
+
+	message := func(id string, tenant domain.TenantID) events.SQSMessage {
+		body, err := json.Marshal(_events.PaymentInitEvent{
+			Header:  _events.EventHeader{CorrelationID: "corr-" + id, TenantID: tenant},
+			Payload: _events.PaymentInitPayload{PaymentID: "pay-" + id, UserID: "user-1", Amount: 20},
+		})
+		require.NoError(t, err)
+		return events.SQSMessage{MessageId: id, Body: string(body)}
+	}
+
+	// --- 2. Actuación ---
+
+	response, err := handler.Handle(context.Background(), events.SQSEvent{Records: []events.SQSMessage{
+		message("msg-1", "globex"),
+		message("msg-2", "initech"),
+		message("msg-3", ""),
+	}})
+
+	// --- 3. Aserción ---
+
+	// Solo se debita la billetera de globex, los otros dos eventos se descartan sin reintentos
+	require.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	assert.Equal(t, []domain.Wallet{
+		{TenantID: "acme", UserID: "user-1", Amount: 100, Version: 1},
+		{TenantID: "globex", UserID: "user-1", Amount: 30, Version: 2},
+	}, repo.Wallets())
+
+	published := eventBus.Events()
+	require.Len(t, published, 1)
+	assert.Equal(t, domain.TenantID("globex"), published[0].Header.TenantID)
+}
+
+// TestLambdaHandler_SignedEvents debita solo los eventos firmados con una clave activa por un productor permitido
+func TestLambdaHandler_SignedEvents(t *testing.T) {
+	// --- 1. Preparación ---
+
+	// Dos claves activas a la vez, como durante una rotación
+	current := []byte("clave-actual-de-al-menos-32-bytes")
+	previous := []byte("clave-anterior-de-al-menos-32-bytes")
+	keysPath := filepath.Join(t.TempDir(), "signing-keys.json")
+	keysFile := fmt.Sprintf(`[{"id": "2026-09", "secret": %q}, {"id": "2026-10", "secret": %q}]`,
+		base64.StdEncoding.EncodeToString(previous), base64.StdEncoding.EncodeToString(current))
+	require.NoError(t, os.WriteFile(keysPath, []byte(keysFile), 0o600))
+
+	cfg := config.Default()
+	cfg.Signing.KeysPath = keysPath
+	cfg.Signing.Sources = []string{"PaymentInit:checkout"}
+
+	repo := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	handler, err := bootstrap.BuildHandler(bootstrap.WithConfig(cfg), bootstrap.WithRepository(repo))
+	require.NoError(t, err)
+
+	message := func(id, source, keyID string, secret []byte) events.SQSMessage {
+		body, err := json.Marshal(_events.PaymentInitEvent{
+			Header:  _events.EventHeader{CorrelationID: "corr-" + id, EventType: "PaymentInit", Source: source},
+			Payload: _events.PaymentInitPayload{PaymentID: "pay-" + id, UserID: "user-1", Amount: 10},
+		})
+		require.NoError(t, err)
+		signature, err := signing.Sign(secret, body)
+		require.NoError(t, err)
+
+		return events.SQSMessage{MessageId: id, Body: string(body), MessageAttributes: map[string]events.SQSMessageAttribute{
+			signing.SignatureAttribute: {DataType: "String", StringValue: &signature},
+			signing.KeyIDAttribute:     {DataType: "String", StringValue: &keyID},
+		}}
+	}
+
+	// --- 2. Actuación ---
+
+	response, err := handler.Handle(context.Background(), events.SQSEvent{Records: []events.SQSMessage{
+		message("msg-1", "checkout", "2026-10", current),
+		message("msg-2", "checkout", "2026-09", previous),
+		message("msg-3", "checkout", "2026-10", previous),
+		message("msg-4", "intruso", "2026-10", current),
+		{MessageId: "msg-5", Body: `{"header":{"correlation_id":"corr-5"},"payload":{"user_id":"user-1","amount":10}}`},
+	}})
+
+	// --- 3. Aserción ---
+
+	// Solo se debitan los dos eventos auténticos, los demás se rechazan sin reintentos
+	require.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	wallet, err := repo.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(80), wallet.Amount)
 }
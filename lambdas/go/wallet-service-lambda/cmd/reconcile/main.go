@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/payment-processor/cmd/bootstrap"
+	"github.com/payment-processor/internal/config"
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/domain/events"
+	"github.com/payment-processor/internal/debit/infra/bus"
+	"github.com/payment-processor/internal/debit/infra/repository"
+)
+
+// reconcile compares the stored balances with the published BalanceDebited events and publishes a
+// BalanceDiscrepancyDetected event for every wallet that does not add up. Exits with 1 when
+// discrepancies are found and with 2 when the inputs can't be read
+func main() {
+	eventsPath := flag.String("events", "", "JSONL file with the published BalanceDebited events, in publishing order")
+	walletsPath := flag.String("wallets", "", "optional JSON or CSV snapshot of the wallets, the configured repository otherwise")
+	flag.Parse()
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+
+	cfg, err := config.FromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(2)
+	}
+
+	f, err := os.Open(*eventsPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open events: %v\n", err)
+		os.Exit(2)
+	}
+	defer f.Close()
+
+	debits, err := readDebits(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read events: %v\n", err)
+		os.Exit(2)
+	}
+
+	var repo ports.WalletRepository
+	if *walletsPath != "" {
+		repo, err = repository.NewInMemoryWalletRepositoryFromFile(*walletsPath)
+	} else {
+		repo, err = bootstrap.BuildRepository(cfg.Repository)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open wallets: %v\n", err)
+		os.Exit(2)
+	}
+
+	useCase := application.NewReconciliationUseCase(repo, bus.NewConsoleEventBus())
+	report, err := useCase.Reconcile(context.Background(), debits)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reconciliation failed: %v\n", err)
+		os.Exit(2)
+	}
+
+	if printReport(os.Stdout, report) > 0 {
+		os.Exit(1)
+	}
+}
+
+// readDebits keeps the BalanceDebited events, other event types sharing the stream are skipped
+func readDebits(r io.Reader) ([]ports.BalanceDebitedRequest, error) {
+	var debits []ports.BalanceDebitedRequest
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var event events.BalanceDebitedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if event.Header.EventType != string(domain.BalanceDebitedEventName) {
+			continue
+		}
+
+		debit := ports.BalanceDebitedRequest{
+			TenantID:      event.Header.TenantID,
+			PaymentID:     event.Payload.PaymentID,
+			UserID:        event.Payload.UserID,
+			AmountDebited: event.Payload.AmountDebited,
+			Principal:     event.Payload.Principal,
+			Fee:           event.Payload.Fee,
+			AmountLeft:    event.Payload.AmountLeft,
+			Currency:      event.Payload.Currency,
+			EventName:     domain.BalanceDebitedEventName,
+			CorrelationID: event.Header.CorrelationID,
+		}
+		for _, leg := range event.Payload.Legs {
+			debit.Legs = append(debit.Legs, ports.DebitedLeg{UserID: leg.UserID, AmountDebited: leg.AmountDebited, AmountLeft: leg.AmountLeft})
+		}
+		debits = append(debits, debit)
+	}
+
+	return debits, scanner.Err()
+}
+
+func printReport(out io.Writer, report application.ReconciliationReport) int {
+	for _, d := range report.Discrepancies {
+		stored := "missing"
+		if d.StoredBalance != nil {
+			stored = fmt.Sprint(*d.StoredBalance)
+		}
+		wallet := string(d.UserID)
+		if d.TenantID != "" {
+			wallet = string(d.TenantID) + ":" + wallet
+		}
+		if d.Currency != "" {
+			wallet += "/" + string(d.Currency)
+		}
+		fmt.Fprintf(out, "DIFF %s expected %v stored %s: %s\n", wallet, d.ExpectedBalance, stored, d.Reason)
+		for _, tx := range d.Transactions {
+			fmt.Fprintf(out, "     payment %s correlation %s debited %v left %v\n", tx.PaymentID, tx.CorrelationID, tx.AmountDebited, tx.AmountLeft)
+		}
+	}
+
+	fmt.Fprintf(out, "%d wallets and %d debits reconciled, %d discrepancies\n", report.Wallets, report.Debits, len(report.Discrepancies))
+	return len(report.Discrepancies)
+}
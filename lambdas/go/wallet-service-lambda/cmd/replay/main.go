@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/payment-processor/cmd/bootstrap"
+	"github.com/payment-processor/internal/config"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/infra/recorder"
+)
+
+// replay feeds a JSONL capture back through the handler and reports every invocation
+// whose produced events or outcome differ from the recorded ones
+func main() {
+	capture := flag.String("capture", "", "JSONL capture written by the recorder")
+	flag.Parse()
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+
+	// the same environment as the captured lambda keeps retries and adapters alike
+	cfg, err := config.FromEnv()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to load configuration: %v\n", err)
+		os.Exit(2)
+	}
+	// a replay must not extend the audit trail nor the history of the real invocations
+	cfg.AuditLogPath = ""
+	cfg.TransactionsPath = ""
+
+	f, err := os.Open(*capture)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to open capture: %v\n", err)
+		os.Exit(2)
+	}
+	defer f.Close()
+
+	invocations, err := recorder.ReadCapture(f)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read capture: %v\n", err)
+		os.Exit(2)
+	}
+
+	mismatches, err := run(context.Background(), os.Stdout, invocations, handlerFactory(cfg))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build handler: %v\n", err)
+		os.Exit(2)
+	}
+	if mismatches > 0 {
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, out io.Writer, invocations []recorder.Invocation, build recorder.HandlerFactory) (int, error) {
+	mismatches := 0
+	for _, inv := range invocations {
+		result, err := recorder.Replay(ctx, inv, build)
+		if err != nil {
+			return mismatches, err
+		}
+		if result.Matches() {
+			fmt.Fprintf(out, "OK   %s (%d events)\n", result.InvocationID, len(result.Produced))
+			continue
+		}
+
+		mismatches++
+		fmt.Fprintf(out, "DIFF %s\n", result.InvocationID)
+		for _, d := range result.Diffs {
+			fmt.Fprintf(out, "     %s\n", d)
+		}
+	}
+
+	fmt.Fprintf(out, "%d invocations replayed, %d mismatches\n", len(invocations), mismatches)
+	return mismatches, nil
+}
+
+// handlerFactory fails when a file of the configuration, such as the fee schedule, the rates,
+// the tenants or the signing keys, cannot be loaded
+func handlerFactory(cfg config.Config) recorder.HandlerFactory {
+	return func(repo ports.WalletRepository, bus ports.EventBusProcessor) (recorder.Handler, error) {
+		return bootstrap.BuildHandler(bootstrap.WithConfig(cfg), bootstrap.WithRepository(repo), bootstrap.WithEventBus(bus))
+	}
+}
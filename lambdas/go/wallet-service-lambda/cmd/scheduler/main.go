@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/payment-processor/cmd/bootstrap"
+	"github.com/payment-processor/internal/config"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-lambda-go/otellambda"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// main runs the scheduled debits of the mandates, invoked by an EventBridge schedule
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.FromEnv()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	logger := slog.New(bootstrap.NewLogHandler(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: cfg.LogLevel})))
+	slog.SetDefault(logger)
+
+	tp := bootstrap.InitTracing(ctx, cfg.Telemetry)
+
+	if sdkTracerProvider, ok := tp.(*sdktrace.TracerProvider); ok {
+		defer func() {
+			if err := sdkTracerProvider.Shutdown(ctx); err != nil {
+				slog.ErrorContext(ctx, "error shutting down tracer provider", "error", err)
+			}
+		}()
+	}
+
+	handler, err := bootstrap.BuildScheduler(bootstrap.WithConfig(cfg))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to build scheduler", "error", err)
+		os.Exit(1)
+	}
+
+	lambda.Start(otellambda.InstrumentHandler(handler.Handle))
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/payment-processor/cmd/bootstrap"
+	"github.com/payment-processor/internal/config"
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/payment-processor/internal/debit/infra/statement"
+)
+
+const dateLayout = "2006-01-02"
+
+// statement renders the statement of a wallet for a period from a transactions file, as CSV or
+// as an ISO 20022 camt.053 document: either the TRANSACTIONS_PATH history of the service or the
+// JSONL file cmd/localserver writes on shutdown. Exits with 2 when the inputs can't be read
+func main() {
+	userID := flag.String("user", "", "wallet to build the statement for")
+	tenant := flag.String("tenant", "", "tenant of the wallet, empty for single tenant deployments")
+	from := flag.String("from", "", "first day of the period, YYYY-MM-DD in UTC")
+	to := flag.String("to", "", "day after the last one of the period, YYYY-MM-DD in UTC")
+	format := flag.String("format", "csv", "output format: csv or camt053")
+	currency := flag.String("currency", "EUR", "currency of the wallet, used by camt053")
+	transactionsPath := flag.String("transactions", "", "JSONL history of TRANSACTIONS_PATH or dump written by cmd/localserver -transactions")
+	walletsPath := flag.String("wallets", "", "optional JSON or CSV snapshot of the wallets, the configured repository otherwise")
+	outPath := flag.String("out", "", "optional output file, stdout otherwise")
+	flag.Parse()
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+
+	render, err := renderer(*format, *currency)
+	if err != nil {
+		fail(err)
+	}
+
+	start, err := time.Parse(dateLayout, *from)
+	if err != nil {
+		fail(fmt.Errorf("invalid -from: %w", err))
+	}
+	end, err := time.Parse(dateLayout, *to)
+	if err != nil {
+		fail(fmt.Errorf("invalid -to: %w", err))
+	}
+
+	cfg, err := config.FromEnv()
+	if err != nil {
+		fail(fmt.Errorf("failed to load configuration: %w", err))
+	}
+
+	transactions, err := repository.NewInMemoryTransactionRepositoryFromFile(*transactionsPath)
+	if err != nil {
+		fail(fmt.Errorf("failed to open transactions: %w", err))
+	}
+
+	var repo ports.WalletRepository
+	if *walletsPath != "" {
+		repo, err = repository.NewInMemoryWalletRepositoryFromFile(*walletsPath)
+	} else {
+		repo, err = bootstrap.BuildRepository(cfg.Repository)
+	}
+	if err != nil {
+		fail(fmt.Errorf("failed to open wallets: %w", err))
+	}
+
+	s, err := application.NewStatementUseCase(transactions, repo).Generate(domain.WithTenant(context.Background(), domain.TenantID(*tenant)), domain.UserID(*userID), start, end)
+	if err != nil {
+		fail(fmt.Errorf("failed to generate statement: %w", err))
+	}
+
+	out := io.Writer(os.Stdout)
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			fail(fmt.Errorf("failed to create output: %w", err))
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if err = render(out, s); err != nil {
+		fail(fmt.Errorf("failed to write statement: %w", err))
+	}
+}
+
+func renderer(format, currency string) (func(io.Writer, application.Statement) error, error) {
+	switch format {
+	case "csv":
+		return statement.WriteCSV, nil
+	case "camt053":
+		return func(w io.Writer, s application.Statement) error { return statement.WriteCamt053(w, s, currency) }, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected csv or camt053", format)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
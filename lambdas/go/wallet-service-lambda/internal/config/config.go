@@ -0,0 +1,483 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/retry"
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// Adapter kinds selectable from the environment
+const (
+	RepositoryMemory = "memory"
+	RepositoryFile   = "file"
+	RepositorySQL    = "sql"
+
+	EventBusConsole = "console"
+	EventBusMemory  = "memory"
+
+	OrderingSequential = "sequential"
+	OrderingPerUser    = "per_user"
+
+	ExporterXRay   = "xray"
+	ExporterStdout = "stdout"
+	ExporterNone   = "none"
+)
+
+// Environment variables read by Load
+const (
+	EnvRepositoryKind       = "WALLET_REPOSITORY"
+	EnvTableName            = "WALLET_TABLE_NAME"
+	EnvSeedPath             = "WALLET_SEED_PATH"
+	EnvDataDir              = "WALLET_DATA_DIR"
+	EnvSQLDriver            = "WALLET_SQL_DRIVER"
+	EnvSQLDSN               = "WALLET_SQL_DSN"
+	EnvEventBusKind         = "EVENT_BUS"
+	EnvEventBusName         = "EVENT_BUS_NAME"
+	EnvOrdering             = "SQS_ORDERING"
+	EnvWorkers              = "SQS_WORKERS"
+	EnvRecordTimeout        = "SQS_RECORD_TIMEOUT"
+	EnvSafetyMargin         = "SQS_SAFETY_MARGIN"
+	EnvRetryMaxAttempts     = "RETRY_MAX_ATTEMPTS"
+	EnvRetryBaseDelay       = "RETRY_BASE_DELAY"
+	EnvRetryMaxDelay        = "RETRY_MAX_DELAY"
+	EnvRetryMaxElapsed      = "RETRY_MAX_ELAPSED"
+	EnvLogLevel             = "LOG_LEVEL"
+	EnvTracesExporter       = "TRACES_EXPORTER"
+	EnvServiceName          = "OTEL_SERVICE_NAME"
+	EnvCapturePath          = "CAPTURE_PATH"
+	EnvAuditLogPath         = "AUDIT_LOG_PATH"
+	EnvTransactionsPath     = "TRANSACTIONS_PATH"
+	EnvFeeSchedulePath      = "FEE_SCHEDULE_PATH"
+	EnvFeeRevenue           = "FEE_REVENUE_ACCOUNT"
+	EnvFXRatesPath          = "FX_RATES_PATH"
+	EnvWalletCurrency       = "WALLET_CURRENCY"
+	EnvFXSpread             = "FX_SPREAD"
+	EnvFXRounding           = "FX_ROUNDING"
+	EnvFXRateLock           = "FX_RATE_LOCK"
+	EnvFallbackCurrencies   = "WALLET_FALLBACK_CURRENCIES"
+	EnvMandatesPath         = "MANDATES_PATH"
+	EnvMandatesStatePath    = "MANDATES_STATE_PATH"
+	EnvMandateRetryAttempts = "MANDATE_RETRY_ATTEMPTS"
+	EnvMandateRetryInterval = "MANDATE_RETRY_INTERVAL"
+	EnvTenantsPath          = "TENANTS_PATH"
+	EnvSigningKeysPath      = "SIGNING_KEYS_PATH"
+	EnvSigningKeysRefresh   = "SIGNING_KEYS_REFRESH"
+	EnvEventSources         = "EVENT_SOURCES"
+)
+
+type (
+	Config struct {
+		Repository Repository
+		EventBus   EventBus
+		Handler    Handler
+		Retry      retry.Config
+		LogLevel   slog.Level
+		Telemetry  Telemetry
+		Fees       Fees
+		FX         FX
+		Mandates   Mandates
+		Signing    Signing
+		// CapturePath enables the recorder when set
+		CapturePath string
+		// AuditLogPath enables the hash-chained audit trail when set
+		AuditLogPath string
+		// TransactionsPath is the JSONL file the transaction history is appended to, the history
+		// is kept in memory when unset
+		TransactionsPath string
+		// TenantsPath is the JSON tenant registry, only events without tenant are accepted when unset
+		TenantsPath string
+	}
+
+	Repository struct {
+		Kind      string
+		TableName string
+		// SeedPath is a JSON or CSV fixture loaded into an empty repository
+		SeedPath string
+		// DataDir holds the log and snapshot of the file repository
+		DataDir string
+		// SQLDriver and SQLDSN open the database of the sql repository
+		SQLDriver string
+		SQLDSN    string
+	}
+
+	EventBus struct {
+		Kind string
+		Name string
+	}
+
+	Handler struct {
+		// Ordering is sequential or per_user, see handler.Ordering
+		Ordering string
+		// Workers bounds the groups of records processed at the same time. Only per_user
+		// ordering splits the batch in groups, sequential runs it as one whatever the workers
+		Workers int
+		// RecordTimeout bounds a single record, zero means no limit
+		RecordTimeout time.Duration
+		// SafetyMargin is the time kept before the invocation deadline to return the response
+		SafetyMargin time.Duration
+	}
+
+	Telemetry struct {
+		TracesExporter string
+		ServiceName    string
+	}
+
+	Fees struct {
+		// SchedulePath is the JSON fee schedule, no fee is charged when unset
+		SchedulePath string
+		// RevenueAccount is the wallet credited with the fees
+		RevenueAccount string
+	}
+
+	FX struct {
+		// RatesPath is the JSON file of the static rate provider, payments in a currency other
+		// than WalletCurrency fail when unset
+		RatesPath      string
+		WalletCurrency string
+		// Spread is the percentage added to the quoted rates
+		Spread   float64
+		Rounding string
+		// RateLock is how long the rate quoted for a payment is reused
+		RateLock time.Duration
+		// FallbackCurrencies are the balances tried, in order, when the one of the payment lacks funds
+		FallbackCurrencies []string
+	}
+
+	Mandates struct {
+		// Path is the JSON file seeding the mandates of the scheduler
+		Path string
+		// StatePath is the JSONL file every created or updated mandate is appended to, the
+		// mandates are kept in memory when unset
+		StatePath string
+		// RetryAttempts and RetryInterval retry the declined or failed cycles of the mandates
+		// created without their own retry
+		RetryAttempts int
+		RetryInterval time.Duration
+	}
+
+	Signing struct {
+		// KeysPath is the JSON file of the active signing keys, events are not verified when unset
+		KeysPath string
+		// KeysRefresh is how often the keys file is read again to pick up a rotation
+		KeysRefresh time.Duration
+		// Sources are the producers allowed by event type, as event_type:source items
+		Sources []string
+	}
+
+	// LookupFunc reads a single variable, os.LookupEnv in production
+	LookupFunc func(key string) (string, bool)
+)
+
+func Default() Config {
+	return Config{
+		Repository: Repository{Kind: RepositoryMemory, TableName: "wallets", SQLDriver: "sqlite"},
+		EventBus:   EventBus{Kind: EventBusConsole, Name: "payments"},
+		Handler:    Handler{Ordering: OrderingSequential, Workers: 10, SafetyMargin: time.Second},
+		Retry:      retry.DefaultConfig(),
+		LogLevel:   slog.LevelInfo,
+		Telemetry:  Telemetry{TracesExporter: ExporterXRay, ServiceName: "wallet-service"},
+		Fees:       Fees{RevenueAccount: "revenue"},
+		FX:         FX{WalletCurrency: "EUR", Rounding: string(domain.RoundHalfUp), RateLock: 5 * time.Minute},
+		Mandates:   Mandates{RetryAttempts: 3, RetryInterval: 24 * time.Hour},
+		Signing:    Signing{KeysRefresh: 5 * time.Minute},
+	}
+}
+
+// FromEnv loads the configuration from the process environment
+func FromEnv() (Config, error) {
+	return Load(os.LookupEnv)
+}
+
+// Load starts from Default and overrides every value present in the environment.
+// All the problems found are reported together
+func Load(lookup LookupFunc) (Config, error) {
+	cfg := Default()
+	p := parser{lookup: lookup}
+
+	p.kind(EnvRepositoryKind, &cfg.Repository.Kind)
+	p.string(EnvTableName, &cfg.Repository.TableName)
+	p.string(EnvSeedPath, &cfg.Repository.SeedPath)
+	p.string(EnvDataDir, &cfg.Repository.DataDir)
+	p.string(EnvSQLDriver, &cfg.Repository.SQLDriver)
+	p.string(EnvSQLDSN, &cfg.Repository.SQLDSN)
+	p.kind(EnvEventBusKind, &cfg.EventBus.Kind)
+	p.string(EnvEventBusName, &cfg.EventBus.Name)
+	p.kind(EnvOrdering, &cfg.Handler.Ordering)
+	p.int(EnvWorkers, &cfg.Handler.Workers)
+	p.duration(EnvRecordTimeout, &cfg.Handler.RecordTimeout)
+	p.duration(EnvSafetyMargin, &cfg.Handler.SafetyMargin)
+	p.int(EnvRetryMaxAttempts, &cfg.Retry.MaxAttempts)
+	p.duration(EnvRetryBaseDelay, &cfg.Retry.BaseDelay)
+	p.duration(EnvRetryMaxDelay, &cfg.Retry.MaxDelay)
+	p.duration(EnvRetryMaxElapsed, &cfg.Retry.MaxElapsed)
+	p.level(EnvLogLevel, &cfg.LogLevel)
+	p.kind(EnvTracesExporter, &cfg.Telemetry.TracesExporter)
+	p.string(EnvServiceName, &cfg.Telemetry.ServiceName)
+	p.string(EnvCapturePath, &cfg.CapturePath)
+	p.string(EnvAuditLogPath, &cfg.AuditLogPath)
+	p.string(EnvTransactionsPath, &cfg.TransactionsPath)
+	p.string(EnvFeeSchedulePath, &cfg.Fees.SchedulePath)
+	p.string(EnvFeeRevenue, &cfg.Fees.RevenueAccount)
+	p.string(EnvFXRatesPath, &cfg.FX.RatesPath)
+	p.string(EnvWalletCurrency, &cfg.FX.WalletCurrency)
+	p.float(EnvFXSpread, &cfg.FX.Spread)
+	p.kind(EnvFXRounding, &cfg.FX.Rounding)
+	p.duration(EnvFXRateLock, &cfg.FX.RateLock)
+	p.list(EnvFallbackCurrencies, &cfg.FX.FallbackCurrencies)
+	p.string(EnvMandatesPath, &cfg.Mandates.Path)
+	p.string(EnvMandatesStatePath, &cfg.Mandates.StatePath)
+	p.int(EnvMandateRetryAttempts, &cfg.Mandates.RetryAttempts)
+	p.duration(EnvMandateRetryInterval, &cfg.Mandates.RetryInterval)
+	p.string(EnvTenantsPath, &cfg.TenantsPath)
+	p.string(EnvSigningKeysPath, &cfg.Signing.KeysPath)
+	p.duration(EnvSigningKeysRefresh, &cfg.Signing.KeysRefresh)
+	p.list(EnvEventSources, &cfg.Signing.Sources)
+
+	if err := errors.Join(p.errs...); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the values that parse fine but can't be wired
+func (c Config) Validate() error {
+	var errs []error
+
+	switch c.Repository.Kind {
+	case RepositoryMemory:
+	case RepositoryFile:
+		if c.Repository.DataDir == "" {
+			errs = append(errs, fmt.Errorf("%s: required by the %s repository", EnvDataDir, RepositoryFile))
+		}
+	case RepositorySQL:
+		if c.Repository.SQLDSN == "" {
+			errs = append(errs, fmt.Errorf("%s: required by the %s repository", EnvSQLDSN, RepositorySQL))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("%s: unsupported repository %q", EnvRepositoryKind, c.Repository.Kind))
+	}
+	if c.Repository.TableName == "" {
+		errs = append(errs, fmt.Errorf("%s: must not be empty", EnvTableName))
+	}
+
+	switch c.EventBus.Kind {
+	case EventBusConsole, EventBusMemory:
+	default:
+		errs = append(errs, fmt.Errorf("%s: unsupported event bus %q", EnvEventBusKind, c.EventBus.Kind))
+	}
+	if c.EventBus.Name == "" {
+		errs = append(errs, fmt.Errorf("%s: must not be empty", EnvEventBusName))
+	}
+
+	switch c.Handler.Ordering {
+	case OrderingSequential, OrderingPerUser:
+	default:
+		errs = append(errs, fmt.Errorf("%s: unsupported ordering %q", EnvOrdering, c.Handler.Ordering))
+	}
+	if c.Handler.Workers < 1 {
+		errs = append(errs, fmt.Errorf("%s: must be at least 1, got %d", EnvWorkers, c.Handler.Workers))
+	}
+	if c.Handler.RecordTimeout < 0 {
+		errs = append(errs, fmt.Errorf("%s: must not be negative", EnvRecordTimeout))
+	}
+	if c.Handler.SafetyMargin < 0 {
+		errs = append(errs, fmt.Errorf("%s: must not be negative", EnvSafetyMargin))
+	}
+
+	if c.Retry.MaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("%s: must be at least 1, got %d", EnvRetryMaxAttempts, c.Retry.MaxAttempts))
+	}
+	if c.Retry.BaseDelay < 0 || c.Retry.MaxDelay < 0 || c.Retry.MaxElapsed < 0 {
+		errs = append(errs, errors.New("retry delays must not be negative"))
+	}
+	if c.Retry.MaxDelay < c.Retry.BaseDelay {
+		errs = append(errs, fmt.Errorf("%s: must not be lower than %s", EnvRetryMaxDelay, EnvRetryBaseDelay))
+	}
+
+	switch c.Telemetry.TracesExporter {
+	case ExporterXRay, ExporterStdout, ExporterNone:
+	default:
+		errs = append(errs, fmt.Errorf("%s: unsupported exporter %q", EnvTracesExporter, c.Telemetry.TracesExporter))
+	}
+
+	if c.Fees.SchedulePath != "" && c.Fees.RevenueAccount == "" {
+		errs = append(errs, fmt.Errorf("%s: required by %s", EnvFeeRevenue, EnvFeeSchedulePath))
+	}
+
+	if c.FX.WalletCurrency == "" {
+		errs = append(errs, fmt.Errorf("%s: must not be empty", EnvWalletCurrency))
+	}
+	switch domain.Rounding(c.FX.Rounding) {
+	case domain.RoundHalfUp, domain.RoundHalfEven, domain.RoundDown, domain.RoundUp:
+	default:
+		errs = append(errs, fmt.Errorf("%s: unsupported rounding %q", EnvFXRounding, c.FX.Rounding))
+	}
+	if c.FX.Spread < 0 {
+		errs = append(errs, fmt.Errorf("%s: must not be negative", EnvFXSpread))
+	}
+	if c.FX.RateLock < 0 {
+		errs = append(errs, fmt.Errorf("%s: must not be negative", EnvFXRateLock))
+	}
+	if len(c.FX.FallbackCurrencies) > 0 && c.FX.RatesPath == "" {
+		errs = append(errs, fmt.Errorf("%s: required by %s", EnvFXRatesPath, EnvFallbackCurrencies))
+	}
+
+	if c.Mandates.RetryAttempts < 0 {
+		errs = append(errs, fmt.Errorf("%s: must not be negative", EnvMandateRetryAttempts))
+	}
+	if c.Mandates.RetryInterval < 0 {
+		errs = append(errs, fmt.Errorf("%s: must not be negative", EnvMandateRetryInterval))
+	}
+
+	if c.Signing.KeysRefresh < 0 {
+		errs = append(errs, fmt.Errorf("%s: must not be negative", EnvSigningKeysRefresh))
+	}
+	if len(c.Signing.Sources) > 0 && c.Signing.KeysPath == "" {
+		errs = append(errs, fmt.Errorf("%s: required by %s", EnvSigningKeysPath, EnvEventSources))
+	}
+	for _, item := range c.Signing.Sources {
+		if eventType, source, ok := strings.Cut(item, ":"); !ok || eventType == "" || source == "" {
+			errs = append(errs, fmt.Errorf("%s: %q is not an event_type:source pair", EnvEventSources, item))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+// Policy prices the conversions into the wallet currency
+func (f FX) Policy() domain.FXPolicy {
+	return domain.FXPolicy{SpreadPercent: f.Spread, Rounding: domain.Rounding(f.Rounding), LockFor: f.RateLock}
+}
+
+// Fallbacks returns FallbackCurrencies as domain currencies
+func (f FX) Fallbacks() []domain.Currency {
+	currencies := make([]domain.Currency, 0, len(f.FallbackCurrencies))
+	for _, c := range f.FallbackCurrencies {
+		currencies = append(currencies, domain.Currency(c))
+	}
+	return currencies
+}
+
+// Retry is the retry of the mandates created without one
+func (m Mandates) Retry() domain.MandateRetry {
+	return domain.MandateRetry{Attempts: m.RetryAttempts, Interval: m.RetryInterval}
+}
+
+// Allowlist groups Sources by event type, nil when no source is restricted
+func (s Signing) Allowlist() map[string][]string {
+	if len(s.Sources) == 0 {
+		return nil
+	}
+	allowlist := make(map[string][]string)
+	for _, item := range s.Sources {
+		eventType, source, _ := strings.Cut(item, ":")
+		allowlist[eventType] = append(allowlist[eventType], source)
+	}
+	return allowlist
+}
+
+// parser keeps the defaults for unset variables and collects the parse errors
+type parser struct {
+	lookup LookupFunc
+	errs   []error
+}
+
+func (p *parser) get(key string) (string, bool) {
+	v, ok := p.lookup(key)
+	if !ok {
+		return "", false
+	}
+	v = strings.TrimSpace(v)
+	return v, v != ""
+}
+
+func (p *parser) string(key string, dst *string) {
+	if v, ok := p.get(key); ok {
+		*dst = v
+	}
+}
+
+// kind reads an adapter selector, matched case insensitively
+func (p *parser) kind(key string, dst *string) {
+	if v, ok := p.get(key); ok {
+		*dst = strings.ToLower(v)
+	}
+}
+
+// list reads a comma separated list, skipping the empty items
+func (p *parser) list(key string, dst *[]string) {
+	v, ok := p.get(key)
+	if !ok {
+		return
+	}
+	var items []string
+	for item := range strings.SplitSeq(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	*dst = items
+}
+
+func (p *parser) int(key string, dst *int) {
+	v, ok := p.get(key)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s: %q is not an integer", key, v))
+		return
+	}
+	*dst = n
+}
+
+func (p *parser) float(key string, dst *float64) {
+	v, ok := p.get(key)
+	if !ok {
+		return
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s: %q is not a number", key, v))
+		return
+	}
+	*dst = f
+}
+
+func (p *parser) duration(key string, dst *time.Duration) {
+	v, ok := p.get(key)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s: %q is not a duration", key, v))
+		return
+	}
+	*dst = d
+}
+
+func (p *parser) level(key string, dst *slog.Level) {
+	v, ok := p.get(key)
+	if !ok {
+		return
+	}
+	if err := dst.UnmarshalText([]byte(v)); err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s: %q is not a log level", key, v))
+	}
+}
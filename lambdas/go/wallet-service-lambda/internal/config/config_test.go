@@ -0,0 +1,251 @@
+package config_test
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return the defaults when nothing is set", testLoad_Defaults)
+	t.Run("should override the defaults with the environment", testLoad_Overrides)
+	t.Run("should ignore blank variables", testLoad_Blank)
+	t.Run("should report every malformed variable", testLoad_Malformed)
+	t.Run("should reject unsupported adapters", testLoad_UnsupportedAdapters)
+	t.Run("should reject inconsistent retry settings", testLoad_InvalidRetry)
+	t.Run("should require a data dir for the file repository", testLoad_FileRepository)
+	t.Run("should require a dsn for the sql repository", testLoad_SQLRepository)
+	t.Run("should reject event sources without signing keys or malformed", testLoad_InvalidSources)
+}
+
+func testLoad_Defaults(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	cfg, err := config.Load(env(nil))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, config.Default(), cfg)
+}
+
+func testLoad_Overrides(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	lookup := env(map[string]string{
+		config.EnvRepositoryKind:       "memory",
+		config.EnvTableName:            "wallets-prod",
+		config.EnvSeedPath:             "testdata/wallets.csv",
+		config.EnvEventBusKind:         "MEMORY",
+		config.EnvEventBusName:         "payments-prod",
+		config.EnvOrdering:             "per_user",
+		config.EnvWorkers:              "4",
+		config.EnvRecordTimeout:        "3s",
+		config.EnvSafetyMargin:         "500ms",
+		config.EnvRetryMaxAttempts:     "5",
+		config.EnvRetryBaseDelay:       "20ms",
+		config.EnvRetryMaxDelay:        "1s",
+		config.EnvRetryMaxElapsed:      "5s",
+		config.EnvLogLevel:             "debug",
+		config.EnvTracesExporter:       "stdout",
+		config.EnvServiceName:          "wallet-prod",
+		config.EnvCapturePath:          "/tmp/capture.jsonl",
+		config.EnvAuditLogPath:         "/tmp/audit.jsonl",
+		config.EnvTransactionsPath:     "/data/transactions.jsonl",
+		config.EnvFeeSchedulePath:      "/etc/fees.json",
+		config.EnvFeeRevenue:           "revenue-eu",
+		config.EnvFXRatesPath:          "/etc/rates.json",
+		config.EnvWalletCurrency:       "USD",
+		config.EnvFXSpread:             "1.5",
+		config.EnvFXRounding:           "HALF_EVEN",
+		config.EnvFXRateLock:           "30s",
+		config.EnvFallbackCurrencies:   "GBP, ,CHF",
+		config.EnvMandatesPath:         "/etc/mandates.json",
+		config.EnvMandatesStatePath:    "/data/mandates.jsonl",
+		config.EnvMandateRetryAttempts: "1",
+		config.EnvMandateRetryInterval: "12h",
+		config.EnvTenantsPath:          "/etc/tenants.json",
+		config.EnvSigningKeysPath:      "/etc/signing-keys.json",
+		config.EnvSigningKeysRefresh:   "1m",
+		config.EnvEventSources:         "PaymentInit:saga, PaymentInit:checkout",
+	})
+
+	// WHEN
+	cfg, err := config.Load(lookup)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, config.Repository{Kind: config.RepositoryMemory, TableName: "wallets-prod", SeedPath: "testdata/wallets.csv", SQLDriver: "sqlite"}, cfg.Repository)
+	assert.Equal(t, config.EventBus{Kind: config.EventBusMemory, Name: "payments-prod"}, cfg.EventBus)
+	assert.Equal(t, config.Handler{Ordering: config.OrderingPerUser, Workers: 4, RecordTimeout: 3 * time.Second, SafetyMargin: 500 * time.Millisecond}, cfg.Handler)
+	assert.Equal(t, 5, cfg.Retry.MaxAttempts)
+	assert.Equal(t, 20*time.Millisecond, cfg.Retry.BaseDelay)
+	assert.Equal(t, time.Second, cfg.Retry.MaxDelay)
+	assert.Equal(t, 5*time.Second, cfg.Retry.MaxElapsed)
+	assert.Equal(t, slog.LevelDebug, cfg.LogLevel)
+	assert.Equal(t, config.Telemetry{TracesExporter: config.ExporterStdout, ServiceName: "wallet-prod"}, cfg.Telemetry)
+	assert.Equal(t, "/tmp/capture.jsonl", cfg.CapturePath)
+	assert.Equal(t, "/tmp/audit.jsonl", cfg.AuditLogPath)
+	assert.Equal(t, "/data/transactions.jsonl", cfg.TransactionsPath)
+	assert.Equal(t, config.Fees{SchedulePath: "/etc/fees.json", RevenueAccount: "revenue-eu"}, cfg.Fees)
+	assert.Equal(t, config.FX{RatesPath: "/etc/rates.json", WalletCurrency: "USD", Spread: 1.5, Rounding: "half_even", RateLock: 30 * time.Second, FallbackCurrencies: []string{"GBP", "CHF"}}, cfg.FX)
+	assert.Equal(t, config.Mandates{Path: "/etc/mandates.json", StatePath: "/data/mandates.jsonl", RetryAttempts: 1, RetryInterval: 12 * time.Hour}, cfg.Mandates)
+	assert.Equal(t, "/etc/tenants.json", cfg.TenantsPath)
+	assert.Equal(t, config.Signing{KeysPath: "/etc/signing-keys.json", KeysRefresh: time.Minute, Sources: []string{"PaymentInit:saga", "PaymentInit:checkout"}}, cfg.Signing)
+	assert.Equal(t, map[string][]string{"PaymentInit": {"saga", "checkout"}}, cfg.Signing.Allowlist())
+}
+
+func testLoad_Blank(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	lookup := env(map[string]string{config.EnvTableName: "  ", config.EnvRetryMaxAttempts: ""})
+
+	// WHEN
+	cfg, err := config.Load(lookup)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, config.Default(), cfg)
+}
+
+func testLoad_Malformed(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	lookup := env(map[string]string{
+		config.EnvRetryMaxAttempts: "three",
+		config.EnvRetryBaseDelay:   "10",
+		config.EnvLogLevel:         "verbose",
+		config.EnvFXSpread:         "1,5",
+	})
+
+	// WHEN
+	_, err := config.Load(lookup)
+
+	// THEN
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid configuration")
+	assert.ErrorContains(t, err, config.EnvRetryMaxAttempts)
+	assert.ErrorContains(t, err, config.EnvRetryBaseDelay)
+	assert.ErrorContains(t, err, config.EnvLogLevel)
+	assert.ErrorContains(t, err, config.EnvFXSpread)
+}
+
+func testLoad_UnsupportedAdapters(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	lookup := env(map[string]string{
+		config.EnvRepositoryKind:     "mongo",
+		config.EnvEventBusKind:       "kafka",
+		config.EnvTracesExporter:     "zipkin",
+		config.EnvFXRounding:         "nearest",
+		config.EnvFallbackCurrencies: "USD",
+	})
+
+	// WHEN
+	_, err := config.Load(lookup)
+
+	// THEN
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `unsupported repository "mongo"`)
+	assert.ErrorContains(t, err, `unsupported event bus "kafka"`)
+	assert.ErrorContains(t, err, `unsupported exporter "zipkin"`)
+	assert.ErrorContains(t, err, `unsupported rounding "nearest"`)
+	assert.ErrorContains(t, err, config.EnvFXRatesPath+": required by "+config.EnvFallbackCurrencies)
+}
+
+func testLoad_InvalidRetry(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	lookup := env(map[string]string{
+		config.EnvRetryMaxAttempts:     "0",
+		config.EnvRetryBaseDelay:       "1s",
+		config.EnvRetryMaxDelay:        "100ms",
+		config.EnvMandateRetryAttempts: "-1",
+	})
+
+	// WHEN
+	_, err := config.Load(lookup)
+
+	// THEN
+	require.Error(t, err)
+	assert.ErrorContains(t, err, config.EnvRetryMaxAttempts)
+	assert.ErrorContains(t, err, config.EnvRetryMaxDelay)
+	assert.ErrorContains(t, err, config.EnvMandateRetryAttempts)
+}
+
+func testLoad_FileRepository(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	withoutDir := env(map[string]string{config.EnvRepositoryKind: "file"})
+	withDir := env(map[string]string{config.EnvRepositoryKind: "file", config.EnvDataDir: "/var/lib/wallets"})
+
+	// WHEN
+	_, errWithoutDir := config.Load(withoutDir)
+	cfg, errWithDir := config.Load(withDir)
+
+	// THEN
+	assert.ErrorContains(t, errWithoutDir, config.EnvDataDir)
+	require.NoError(t, errWithDir)
+	assert.Equal(t, "/var/lib/wallets", cfg.Repository.DataDir)
+}
+
+func testLoad_SQLRepository(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	withoutDSN := env(map[string]string{config.EnvRepositoryKind: "sql"})
+	withDSN := env(map[string]string{config.EnvRepositoryKind: "sql", config.EnvSQLDSN: "wallets.db"})
+
+	// WHEN
+	_, errWithoutDSN := config.Load(withoutDSN)
+	cfg, errWithDSN := config.Load(withDSN)
+
+	// THEN
+	assert.ErrorContains(t, errWithoutDSN, config.EnvSQLDSN)
+	require.NoError(t, errWithDSN)
+	assert.Equal(t, "sqlite", cfg.Repository.SQLDriver)
+	assert.Equal(t, "wallets.db", cfg.Repository.SQLDSN)
+}
+
+func testLoad_InvalidSources(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	withoutKeys := env(map[string]string{config.EnvEventSources: "PaymentInit:saga"})
+	malformed := env(map[string]string{
+		config.EnvSigningKeysPath:    "/etc/signing-keys.json",
+		config.EnvSigningKeysRefresh: "-1s",
+		config.EnvEventSources:       "PaymentInit,:saga",
+	})
+
+	// WHEN
+	_, errWithoutKeys := config.Load(withoutKeys)
+	_, errMalformed := config.Load(malformed)
+
+	// THEN
+	assert.ErrorContains(t, errWithoutKeys, config.EnvSigningKeysPath)
+	assert.ErrorContains(t, errMalformed, config.EnvSigningKeysRefresh)
+	assert.ErrorContains(t, errMalformed, `"PaymentInit" is not an event_type:source pair`)
+	assert.ErrorContains(t, errMalformed, `":saga" is not an event_type:source pair`)
+}
+
+// --- Helper Functions ---
+
+func env(vars map[string]string) config.LookupFunc {
+	return func(key string) (string, bool) {
+		v, ok := vars[key]
+		return v, ok
+	}
+}
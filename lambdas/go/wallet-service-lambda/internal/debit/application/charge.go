@@ -0,0 +1,128 @@
+package application
+
+import (
+	"context"
+	"slices"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// charge is the amount and fee debited from the balance in currency, empty for the wallet one.
+// conversion is set when the payment was charged in a currency other than the one of the balance
+type charge struct {
+	currency   domain.Currency
+	amount     domain.Amount
+	fee        domain.Amount
+	conversion *domain.Conversion
+}
+
+func (c charge) total() domain.Amount {
+	return c.amount + c.fee
+}
+
+// charge picks the balance debited for the request: the one of the payment currency, or the wallet
+// one when the wallet does not hold it, then the fallback currencies the wallet holds. The first
+// balance covering the payment and its fee wins. When none does the charge on the first one is
+// returned, so its debit fails with insufficient funds
+func (h *UseCaseHandler) charge(ctx context.Context, req Request, wallet domain.Wallet) (charge, error) {
+	var first charge
+
+	for i, currency := range h.candidates(ctx, req, wallet) {
+		c, err := h.priceIn(ctx, req, currency)
+		if err != nil {
+			return first, err
+		}
+		if i == 0 {
+			first = c
+		}
+		if wallet.Balance(currency) >= c.total() {
+			return c, nil
+		}
+	}
+
+	return first, nil
+}
+
+// candidates lists the balances charge tries, falling back on the currencies of the tenant of ctx
+func (h *UseCaseHandler) candidates(ctx context.Context, req Request, wallet domain.Wallet) []domain.Currency {
+	requested := h.balanceCurrency(req.Currency)
+	if !wallet.Holds(requested) {
+		requested = ""
+	}
+
+	candidates := []domain.Currency{requested}
+	for _, currency := range h.fallbackOf(ctx) {
+		currency = h.balanceCurrency(currency)
+		if wallet.Holds(currency) && !slices.Contains(candidates, currency) {
+			candidates = append(candidates, currency)
+		}
+	}
+	return candidates
+}
+
+// priceIn converts the request into the currency of the balance when they differ and prices its fee
+func (h *UseCaseHandler) priceIn(ctx context.Context, req Request, currency domain.Currency) (charge, error) {
+	c := charge{currency: currency, amount: req.Amount}
+
+	from, to := h.currencyCode(h.balanceCurrency(req.Currency)), h.currencyCode(currency)
+	if from != to {
+		conversion, err := h.convert(ctx, req, req.Amount, from, to)
+		if err != nil {
+			return c, err
+		}
+		c.amount = conversion.Converted
+		c.conversion = &conversion
+	}
+
+	fee, err := h.feeIn(ctx, req, c)
+	if err != nil {
+		return c, err
+	}
+	c.fee = fee
+	return c, nil
+}
+
+// feeIn prices the fee of the charge in the currency of its balance. The schedule is in the wallet
+// currency, so the fee on another balance is priced on the payment in the wallet currency and then
+// converted at the rate locked for the payment, as its amount is
+func (h *UseCaseHandler) feeIn(ctx context.Context, req Request, c charge) (domain.Amount, error) {
+	if c.currency == "" || h.fees == nil {
+		return h.fee(req, c.amount), nil
+	}
+
+	walletCode, balanceCode := h.currencyCode(""), h.currencyCode(c.currency)
+	priced := req.Amount
+	if from := h.currencyCode(h.balanceCurrency(req.Currency)); from != walletCode {
+		conversion, err := h.convert(ctx, req, req.Amount, from, walletCode)
+		if err != nil {
+			return 0, err
+		}
+		priced = conversion.Converted
+	}
+
+	fee := h.fee(req, priced)
+	if fee == 0 {
+		return 0, nil
+	}
+	conversion, err := h.convert(ctx, req, fee, walletCode, balanceCode)
+	if err != nil {
+		return 0, err
+	}
+	return conversion.Converted, nil
+}
+
+// balanceCurrency is the key of the balance in currency, empty for the wallet one
+func (h *UseCaseHandler) balanceCurrency(currency domain.Currency) domain.Currency {
+	if currency == h.walletCurrency {
+		return ""
+	}
+	return currency
+}
+
+// currencyCode is the currency of the balance keyed by currency
+func (h *UseCaseHandler) currencyCode(currency domain.Currency) domain.Currency {
+	if currency == "" {
+		return h.walletCurrency
+	}
+	return currency
+}
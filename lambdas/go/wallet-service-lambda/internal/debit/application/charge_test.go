@@ -0,0 +1,226 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/ports/mocks"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseCaseHandlerBalances(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should debit the balance of the payment currency", testBalances_HeldCurrency)
+	t.Run("should fall back to another currency when the balance lacks funds", testBalances_Fallback)
+	t.Run("should debit no balance when none covers the payment", testBalances_InsufficientFunds)
+	t.Run("should price the fee in the wallet currency and credit it in the debited one", testBalances_Fee)
+	t.Run("should convert the fee at the rate locked for the payment", testBalances_FeeLockedRate)
+	t.Run("should record the movement in the debited currency", testBalances_Transactions)
+}
+
+func testBalances_HeldCurrency(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(multiCurrencyWallet(100, 50))
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 20, Currency: "USD", CorrelationID: "corr-1"}
+
+	busMock.EXPECT().Publish(mock.Anything, ports.BalanceDebitedRequest{
+		PaymentID:     "pay-1",
+		UserID:        "user-1",
+		AmountDebited: 20,
+		Principal:     20,
+		AmountLeft:    30,
+		EventName:     domain.BalanceDebitedEventName,
+		CorrelationID: "corr-1",
+		Currency:      "USD",
+		Balances:      map[domain.Currency]domain.Amount{"EUR": 100, "USD": 30},
+	}).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithExchangeRates(rates, fxPolicy),
+		application.WithWalletCurrency("EUR"),
+	)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "user-1", Amount: 100, Balances: map[domain.Currency]domain.Amount{"USD": 30}, Version: 2},
+	}, repo.Wallets())
+	rates.AssertNotCalled(t, "Rate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func testBalances_Fallback(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(multiCurrencyWallet(5, 50))
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 20, CorrelationID: "corr-1"}
+
+	rates.EXPECT().Rate(mock.Anything, domain.Currency("EUR"), domain.Currency("USD")).
+		Return(domain.Rate{ID: "eur-usd-1", From: "EUR", To: "USD", Value: 1.1}, nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.Currency == "USD" && req.OriginalCurrency == "EUR" && req.OriginalAmount == 20 && req.RateID == "eur-usd-1"
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithExchangeRates(rates, fxPolicy),
+		application.WithWalletCurrency("EUR"),
+		application.WithCurrencyFallback("USD"),
+	)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, err)
+	wallet := repo.Wallets()[0]
+	assert.Equal(t, domain.Amount(5), wallet.Amount)
+	assert.InDelta(t, 27.78, float64(wallet.Balance("USD")), 1e-9)
+}
+
+func testBalances_InsufficientFunds(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(multiCurrencyWallet(5, 10))
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{UserID: "user-1", Amount: 20, CorrelationID: "corr-1"}
+
+	rates.EXPECT().Rate(mock.Anything, domain.Currency("EUR"), domain.Currency("USD")).
+		Return(domain.Rate{ID: "eur-usd-1", From: "EUR", To: "USD", Value: 1.1}, nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithExchangeRates(rates, fxPolicy),
+		application.WithWalletCurrency("EUR"),
+		application.WithCurrencyFallback("USD"),
+	)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.True(t, domain.IsInsufficientFunds(err))
+	assert.Equal(t, []domain.Wallet{multiCurrencyWallet(5, 10)}, repo.Wallets())
+	busMock.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func testBalances_Fee(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		multiCurrencyWallet(100, 50),
+		domain.Wallet{UserID: "revenue", Amount: 0, Version: 1},
+	)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 20, Currency: "USD", CorrelationID: "corr-1"}
+
+	rates.EXPECT().Rate(mock.Anything, domain.Currency("USD"), domain.Currency("EUR")).
+		Return(domain.Rate{ID: "usd-eur-1", From: "USD", To: "EUR", Value: 0.9}, nil).Once()
+	rates.EXPECT().Rate(mock.Anything, domain.Currency("EUR"), domain.Currency("USD")).
+		Return(domain.Rate{ID: "eur-usd-1", From: "EUR", To: "USD", Value: 1.1}, nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.Currency == "USD" && req.Principal == 20 && req.Fee == 1.11 && req.RateID == ""
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithExchangeRates(rates, fxPolicy),
+		application.WithWalletCurrency("EUR"),
+		application.WithFeeSchedule(schedule, "revenue"),
+	)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, err)
+	wallets := repo.Wallets()
+	assert.InDelta(t, 1.11, float64(wallets[0].Balance("USD")), 1e-9)
+	assert.Equal(t, domain.Amount(100), wallets[1].Amount)
+	assert.InDelta(t, 28.89, float64(wallets[1].Balance("USD")), 1e-9)
+}
+
+func testBalances_FeeLockedRate(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		multiCurrencyWallet(5, 50),
+		domain.Wallet{UserID: "revenue", Amount: 0, Version: 1},
+	)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 20, CorrelationID: "corr-1", MerchantCategory: "travel"}
+
+	rates.EXPECT().Rate(mock.Anything, domain.Currency("EUR"), domain.Currency("USD")).
+		Return(domain.Rate{ID: "eur-usd-1", From: "EUR", To: "USD", Value: 1.1}, nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.Currency == "USD" && req.Principal == 22.22 && req.Fee == 2.22 && req.RateID == "eur-usd-1"
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithExchangeRates(rates, fxPolicy),
+		application.WithWalletCurrency("EUR"),
+		application.WithCurrencyFallback("USD"),
+		application.WithFeeSchedule(schedule, "revenue"),
+	)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, err)
+	wallets := repo.Wallets()
+	assert.InDelta(t, 2.22, float64(wallets[0].Balance("USD")), 1e-9)
+	assert.InDelta(t, 25.56, float64(wallets[1].Balance("USD")), 1e-9)
+}
+
+func testBalances_Transactions(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(multiCurrencyWallet(100, 50))
+	busMock := mocks.NewMockEventBusProcessor(t)
+	transactions := repository.NewInMemoryTransactionRepository()
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 20, Currency: "USD", CorrelationID: "corr-1"}
+
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithWalletCurrency("EUR"),
+		application.WithTransactionRepository(transactions),
+	)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, err)
+	recorded := transactions.Transactions()
+	require.Len(t, recorded, 1)
+	assert.Equal(t, domain.Currency("USD"), recorded[0].Currency)
+	assert.Equal(t, domain.Amount(30), recorded[0].BalanceAfter)
+}
+
+// --- Helper Functions ---
+
+func multiCurrencyWallet(eur, usd domain.Amount) domain.Wallet {
+	return domain.Wallet{UserID: "user-1", Amount: eur, Balances: map[domain.Currency]domain.Amount{"USD": usd}, Version: 1}
+}
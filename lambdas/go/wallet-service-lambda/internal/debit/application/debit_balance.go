@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"maps"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/retry"
 	"github.com/payment-processor/internal/debit/domain"
 	"github.com/payment-processor/internal/debit/infra/repository"
 	"go.opentelemetry.io/otel"
@@ -13,106 +17,425 @@ import (
 	"go.opentelemetry.io/otel/codes"
 )
 
-const maxRetries = 3
-
 type (
 	Request struct {
+		PaymentID     string
 		UserID        domain.UserID
 		Amount        domain.Amount
 		CorrelationID string
+		// MerchantCategory and UserTier select the fee rule of the payment
+		MerchantCategory string
+		UserTier         string
+		// Currency of Amount, empty when it is the one of the wallet
+		Currency domain.Currency
 	}
 
 	UseCaseHandler struct {
 		walletRepo     ports.WalletRepository
 		eventProcessor ports.EventBusProcessor
+		retryPolicy    *retry.Policy
+		auditTrail     ports.AuditTrail
+		actor          string
+		transactions   ports.TransactionRepository
+		fees           *domain.FeeSchedule
+		revenueAccount domain.UserID
+		fx             *exchange
+		walletCurrency domain.Currency
+		fallback       []domain.Currency
+		tenants        domain.Tenants
+		now            func() time.Time
 	}
+
+	Option func(*UseCaseHandler)
 )
 
+// WithRetryPolicy replaces the default policy used for lock conflicts and transient errors
+func WithRetryPolicy(p *retry.Policy) Option {
+	return func(h *UseCaseHandler) { h.retryPolicy = p }
+}
+
+// WithAuditTrail records the outcome of every request in trail, signed by actor
+func WithAuditTrail(trail ports.AuditTrail, actor string) Option {
+	return func(h *UseCaseHandler) {
+		h.auditTrail = trail
+		h.actor = actor
+	}
+}
+
+// WithTransactionRepository records every applied debit in the history of the wallet
+func WithTransactionRepository(repo ports.TransactionRepository) Option {
+	return func(h *UseCaseHandler) { h.transactions = repo }
+}
+
+// WithClock replaces the clock stamping the transactions and expiring the locked rates
+func WithClock(now func() time.Time) Option {
+	return func(h *UseCaseHandler) { h.now = now }
+}
+
+// WithFeeSchedule charges the fee of every debit on top of its amount and credits it to the
+// revenue account, a wallet that has to exist in the repository. Payments of the revenue account
+// itself are rejected
+func WithFeeSchedule(schedule domain.FeeSchedule, revenueAccount domain.UserID) Option {
+	return func(h *UseCaseHandler) {
+		h.fees = &schedule
+		h.revenueAccount = revenueAccount
+	}
+}
+
 func (h *UseCaseHandler) Handle(ctx context.Context, req Request) error {
 	tracer := otel.Tracer("wallet-service.application")
 	ctx, span := tracer.Start(ctx, "UseCase.HandleDebit")
 	defer span.End()
 
 	span.SetAttributes(
+		attribute.String("tenant.id", string(domain.TenantFrom(ctx))),
 		attribute.String("user.id", string(req.UserID)),
 		attribute.Float64("debit.amount", float64(req.Amount)),
 	)
 
-	slog.InfoContext(ctx, "Handling debit request", "userID", req.UserID)
-
-	var err error
-	var wallet domain.Wallet
+	slog.InfoContext(ctx, "Handling debit request", "userID", req.UserID, "currency", req.Currency)
 
-	for i := 0; i < maxRetries; i++ {
-		readCtx, readSpan := tracer.Start(ctx, "Repository.Get")
-		wallet, err = h.walletRepo.Get(readCtx, req.UserID)
-		readSpan.End()
+	err := h.admit(ctx, req.Amount, req.Currency)
+	if err == nil && h.fees != nil && req.UserID == h.revenueAccount {
+		err = domain.NewRevenueAccountDebitError(string(req.UserID))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Debit rejected")
+		slog.WarnContext(ctx, "debit rejected by the tenant", "userID", req.UserID, "error", err)
+		h.audit(ctx, newAuditRecord(req, charge{currency: h.balanceCurrency(req.Currency)}, ports.AuditOutcomeRejected, 0, nil, nil), err)
+		return err
+	}
 
-		if err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, "Failed to get wallet")
-			slog.ErrorContext(ctx, "Error getting funds for user", "userID", req.UserID, "error", err)
-			return domain.NewGetFundsError(string(req.UserID), err) // Error no recuperable, salimos.
-		}
+	var result debitAttempt
+	attempt := 0
 
-		if err = wallet.Debit(req.Amount); err != nil {
-			span.RecordError(err)
-			span.SetStatus(codes.Error, "Insufficient funds")
-			slog.ErrorContext(ctx, "Error debiting amount from wallet", "amount", req.Amount, "userID", req.UserID, "error", err)
-			return err
-		}
+	err = h.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		attempt++
 
-		updateCtx, updateSpan := tracer.Start(ctx, "Repository.UpdateWithOutbox")
-		err = h.walletRepo.Update(updateCtx, wallet)
-		updateSpan.End()
+		var err error
+		result, err = h.debit(ctx, req, attempt)
+		return err
+	}, isTransient)
 
-		if err == nil {
-			slog.InfoContext(ctx, "Debited amount for user %s", "userID", req.UserID)
-			break
-		}
-
-		// Optimistic blocking
-		if errors.Is(err, repository.ErrVersionMismatch) {
-			slog.WarnContext(ctx, "version mismatch detected, retrying transaction", "attempt", i+1, "userId", req.UserID)
-			continue
-		}
+	c := result.charge
+	before := balanceIn(result.read, c.currency)
 
+	if errors.Is(err, retry.ErrExhausted) && errors.Is(err, repository.ErrVersionMismatch) {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, "Unrecoverable repository error")
-		slog.ErrorContext(ctx, "unrecoverable repository error on update", "error", err, "userId", req.UserID)
-		return domain.NewDebitFundsError(string(req.UserID), err)
+		span.SetStatus(codes.Error, "Transaction failed after max retries")
+		slog.ErrorContext(ctx, "transaction failed after max retries", "error", err, "userId", req.UserID)
+		err = domain.NewMaxRetriesError(string(req.UserID), err)
+		h.audit(ctx, newAuditRecord(req, c, ports.AuditOutcomeRetriesExhausted, attempt, before, nil), err)
+		return err
+	}
+	if domain.IsInsufficientFunds(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Debit failed")
+		h.audit(ctx, newAuditRecord(req, c, ports.AuditOutcomeInsufficientFunds, attempt, before, before), err)
+		return err
+	}
+	if domain.IsPaymentApplied(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Payment already applied")
+		h.audit(ctx, newAuditRecord(req, c, ports.AuditOutcomeAlreadyApplied, attempt, before, before), err)
+		fallback := toDebitEventRequest(domain.TenantFrom(ctx), result.read, req, c, h.walletCurrency)
+		if pubErr := h.republish(context.WithoutCancel(ctx), req, &fallback); pubErr != nil {
+			span.RecordError(pubErr)
+			return pubErr
+		}
+		return err
 	}
-
 	if err != nil {
 		span.RecordError(err)
-		span.SetStatus(codes.Error, "Transaction failed after max retries")
-		slog.ErrorContext(ctx, "transaction failed after max retries", "error", err, "userId", req.UserID)
-		return domain.NewMaxRetriesError(string(req.UserID), err)
+		span.SetStatus(codes.Error, "Debit failed")
+		h.audit(ctx, newAuditRecord(req, c, ports.AuditOutcomeFailed, attempt, before, nil), err)
+		return err
 	}
 
-	if err = h.eventProcessor.Publish(ctx, toDebitEventRequest(wallet, req.Amount)); err != nil {
+	span.SetAttributes(attribute.Float64("debit.fee", float64(c.fee)), attribute.String("debit.currency", string(h.currencyCode(c.currency))))
+	if c.conversion != nil {
+		span.SetAttributes(attribute.String("fx.rate_id", c.conversion.RateID), attribute.Float64("fx.converted", float64(c.conversion.Converted)))
+	}
+
+	slog.InfoContext(ctx, "Debited amount for user", "userID", req.UserID, "attempts", attempt, "fee", c.fee, "currency", c.currency)
+
+	// the debit is committed: a deadline or safety margin reached from here on must not fail the
+	// request, its redelivery would debit the wallet again. The retry policy still bounds the publish
+	ctx = context.WithoutCancel(ctx)
+
+	h.recordTransaction(ctx, req, domain.TransactionDebit, result.wallet, c)
+	if c.fee > 0 {
+		h.recordTransaction(ctx, req, domain.TransactionCredit, result.revenue, charge{currency: c.currency, amount: c.fee})
+	}
+
+	after := balanceIn(result.wallet, c.currency)
+
+	err = h.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return h.eventProcessor.Publish(ctx, toDebitEventRequest(domain.TenantFrom(ctx), result.wallet, req, c, h.walletCurrency))
+	}, domain.IsRetryable)
+	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, "Publish event failed")
 		slog.ErrorContext(ctx, "error publishing event after successful debit", "error", err)
-		return domain.NewPublishMessageError(string(req.UserID), err)
+		err = domain.NewPublishMessageError(string(req.UserID), err)
+		h.audit(ctx, newAuditRecord(req, c, ports.AuditOutcomePublishFailed, attempt, before, after), err)
+		return err
 	}
 
+	h.audit(ctx, newAuditRecord(req, c, ports.AuditOutcomeDebited, attempt, before, after), nil)
+
 	slog.InfoContext(ctx, "Finished request for user %s", "userID", req.UserID)
 	return nil
 }
 
-func toDebitEventRequest(wallet domain.Wallet, amountToDebit domain.Amount) ports.BalanceDebitedRequest {
-	return ports.BalanceDebitedRequest{
+// fee is zero when no schedule is configured
+func (h *UseCaseHandler) fee(req Request, amount domain.Amount) domain.Amount {
+	if h.fees == nil {
+		return 0
+	}
+	return h.fees.Fee(amount, req.MerchantCategory, req.UserTier)
+}
+
+// recordTransaction adds the movement of the charge to the history of the wallet, as written, with
+// its fee and conversion, so the event of the payment can be rebuilt from it. As with the audit
+// trail, a failure is only logged since the debit is already applied. The id is derived from the
+// payment, so a redelivered payment finds its movement already recorded. Requests without payment
+// id cannot be matched and get a random one
+func (h *UseCaseHandler) recordTransaction(ctx context.Context, req Request, txType domain.TransactionType, wallet domain.Wallet, c charge) {
+	if h.transactions == nil {
+		return
+	}
+
+	tx := domain.Transaction{
+		ID:            transactionID(ctx, req.PaymentID, wallet.UserID, txType),
+		PaymentID:     req.PaymentID,
+		CorrelationID: req.CorrelationID,
 		UserID:        wallet.UserID,
-		AmountDebited: amountToDebit,
-		AmountLeft:    wallet.Amount,
+		Type:          txType,
+		Amount:        c.total(),
+		Currency:      c.currency,
+		BalanceAfter:  wallet.Balance(c.currency),
+		Timestamp:     h.now().UTC(),
+		Fee:           c.fee,
+	}
+	if c.conversion != nil {
+		tx.OriginalAmount = c.conversion.Original
+		tx.OriginalCurrency = c.conversion.From
+		tx.RateID = c.conversion.RateID
+		tx.Rate = c.conversion.Rate
+	}
+	err := h.transactions.Append(ctx, tx)
+	if errors.Is(err, repository.ErrDuplicateTransaction) {
+		slog.InfoContext(ctx, "transaction already recorded", "userId", wallet.UserID, "paymentId", req.PaymentID, "type", txType)
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record transaction", "error", err, "userId", wallet.UserID, "paymentId", req.PaymentID)
+	}
+}
+
+func transactionID(ctx context.Context, paymentID string, user domain.UserID, txType domain.TransactionType) string {
+	if paymentID == "" {
+		return uuid.NewString()
+	}
+	return domain.TransactionID(domain.TenantFrom(ctx), paymentID, user, txType)
+}
+
+// Drop records a request rejected before reaching the wallet, as the handler does with invalid events
+func (h *UseCaseHandler) Drop(ctx context.Context, req Request, reason error) {
+	h.audit(ctx, newAuditRecord(req, charge{}, ports.AuditOutcomeValidationDropped, 0, nil, nil), reason)
+}
+
+// Reject records a request whose event could not be authenticated. Its fields are as claimed by
+// an unknown producer
+func (h *UseCaseHandler) Reject(ctx context.Context, req Request, reason error) {
+	h.audit(ctx, newAuditRecord(req, charge{}, ports.AuditOutcomeUnauthenticated, 0, nil, nil), reason)
+}
+
+// audit writes the record to the audit trail. A failed write is logged and not returned: the
+// debit may already be applied and the redelivery of the message would apply it twice
+func (h *UseCaseHandler) audit(ctx context.Context, record ports.AuditRecord, err error) {
+	if h.auditTrail == nil {
+		return
+	}
+
+	record.Actor = h.actor
+	record.TenantID = domain.TenantFrom(ctx)
+	if err != nil {
+		record.Reason = err.Error()
+		var domainErr *domain.Error
+		if errors.As(err, &domainErr) {
+			record.ErrorCode = domainErr.Code
+		}
+	}
+
+	if appendErr := h.auditTrail.Append(ctx, record); appendErr != nil {
+		slog.ErrorContext(ctx, "failed to write audit record", "error", appendErr, "outcome", record.Outcome, "userId", record.UserID)
+	}
+}
+
+// debitAttempt is the wallet as read and as debited by a single attempt, the revenue account as
+// credited when a fee is charged and what was charged
+type debitAttempt struct {
+	read, wallet, revenue domain.Wallet
+	charge                charge
+}
+
+// debit runs a single read-debit-write attempt. The write carries the payment id, so a payment the
+// wallet already applied is rejected by the same write instead of debited twice, even when its
+// transaction or its mandate cycle were lost. Both wallets are written together, so the fee is
+// never credited without its debit, but only the debited one is version checked: the revenue
+// account is credited with an increment, so concurrent debits don't conflict on it. Version
+// mismatches are returned as is so the retry policy can tell them apart from the errors that end
+// the request
+func (h *UseCaseHandler) debit(ctx context.Context, req Request, attempt int) (debitAttempt, error) {
+	tracer := otel.Tracer("wallet-service.application")
+
+	readCtx, readSpan := tracer.Start(ctx, "Repository.Get")
+	read, err := h.walletRepo.Get(readCtx, req.UserID)
+	readSpan.End()
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Error getting funds for user", "userID", req.UserID, "attempt", attempt, "error", err)
+		return debitAttempt{}, domain.NewGetFundsError(string(req.UserID), err)
+	}
+
+	result := debitAttempt{read: read, wallet: read}
+
+	// the balance left may no longer cover a payment already applied, which would then be
+	// declined instead of published again
+	if _, ok := h.recordedDebit(ctx, req.PaymentID, req.UserID); ok {
+		slog.WarnContext(ctx, "payment already recorded", "paymentId", req.PaymentID, "userId", req.UserID)
+		return result, domain.NewPaymentAppliedError(string(req.UserID), req.PaymentID, repository.ErrPaymentApplied)
+	}
+
+	result.charge, err = h.charge(ctx, req, read)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error pricing the payment", "currency", req.Currency, "userID", req.UserID, "error", err)
+		return result, err
+	}
+
+	c := result.charge
+	if err = result.wallet.DebitIn(c.currency, c.total()); err != nil {
+		slog.ErrorContext(ctx, "Error debiting amount from wallet", "amount", c.amount, "fee", c.fee, "currency", c.currency, "userID", req.UserID, "error", err)
+		return result, err
+	}
+
+	result.wallet.Payment = req.PaymentID
+
+	updateCtx, updateSpan := tracer.Start(ctx, "Repository.UpdateWithOutbox")
+	if c.fee > 0 {
+		result.revenue, err = h.walletRepo.UpdateAndCredit(updateCtx, result.wallet, ports.Credit{UserID: h.revenueAccount, Currency: c.currency, Amount: c.fee})
+	} else {
+		err = h.walletRepo.Update(updateCtx, result.wallet)
+	}
+	updateSpan.End()
+
+	// Optimistic blocking
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		slog.WarnContext(ctx, "version mismatch detected", "attempt", attempt, "userId", req.UserID)
+		return result, err
+	}
+	if errors.Is(err, repository.ErrPaymentApplied) {
+		slog.WarnContext(ctx, "payment already applied", "paymentId", req.PaymentID, "userId", req.UserID)
+		return result, domain.NewPaymentAppliedError(string(req.UserID), req.PaymentID, err)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error on update", "error", err, "attempt", attempt, "userId", req.UserID)
+		return result, domain.NewDebitFundsError(string(req.UserID), err)
+	}
+
+	return result, nil
+}
+
+// isTransient tells the errors worth another attempt: optimistic lock conflicts and dependencies
+// reported as temporarily unavailable
+func isTransient(err error) bool {
+	return errors.Is(err, repository.ErrVersionMismatch) || domain.IsRetryable(err)
+}
+
+// newAuditRecord audits the amount charged when the payment was converted, the one requested otherwise
+func newAuditRecord(req Request, c charge, outcome ports.AuditOutcome, attempts int, before, after *domain.Amount) ports.AuditRecord {
+	amount := req.Amount
+	if c.conversion != nil {
+		amount = c.amount
+	}
+
+	return ports.AuditRecord{
+		Outcome:       outcome,
+		PaymentID:     req.PaymentID,
+		CorrelationID: req.CorrelationID,
+		UserID:        req.UserID,
+		Amount:        amount,
+		Fee:           c.fee,
+		Currency:      c.currency,
+		BalanceBefore: before,
+		BalanceAfter:  after,
+		Attempts:      attempts,
+	}
+}
+
+// balanceIn is nil for wallets that were never read
+func balanceIn(wallet domain.Wallet, currency domain.Currency) *domain.Amount {
+	if wallet.UserID == "" {
+		return nil
+	}
+	balance := wallet.Balance(currency)
+	return &balance
+}
+
+// toDebitEventRequest names the debited balance by its currency unless it is the wallet one and the
+// payment was not converted, as before wallets held several currencies
+func toDebitEventRequest(tenant domain.TenantID, wallet domain.Wallet, req Request, c charge, walletCurrency domain.Currency) ports.BalanceDebitedRequest {
+	debited := ports.BalanceDebitedRequest{
+		TenantID:      tenant,
+		PaymentID:     req.PaymentID,
+		UserID:        wallet.UserID,
+		AmountDebited: c.total(),
+		Principal:     c.amount,
+		Fee:           c.fee,
+		AmountLeft:    wallet.Balance(c.currency),
 		EventName:     domain.BalanceDebitedEventName,
+		CorrelationID: req.CorrelationID,
+		Currency:      c.currency,
+		Balances:      balancesOf(wallet, walletCurrency),
+	}
+	if c.conversion != nil {
+		debited.OriginalAmount = c.conversion.Original
+		debited.OriginalCurrency = c.conversion.From
+		debited.Currency = c.conversion.To
+		debited.RateID = c.conversion.RateID
+		debited.Rate = c.conversion.Rate
+	}
+
+	return debited
+}
+
+// balancesOf lists every balance of a multi-currency wallet, the wallet currency one included when
+// it is known. Single currency wallets list none
+func balancesOf(wallet domain.Wallet, walletCurrency domain.Currency) map[domain.Currency]domain.Amount {
+	if len(wallet.Balances) == 0 {
+		return nil
 	}
+
+	balances := maps.Clone(wallet.Balances)
+	if walletCurrency != "" {
+		balances[walletCurrency] = wallet.Amount
+	}
+	return balances
 }
 
-func NewDebitBalanceUseCaseHandler(repo ports.WalletRepository, bus ports.EventBusProcessor) *UseCaseHandler {
-	return &UseCaseHandler{
+func NewDebitBalanceUseCaseHandler(repo ports.WalletRepository, bus ports.EventBusProcessor, opts ...Option) *UseCaseHandler {
+	h := &UseCaseHandler{
 		walletRepo:     repo,
 		eventProcessor: bus,
+		retryPolicy:    retry.NewPolicy(retry.DefaultConfig()),
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+
+	return h
 }
@@ -4,13 +4,17 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
 	"github.com/payment-processor/internal/debit/application/ports/mocks" // Importa mocks de los puertos
+	"github.com/payment-processor/internal/debit/application/retry"
 	"github.com/payment-processor/internal/debit/domain"
 	"github.com/payment-processor/internal/debit/infra/repository" // Para el error de versión
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUseCaseHandler(t *testing.T) {
@@ -23,6 +27,18 @@ func TestUseCaseHandler(t *testing.T) {
 	t.Run("should succeed after one retry on version mismatch", testUseCase_OptimisticLockingRetrySuccess)
 	t.Run("should fail after max retries on version mismatch", testUseCase_OptimisticLockingMaxRetries)
 	t.Run("should return error when event bus fails to publish", testUseCase_EventBusError)
+	t.Run("should retry transient repository errors with backoff", testUseCase_TransientRepositoryErrorRetried)
+	t.Run("should retry transient event bus errors", testUseCase_TransientEventBusErrorRetried)
+	t.Run("should audit a successful debit with both balances", testUseCase_AuditDebited)
+	t.Run("should audit insufficient funds with the error code", testUseCase_AuditInsufficientFunds)
+	t.Run("should audit retry exhaustion", testUseCase_AuditRetriesExhausted)
+	t.Run("should audit dropped requests", testUseCase_AuditDropped)
+	t.Run("should audit unauthenticated requests with their error code", testUseCase_AuditUnauthenticated)
+	t.Run("should not fail the debit when the audit trail fails", testUseCase_AuditTrailError)
+	t.Run("should record the applied debit as a transaction", testUseCase_RecordsTransaction)
+	t.Run("should record a redelivered debit once", testUseCase_RecordsTransactionOnce)
+	t.Run("should reject and audit a payment the wallet already applied", testUseCase_PaymentAlreadyApplied)
+	t.Run("should publish a committed debit after the deadline passes", testUseCase_DeadlineAfterCommit)
 }
 
 func testUseCase_Success(t *testing.T) {
@@ -50,6 +66,36 @@ func testUseCase_Success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func testUseCase_DeadlineAfterCommit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	req := application.Request{UserID: "user-123", Amount: 30}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(domain.Wallet{UserID: "user-123", Amount: 100, Version: 1}, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Run(func(context.Context, domain.Wallet) {
+		<-ctx.Done()
+	}).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.MatchedBy(isLive), mock.Anything).Return(nil).Once()
+	auditMock.EXPECT().Append(mock.MatchedBy(isLive), mock.MatchedBy(func(record ports.AuditRecord) bool {
+		return record.Outcome == ports.AuditOutcomeDebited
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithAuditTrail(auditMock, "wallet-service"))
+
+	// WHEN
+	err := useCase.Handle(ctx, req)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
+
 func testUseCase_InsufficientFunds(t *testing.T) {
 	t.Parallel()
 
@@ -204,3 +250,323 @@ func testUseCase_EventBusError(t *testing.T) {
 	assert.ErrorAs(t, err, &domainErr)
 	assert.Equal(t, "5003", domainErr.Code)
 }
+
+func testUseCase_TransientRepositoryErrorRetried(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{UserID: "user-123", Amount: 30}
+	wallet := domain.Wallet{UserID: "user-123", Amount: 100, Version: 1}
+	unavailable := domain.NewDependencyUnavailableError("wallet-repository", errors.New("throttled"))
+
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(domain.Wallet{}, unavailable).Once()
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(wallet, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+
+	clock := &fakeClock{now: time.Now()}
+	policy := retry.NewPolicy(retry.Config{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Second}, retry.WithClock(clock))
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithRetryPolicy(policy))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Len(t, clock.sleeps, 1)
+}
+
+func testUseCase_TransientEventBusErrorRetried(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{UserID: "user-123", Amount: 30}
+	wallet := domain.Wallet{UserID: "user-123", Amount: 100, Version: 1}
+	unavailable := domain.NewDependencyUnavailableError("event-bus", errors.New("throttled"))
+
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(wallet, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(unavailable).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+
+	clock := &fakeClock{now: time.Now()}
+	policy := retry.NewPolicy(retry.Config{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Second}, retry.WithClock(clock))
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithRetryPolicy(policy))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Len(t, clock.sleeps, 1)
+}
+
+func testUseCase_AuditDebited(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-123", Amount: 30, CorrelationID: "corr-1"}
+
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(domain.Wallet{UserID: "user-123", Amount: 100, Version: 1}, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+	auditMock.EXPECT().Append(mock.Anything, ports.AuditRecord{
+		Outcome:       ports.AuditOutcomeDebited,
+		PaymentID:     "pay-1",
+		CorrelationID: "corr-1",
+		UserID:        "user-123",
+		Amount:        30,
+		BalanceBefore: amount(100),
+		BalanceAfter:  amount(70),
+		Actor:         "wallet-service",
+		Attempts:      1,
+	}).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithAuditTrail(auditMock, "wallet-service"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testUseCase_AuditInsufficientFunds(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	req := application.Request{UserID: "user-123", Amount: 30}
+
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(domain.Wallet{UserID: "user-123", Amount: 20, Version: 1}, nil).Once()
+	auditMock.EXPECT().Append(mock.Anything, mock.MatchedBy(func(r ports.AuditRecord) bool {
+		return r.Outcome == ports.AuditOutcomeInsufficientFunds && r.ErrorCode == "4001" &&
+			*r.BalanceBefore == 20 && *r.BalanceAfter == 20
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithAuditTrail(auditMock, "wallet-service"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.Error(t, err)
+}
+
+func testUseCase_AuditRetriesExhausted(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	req := application.Request{UserID: "user-123", Amount: 30}
+
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(domain.Wallet{UserID: "user-123", Amount: 100, Version: 1}, nil).Times(3)
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(repository.ErrVersionMismatch).Times(3)
+	auditMock.EXPECT().Append(mock.Anything, mock.MatchedBy(func(r ports.AuditRecord) bool {
+		return r.Outcome == ports.AuditOutcomeRetriesExhausted && r.ErrorCode == "4002" &&
+			r.Attempts == 3 && *r.BalanceBefore == 100 && r.BalanceAfter == nil
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithAuditTrail(auditMock, "wallet-service"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.Error(t, err)
+}
+
+func testUseCase_AuditDropped(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	req := application.Request{Amount: 30, CorrelationID: "corr-1"}
+
+	auditMock.EXPECT().Append(mock.Anything, ports.AuditRecord{
+		Outcome:       ports.AuditOutcomeValidationDropped,
+		CorrelationID: "corr-1",
+		Amount:        30,
+		Reason:        "user_id is missing",
+		Actor:         "wallet-service",
+	}).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithAuditTrail(auditMock, "wallet-service"))
+
+	// WHEN
+	useCase.Drop(context.Background(), req, errors.New("user_id is missing"))
+
+	// THEN
+	repoMock.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func testUseCase_AuditUnauthenticated(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-123", Amount: 30, CorrelationID: "corr-1"}
+
+	auditMock.EXPECT().Append(mock.Anything, ports.AuditRecord{
+		Outcome:       ports.AuditOutcomeUnauthenticated,
+		PaymentID:     "pay-1",
+		CorrelationID: "corr-1",
+		UserID:        "user-123",
+		Amount:        30,
+		ErrorCode:     "4007",
+		Reason:        "invalid signature error",
+		Actor:         "wallet-service",
+	}).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithAuditTrail(auditMock, "wallet-service"))
+
+	// WHEN
+	useCase.Reject(context.Background(), req, domain.NewInvalidSignatureError("key-1", nil))
+
+	// THEN
+	repoMock.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func testUseCase_AuditTrailError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	req := application.Request{UserID: "user-123", Amount: 30}
+
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(domain.Wallet{UserID: "user-123", Amount: 100, Version: 1}, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+	auditMock.EXPECT().Append(mock.Anything, mock.Anything).Return(errors.New("disk full")).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithAuditTrail(auditMock, "wallet-service"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testUseCase_RecordsTransaction(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	transactionsMock := mocks.NewMockTransactionRepository(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-123", Amount: 30, CorrelationID: "corr-1"}
+
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(domain.Wallet{UserID: "user-123", Amount: 100, Version: 1}, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+	transactionsMock.EXPECT().Get(mock.Anything, mock.Anything).Return(domain.Transaction{}, repository.ErrTransactionNotFound).Once()
+	transactionsMock.EXPECT().Append(mock.Anything, mock.MatchedBy(func(tx domain.Transaction) bool {
+		return tx.ID == domain.TransactionID("", "pay-1", "user-123", domain.TransactionDebit) && tx.PaymentID == "pay-1" && tx.CorrelationID == "corr-1" && tx.UserID == "user-123" &&
+			tx.Type == domain.TransactionDebit && tx.Amount == 30 && tx.BalanceAfter == 70 && !tx.Timestamp.IsZero()
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithTransactionRepository(transactionsMock))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testUseCase_RecordsTransactionOnce(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	transactions := repository.NewInMemoryTransactionRepository()
+	req := application.Request{PaymentID: "pay-1", UserID: "user-123", Amount: 30}
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(domain.Wallet{UserID: "user-123", Amount: 100, Version: 1}, nil).Twice()
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Twice()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithTransactionRepository(transactions))
+
+	// WHEN
+	first := useCase.Handle(context.Background(), req)
+	redelivered := useCase.Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, first)
+	assert.True(t, domain.IsPaymentApplied(redelivered))
+	recorded := transactions.Transactions()
+	require.Len(t, recorded, 1)
+	assert.Equal(t, domain.TransactionID("", "pay-1", "user-123", domain.TransactionDebit), recorded[0].ID)
+}
+
+func testUseCase_PaymentAlreadyApplied(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-123", Amount: 100, Version: 1})
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-123", Amount: 30}
+
+	// published by the debit and again by the redelivery
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.AmountDebited == 30 && req.AmountLeft == 70
+	})).Return(nil).Twice()
+	auditMock.EXPECT().Append(mock.Anything, mock.MatchedBy(func(r ports.AuditRecord) bool {
+		return r.Outcome == ports.AuditOutcomeDebited
+	})).Return(nil).Once()
+	auditMock.EXPECT().Append(mock.Anything, mock.MatchedBy(func(r ports.AuditRecord) bool {
+		return r.Outcome == ports.AuditOutcomeAlreadyApplied && r.ErrorCode == "4010" &&
+			*r.BalanceBefore == 70 && *r.BalanceAfter == 70
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock, application.WithAuditTrail(auditMock, "wallet-service"))
+
+	// WHEN
+	first := useCase.Handle(context.Background(), req)
+	redelivered := useCase.Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, first)
+	assert.True(t, domain.IsPaymentApplied(redelivered))
+	assert.Equal(t, domain.Amount(70), repo.Wallets()[0].Amount)
+}
+
+// --- Helper Functions ---
+
+func amount(a domain.Amount) *domain.Amount { return &a }
+
+// isLive matches a context that is not cancelled
+func isLive(ctx context.Context) bool { return ctx.Err() == nil }
+
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(_ context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
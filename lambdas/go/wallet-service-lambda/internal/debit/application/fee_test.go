@@ -0,0 +1,204 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/ports/mocks"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var schedule = domain.FeeSchedule{
+	Default:            domain.FeeRule{Fixed: 1},
+	MerchantCategories: map[string]domain.FeeRule{"travel": {Percentage: 10}},
+	UserTiers:          map[string]domain.FeeRule{"premium": {}},
+}
+
+func TestUseCaseHandlerFees(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should debit the fee and credit it to the revenue account", testFees_Charged)
+	t.Run("should reject a debit that only fits without the fee", testFees_InsufficientFunds)
+	t.Run("should skip the revenue account when the fee is zero", testFees_Zero)
+	t.Run("should record the debit and the revenue credit", testFees_Transactions)
+	t.Run("should credit the revenue account without locking it", testFees_RevenueNotLocked)
+	t.Run("should reject payments of the revenue account", testFees_RevenuePayer)
+}
+
+func testFees_Charged(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := feeRepository(100)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 50, CorrelationID: "corr-1", MerchantCategory: "travel"}
+
+	busMock.EXPECT().Publish(mock.Anything, ports.BalanceDebitedRequest{
+		PaymentID:     "pay-1",
+		UserID:        "user-1",
+		AmountDebited: 55,
+		Principal:     50,
+		Fee:           5,
+		AmountLeft:    45,
+		EventName:     domain.BalanceDebitedEventName,
+		CorrelationID: "corr-1",
+	}).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock, application.WithFeeSchedule(schedule, "revenue"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "revenue", Amount: 5, Version: 2},
+		{UserID: "user-1", Amount: 45, Version: 2},
+	}, repo.Wallets())
+}
+
+func testFees_InsufficientFunds(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := feeRepository(50)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{UserID: "user-1", Amount: 50, CorrelationID: "corr-1"}
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock, application.WithFeeSchedule(schedule, "revenue"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.True(t, domain.IsInsufficientFunds(err))
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "revenue", Amount: 0, Version: 1},
+		{UserID: "user-1", Amount: 50, Version: 1},
+	}, repo.Wallets())
+}
+
+func testFees_Zero(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{UserID: "user-1", Amount: 50, CorrelationID: "corr-1", UserTier: "premium"}
+
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1}, nil).Once()
+	repoMock.EXPECT().Update(mock.Anything, domain.Wallet{UserID: "user-1", Amount: 50, Version: 1}).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.AmountDebited == 50 && req.Principal == 50 && req.Fee == 0
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithFeeSchedule(schedule, "revenue"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testFees_Transactions(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := feeRepository(100)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	transactions := repository.NewInMemoryTransactionRepository()
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 20, CorrelationID: "corr-1"}
+
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithFeeSchedule(schedule, "revenue"),
+		application.WithTransactionRepository(transactions),
+	)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, err)
+	recorded := transactions.Transactions()
+	require.Len(t, recorded, 2)
+	assert.Equal(t, domain.TransactionDebit, recorded[0].Type)
+	assert.Equal(t, domain.Amount(21), recorded[0].Amount)
+	assert.Equal(t, domain.Amount(79), recorded[0].BalanceAfter)
+	assert.Equal(t, domain.TransactionCredit, recorded[1].Type)
+	assert.Equal(t, domain.UserID("revenue"), recorded[1].UserID)
+	assert.Equal(t, domain.Amount(1), recorded[1].Amount)
+}
+
+func testFees_RevenueNotLocked(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{UserID: "user-1", Amount: 50, CorrelationID: "corr-1", MerchantCategory: "travel"}
+
+	repoMock.EXPECT().Get(mock.Anything, req.UserID).Return(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1}, nil).Once()
+	repoMock.EXPECT().UpdateAndCredit(mock.Anything,
+		domain.Wallet{UserID: "user-1", Amount: 45, Version: 1},
+		ports.Credit{UserID: "revenue", Amount: 5},
+	).Return(domain.Wallet{UserID: "revenue", Amount: 905, Version: 42}, nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithFeeSchedule(schedule, "revenue"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testFees_RevenuePayer(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := feeRepository(100)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	req := application.Request{UserID: "revenue", Amount: 50, CorrelationID: "corr-1"}
+
+	auditMock.EXPECT().Append(mock.Anything, mock.MatchedBy(func(record ports.AuditRecord) bool {
+		return record.Outcome == ports.AuditOutcomeRejected && record.ErrorCode == "4009"
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithFeeSchedule(schedule, "revenue"),
+		application.WithAuditTrail(auditMock, "test"),
+	)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "4009", domainErr.Code)
+	assert.False(t, domain.IsRetryable(err))
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "revenue", Amount: 0, Version: 1},
+		{UserID: "user-1", Amount: 100, Version: 1},
+	}, repo.Wallets())
+}
+
+// --- Helper Functions ---
+
+func feeRepository(balance domain.Amount) *repository.InMemoryWalletRepository {
+	return repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{UserID: "user-1", Amount: balance, Version: 1},
+		domain.Wallet{UserID: "revenue", Amount: 0, Version: 1},
+	)
+}
@@ -0,0 +1,152 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+)
+
+// exchange converts the payments charged in a currency other than the one of the debited
+// balance. The quote of a payment is locked, so every attempt and redelivery of the payment within
+// the lock is converted at the same rate. Until the payment is applied the lock only lives in the
+// process, nothing was charged at that rate yet. Once applied the quote is kept with the debit
+// transaction and read back from it, so a redelivery handled by another instance or after a
+// restart converts the payment as it was charged
+type exchange struct {
+	provider ports.RateProvider
+	policy   domain.FXPolicy
+
+	mu    sync.Mutex
+	locks map[string]lockedRate
+}
+
+type lockedRate struct {
+	rate  domain.Rate
+	until time.Time
+}
+
+// WithExchangeRates converts the requests charged in a currency other than the one of the debited
+// balance with the rates quoted by provider. Without it such requests fail
+func WithExchangeRates(provider ports.RateProvider, policy domain.FXPolicy) Option {
+	return func(h *UseCaseHandler) {
+		h.fx = &exchange{
+			provider: provider,
+			policy:   policy,
+			locks:    make(map[string]lockedRate),
+		}
+	}
+}
+
+// WithWalletCurrency names the currency of Wallet.Amount, the balance debited by the requests
+// without currency
+func WithWalletCurrency(currency domain.Currency) Option {
+	return func(h *UseCaseHandler) { h.walletCurrency = currency }
+}
+
+// WithCurrencyFallback debits the first of currencies the wallet holds with enough funds, converting
+// the payment, when the balance of the payment currency lacks them
+func WithCurrencyFallback(currencies ...domain.Currency) Option {
+	return func(h *UseCaseHandler) { h.fallback = currencies }
+}
+
+// convert prices amount, charged in from, in the currency to, at the rate the payment was already
+// charged at if any
+func (h *UseCaseHandler) convert(ctx context.Context, req Request, amount domain.Amount, from, to domain.Currency) (domain.Conversion, error) {
+	if h.fx == nil {
+		return domain.Conversion{}, domain.NewExchangeRateError(from, to, errors.New("no rate provider configured"))
+	}
+
+	if applied, ok := h.appliedConversion(ctx, req, from, to); ok {
+		return h.fx.policy.ConvertAt(amount, applied), nil
+	}
+
+	var rate domain.Rate
+	err := h.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		var err error
+		rate, err = h.fx.rate(ctx, req.PaymentID, from, to, h.now())
+		return err
+	}, domain.IsRetryable)
+	if err != nil {
+		return domain.Conversion{}, domain.NewExchangeRateError(from, to, err)
+	}
+
+	return h.fx.policy.Convert(amount, rate), nil
+}
+
+// appliedConversion reads back the quote kept with the debit of a payment already applied. A
+// history that can't be read leaves the payment to be quoted again, as it would be without one
+func (h *UseCaseHandler) appliedConversion(ctx context.Context, req Request, from, to domain.Currency) (domain.Conversion, bool) {
+	if h.transactions == nil || req.PaymentID == "" {
+		return domain.Conversion{}, false
+	}
+
+	tx, err := h.transactions.Get(ctx, transactionID(ctx, req.PaymentID, req.UserID, domain.TransactionDebit))
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return domain.Conversion{}, false
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to read the applied rate, quoting again", "paymentId", req.PaymentID, "error", err)
+		return domain.Conversion{}, false
+	}
+	if tx.RateID == "" || tx.OriginalCurrency != from || h.currencyCode(tx.Currency) != to {
+		return domain.Conversion{}, false
+	}
+
+	return domain.Conversion{RateID: tx.RateID, Rate: tx.Rate, From: from, To: to}, true
+}
+
+// rate returns the quote locked for the payment, or a new one that gets locked. Requests without
+// payment id can't be told apart and are always quoted. Payment ids are only unique per tenant
+func (e *exchange) rate(ctx context.Context, paymentID string, from, to domain.Currency, now time.Time) (domain.Rate, error) {
+	key := string(domain.TenantFrom(ctx)) + "/" + paymentID + "/" + string(from) + "/" + string(to)
+
+	if rate, ok := e.locked(key, now); ok {
+		return rate, nil
+	}
+
+	rate, err := e.provider.Rate(ctx, from, to)
+	if err != nil {
+		return domain.Rate{}, err
+	}
+	if rate.Value <= 0 {
+		return domain.Rate{}, fmt.Errorf("rate %s is not positive", rate.ID)
+	}
+	if rate.Expired(now) {
+		return domain.Rate{}, fmt.Errorf("rate %s expired at %s", rate.ID, rate.ExpiresAt)
+	}
+	if paymentID == "" {
+		return rate, nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// A concurrent delivery of the same payment may have locked its own quote meanwhile
+	if locked, ok := e.locks[key]; ok && now.Before(locked.until) {
+		return locked.rate, nil
+	}
+
+	maps.DeleteFunc(e.locks, func(_ string, l lockedRate) bool { return !now.Before(l.until) })
+	e.locks[key] = lockedRate{rate: rate, until: e.policy.LockUntil(rate, now)}
+
+	return rate, nil
+}
+
+func (e *exchange) locked(key string, now time.Time) (domain.Rate, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	locked, ok := e.locks[key]
+	if !ok || !now.Before(locked.until) {
+		return domain.Rate{}, false
+	}
+	return locked.rate, true
+}
@@ -0,0 +1,268 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/ports/mocks"
+	"github.com/payment-processor/internal/debit/application/retry"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var fxPolicy = domain.FXPolicy{SpreadPercent: 1, Rounding: domain.RoundHalfUp, LockFor: 5 * time.Minute}
+
+func TestUseCaseHandlerExchange(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should debit the converted amount and publish both amounts", testFX_Converted)
+	t.Run("should not convert payments in the wallet currency", testFX_SameCurrency)
+	t.Run("should reuse the rate locked for the payment", testFX_LockedRate)
+	t.Run("should quote again once the lock expires", testFX_LockExpired)
+	t.Run("should read back the rate of an applied payment after a restart", testFX_AppliedRateAfterRestart)
+	t.Run("should fail without a rate provider", testFX_NoProvider)
+	t.Run("should reject an expired quote", testFX_ExpiredQuote)
+	t.Run("should retry a provider outage", testFX_ProviderUnavailable)
+}
+
+func testFX_Converted(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 20, Currency: "USD", CorrelationID: "corr-1"}
+
+	rates.EXPECT().Rate(mock.Anything, domain.Currency("USD"), domain.Currency("EUR")).Return(usdToEUR(), nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, ports.BalanceDebitedRequest{
+		PaymentID:        "pay-1",
+		UserID:           "user-1",
+		AmountDebited:    18.18,
+		Principal:        18.18,
+		AmountLeft:       81.82,
+		EventName:        domain.BalanceDebitedEventName,
+		CorrelationID:    "corr-1",
+		OriginalAmount:   20,
+		OriginalCurrency: "USD",
+		Currency:         "EUR",
+		RateID:           "usd-eur-1",
+		Rate:             0.909,
+	}).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock, application.WithExchangeRates(rates, fxPolicy), application.WithWalletCurrency("EUR"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, err)
+	assert.InDelta(t, 81.82, float64(repo.Wallets()[0].Amount), 1e-9)
+}
+
+func testFX_SameCurrency(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{UserID: "user-1", Amount: 20, Currency: "EUR", CorrelationID: "corr-1"}
+
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.AmountDebited == 20 && req.RateID == "" && req.OriginalCurrency == ""
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock, application.WithExchangeRates(rates, fxPolicy), application.WithWalletCurrency("EUR"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+	rates.AssertNotCalled(t, "Rate", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func testFX_LockedRate(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 10, Currency: "USD", CorrelationID: "corr-1"}
+
+	rates.EXPECT().Rate(mock.Anything, domain.Currency("USD"), domain.Currency("EUR")).Return(usdToEUR(), nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.RateID == "usd-eur-1"
+	})).Return(nil).Twice()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock, application.WithExchangeRates(rates, fxPolicy), application.WithWalletCurrency("EUR"))
+
+	// WHEN
+	first := useCase.Handle(context.Background(), req)
+	redelivered := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, first)
+	assert.True(t, domain.IsPaymentApplied(redelivered))
+}
+
+func testFX_AppliedRateAfterRestart(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	transactions := repository.NewInMemoryTransactionRepository()
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 20, Currency: "USD", CorrelationID: "corr-1"}
+
+	rates.EXPECT().Rate(mock.Anything, domain.Currency("USD"), domain.Currency("EUR")).Return(usdToEUR(), nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.Principal == 18.18 && req.OriginalAmount == 20 && req.RateID == "usd-eur-1"
+	})).Return(nil).Twice()
+
+	newUseCase := func() *application.UseCaseHandler {
+		return application.NewDebitBalanceUseCaseHandler(repo, busMock,
+			application.WithExchangeRates(rates, fxPolicy),
+			application.WithWalletCurrency("EUR"),
+			application.WithTransactionRepository(transactions),
+		)
+	}
+
+	// WHEN
+	first := newUseCase().Handle(context.Background(), req)
+	redelivered := newUseCase().Handle(context.Background(), req)
+
+	// THEN
+	require.NoError(t, first)
+	assert.True(t, domain.IsPaymentApplied(redelivered))
+	recorded := transactions.Transactions()
+	require.Len(t, recorded, 1)
+	assert.Equal(t, domain.Amount(20), recorded[0].OriginalAmount)
+	assert.Equal(t, domain.Currency("USD"), recorded[0].OriginalCurrency)
+	assert.Equal(t, "usd-eur-1", recorded[0].RateID)
+	assert.InDelta(t, 0.909, recorded[0].Rate, 1e-9)
+}
+
+func testFX_LockExpired(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repo := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 10, Currency: "USD", CorrelationID: "corr-1"}
+
+	requoted := usdToEUR()
+	requoted.ID = "usd-eur-2"
+	repo.EXPECT().Get(mock.Anything, domain.UserID("user-1")).Return(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1}, nil).Twice()
+	repo.EXPECT().Update(mock.Anything, mock.Anything).Return(nil).Twice()
+	rates.EXPECT().Rate(mock.Anything, mock.Anything, mock.Anything).Return(usdToEUR(), nil).Once()
+	rates.EXPECT().Rate(mock.Anything, mock.Anything, mock.Anything).Return(requoted, nil).Once()
+
+	var published []string
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, req ports.BalanceDebitedRequest) error {
+		published = append(published, req.RateID)
+		return nil
+	}).Twice()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithExchangeRates(rates, fxPolicy), application.WithWalletCurrency("EUR"),
+		application.WithClock(func() time.Time { return now }),
+	)
+
+	// WHEN
+	require.NoError(t, useCase.Handle(context.Background(), req))
+	now = now.Add(fxPolicy.LockFor)
+	require.NoError(t, useCase.Handle(context.Background(), req))
+
+	// THEN
+	assert.Equal(t, []string{"usd-eur-1", "usd-eur-2"}, published)
+}
+
+func testFX_NoProvider(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{UserID: "user-1", Amount: 10, Currency: "USD", CorrelationID: "corr-1"}
+
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-1")).Return(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1}, nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithWalletCurrency("EUR"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.ErrorContains(t, err, "exchange rate error")
+	assert.False(t, domain.IsRetryable(err))
+	repoMock.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func testFX_ExpiredQuote(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{UserID: "user-1", Amount: 10, Currency: "USD", CorrelationID: "corr-1"}
+
+	stale := usdToEUR()
+	stale.ExpiresAt = now.Add(-time.Second)
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-1")).Return(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1}, nil).Once()
+	rates.EXPECT().Rate(mock.Anything, mock.Anything, mock.Anything).Return(stale, nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock,
+		application.WithExchangeRates(rates, fxPolicy), application.WithWalletCurrency("EUR"),
+		application.WithClock(func() time.Time { return now }),
+	)
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.ErrorContains(t, errors.Unwrap(err), "expired")
+}
+
+func testFX_ProviderUnavailable(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	req := application.Request{UserID: "user-1", Amount: 10, Currency: "USD", CorrelationID: "corr-1"}
+
+	unavailable := domain.NewDependencyUnavailableError("rates", errors.New("timeout"))
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-1")).Return(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1}, nil).Once()
+	rates.EXPECT().Rate(mock.Anything, mock.Anything, mock.Anything).Return(domain.Rate{}, unavailable).Times(3)
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithExchangeRates(rates, fxPolicy), application.WithWalletCurrency("EUR"))
+
+	// WHEN
+	err := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.ErrorContains(t, err, "exchange rate error")
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+}
+
+// --- Helper Functions ---
+
+func usdToEUR() domain.Rate {
+	return domain.Rate{ID: "usd-eur-1", From: "USD", To: "EUR", Value: 0.9}
+}
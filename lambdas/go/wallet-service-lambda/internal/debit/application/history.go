@@ -0,0 +1,59 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var ErrInvalidQuery = errors.New("invalid transaction query")
+
+// TransactionHistoryUseCase serves the history of a wallet to the inbound adapters
+type TransactionHistoryUseCase struct {
+	history ports.TransactionHistory
+}
+
+// History validates the query and returns a page of transactions, newest first. A missing limit
+// takes the default page size and larger ones are capped
+func (u *TransactionHistoryUseCase) History(ctx context.Context, query ports.TransactionQuery) (ports.TransactionPage, error) {
+	if err := validateQuery(query); err != nil {
+		return ports.TransactionPage{}, err
+	}
+
+	switch {
+	case query.Limit <= 0:
+		query.Limit = defaultPageSize
+	case query.Limit > maxPageSize:
+		query.Limit = maxPageSize
+	}
+
+	return u.history.History(ctx, query)
+}
+
+func validateQuery(query ports.TransactionQuery) error {
+	if query.UserID == "" {
+		return fmt.Errorf("%w: user_id is missing", ErrInvalidQuery)
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidQuery)
+	}
+	for _, t := range query.Types {
+		if t != domain.TransactionDebit && t != domain.TransactionCredit {
+			return fmt.Errorf("%w: unknown transaction type %q", ErrInvalidQuery, t)
+		}
+	}
+
+	return nil
+}
+
+func NewTransactionHistoryUseCase(history ports.TransactionHistory) *TransactionHistoryUseCase {
+	return &TransactionHistoryUseCase{history: history}
+}
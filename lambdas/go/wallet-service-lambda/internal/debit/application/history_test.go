@@ -0,0 +1,75 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/ports/mocks"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionHistoryUseCase(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should apply the default page size", testHistory_DefaultLimit)
+	t.Run("should cap the page size", testHistory_MaxLimit)
+	t.Run("should reject invalid queries", testHistory_InvalidQuery)
+}
+
+func testHistory_DefaultLimit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	historyMock := mocks.NewMockTransactionHistory(t)
+	expected := ports.TransactionPage{NextCursor: "next"}
+	historyMock.EXPECT().History(mock.Anything, ports.TransactionQuery{UserID: "user-1", Limit: 20}).Return(expected, nil).Once()
+
+	// WHEN
+	page, err := application.NewTransactionHistoryUseCase(historyMock).History(context.Background(), ports.TransactionQuery{UserID: "user-1"})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, expected, page)
+}
+
+func testHistory_MaxLimit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	historyMock := mocks.NewMockTransactionHistory(t)
+	historyMock.EXPECT().History(mock.Anything, ports.TransactionQuery{UserID: "user-1", Limit: 100}).Return(ports.TransactionPage{}, nil).Once()
+
+	// WHEN
+	_, err := application.NewTransactionHistoryUseCase(historyMock).History(context.Background(), ports.TransactionQuery{UserID: "user-1", Limit: 5000})
+
+	// THEN
+	require.NoError(t, err)
+}
+
+func testHistory_InvalidQuery(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	queries := map[string]ports.TransactionQuery{
+		"missing user":   {},
+		"inverted range": {UserID: "user-1", From: now, To: now.Add(-time.Hour)},
+		"unknown type":   {UserID: "user-1", Types: []domain.TransactionType{"refund"}},
+	}
+
+	for name, query := range queries {
+		// GIVEN
+		historyMock := mocks.NewMockTransactionHistory(t)
+
+		// WHEN
+		_, err := application.NewTransactionHistoryUseCase(historyMock).History(context.Background(), query)
+
+		// THEN
+		assert.ErrorIs(t, err, application.ErrInvalidQuery, name)
+	}
+}
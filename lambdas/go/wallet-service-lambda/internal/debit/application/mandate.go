@@ -0,0 +1,269 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/retry"
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+var ErrInvalidMandate = errors.New("invalid mandate")
+
+// CycleOutcome tells what a scheduler run did with a cycle of a mandate
+type CycleOutcome string
+
+const (
+	CycleDebited CycleOutcome = "debited"
+	// CycleAlreadyDebited cycles were debited by a run that could not save the mandate, their event
+	// is published again
+	CycleAlreadyDebited CycleOutcome = "already_debited"
+	// CycleRetrying cycles were declined for insufficient funds and are tried again later
+	CycleRetrying CycleOutcome = "retrying"
+	// CycleSkipped cycles were declined for insufficient funds on their last attempt
+	CycleSkipped CycleOutcome = "skipped"
+	// CycleFailed cycles hit any other error and are tried again as declined cycles. Once out of
+	// attempts they are skipped, or settled as CycleAlreadyDebited with the error when the history
+	// records their debit
+	CycleFailed CycleOutcome = "failed"
+)
+
+type (
+	CycleResult struct {
+		MandateID string
+		Cycle     int
+		PaymentID string
+		Outcome   CycleOutcome
+		Err       error
+	}
+
+	SchedulerReport struct {
+		Mandates int
+		Cycles   []CycleResult
+	}
+
+	// MandateUseCase manages the recurring debit mandates and materialises their due cycles as
+	// debits of the debit use case
+	MandateUseCase struct {
+		mandates     ports.MandateRepository
+		debits       *UseCaseHandler
+		history      ports.TransactionHistory
+		retryPolicy  *retry.Policy
+		defaultRetry domain.MandateRetry
+		now          func() time.Time
+	}
+
+	MandateOption func(*MandateUseCase)
+)
+
+// WithMandateClock replaces the clock stamping the cancellations
+func WithMandateClock(now func() time.Time) MandateOption {
+	return func(u *MandateUseCase) { u.now = now }
+}
+
+// WithDefaultMandateRetry is the retry of the mandates created without one
+func WithDefaultMandateRetry(r domain.MandateRetry) MandateOption {
+	return func(u *MandateUseCase) { u.defaultRetry = r }
+}
+
+// Create stores a new active mandate, its first cycle falls due at Start
+func (u *MandateUseCase) Create(ctx context.Context, mandate domain.Mandate) error {
+	mandate = domain.Mandate{
+		ID:               mandate.ID,
+		UserID:           mandate.UserID,
+		Amount:           mandate.Amount,
+		Currency:         mandate.Currency,
+		MerchantCategory: mandate.MerchantCategory,
+		Cadence:          mandate.Cadence,
+		Start:            mandate.Start,
+		End:              mandate.End,
+		MaxAmount:        mandate.MaxAmount,
+		Retry:            mandate.Retry,
+		Status:           domain.MandateActive,
+	}
+	if mandate.Retry == (domain.MandateRetry{}) {
+		mandate.Retry = u.defaultRetry
+	}
+
+	if err := mandate.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidMandate, err)
+	}
+	return u.mandates.Create(ctx, mandate)
+}
+
+// Cancel stops the mandate, a cycle already being debited by a concurrent run may still complete
+func (u *MandateUseCase) Cancel(ctx context.Context, id string) (domain.Mandate, error) {
+	var mandate domain.Mandate
+
+	err := u.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if mandate, err = u.mandates.Get(ctx, id); err != nil {
+			return err
+		}
+		if err = mandate.Cancel(u.now().UTC()); err != nil {
+			return err
+		}
+		return u.mandates.Update(ctx, mandate)
+	}, isTransient)
+
+	return mandate, err
+}
+
+// Run debits every cycle due at the given time, oldest first, so a mandate missed by earlier runs
+// catches up. Every cycle is debited with a payment id derived from the mandate and the cycle, and
+// a cycle already found in the history is not debited again. Declined and failed cycles stop their
+// mandate until their retry. Every mandate is debited in its own tenant. Only the mandates that
+// could not be listed or saved are returned as errors, the outcome of every cycle is in the report
+func (u *MandateUseCase) Run(ctx context.Context, at time.Time) (SchedulerReport, error) {
+	var report SchedulerReport
+
+	mandates, err := u.mandates.Active(ctx)
+	if err != nil {
+		return report, fmt.Errorf("failed to list mandates: %w", err)
+	}
+
+	var errs []error
+	for _, mandate := range mandates {
+		report.Mandates++
+
+		results, err := u.runMandate(domain.WithTenant(ctx, mandate.TenantID), mandate, at)
+		report.Cycles = append(report.Cycles, results...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+func (u *MandateUseCase) runMandate(ctx context.Context, mandate domain.Mandate, at time.Time) ([]CycleResult, error) {
+	var results []CycleResult
+	read := mandate
+
+	for mandate.Due(at) {
+		result := u.debitCycle(ctx, &mandate, at)
+		results = append(results, result)
+
+		if result.Outcome == CycleRetrying || result.Outcome == CycleFailed {
+			break
+		}
+	}
+
+	mandate.Complete()
+	if mandate == read {
+		return results, nil
+	}
+
+	if err := u.mandates.Update(ctx, mandate); err != nil {
+		slog.ErrorContext(ctx, "failed to save mandate", "mandateId", mandate.ID, "error", err)
+		return results, fmt.Errorf("failed to save mandate %s: %w", mandate.ID, err)
+	}
+	return results, nil
+}
+
+// debitCycle debits the current cycle of the mandate and moves the mandate past it, or schedules
+// its retry
+func (u *MandateUseCase) debitCycle(ctx context.Context, mandate *domain.Mandate, at time.Time) CycleResult {
+	paymentID := mandate.PaymentID(mandate.Cycle)
+	result := CycleResult{MandateID: mandate.ID, Cycle: mandate.Cycle, PaymentID: paymentID}
+
+	req := Request{
+		PaymentID:        paymentID,
+		UserID:           mandate.UserID,
+		Amount:           mandate.Amount,
+		CorrelationID:    paymentID,
+		MerchantCategory: mandate.MerchantCategory,
+		Currency:         mandate.Currency,
+	}
+
+	debited, err := u.debited(ctx, mandate.UserID, paymentID)
+	if err == nil && debited {
+		// the run that debited it may have stopped before publishing its event
+		err = u.debits.republish(ctx, req, nil)
+		if err == nil {
+			mandate.Settle()
+			result.Outcome = CycleAlreadyDebited
+			return result
+		}
+	}
+	if err == nil {
+		err = u.debits.Handle(ctx, req)
+	}
+
+	switch {
+	case err == nil:
+		mandate.Settle()
+		result.Outcome = CycleDebited
+	case domain.IsPaymentApplied(err):
+		mandate.Settle()
+		result.Outcome = CycleAlreadyDebited
+	case domain.IsInsufficientFunds(err):
+		result.Outcome, result.Err = CycleSkipped, err
+		if mandate.Decline(at) {
+			result.Outcome = CycleRetrying
+		}
+	default:
+		result.Outcome, result.Err = CycleFailed, err
+		u.fail(ctx, mandate, &result, at)
+	}
+
+	slog.InfoContext(ctx, "Mandate cycle processed", "mandateId", result.MandateID, "cycle", result.Cycle, "outcome", result.Outcome, "error", result.Err)
+	return result
+}
+
+// fail schedules the retry of a failed cycle. Out of attempts, the cycle is only skipped once the
+// history shows it was not debited: a charged cycle is settled even if its event never went out
+func (u *MandateUseCase) fail(ctx context.Context, mandate *domain.Mandate, result *CycleResult, at time.Time) {
+	if mandate.Postpone(at) {
+		return
+	}
+
+	debited, err := u.debited(ctx, mandate.UserID, result.PaymentID)
+	switch {
+	case err != nil:
+		result.Err = errors.Join(result.Err, err)
+	case debited:
+		slog.ErrorContext(ctx, "mandate cycle settled without its event", "mandateId", mandate.ID, "cycle", result.Cycle, "error", result.Err)
+		mandate.Settle()
+		result.Outcome = CycleAlreadyDebited
+	default:
+		mandate.Skip()
+		result.Outcome = CycleSkipped
+	}
+}
+
+// debited looks the payment of a cycle up in the history of the wallet
+func (u *MandateUseCase) debited(ctx context.Context, userID domain.UserID, paymentID string) (bool, error) {
+	page, err := u.history.History(ctx, ports.TransactionQuery{
+		UserID:    userID,
+		PaymentID: paymentID,
+		Types:     []domain.TransactionType{domain.TransactionDebit},
+		Limit:     1,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to read the history of %s: %w", userID, err)
+	}
+	return len(page.Transactions) > 0, nil
+}
+
+// NewMandateUseCase debits the cycles through debits. The history spares the debit of the cycles
+// it records, the wallet repository rejects the payment of a cycle it already applied when the
+// history lost it
+func NewMandateUseCase(repo ports.MandateRepository, debits *UseCaseHandler, history ports.TransactionHistory, opts ...MandateOption) *MandateUseCase {
+	u := &MandateUseCase{
+		mandates:    repo,
+		debits:      debits,
+		history:     history,
+		retryPolicy: retry.NewPolicy(retry.DefaultConfig()),
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
+}
@@ -0,0 +1,403 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/ports/mocks"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var subscriptionStart = time.Date(2026, 1, 1, 8, 0, 0, 0, time.UTC)
+
+func TestMandateUseCase(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should debit every due cycle with its own payment id", testMandates_CatchUp)
+	t.Run("should not debit a cycle found in the history", testMandates_AlreadyDebited)
+	t.Run("should not debit again a cycle applied before a cold start", testMandates_ColdStart)
+	t.Run("should retry a declined cycle and then skip it", testMandates_InsufficientFunds)
+	t.Run("should retry a failed cycle", testMandates_Failed)
+	t.Run("should move past a failed cycle out of attempts", testMandates_FailedOutOfAttempts)
+	t.Run("should publish again a cycle debited by a run that failed to publish", testMandates_Republish)
+	t.Run("should stop debiting a cancelled mandate", testMandates_Cancel)
+	t.Run("should complete a mandate past its end", testMandates_Complete)
+	t.Run("should reject an invalid mandate", testMandates_Invalid)
+	t.Run("should debit every mandate in its own tenant", testMandates_Tenants)
+}
+
+func testMandates_CatchUp(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	s := newScheduler(t, 100)
+	require.NoError(t, s.useCase.Create(context.Background(), subscription()))
+
+	// WHEN
+	report, err := s.useCase.Run(context.Background(), subscriptionStart.AddDate(0, 2, 0))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Mandates)
+	assert.Equal(t, []application.CycleResult{
+		{MandateID: "sub-1", Cycle: 0, PaymentID: "mandate:sub-1:0", Outcome: application.CycleDebited},
+		{MandateID: "sub-1", Cycle: 1, PaymentID: "mandate:sub-1:1", Outcome: application.CycleDebited},
+		{MandateID: "sub-1", Cycle: 2, PaymentID: "mandate:sub-1:2", Outcome: application.CycleDebited},
+	}, report.Cycles)
+	assert.Equal(t, domain.Amount(70), s.wallets.Wallets()[0].Amount)
+	mandate := s.mandate(t)
+	assert.Equal(t, 3, mandate.Cycle)
+	assert.Equal(t, domain.Amount(30), mandate.Total)
+}
+
+func testMandates_AlreadyDebited(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	s := newScheduler(t, 100)
+	require.NoError(t, s.useCase.Create(context.Background(), subscription()))
+	require.NoError(t, s.transactions.Append(context.Background(), domain.Transaction{
+		ID:        domain.TransactionID("", "mandate:sub-1:0", "user-1", domain.TransactionDebit),
+		PaymentID: "mandate:sub-1:0",
+		UserID:    "user-1",
+		Type:      domain.TransactionDebit,
+		Amount:    10,
+		Timestamp: subscriptionStart,
+	}))
+
+	// WHEN
+	report, err := s.useCase.Run(context.Background(), subscriptionStart)
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, report.Cycles, 1)
+	assert.Equal(t, application.CycleAlreadyDebited, report.Cycles[0].Outcome)
+	assert.Equal(t, domain.Amount(100), s.wallets.Wallets()[0].Amount)
+	assert.Equal(t, 1, s.mandate(t).Cycle)
+}
+
+func testMandates_ColdStart(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	s := newScheduler(t, 100)
+	require.NoError(t, s.useCase.Create(context.Background(), subscription()))
+	_, err := s.useCase.Run(context.Background(), subscriptionStart)
+	require.NoError(t, err)
+
+	busMock := mocks.NewMockEventBusProcessor(t)
+	mandates := repository.NewInMemoryMandateRepository()
+	transactions := repository.NewInMemoryTransactionRepository()
+
+	// the history was lost with the cold start, the event is published as priced now
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.PaymentID == "mandate:sub-1:0" && req.AmountDebited == 10 && req.AmountLeft == 90
+	})).Return(nil).Once()
+	debits := application.NewDebitBalanceUseCaseHandler(s.wallets, busMock, application.WithTransactionRepository(transactions))
+	restarted := application.NewMandateUseCase(mandates, debits, transactions,
+		application.WithMandateClock(func() time.Time { return february }),
+	)
+	require.NoError(t, restarted.Create(context.Background(), subscription()))
+
+	// WHEN
+	report, err := restarted.Run(context.Background(), subscriptionStart)
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, report.Cycles, 1)
+	assert.Equal(t, application.CycleAlreadyDebited, report.Cycles[0].Outcome)
+	assert.Equal(t, domain.Amount(90), s.wallets.Wallets()[0].Amount)
+	mandate, err := mandates.Get(context.Background(), "sub-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, mandate.Cycle)
+}
+
+func testMandates_InsufficientFunds(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	s := newScheduler(t, 5)
+	m := subscription()
+	m.Retry = domain.MandateRetry{Attempts: 1, Interval: 24 * time.Hour}
+	require.NoError(t, s.useCase.Create(context.Background(), m))
+
+	// WHEN
+	declined, err := s.useCase.Run(context.Background(), subscriptionStart)
+	require.NoError(t, err)
+	early, err := s.useCase.Run(context.Background(), subscriptionStart.Add(time.Hour))
+	require.NoError(t, err)
+	skipped, err := s.useCase.Run(context.Background(), subscriptionStart.Add(24*time.Hour))
+	require.NoError(t, err)
+
+	// THEN
+	require.Len(t, declined.Cycles, 1)
+	assert.Equal(t, application.CycleRetrying, declined.Cycles[0].Outcome)
+	assert.True(t, domain.IsInsufficientFunds(declined.Cycles[0].Err))
+	assert.Empty(t, early.Cycles)
+	require.Len(t, skipped.Cycles, 1)
+	assert.Equal(t, application.CycleSkipped, skipped.Cycles[0].Outcome)
+	mandate := s.mandate(t)
+	assert.Equal(t, 1, mandate.Cycle)
+	assert.Zero(t, mandate.Total)
+}
+
+func testMandates_Failed(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	history := mocks.NewMockTransactionHistory(t)
+	mandates := repository.NewInMemoryMandateRepository()
+	wallets := repository.NewInMemoryWalletRepository()
+	debits := application.NewDebitBalanceUseCaseHandler(wallets, mocks.NewMockEventBusProcessor(t))
+	useCase := application.NewMandateUseCase(mandates, debits, history,
+		application.WithDefaultMandateRetry(domain.MandateRetry{Attempts: 1, Interval: 24 * time.Hour}),
+	)
+	require.NoError(t, useCase.Create(context.Background(), subscription()))
+
+	history.EXPECT().History(mock.Anything, mock.Anything).Return(ports.TransactionPage{}, nil).Once()
+
+	// WHEN
+	report, err := useCase.Run(context.Background(), subscriptionStart.AddDate(0, 1, 0))
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, report.Cycles, 1)
+	assert.Equal(t, application.CycleFailed, report.Cycles[0].Outcome)
+	stored, err := mandates.Get(context.Background(), "sub-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, stored.Cycle)
+	assert.Equal(t, 1, stored.Attempts)
+	assert.Equal(t, subscriptionStart.AddDate(0, 1, 1), stored.RetryAt)
+}
+
+func testMandates_FailedOutOfAttempts(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		history ports.TransactionPage
+		outcome application.CycleOutcome
+		total   domain.Amount
+	}{
+		"not debited": {
+			outcome: application.CycleSkipped,
+		},
+		"debited": {
+			history: ports.TransactionPage{Transactions: []domain.Transaction{{PaymentID: "mandate:sub-1:0"}}},
+			outcome: application.CycleAlreadyDebited,
+			total:   10,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			history := mocks.NewMockTransactionHistory(t)
+			mandates := repository.NewInMemoryMandateRepository()
+			wallets := repository.NewInMemoryWalletRepository()
+			debits := application.NewDebitBalanceUseCaseHandler(wallets, mocks.NewMockEventBusProcessor(t))
+			useCase := application.NewMandateUseCase(mandates, debits, history)
+			require.NoError(t, useCase.Create(context.Background(), subscription()))
+
+			history.EXPECT().History(mock.Anything, mock.Anything).Return(ports.TransactionPage{}, nil).Once()
+			history.EXPECT().History(mock.Anything, mock.Anything).Return(tc.history, nil).Once()
+
+			// WHEN
+			report, err := useCase.Run(context.Background(), subscriptionStart)
+
+			// THEN
+			require.NoError(t, err)
+			require.Len(t, report.Cycles, 1)
+			assert.Equal(t, tc.outcome, report.Cycles[0].Outcome)
+			assert.Error(t, report.Cycles[0].Err)
+			stored, err := mandates.Get(context.Background(), "sub-1")
+			require.NoError(t, err)
+			assert.Equal(t, 1, stored.Cycle)
+			assert.Equal(t, tc.total, stored.Total)
+		})
+	}
+}
+
+func testMandates_Republish(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	busMock := mocks.NewMockEventBusProcessor(t)
+	wallets := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	mandates := repository.NewInMemoryMandateRepository()
+	transactions := repository.NewInMemoryTransactionRepository()
+	debits := application.NewDebitBalanceUseCaseHandler(wallets, busMock, application.WithTransactionRepository(transactions))
+	useCase := application.NewMandateUseCase(mandates, debits, transactions)
+	m := subscription()
+	m.Retry = domain.MandateRetry{Attempts: 1, Interval: time.Hour}
+	require.NoError(t, useCase.Create(context.Background(), m))
+
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("bus down")).Once()
+	failed, err := useCase.Run(context.Background(), subscriptionStart)
+	require.NoError(t, err)
+	require.Len(t, failed.Cycles, 1)
+	require.Equal(t, application.CycleFailed, failed.Cycles[0].Outcome)
+
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.PaymentID == "mandate:sub-1:0" && req.AmountDebited == 10
+	})).Return(nil).Once()
+
+	// WHEN
+	report, err := useCase.Run(context.Background(), subscriptionStart.Add(time.Hour))
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, report.Cycles, 1)
+	assert.Equal(t, application.CycleAlreadyDebited, report.Cycles[0].Outcome)
+	assert.Equal(t, domain.Amount(90), wallets.Wallets()[0].Amount)
+	stored, err := mandates.Get(context.Background(), "sub-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.Cycle)
+}
+
+func testMandates_Cancel(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	s := newScheduler(t, 100)
+	require.NoError(t, s.useCase.Create(context.Background(), subscription()))
+
+	// WHEN
+	cancelled, err := s.useCase.Cancel(context.Background(), "sub-1")
+	require.NoError(t, err)
+	_, again := s.useCase.Cancel(context.Background(), "sub-1")
+	_, missing := s.useCase.Cancel(context.Background(), "sub-2")
+	report, runErr := s.useCase.Run(context.Background(), subscriptionStart.AddDate(0, 2, 0))
+
+	// THEN
+	require.NoError(t, runErr)
+	assert.Equal(t, domain.MandateCancelled, cancelled.Status)
+	assert.Equal(t, february, cancelled.CancelledAt)
+	assert.ErrorContains(t, again, "mandate not active error")
+	assert.ErrorIs(t, missing, repository.ErrMandateNotFound)
+	assert.Zero(t, report.Mandates)
+	assert.Equal(t, domain.Amount(100), s.wallets.Wallets()[0].Amount)
+}
+
+func testMandates_Complete(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	s := newScheduler(t, 100)
+	m := subscription()
+	m.End = subscriptionStart.AddDate(0, 1, 0)
+	require.NoError(t, s.useCase.Create(context.Background(), m))
+
+	// WHEN
+	report, err := s.useCase.Run(context.Background(), subscriptionStart.AddDate(1, 0, 0))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Len(t, report.Cycles, 2)
+	mandate := s.mandate(t)
+	assert.Equal(t, domain.MandateCompleted, mandate.Status)
+	assert.Equal(t, domain.Amount(20), mandate.Total)
+}
+
+func testMandates_Invalid(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	s := newScheduler(t, 100)
+	m := subscription()
+	m.Amount = 0
+
+	// WHEN
+	err := s.useCase.Create(context.Background(), m)
+
+	// THEN
+	assert.ErrorIs(t, err, application.ErrInvalidMandate)
+	assert.ErrorContains(t, err, "amount must be positive")
+}
+
+func testMandates_Tenants(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	busMock := mocks.NewMockEventBusProcessor(t)
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.TenantID == "acme"
+	})).Return(nil).Once()
+
+	wallets := repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{TenantID: "acme", UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{TenantID: "globex", UserID: "user-1", Amount: 100, Version: 1},
+	)
+	mandates := repository.NewInMemoryMandateRepository()
+	transactions := repository.NewInMemoryTransactionRepository()
+	debits := application.NewDebitBalanceUseCaseHandler(wallets, busMock,
+		application.WithTransactionRepository(transactions),
+		application.WithTenants(registry),
+		application.WithWalletCurrency("EUR"),
+	)
+	useCase := application.NewMandateUseCase(mandates, debits, transactions,
+		application.WithMandateClock(func() time.Time { return february }),
+	)
+	require.NoError(t, useCase.Create(domain.WithTenant(context.Background(), "acme"), subscription()))
+
+	// WHEN
+	report, err := useCase.Run(context.Background(), subscriptionStart)
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, report.Cycles, 1)
+	assert.Equal(t, application.CycleDebited, report.Cycles[0].Outcome)
+	assert.Equal(t, []domain.Wallet{
+		{TenantID: "acme", UserID: "user-1", Amount: 90, Version: 2},
+		{TenantID: "globex", UserID: "user-1", Amount: 100, Version: 1},
+	}, wallets.Wallets())
+}
+
+// --- Helper Functions ---
+
+type scheduler struct {
+	useCase      *application.MandateUseCase
+	wallets      *repository.InMemoryWalletRepository
+	mandates     *repository.InMemoryMandateRepository
+	transactions *repository.InMemoryTransactionRepository
+}
+
+func (s scheduler) mandate(t *testing.T) domain.Mandate {
+	t.Helper()
+
+	mandate, err := s.mandates.Get(context.Background(), "sub-1")
+	require.NoError(t, err)
+	return mandate
+}
+
+// newScheduler debits a single wallet holding balance, publishing to a bus that accepts any event
+func newScheduler(t *testing.T, balance domain.Amount) scheduler {
+	t.Helper()
+
+	busMock := mocks.NewMockEventBusProcessor(t)
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	s := scheduler{
+		wallets:      repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-1", Amount: balance, Version: 1}),
+		mandates:     repository.NewInMemoryMandateRepository(),
+		transactions: repository.NewInMemoryTransactionRepository(),
+	}
+	debits := application.NewDebitBalanceUseCaseHandler(s.wallets, busMock, application.WithTransactionRepository(s.transactions))
+	s.useCase = application.NewMandateUseCase(s.mandates, debits, s.transactions,
+		application.WithMandateClock(func() time.Time { return february }),
+	)
+	return s
+}
+
+func subscription() domain.Mandate {
+	return domain.Mandate{ID: "sub-1", UserID: "user-1", Amount: 10, Cadence: domain.CadenceMonthly, Start: subscriptionStart}
+}
@@ -0,0 +1,49 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// AuditOutcome is how a debit attempt ended
+type AuditOutcome string
+
+const (
+	AuditOutcomeDebited           AuditOutcome = "debited"
+	AuditOutcomeInsufficientFunds AuditOutcome = "insufficient_funds"
+	AuditOutcomeValidationDropped AuditOutcome = "validation_dropped"
+	AuditOutcomeRetriesExhausted  AuditOutcome = "retries_exhausted"
+	// AuditOutcomePublishFailed is a debit applied to the wallet whose event could not be published
+	AuditOutcomePublishFailed AuditOutcome = "publish_failed"
+	// AuditOutcomeRejected is a payment the tenant does not accept, by currency or amount
+	AuditOutcomeRejected AuditOutcome = "rejected"
+	// AuditOutcomeUnauthenticated is an event whose signature or producer could not be verified
+	AuditOutcomeUnauthenticated AuditOutcome = "unauthenticated"
+	// AuditOutcomeAlreadyApplied is a payment the wallet had already applied, left untouched
+	AuditOutcomeAlreadyApplied AuditOutcome = "already_applied"
+	AuditOutcomeFailed         AuditOutcome = "failed"
+)
+
+// AuditRecord describes a single debit attempt. Balances are nil when the wallet could not be read,
+// they are the ones in Currency, empty for the wallet currency. TenantID owns the wallet
+type AuditRecord struct {
+	TenantID      domain.TenantID
+	Outcome       AuditOutcome
+	PaymentID     string
+	CorrelationID string
+	UserID        domain.UserID
+	Amount        domain.Amount
+	Fee           domain.Amount
+	Currency      domain.Currency
+	BalanceBefore *domain.Amount
+	BalanceAfter  *domain.Amount
+	ErrorCode     string
+	Reason        string
+	Actor         string
+	Attempts      int
+}
+
+type AuditTrail interface {
+	Append(context.Context, AuditRecord) error
+}
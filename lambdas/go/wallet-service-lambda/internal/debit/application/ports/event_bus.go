@@ -6,14 +6,55 @@ import (
 	"github.com/payment-processor/internal/debit/domain"
 )
 
+// BalanceDebitedRequest debits Principal plus Fee, AmountDebited being their sum. A split payment
+// carries one leg per wallet, AmountDebited is the total and UserID and AmountLeft are empty.
+// A payment converted into the debited Currency keeps its OriginalAmount and OriginalCurrency and
+// the quote applied, spread included. AmountLeft is the balance in Currency, empty for the wallet
+// currency, and multi-currency wallets list every balance they hold in Balances. TenantID owns
+// the debited wallets
 type BalanceDebitedRequest struct {
+	TenantID         domain.TenantID
+	PaymentID        string
+	UserID           domain.UserID
+	AmountDebited    domain.Amount
+	Principal        domain.Amount
+	Fee              domain.Amount
+	AmountLeft       domain.Amount
+	EventName        domain.Event
+	CorrelationID    string
+	Legs             []DebitedLeg
+	OriginalAmount   domain.Amount
+	OriginalCurrency domain.Currency
+	Currency         domain.Currency
+	RateID           string
+	Rate             float64
+	Balances         map[domain.Currency]domain.Amount
+}
+
+type DebitedLeg struct {
 	UserID        domain.UserID
 	AmountDebited domain.Amount
 	AmountLeft    domain.Amount
-	EventName     domain.Event
-	CorrelationID string
 }
 
 type EventBusProcessor interface {
 	Publish(context.Context, BalanceDebitedRequest) error
 }
+
+// BalanceDiscrepancyRequest reports a wallet whose stored balance does not match its debit history,
+// Currency naming the balance when it is not the wallet currency one. StoredBalance is nil when
+// the wallet does not exist
+type BalanceDiscrepancyRequest struct {
+	TenantID        domain.TenantID
+	UserID          domain.UserID
+	Currency        domain.Currency
+	ExpectedBalance domain.Amount
+	StoredBalance   *domain.Amount
+	Reason          string
+	Transactions    []BalanceDebitedRequest
+	EventName       domain.Event
+}
+
+type DiscrepancyPublisher interface {
+	PublishDiscrepancy(context.Context, BalanceDiscrepancyRequest) error
+}
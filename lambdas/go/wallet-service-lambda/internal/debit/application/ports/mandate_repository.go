@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+type MandateRepository interface {
+	Get(ctx context.Context, id string) (domain.Mandate, error)
+	// Create stores a new mandate, failing when its id is taken
+	Create(context.Context, domain.Mandate) error
+	// Update applies the same version check as WalletRepository.Update
+	Update(context.Context, domain.Mandate) error
+	// Active lists the active mandates sorted by id
+	Active(context.Context) ([]domain.Mandate, error)
+}
@@ -0,0 +1,96 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockAuditTrail creates a new instance of MockAuditTrail. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockAuditTrail(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockAuditTrail {
+	mock := &MockAuditTrail{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockAuditTrail is an autogenerated mock type for the AuditTrail type
+type MockAuditTrail struct {
+	mock.Mock
+}
+
+type MockAuditTrail_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockAuditTrail) EXPECT() *MockAuditTrail_Expecter {
+	return &MockAuditTrail_Expecter{mock: &_m.Mock}
+}
+
+// Append provides a mock function for the type MockAuditTrail
+func (_mock *MockAuditTrail) Append(context1 context.Context, auditRecord ports.AuditRecord) error {
+	ret := _mock.Called(context1, auditRecord)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Append")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, ports.AuditRecord) error); ok {
+		r0 = returnFunc(context1, auditRecord)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockAuditTrail_Append_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Append'
+type MockAuditTrail_Append_Call struct {
+	*mock.Call
+}
+
+// Append is a helper method to define mock.On call
+//   - context1 context.Context
+//   - auditRecord ports.AuditRecord
+func (_e *MockAuditTrail_Expecter) Append(context1 interface{}, auditRecord interface{}) *MockAuditTrail_Append_Call {
+	return &MockAuditTrail_Append_Call{Call: _e.mock.On("Append", context1, auditRecord)}
+}
+
+func (_c *MockAuditTrail_Append_Call) Run(run func(context1 context.Context, auditRecord ports.AuditRecord)) *MockAuditTrail_Append_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 ports.AuditRecord
+		if args[1] != nil {
+			arg1 = args[1].(ports.AuditRecord)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockAuditTrail_Append_Call) Return(err error) *MockAuditTrail_Append_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockAuditTrail_Append_Call) RunAndReturn(run func(context1 context.Context, auditRecord ports.AuditRecord) error) *MockAuditTrail_Append_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,96 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockDiscrepancyPublisher creates a new instance of MockDiscrepancyPublisher. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockDiscrepancyPublisher(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockDiscrepancyPublisher {
+	mock := &MockDiscrepancyPublisher{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockDiscrepancyPublisher is an autogenerated mock type for the DiscrepancyPublisher type
+type MockDiscrepancyPublisher struct {
+	mock.Mock
+}
+
+type MockDiscrepancyPublisher_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockDiscrepancyPublisher) EXPECT() *MockDiscrepancyPublisher_Expecter {
+	return &MockDiscrepancyPublisher_Expecter{mock: &_m.Mock}
+}
+
+// PublishDiscrepancy provides a mock function for the type MockDiscrepancyPublisher
+func (_mock *MockDiscrepancyPublisher) PublishDiscrepancy(context1 context.Context, balanceDiscrepancyRequest ports.BalanceDiscrepancyRequest) error {
+	ret := _mock.Called(context1, balanceDiscrepancyRequest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishDiscrepancy")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, ports.BalanceDiscrepancyRequest) error); ok {
+		r0 = returnFunc(context1, balanceDiscrepancyRequest)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockDiscrepancyPublisher_PublishDiscrepancy_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PublishDiscrepancy'
+type MockDiscrepancyPublisher_PublishDiscrepancy_Call struct {
+	*mock.Call
+}
+
+// PublishDiscrepancy is a helper method to define mock.On call
+//   - context1 context.Context
+//   - balanceDiscrepancyRequest ports.BalanceDiscrepancyRequest
+func (_e *MockDiscrepancyPublisher_Expecter) PublishDiscrepancy(context1 interface{}, balanceDiscrepancyRequest interface{}) *MockDiscrepancyPublisher_PublishDiscrepancy_Call {
+	return &MockDiscrepancyPublisher_PublishDiscrepancy_Call{Call: _e.mock.On("PublishDiscrepancy", context1, balanceDiscrepancyRequest)}
+}
+
+func (_c *MockDiscrepancyPublisher_PublishDiscrepancy_Call) Run(run func(context1 context.Context, balanceDiscrepancyRequest ports.BalanceDiscrepancyRequest)) *MockDiscrepancyPublisher_PublishDiscrepancy_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 ports.BalanceDiscrepancyRequest
+		if args[1] != nil {
+			arg1 = args[1].(ports.BalanceDiscrepancyRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockDiscrepancyPublisher_PublishDiscrepancy_Call) Return(err error) *MockDiscrepancyPublisher_PublishDiscrepancy_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockDiscrepancyPublisher_PublishDiscrepancy_Call) RunAndReturn(run func(context1 context.Context, balanceDiscrepancyRequest ports.BalanceDiscrepancyRequest) error) *MockDiscrepancyPublisher_PublishDiscrepancy_Call {
+	_c.Call.Return(run)
+	return _c
+}
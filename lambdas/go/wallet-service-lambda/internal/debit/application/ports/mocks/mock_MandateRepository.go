@@ -0,0 +1,281 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/payment-processor/internal/debit/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockMandateRepository creates a new instance of MockMandateRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockMandateRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockMandateRepository {
+	mock := &MockMandateRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockMandateRepository is an autogenerated mock type for the MandateRepository type
+type MockMandateRepository struct {
+	mock.Mock
+}
+
+type MockMandateRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockMandateRepository) EXPECT() *MockMandateRepository_Expecter {
+	return &MockMandateRepository_Expecter{mock: &_m.Mock}
+}
+
+// Active provides a mock function for the type MockMandateRepository
+func (_mock *MockMandateRepository) Active(context1 context.Context) ([]domain.Mandate, error) {
+	ret := _mock.Called(context1)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Active")
+	}
+
+	var r0 []domain.Mandate
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context) ([]domain.Mandate, error)); ok {
+		return returnFunc(context1)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context) []domain.Mandate); ok {
+		r0 = returnFunc(context1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Mandate)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = returnFunc(context1)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMandateRepository_Active_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Active'
+type MockMandateRepository_Active_Call struct {
+	*mock.Call
+}
+
+// Active is a helper method to define mock.On call
+//   - context1 context.Context
+func (_e *MockMandateRepository_Expecter) Active(context1 interface{}) *MockMandateRepository_Active_Call {
+	return &MockMandateRepository_Active_Call{Call: _e.mock.On("Active", context1)}
+}
+
+func (_c *MockMandateRepository_Active_Call) Run(run func(context1 context.Context)) *MockMandateRepository_Active_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMandateRepository_Active_Call) Return(mandates []domain.Mandate, err error) *MockMandateRepository_Active_Call {
+	_c.Call.Return(mandates, err)
+	return _c
+}
+
+func (_c *MockMandateRepository_Active_Call) RunAndReturn(run func(context1 context.Context) ([]domain.Mandate, error)) *MockMandateRepository_Active_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Create provides a mock function for the type MockMandateRepository
+func (_mock *MockMandateRepository) Create(context1 context.Context, mandate domain.Mandate) error {
+	ret := _mock.Called(context1, mandate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Mandate) error); ok {
+		r0 = returnFunc(context1, mandate)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMandateRepository_Create_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Create'
+type MockMandateRepository_Create_Call struct {
+	*mock.Call
+}
+
+// Create is a helper method to define mock.On call
+//   - context1 context.Context
+//   - mandate domain.Mandate
+func (_e *MockMandateRepository_Expecter) Create(context1 interface{}, mandate interface{}) *MockMandateRepository_Create_Call {
+	return &MockMandateRepository_Create_Call{Call: _e.mock.On("Create", context1, mandate)}
+}
+
+func (_c *MockMandateRepository_Create_Call) Run(run func(context1 context.Context, mandate domain.Mandate)) *MockMandateRepository_Create_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.Mandate
+		if args[1] != nil {
+			arg1 = args[1].(domain.Mandate)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMandateRepository_Create_Call) Return(err error) *MockMandateRepository_Create_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMandateRepository_Create_Call) RunAndReturn(run func(context1 context.Context, mandate domain.Mandate) error) *MockMandateRepository_Create_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function for the type MockMandateRepository
+func (_mock *MockMandateRepository) Get(ctx context.Context, id string) (domain.Mandate, error) {
+	ret := _mock.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 domain.Mandate
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (domain.Mandate, error)); ok {
+		return returnFunc(ctx, id)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) domain.Mandate); ok {
+		r0 = returnFunc(ctx, id)
+	} else {
+		r0 = ret.Get(0).(domain.Mandate)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockMandateRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockMandateRepository_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *MockMandateRepository_Expecter) Get(ctx interface{}, id interface{}) *MockMandateRepository_Get_Call {
+	return &MockMandateRepository_Get_Call{Call: _e.mock.On("Get", ctx, id)}
+}
+
+func (_c *MockMandateRepository_Get_Call) Run(run func(ctx context.Context, id string)) *MockMandateRepository_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMandateRepository_Get_Call) Return(mandate domain.Mandate, err error) *MockMandateRepository_Get_Call {
+	_c.Call.Return(mandate, err)
+	return _c
+}
+
+func (_c *MockMandateRepository_Get_Call) RunAndReturn(run func(ctx context.Context, id string) (domain.Mandate, error)) *MockMandateRepository_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Update provides a mock function for the type MockMandateRepository
+func (_mock *MockMandateRepository) Update(context1 context.Context, mandate domain.Mandate) error {
+	ret := _mock.Called(context1, mandate)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Mandate) error); ok {
+		r0 = returnFunc(context1, mandate)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockMandateRepository_Update_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Update'
+type MockMandateRepository_Update_Call struct {
+	*mock.Call
+}
+
+// Update is a helper method to define mock.On call
+//   - context1 context.Context
+//   - mandate domain.Mandate
+func (_e *MockMandateRepository_Expecter) Update(context1 interface{}, mandate interface{}) *MockMandateRepository_Update_Call {
+	return &MockMandateRepository_Update_Call{Call: _e.mock.On("Update", context1, mandate)}
+}
+
+func (_c *MockMandateRepository_Update_Call) Run(run func(context1 context.Context, mandate domain.Mandate)) *MockMandateRepository_Update_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.Mandate
+		if args[1] != nil {
+			arg1 = args[1].(domain.Mandate)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockMandateRepository_Update_Call) Return(err error) *MockMandateRepository_Update_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockMandateRepository_Update_Call) RunAndReturn(run func(context1 context.Context, mandate domain.Mandate) error) *MockMandateRepository_Update_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,111 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/payment-processor/internal/debit/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockRateProvider creates a new instance of MockRateProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockRateProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockRateProvider {
+	mock := &MockRateProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockRateProvider is an autogenerated mock type for the RateProvider type
+type MockRateProvider struct {
+	mock.Mock
+}
+
+type MockRateProvider_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockRateProvider) EXPECT() *MockRateProvider_Expecter {
+	return &MockRateProvider_Expecter{mock: &_m.Mock}
+}
+
+// Rate provides a mock function for the type MockRateProvider
+func (_mock *MockRateProvider) Rate(ctx context.Context, from domain.Currency, to domain.Currency) (domain.Rate, error) {
+	ret := _mock.Called(ctx, from, to)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Rate")
+	}
+
+	var r0 domain.Rate
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Currency, domain.Currency) (domain.Rate, error)); ok {
+		return returnFunc(ctx, from, to)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Currency, domain.Currency) domain.Rate); ok {
+		r0 = returnFunc(ctx, from, to)
+	} else {
+		r0 = ret.Get(0).(domain.Rate)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, domain.Currency, domain.Currency) error); ok {
+		r1 = returnFunc(ctx, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockRateProvider_Rate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Rate'
+type MockRateProvider_Rate_Call struct {
+	*mock.Call
+}
+
+// Rate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - from domain.Currency
+//   - to domain.Currency
+func (_e *MockRateProvider_Expecter) Rate(ctx interface{}, from interface{}, to interface{}) *MockRateProvider_Rate_Call {
+	return &MockRateProvider_Rate_Call{Call: _e.mock.On("Rate", ctx, from, to)}
+}
+
+func (_c *MockRateProvider_Rate_Call) Run(run func(ctx context.Context, from domain.Currency, to domain.Currency)) *MockRateProvider_Rate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.Currency
+		if args[1] != nil {
+			arg1 = args[1].(domain.Currency)
+		}
+		var arg2 domain.Currency
+		if args[2] != nil {
+			arg2 = args[2].(domain.Currency)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockRateProvider_Rate_Call) Return(rate domain.Rate, err error) *MockRateProvider_Rate_Call {
+	_c.Call.Return(rate, err)
+	return _c
+}
+
+func (_c *MockRateProvider_Rate_Call) RunAndReturn(run func(ctx context.Context, from domain.Currency, to domain.Currency) (domain.Rate, error)) *MockRateProvider_Rate_Call {
+	_c.Call.Return(run)
+	return _c
+}
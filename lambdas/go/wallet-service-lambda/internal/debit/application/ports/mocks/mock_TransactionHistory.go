@@ -0,0 +1,105 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockTransactionHistory creates a new instance of MockTransactionHistory. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTransactionHistory(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTransactionHistory {
+	mock := &MockTransactionHistory{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTransactionHistory is an autogenerated mock type for the TransactionHistory type
+type MockTransactionHistory struct {
+	mock.Mock
+}
+
+type MockTransactionHistory_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTransactionHistory) EXPECT() *MockTransactionHistory_Expecter {
+	return &MockTransactionHistory_Expecter{mock: &_m.Mock}
+}
+
+// History provides a mock function for the type MockTransactionHistory
+func (_mock *MockTransactionHistory) History(context1 context.Context, transactionQuery ports.TransactionQuery) (ports.TransactionPage, error) {
+	ret := _mock.Called(context1, transactionQuery)
+
+	if len(ret) == 0 {
+		panic("no return value specified for History")
+	}
+
+	var r0 ports.TransactionPage
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, ports.TransactionQuery) (ports.TransactionPage, error)); ok {
+		return returnFunc(context1, transactionQuery)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, ports.TransactionQuery) ports.TransactionPage); ok {
+		r0 = returnFunc(context1, transactionQuery)
+	} else {
+		r0 = ret.Get(0).(ports.TransactionPage)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, ports.TransactionQuery) error); ok {
+		r1 = returnFunc(context1, transactionQuery)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTransactionHistory_History_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'History'
+type MockTransactionHistory_History_Call struct {
+	*mock.Call
+}
+
+// History is a helper method to define mock.On call
+//   - context1 context.Context
+//   - transactionQuery ports.TransactionQuery
+func (_e *MockTransactionHistory_Expecter) History(context1 interface{}, transactionQuery interface{}) *MockTransactionHistory_History_Call {
+	return &MockTransactionHistory_History_Call{Call: _e.mock.On("History", context1, transactionQuery)}
+}
+
+func (_c *MockTransactionHistory_History_Call) Run(run func(context1 context.Context, transactionQuery ports.TransactionQuery)) *MockTransactionHistory_History_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 ports.TransactionQuery
+		if args[1] != nil {
+			arg1 = args[1].(ports.TransactionQuery)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransactionHistory_History_Call) Return(transactionPage ports.TransactionPage, err error) *MockTransactionHistory_History_Call {
+	_c.Call.Return(transactionPage, err)
+	return _c
+}
+
+func (_c *MockTransactionHistory_History_Call) RunAndReturn(run func(context1 context.Context, transactionQuery ports.TransactionQuery) (ports.TransactionPage, error)) *MockTransactionHistory_History_Call {
+	_c.Call.Return(run)
+	return _c
+}
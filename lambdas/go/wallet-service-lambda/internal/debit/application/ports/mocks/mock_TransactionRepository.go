@@ -0,0 +1,162 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/payment-processor/internal/debit/domain"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockTransactionRepository creates a new instance of MockTransactionRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockTransactionRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockTransactionRepository {
+	mock := &MockTransactionRepository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockTransactionRepository is an autogenerated mock type for the TransactionRepository type
+type MockTransactionRepository struct {
+	mock.Mock
+}
+
+type MockTransactionRepository_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockTransactionRepository) EXPECT() *MockTransactionRepository_Expecter {
+	return &MockTransactionRepository_Expecter{mock: &_m.Mock}
+}
+
+// Append provides a mock function for the type MockTransactionRepository
+func (_mock *MockTransactionRepository) Append(context1 context.Context, transaction domain.Transaction) error {
+	ret := _mock.Called(context1, transaction)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Append")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Transaction) error); ok {
+		r0 = returnFunc(context1, transaction)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockTransactionRepository_Append_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Append'
+type MockTransactionRepository_Append_Call struct {
+	*mock.Call
+}
+
+// Append is a helper method to define mock.On call
+//   - context1 context.Context
+//   - transaction domain.Transaction
+func (_e *MockTransactionRepository_Expecter) Append(context1 interface{}, transaction interface{}) *MockTransactionRepository_Append_Call {
+	return &MockTransactionRepository_Append_Call{Call: _e.mock.On("Append", context1, transaction)}
+}
+
+func (_c *MockTransactionRepository_Append_Call) Run(run func(context1 context.Context, transaction domain.Transaction)) *MockTransactionRepository_Append_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.Transaction
+		if args[1] != nil {
+			arg1 = args[1].(domain.Transaction)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransactionRepository_Append_Call) Return(err error) *MockTransactionRepository_Append_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockTransactionRepository_Append_Call) RunAndReturn(run func(context1 context.Context, transaction domain.Transaction) error) *MockTransactionRepository_Append_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Get provides a mock function for the type MockTransactionRepository
+func (_mock *MockTransactionRepository) Get(context1 context.Context, s string) (domain.Transaction, error) {
+	ret := _mock.Called(context1, s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 domain.Transaction
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) (domain.Transaction, error)); ok {
+		return returnFunc(context1, s)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, string) domain.Transaction); ok {
+		r0 = returnFunc(context1, s)
+	} else {
+		r0 = ret.Get(0).(domain.Transaction)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = returnFunc(context1, s)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockTransactionRepository_Get_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Get'
+type MockTransactionRepository_Get_Call struct {
+	*mock.Call
+}
+
+// Get is a helper method to define mock.On call
+//   - context1 context.Context
+//   - s string
+func (_e *MockTransactionRepository_Expecter) Get(context1 interface{}, s interface{}) *MockTransactionRepository_Get_Call {
+	return &MockTransactionRepository_Get_Call{Call: _e.mock.On("Get", context1, s)}
+}
+
+func (_c *MockTransactionRepository_Get_Call) Run(run func(context1 context.Context, s string)) *MockTransactionRepository_Get_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockTransactionRepository_Get_Call) Return(transaction domain.Transaction, err error) *MockTransactionRepository_Get_Call {
+	_c.Call.Return(transaction, err)
+	return _c
+}
+
+func (_c *MockTransactionRepository_Get_Call) RunAndReturn(run func(context1 context.Context, s string) (domain.Transaction, error)) *MockTransactionRepository_Get_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -7,6 +7,7 @@ package mocks
 import (
 	"context"
 
+	"github.com/payment-processor/internal/debit/application/ports"
 	"github.com/payment-processor/internal/debit/domain"
 	mock "github.com/stretchr/testify/mock"
 )
@@ -160,3 +161,204 @@ func (_c *MockWalletRepository_Update_Call) RunAndReturn(run func(context1 conte
 	_c.Call.Return(run)
 	return _c
 }
+
+// UpdateAll provides a mock function for the type MockWalletRepository
+func (_mock *MockWalletRepository) UpdateAll(context1 context.Context, wallets []domain.Wallet) error {
+	ret := _mock.Called(context1, wallets)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAll")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []domain.Wallet) error); ok {
+		r0 = returnFunc(context1, wallets)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockWalletRepository_UpdateAll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateAll'
+type MockWalletRepository_UpdateAll_Call struct {
+	*mock.Call
+}
+
+// UpdateAll is a helper method to define mock.On call
+//   - context1 context.Context
+//   - wallets []domain.Wallet
+func (_e *MockWalletRepository_Expecter) UpdateAll(context1 interface{}, wallets interface{}) *MockWalletRepository_UpdateAll_Call {
+	return &MockWalletRepository_UpdateAll_Call{Call: _e.mock.On("UpdateAll", context1, wallets)}
+}
+
+func (_c *MockWalletRepository_UpdateAll_Call) Run(run func(context1 context.Context, wallets []domain.Wallet)) *MockWalletRepository_UpdateAll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []domain.Wallet
+		if args[1] != nil {
+			arg1 = args[1].([]domain.Wallet)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWalletRepository_UpdateAll_Call) Return(err error) *MockWalletRepository_UpdateAll_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockWalletRepository_UpdateAll_Call) RunAndReturn(run func(context1 context.Context, wallets []domain.Wallet) error) *MockWalletRepository_UpdateAll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateAllAndCredit provides a mock function for the type MockWalletRepository
+func (_mock *MockWalletRepository) UpdateAllAndCredit(context1 context.Context, wallets []domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	ret := _mock.Called(context1, wallets, credit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAllAndCredit")
+	}
+
+	var r0 domain.Wallet
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []domain.Wallet, ports.Credit) (domain.Wallet, error)); ok {
+		return returnFunc(context1, wallets, credit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, []domain.Wallet, ports.Credit) domain.Wallet); ok {
+		r0 = returnFunc(context1, wallets, credit)
+	} else {
+		r0 = ret.Get(0).(domain.Wallet)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, []domain.Wallet, ports.Credit) error); ok {
+		r1 = returnFunc(context1, wallets, credit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWalletRepository_UpdateAllAndCredit_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateAllAndCredit'
+type MockWalletRepository_UpdateAllAndCredit_Call struct {
+	*mock.Call
+}
+
+// UpdateAllAndCredit is a helper method to define mock.On call
+//   - context1 context.Context
+//   - wallets []domain.Wallet
+//   - credit ports.Credit
+func (_e *MockWalletRepository_Expecter) UpdateAllAndCredit(context1 interface{}, wallets interface{}, credit interface{}) *MockWalletRepository_UpdateAllAndCredit_Call {
+	return &MockWalletRepository_UpdateAllAndCredit_Call{Call: _e.mock.On("UpdateAllAndCredit", context1, wallets, credit)}
+}
+
+func (_c *MockWalletRepository_UpdateAllAndCredit_Call) Run(run func(context1 context.Context, wallets []domain.Wallet, credit ports.Credit)) *MockWalletRepository_UpdateAllAndCredit_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 []domain.Wallet
+		if args[1] != nil {
+			arg1 = args[1].([]domain.Wallet)
+		}
+		var arg2 ports.Credit
+		if args[2] != nil {
+			arg2 = args[2].(ports.Credit)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWalletRepository_UpdateAllAndCredit_Call) Return(wallet domain.Wallet, err error) *MockWalletRepository_UpdateAllAndCredit_Call {
+	_c.Call.Return(wallet, err)
+	return _c
+}
+
+func (_c *MockWalletRepository_UpdateAllAndCredit_Call) RunAndReturn(run func(context1 context.Context, wallets []domain.Wallet, credit ports.Credit) (domain.Wallet, error)) *MockWalletRepository_UpdateAllAndCredit_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateAndCredit provides a mock function for the type MockWalletRepository
+func (_mock *MockWalletRepository) UpdateAndCredit(context1 context.Context, wallet domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	ret := _mock.Called(context1, wallet, credit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateAndCredit")
+	}
+
+	var r0 domain.Wallet
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Wallet, ports.Credit) (domain.Wallet, error)); ok {
+		return returnFunc(context1, wallet, credit)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, domain.Wallet, ports.Credit) domain.Wallet); ok {
+		r0 = returnFunc(context1, wallet, credit)
+	} else {
+		r0 = ret.Get(0).(domain.Wallet)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, domain.Wallet, ports.Credit) error); ok {
+		r1 = returnFunc(context1, wallet, credit)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockWalletRepository_UpdateAndCredit_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateAndCredit'
+type MockWalletRepository_UpdateAndCredit_Call struct {
+	*mock.Call
+}
+
+// UpdateAndCredit is a helper method to define mock.On call
+//   - context1 context.Context
+//   - wallet domain.Wallet
+//   - credit ports.Credit
+func (_e *MockWalletRepository_Expecter) UpdateAndCredit(context1 interface{}, wallet interface{}, credit interface{}) *MockWalletRepository_UpdateAndCredit_Call {
+	return &MockWalletRepository_UpdateAndCredit_Call{Call: _e.mock.On("UpdateAndCredit", context1, wallet, credit)}
+}
+
+func (_c *MockWalletRepository_UpdateAndCredit_Call) Run(run func(context1 context.Context, wallet domain.Wallet, credit ports.Credit)) *MockWalletRepository_UpdateAndCredit_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 domain.Wallet
+		if args[1] != nil {
+			arg1 = args[1].(domain.Wallet)
+		}
+		var arg2 ports.Credit
+		if args[2] != nil {
+			arg2 = args[2].(ports.Credit)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockWalletRepository_UpdateAndCredit_Call) Return(wallet1 domain.Wallet, err error) *MockWalletRepository_UpdateAndCredit_Call {
+	_c.Call.Return(wallet1, err)
+	return _c
+}
+
+func (_c *MockWalletRepository_UpdateAndCredit_Call) RunAndReturn(run func(context1 context.Context, wallet domain.Wallet, credit ports.Credit) (domain.Wallet, error)) *MockWalletRepository_UpdateAndCredit_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// RateProvider quotes the rate converting from into to. Unavailable providers return retryable
+// domain errors
+type RateProvider interface {
+	Rate(ctx context.Context, from, to domain.Currency) (domain.Rate, error)
+}
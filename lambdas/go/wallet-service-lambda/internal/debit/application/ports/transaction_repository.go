@@ -0,0 +1,39 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// TransactionQuery selects the history of a wallet. From is inclusive and To exclusive, zero
+// values leave the range open. An empty Types returns every type and an empty Currencies every
+// balance, the wallet currency one being the empty currency. A PaymentID keeps its movements only
+type TransactionQuery struct {
+	UserID     domain.UserID
+	PaymentID  string
+	From       time.Time
+	To         time.Time
+	Types      []domain.TransactionType
+	Currencies []domain.Currency
+	Limit      int
+	// Cursor is the NextCursor of the previous page, empty for the first one
+	Cursor string
+}
+
+// TransactionPage holds the newest transactions first. NextCursor is empty on the last page
+type TransactionPage struct {
+	Transactions []domain.Transaction
+	NextCursor   string
+}
+
+type TransactionRepository interface {
+	Append(context.Context, domain.Transaction) error
+	// Get returns the transaction of the given id, ErrTransactionNotFound when none was recorded
+	Get(context.Context, string) (domain.Transaction, error)
+}
+
+type TransactionHistory interface {
+	History(context.Context, TransactionQuery) (TransactionPage, error)
+}
@@ -6,7 +6,23 @@ import (
 	"github.com/payment-processor/internal/debit/domain"
 )
 
+// Credit adds Amount to the balance in Currency of a wallet, empty for the wallet currency
+type Credit struct {
+	UserID   domain.UserID
+	Currency domain.Currency
+	Amount   domain.Amount
+}
+
 type WalletRepository interface {
 	Get(context.Context, domain.UserID) (domain.Wallet, error)
 	Update(context.Context, domain.Wallet) error
+	// UpdateAll applies every update or none, each one with the same version check as Update
+	UpdateAll(context.Context, []domain.Wallet) error
+	// UpdateAndCredit applies the update, with the version check of Update, and the credit of
+	// another wallet or none of them. The credit is an increment without version check, so
+	// concurrent writes crediting the same wallet never conflict on it. Returns the credited
+	// wallet as written
+	UpdateAndCredit(context.Context, domain.Wallet, Credit) (domain.Wallet, error)
+	// UpdateAllAndCredit is UpdateAndCredit for a batch of updates, as written by UpdateAll
+	UpdateAllAndCredit(context.Context, []domain.Wallet, Credit) (domain.Wallet, error)
 }
@@ -0,0 +1,239 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+)
+
+// balanceTolerance absorbs the float rounding of amounts computed in different places
+const balanceTolerance = 1e-6
+
+// Reasons of a discrepancy
+const (
+	ReasonStoredBalanceMismatch = "stored balance does not match the last debit"
+	ReasonBrokenHistory         = "debit does not follow the balance left by the previous one"
+	ReasonWalletNotFound        = "wallet of the debit history does not exist"
+)
+
+type (
+	// Discrepancy lists the debits that do not add up for the balance in Currency of a wallet, empty
+	// for the wallet currency
+	Discrepancy struct {
+		TenantID        domain.TenantID
+		UserID          domain.UserID
+		Currency        domain.Currency
+		ExpectedBalance domain.Amount
+		StoredBalance   *domain.Amount
+		Reason          string
+		Transactions    []ports.BalanceDebitedRequest
+	}
+
+	ReconciliationReport struct {
+		Wallets       int
+		Debits        int
+		Discrepancies []Discrepancy
+	}
+
+	// ReconciliationUseCase recomputes the balances from the debit history and compares them
+	// with the repository
+	ReconciliationUseCase struct {
+		walletRepo ports.WalletRepository
+		publisher  ports.DiscrepancyPublisher
+	}
+)
+
+// Reconcile checks every wallet present in debits, which must be in the order they were applied.
+// Each debit has to start from the balance left by the previous one of the same wallet, and the
+// last one has to match the stored balance. Wallets without debits are not checked and every leg
+// of a split payment counts as a debit of its wallet. Each wallet is read in the tenant of its debits.
+// A BalanceDiscrepancyDetected event is published for every discrepancy found
+func (u *ReconciliationUseCase) Reconcile(ctx context.Context, debits []ports.BalanceDebitedRequest) (ReconciliationReport, error) {
+	debits = perWallet(debits)
+	report := ReconciliationReport{Debits: len(debits)}
+
+	for _, history := range debitsByUser(debits) {
+		report.Wallets++
+
+		found, err := u.reconcileWallet(ctx, history)
+		if err != nil {
+			return report, err
+		}
+		report.Discrepancies = append(report.Discrepancies, found...)
+	}
+
+	var errs []error
+	for _, d := range report.Discrepancies {
+		slog.WarnContext(ctx, "balance discrepancy detected", "tenantId", d.TenantID, "userId", d.UserID, "reason", d.Reason, "expected", d.ExpectedBalance)
+		if err := u.publisher.PublishDiscrepancy(ctx, toDiscrepancyRequest(d)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to publish discrepancy of %s: %w", d.UserID, err))
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// reconcileWallet checks every balance of the wallet on its own, with the debits of its currency
+func (u *ReconciliationUseCase) reconcileWallet(ctx context.Context, history []ports.BalanceDebitedRequest) ([]Discrepancy, error) {
+	last := history[len(history)-1]
+	wallet, err := u.walletRepo.Get(domain.WithTenant(ctx, last.TenantID), last.UserID)
+	found := !errors.Is(err, repository.ErrWalletNotFound)
+	if err != nil && found {
+		return nil, domain.NewGetFundsError(string(last.UserID), err)
+	}
+
+	var discrepancies []Discrepancy
+	for _, balance := range debitsByBalance(history, wallet) {
+		discrepancies = append(discrepancies, reconcileBalance(balance.currency, balance.debits, wallet, found)...)
+	}
+	return discrepancies, nil
+}
+
+func reconcileBalance(currency domain.Currency, history []ports.BalanceDebitedRequest, wallet domain.Wallet, found bool) []Discrepancy {
+	var discrepancies []Discrepancy
+
+	for i := 1; i < len(history); i++ {
+		previous, current := history[i-1], history[i]
+		if !sameAmount(previous.AmountLeft-current.AmountDebited, current.AmountLeft) {
+			discrepancies = append(discrepancies, Discrepancy{
+				TenantID:        current.TenantID,
+				UserID:          current.UserID,
+				Currency:        currency,
+				ExpectedBalance: previous.AmountLeft - current.AmountDebited,
+				Reason:          ReasonBrokenHistory,
+				Transactions:    []ports.BalanceDebitedRequest{previous, current},
+			})
+		}
+	}
+
+	last := history[len(history)-1]
+	if !found {
+		return append(discrepancies, Discrepancy{
+			TenantID:        last.TenantID,
+			UserID:          last.UserID,
+			Currency:        currency,
+			ExpectedBalance: last.AmountLeft,
+			Reason:          ReasonWalletNotFound,
+			Transactions:    []ports.BalanceDebitedRequest{last},
+		})
+	}
+
+	if stored := wallet.Balance(currency); !sameAmount(stored, last.AmountLeft) {
+		discrepancies = append(discrepancies, Discrepancy{
+			TenantID:        last.TenantID,
+			UserID:          last.UserID,
+			Currency:        currency,
+			ExpectedBalance: last.AmountLeft,
+			StoredBalance:   &stored,
+			Reason:          ReasonStoredBalanceMismatch,
+			Transactions:    []ports.BalanceDebitedRequest{last},
+		})
+	}
+
+	return discrepancies
+}
+
+type balanceHistory struct {
+	currency domain.Currency
+	debits   []ports.BalanceDebitedRequest
+}
+
+// debitsByBalance groups the debits by the balance of wallet they left, ordered by first debit.
+// Debits naming a currency the wallet does not hold, the wallet currency being one, left the
+// wallet currency balance
+func debitsByBalance(history []ports.BalanceDebitedRequest, wallet domain.Wallet) []balanceHistory {
+	var balances []balanceHistory
+	index := make(map[domain.Currency]int)
+
+	for _, debit := range history {
+		currency := debit.Currency
+		if !wallet.Holds(currency) {
+			currency = ""
+		}
+
+		i, ok := index[currency]
+		if !ok {
+			i = len(balances)
+			index[currency] = i
+			balances = append(balances, balanceHistory{currency: currency})
+		}
+		balances[i].debits = append(balances[i].debits, debit)
+	}
+
+	return balances
+}
+
+// debitsByUser keeps the order of the debits of each wallet and orders the wallets by first debit.
+// The same user in two tenants owns two wallets
+func debitsByUser(debits []ports.BalanceDebitedRequest) [][]ports.BalanceDebitedRequest {
+	type walletKey struct {
+		tenant domain.TenantID
+		user   domain.UserID
+	}
+
+	var histories [][]ports.BalanceDebitedRequest
+	index := make(map[walletKey]int)
+
+	for _, debit := range debits {
+		key := walletKey{tenant: debit.TenantID, user: debit.UserID}
+		i, ok := index[key]
+		if !ok {
+			i = len(histories)
+			index[key] = i
+			histories = append(histories, nil)
+		}
+		histories[i] = append(histories[i], debit)
+	}
+
+	return histories
+}
+
+// perWallet turns every leg of a split payment into a debit of its own wallet
+func perWallet(debits []ports.BalanceDebitedRequest) []ports.BalanceDebitedRequest {
+	result := make([]ports.BalanceDebitedRequest, 0, len(debits))
+	for _, debit := range debits {
+		if len(debit.Legs) == 0 {
+			result = append(result, debit)
+			continue
+		}
+		for _, leg := range debit.Legs {
+			result = append(result, ports.BalanceDebitedRequest{
+				TenantID:      debit.TenantID,
+				PaymentID:     debit.PaymentID,
+				UserID:        leg.UserID,
+				AmountDebited: leg.AmountDebited,
+				AmountLeft:    leg.AmountLeft,
+				EventName:     debit.EventName,
+				CorrelationID: debit.CorrelationID,
+			})
+		}
+	}
+	return result
+}
+
+func sameAmount(a, b domain.Amount) bool {
+	return math.Abs(float64(a-b)) < balanceTolerance
+}
+
+func toDiscrepancyRequest(d Discrepancy) ports.BalanceDiscrepancyRequest {
+	return ports.BalanceDiscrepancyRequest{
+		TenantID:        d.TenantID,
+		UserID:          d.UserID,
+		Currency:        d.Currency,
+		ExpectedBalance: d.ExpectedBalance,
+		StoredBalance:   d.StoredBalance,
+		Reason:          d.Reason,
+		Transactions:    d.Transactions,
+		EventName:       domain.BalanceDiscrepancyDetectedEventName,
+	}
+}
+
+func NewReconciliationUseCase(repo ports.WalletRepository, publisher ports.DiscrepancyPublisher) *ReconciliationUseCase {
+	return &ReconciliationUseCase{walletRepo: repo, publisher: publisher}
+}
@@ -0,0 +1,224 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/ports/mocks"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconciliationUseCase(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should report nothing when balances match the history", testReconcile_Consistent)
+	t.Run("should report a stored balance that drifted from the last debit", testReconcile_StoredBalanceMismatch)
+	t.Run("should report a debit that does not follow the previous one", testReconcile_BrokenHistory)
+	t.Run("should report a missing wallet", testReconcile_WalletNotFound)
+	t.Run("should stop when the repository fails", testReconcile_RepositoryError)
+	t.Run("should reconcile every leg of a split payment", testReconcile_SplitPayment)
+	t.Run("should reconcile every currency balance on its own", testReconcile_CurrencyBalances)
+}
+
+func testReconcile_Consistent(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{UserID: "user-1", Amount: 60, Version: 3},
+		domain.Wallet{UserID: "user-2", Amount: 5, Version: 2},
+	)
+	publisherMock := mocks.NewMockDiscrepancyPublisher(t)
+	debits := []ports.BalanceDebitedRequest{
+		debited("user-1", "pay-1", 30, 70),
+		debited("user-2", "pay-2", 5, 5),
+		debited("user-1", "pay-3", 10, 60),
+	}
+
+	// WHEN
+	report, err := application.NewReconciliationUseCase(repo, publisherMock).Reconcile(context.Background(), debits)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, application.ReconciliationReport{Wallets: 2, Debits: 3}, report)
+}
+
+func testReconcile_StoredBalanceMismatch(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-1", Amount: 50, Version: 3})
+	publisherMock := mocks.NewMockDiscrepancyPublisher(t)
+	debits := []ports.BalanceDebitedRequest{debited("user-1", "pay-1", 30, 70), debited("user-1", "pay-2", 10, 60)}
+
+	stored := domain.Amount(50)
+	expected := application.Discrepancy{
+		UserID:          "user-1",
+		ExpectedBalance: 60,
+		StoredBalance:   &stored,
+		Reason:          application.ReasonStoredBalanceMismatch,
+		Transactions:    debits[1:],
+	}
+	publisherMock.EXPECT().PublishDiscrepancy(mock.Anything, ports.BalanceDiscrepancyRequest{
+		UserID:          "user-1",
+		ExpectedBalance: 60,
+		StoredBalance:   &stored,
+		Reason:          application.ReasonStoredBalanceMismatch,
+		Transactions:    debits[1:],
+		EventName:       domain.BalanceDiscrepancyDetectedEventName,
+	}).Return(nil).Once()
+
+	// WHEN
+	report, err := application.NewReconciliationUseCase(repo, publisherMock).Reconcile(context.Background(), debits)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []application.Discrepancy{expected}, report.Discrepancies)
+}
+
+func testReconcile_BrokenHistory(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-1", Amount: 40, Version: 4})
+	publisherMock := mocks.NewMockDiscrepancyPublisher(t)
+	debits := []ports.BalanceDebitedRequest{debited("user-1", "pay-1", 30, 70), debited("user-1", "pay-2", 10, 40)}
+
+	publisherMock.EXPECT().PublishDiscrepancy(mock.Anything, mock.MatchedBy(func(req ports.BalanceDiscrepancyRequest) bool {
+		return req.Reason == application.ReasonBrokenHistory && len(req.Transactions) == 2
+	})).Return(nil).Once()
+
+	// WHEN
+	report, err := application.NewReconciliationUseCase(repo, publisherMock).Reconcile(context.Background(), debits)
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, report.Discrepancies, 1)
+	assert.Equal(t, domain.Amount(60), report.Discrepancies[0].ExpectedBalance)
+	assert.Nil(t, report.Discrepancies[0].StoredBalance)
+}
+
+func testReconcile_WalletNotFound(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith()
+	publisherMock := mocks.NewMockDiscrepancyPublisher(t)
+	debits := []ports.BalanceDebitedRequest{debited("user-9", "pay-1", 30, 70)}
+
+	publisherMock.EXPECT().PublishDiscrepancy(mock.Anything, mock.Anything).Return(errors.New("bus is down")).Once()
+
+	// WHEN
+	report, err := application.NewReconciliationUseCase(repo, publisherMock).Reconcile(context.Background(), debits)
+
+	// THEN
+	assert.ErrorContains(t, err, "bus is down")
+	require.Len(t, report.Discrepancies, 1)
+	assert.Equal(t, application.ReasonWalletNotFound, report.Discrepancies[0].Reason)
+}
+
+func testReconcile_RepositoryError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	publisherMock := mocks.NewMockDiscrepancyPublisher(t)
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-1")).Return(domain.Wallet{}, errors.New("dynamo is down")).Once()
+
+	// WHEN
+	_, err := application.NewReconciliationUseCase(repoMock, publisherMock).
+		Reconcile(context.Background(), []ports.BalanceDebitedRequest{debited("user-1", "pay-1", 30, 70)})
+
+	// THEN
+	assert.ErrorContains(t, err, "get funds error")
+}
+
+func testReconcile_SplitPayment(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{UserID: "user-1", Amount: 60, Version: 3},
+		domain.Wallet{UserID: "user-2", Amount: 25, Version: 2},
+	)
+	publisherMock := mocks.NewMockDiscrepancyPublisher(t)
+	split := ports.BalanceDebitedRequest{
+		PaymentID:     "pay-2",
+		AmountDebited: 30,
+		EventName:     domain.BalanceDebitedEventName,
+		Legs: []ports.DebitedLeg{
+			{UserID: "user-1", AmountDebited: 10, AmountLeft: 60},
+			{UserID: "user-2", AmountDebited: 20, AmountLeft: 30},
+		},
+	}
+	debits := []ports.BalanceDebitedRequest{debited("user-1", "pay-1", 30, 70), split}
+
+	publisherMock.EXPECT().PublishDiscrepancy(mock.Anything, mock.MatchedBy(func(req ports.BalanceDiscrepancyRequest) bool {
+		return req.UserID == "user-2" && req.Reason == application.ReasonStoredBalanceMismatch
+	})).Return(nil).Once()
+
+	// WHEN
+	report, err := application.NewReconciliationUseCase(repo, publisherMock).Reconcile(context.Background(), debits)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Wallets)
+	assert.Equal(t, 3, report.Debits)
+	require.Len(t, report.Discrepancies, 1)
+	assert.Equal(t, domain.Amount(30), report.Discrepancies[0].ExpectedBalance)
+	assert.Equal(t, "pay-2", report.Discrepancies[0].Transactions[0].PaymentID)
+}
+
+func testReconcile_CurrencyBalances(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{UserID: "user-1", Amount: 60, Balances: map[domain.Currency]domain.Amount{"USD": 35}, Version: 5},
+	)
+	publisherMock := mocks.NewMockDiscrepancyPublisher(t)
+	debits := []ports.BalanceDebitedRequest{
+		debited("user-1", "pay-1", 30, 70),
+		debitedIn("USD", debited("user-1", "pay-2", 10, 40)),
+		debitedIn("USD", debited("user-1", "pay-3", 10, 30)),
+		debitedIn("EUR", debited("user-1", "pay-4", 10, 60)),
+	}
+
+	publisherMock.EXPECT().PublishDiscrepancy(mock.Anything, mock.MatchedBy(func(req ports.BalanceDiscrepancyRequest) bool {
+		return req.Currency == "USD" && req.Reason == application.ReasonStoredBalanceMismatch && *req.StoredBalance == 35
+	})).Return(nil).Once()
+
+	// WHEN
+	report, err := application.NewReconciliationUseCase(repo, publisherMock).Reconcile(context.Background(), debits)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Wallets)
+	require.Len(t, report.Discrepancies, 1)
+	assert.Equal(t, domain.Amount(30), report.Discrepancies[0].ExpectedBalance)
+}
+
+// --- Helper Functions ---
+
+func debited(userID domain.UserID, paymentID string, amount, left domain.Amount) ports.BalanceDebitedRequest {
+	return ports.BalanceDebitedRequest{
+		PaymentID:     paymentID,
+		UserID:        userID,
+		AmountDebited: amount,
+		AmountLeft:    left,
+		EventName:     domain.BalanceDebitedEventName,
+	}
+}
+
+// debitedIn names the currency of the debited balance, as the converted payments do
+func debitedIn(currency domain.Currency, debit ports.BalanceDebitedRequest) ports.BalanceDebitedRequest {
+	debit.Currency = currency
+	return debit
+}
@@ -0,0 +1,116 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+)
+
+var errDebitNotRecorded = errors.New("debit of the applied payment not recorded")
+
+// republish publishes again the BalanceDebited event of a payment the wallet already applied. A
+// debit committed whose event was lost, the publish failed or the process stopped before it, is
+// rejected as applied when redelivered: its event is what moves the payment on, downstream drops
+// the duplicates. The event is rebuilt from the debit recorded in the history. Without it the
+// fallback, the payment as priced now against the wallet as read, is published instead, and
+// without a fallback nothing is
+func (h *UseCaseHandler) republish(ctx context.Context, req Request, fallback *ports.BalanceDebitedRequest) error {
+	tx, ok := h.recordedDebit(ctx, req.PaymentID, req.UserID)
+	switch {
+	case ok:
+		return h.publishAgain(ctx, req.PaymentID, h.debitedFrom(ctx, tx))
+	case fallback != nil:
+		slog.WarnContext(ctx, "debit of the applied payment not recorded, publishing it as priced now", "paymentId", req.PaymentID, "userId", req.UserID)
+		return h.publishAgain(ctx, req.PaymentID, *fallback)
+	default:
+		return domain.NewPublishMessageError(req.PaymentID, errDebitNotRecorded)
+	}
+}
+
+// republishSplit is republish for a split payment, rebuilt from the debits of every leg
+func (h *UseCaseHandler) republishSplit(ctx context.Context, req SplitRequest, read []domain.Wallet, charges []charge) error {
+	debited := ports.BalanceDebitedRequest{
+		TenantID:      domain.TenantFrom(ctx),
+		PaymentID:     req.PaymentID,
+		EventName:     domain.BalanceDebitedEventName,
+		CorrelationID: req.CorrelationID,
+		Legs:          make([]ports.DebitedLeg, 0, len(req.Legs)),
+	}
+	for _, leg := range req.Legs {
+		tx, ok := h.recordedDebit(ctx, req.PaymentID, leg.UserID)
+		if !ok {
+			if len(read) < len(req.Legs) {
+				return domain.NewPublishMessageError(req.PaymentID, errDebitNotRecorded)
+			}
+			slog.WarnContext(ctx, "debit of the applied split payment not recorded, publishing it as priced now", "paymentId", req.PaymentID, "userId", leg.UserID)
+			return h.publishAgain(ctx, req.PaymentID, toSplitDebitEventRequest(domain.TenantFrom(ctx), read, req, charges))
+		}
+
+		debited.AmountDebited += tx.Amount
+		debited.Principal += tx.Principal()
+		debited.Fee += tx.Fee
+		debited.Legs = append(debited.Legs, ports.DebitedLeg{UserID: tx.UserID, AmountDebited: tx.Amount, AmountLeft: tx.BalanceAfter})
+	}
+
+	return h.publishAgain(ctx, req.PaymentID, debited)
+}
+
+// recordedDebit looks the debit of the payment up in the history of the wallet. A history that
+// can't be read is logged and treated as one without the debit
+func (h *UseCaseHandler) recordedDebit(ctx context.Context, paymentID string, userID domain.UserID) (domain.Transaction, bool) {
+	if h.transactions == nil || paymentID == "" {
+		return domain.Transaction{}, false
+	}
+
+	tx, err := h.transactions.Get(ctx, transactionID(ctx, paymentID, userID, domain.TransactionDebit))
+	if err != nil {
+		if !errors.Is(err, repository.ErrTransactionNotFound) {
+			slog.ErrorContext(ctx, "failed to read the debit of the payment", "paymentId", paymentID, "userId", userID, "error", err)
+		}
+		return domain.Transaction{}, false
+	}
+	return tx, true
+}
+
+// debitedFrom is the event toDebitEventRequest built for the recorded debit, but the balances of
+// every currency, which are no longer the ones the debit left
+func (h *UseCaseHandler) debitedFrom(ctx context.Context, tx domain.Transaction) ports.BalanceDebitedRequest {
+	debited := ports.BalanceDebitedRequest{
+		TenantID:      domain.TenantFrom(ctx),
+		PaymentID:     tx.PaymentID,
+		UserID:        tx.UserID,
+		AmountDebited: tx.Amount,
+		Principal:     tx.Principal(),
+		Fee:           tx.Fee,
+		AmountLeft:    tx.BalanceAfter,
+		EventName:     domain.BalanceDebitedEventName,
+		CorrelationID: tx.CorrelationID,
+		Currency:      tx.Currency,
+	}
+	if tx.RateID != "" {
+		debited.OriginalAmount = tx.OriginalAmount
+		debited.OriginalCurrency = tx.OriginalCurrency
+		debited.Currency = h.currencyCode(tx.Currency)
+		debited.RateID = tx.RateID
+		debited.Rate = tx.Rate
+	}
+
+	return debited
+}
+
+func (h *UseCaseHandler) publishAgain(ctx context.Context, paymentID string, debited ports.BalanceDebitedRequest) error {
+	err := h.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return h.eventProcessor.Publish(ctx, debited)
+	}, domain.IsRetryable)
+	if err != nil {
+		slog.ErrorContext(ctx, "error publishing the event of an applied payment again", "paymentId", paymentID, "error", err)
+		return domain.NewPublishMessageError(paymentID, err)
+	}
+
+	slog.InfoContext(ctx, "Published the event of an applied payment again", "paymentId", paymentID)
+	return nil
+}
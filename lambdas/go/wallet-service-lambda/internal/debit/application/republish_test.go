@@ -0,0 +1,123 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/ports/mocks"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepublish(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should publish again the recorded debit of a payment whose event was lost", testRepublish_Debit)
+	t.Run("should fail the redelivery while the event can't be published", testRepublish_PublishFails)
+	t.Run("should publish again every recorded leg of a split payment", testRepublish_Split)
+}
+
+func testRepublish_Debit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{UserID: "revenue", Amount: 0, Version: 1},
+	)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 50, CorrelationID: "corr-1"}
+
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("eventbridge is down")).Once()
+	busMock.EXPECT().Publish(mock.Anything, ports.BalanceDebitedRequest{
+		PaymentID:     "pay-1",
+		UserID:        "user-1",
+		AmountDebited: 51,
+		Principal:     50,
+		Fee:           1,
+		AmountLeft:    49,
+		EventName:     domain.BalanceDebitedEventName,
+		CorrelationID: "corr-1",
+	}).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithFeeSchedule(schedule, "revenue"),
+		application.WithTransactionRepository(repository.NewInMemoryTransactionRepository()),
+	)
+
+	// WHEN
+	first := useCase.Handle(context.Background(), req)
+	redelivered := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.Error(t, first)
+	assert.True(t, domain.IsPaymentApplied(redelivered))
+	assert.Equal(t, domain.Amount(49), repo.Wallets()[1].Amount)
+}
+
+func testRepublish_PublishFails(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 50, CorrelationID: "corr-1"}
+
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("eventbridge is down")).Twice()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithTransactionRepository(repository.NewInMemoryTransactionRepository()),
+	)
+
+	// WHEN
+	first := useCase.Handle(context.Background(), req)
+	redelivered := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assert.Error(t, first)
+	require.Error(t, redelivered)
+	assert.False(t, domain.IsPaymentApplied(redelivered))
+	assert.Equal(t, domain.Amount(50), repo.Wallets()[0].Amount)
+}
+
+func testRepublish_Split(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{UserID: "user-2", Amount: 50, Version: 1},
+	)
+	busMock := mocks.NewMockEventBusProcessor(t)
+
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("eventbridge is down")).Once()
+	busMock.EXPECT().Publish(mock.Anything, ports.BalanceDebitedRequest{
+		PaymentID:     "pay-1",
+		AmountDebited: 50,
+		Principal:     50,
+		EventName:     domain.BalanceDebitedEventName,
+		CorrelationID: "corr-1",
+		Legs: []ports.DebitedLeg{
+			{UserID: "user-1", AmountDebited: 30, AmountLeft: 70},
+			{UserID: "user-2", AmountDebited: 20, AmountLeft: 30},
+		},
+	}).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithTransactionRepository(repository.NewInMemoryTransactionRepository()),
+	)
+
+	// WHEN
+	first := useCase.HandleSplit(context.Background(), splitRequest())
+	redelivered := useCase.HandleSplit(context.Background(), splitRequest())
+
+	// THEN
+	assert.Error(t, first)
+	assert.True(t, domain.IsPaymentApplied(redelivered))
+}
@@ -0,0 +1,150 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+var ErrExhausted = errors.New("retry attempts exhausted")
+
+// ExhaustedError is returned when a retryable error persists after the last allowed attempt.
+// It unwraps to both ErrExhausted and the last error returned by the operation
+type ExhaustedError struct {
+	Attempts int
+	Last     error
+}
+
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("retry attempts exhausted after %d attempts: %v", e.Attempts, e.Last)
+}
+
+func (e *ExhaustedError) Unwrap() []error { return []error{ErrExhausted, e.Last} }
+
+type Clock interface {
+	Now() time.Time
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+type Config struct {
+	// MaxAttempts counts the first call, 1 disables retries
+	MaxAttempts int
+	// BaseDelay is the backoff cap of the first retry, doubled on every attempt up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MaxElapsed bounds the total time spent retrying, zero only uses the context deadline
+	MaxElapsed time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		MaxAttempts: 3,
+		BaseDelay:   10 * time.Millisecond,
+		MaxDelay:    200 * time.Millisecond,
+		MaxElapsed:  2 * time.Second,
+	}
+}
+
+type Option func(*Policy)
+
+func WithClock(c Clock) Option {
+	return func(p *Policy) { p.clock = c }
+}
+
+// WithRandom replaces the source of the jitter, it must return values in [0, 1)
+func WithRandom(r func() float64) Option {
+	return func(p *Policy) { p.random = r }
+}
+
+// Policy retries an operation with exponential backoff and full jitter
+type Policy struct {
+	config Config
+	clock  Clock
+	random func() float64
+}
+
+// Do calls fn until it succeeds or returns an error shouldRetry rejects. A retry is never
+// started if its delay would go past MaxElapsed or the context deadline
+func (p *Policy) Do(ctx context.Context, fn func(context.Context) error, shouldRetry func(error) bool) error {
+	start := p.clock.Now()
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || !shouldRetry(err) {
+			return err
+		}
+
+		if attempt >= p.config.MaxAttempts {
+			return &ExhaustedError{Attempts: attempt, Last: err}
+		}
+
+		delay := p.backoff(attempt)
+		if !p.fits(ctx, start, delay) {
+			return &ExhaustedError{Attempts: attempt, Last: err}
+		}
+
+		if sleepErr := p.clock.Sleep(ctx, delay); sleepErr != nil {
+			return &ExhaustedError{Attempts: attempt, Last: errors.Join(err, sleepErr)}
+		}
+	}
+}
+
+func (p *Policy) MaxAttempts() int { return p.config.MaxAttempts }
+
+// backoff returns a random delay between zero and the exponential cap of the attempt
+func (p *Policy) backoff(attempt int) time.Duration {
+	ceiling := p.config.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > p.config.MaxDelay {
+		ceiling = p.config.MaxDelay
+	}
+
+	return time.Duration(p.random() * float64(ceiling))
+}
+
+func (p *Policy) fits(ctx context.Context, start time.Time, delay time.Duration) bool {
+	wakeUp := p.clock.Now().Add(delay)
+
+	if p.config.MaxElapsed > 0 && wakeUp.Sub(start) > p.config.MaxElapsed {
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && !wakeUp.Before(deadline) {
+		return false
+	}
+
+	return true
+}
+
+func NewPolicy(config Config, opts ...Option) *Policy {
+	defaults := DefaultConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.MaxDelay < config.BaseDelay {
+		config.MaxDelay = config.BaseDelay
+	}
+
+	p := &Policy{config: config, clock: systemClock{}, random: rand.Float64}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
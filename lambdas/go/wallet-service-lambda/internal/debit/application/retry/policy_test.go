@@ -0,0 +1,177 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	errTransient = errors.New("transient")
+	errFatal     = errors.New("fatal")
+)
+
+func TestPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should return nil on first success without sleeping", testPolicy_Success)
+	t.Run("should not retry errors rejected by the classifier", testPolicy_NotRetryable)
+	t.Run("should back off exponentially with full jitter", testPolicy_Backoff)
+	t.Run("should cap the backoff at max delay", testPolicy_MaxDelay)
+	t.Run("should stop before exceeding max elapsed time", testPolicy_MaxElapsed)
+	t.Run("should stop before exceeding the context deadline", testPolicy_ContextDeadline)
+}
+
+func testPolicy_Success(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	policy := retry.NewPolicy(retry.Config{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute}, retry.WithClock(clock))
+
+	// WHEN
+	calls, err := run(policy, context.Background(), nil)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, clock.sleeps)
+}
+
+func testPolicy_NotRetryable(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	policy := retry.NewPolicy(retry.Config{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute}, retry.WithClock(clock))
+
+	// WHEN
+	calls, err := run(policy, context.Background(), []error{errFatal})
+
+	// THEN
+	assert.Equal(t, errFatal, err)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, clock.sleeps)
+}
+
+func testPolicy_Backoff(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	policy := retry.NewPolicy(
+		retry.Config{MaxAttempts: 4, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Minute},
+		retry.WithClock(clock),
+		retry.WithRandom(func() float64 { return 0.5 }),
+	)
+
+	// WHEN
+	calls, err := run(policy, context.Background(), []error{errTransient, errTransient, errTransient, errTransient})
+
+	// THEN
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.ErrorIs(t, err, errTransient)
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 200 * time.Millisecond}, clock.sleeps)
+}
+
+func testPolicy_MaxDelay(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	policy := retry.NewPolicy(
+		retry.Config{MaxAttempts: 4, BaseDelay: 100 * time.Millisecond, MaxDelay: 150 * time.Millisecond},
+		retry.WithClock(clock),
+		retry.WithRandom(func() float64 { return 0.99 }),
+	)
+
+	// WHEN
+	calls, err := run(policy, context.Background(), []error{errTransient, errTransient, errTransient})
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, []time.Duration{99 * time.Millisecond, 148500 * time.Microsecond, 148500 * time.Microsecond}, clock.sleeps)
+}
+
+func testPolicy_MaxElapsed(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	policy := retry.NewPolicy(
+		retry.Config{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: time.Second, MaxElapsed: 2500 * time.Millisecond},
+		retry.WithClock(clock),
+		retry.WithRandom(func() float64 { return 0.99 }),
+	)
+
+	// WHEN
+	calls, err := run(policy, context.Background(), []error{errTransient, errTransient, errTransient, errTransient})
+
+	// THEN
+	var exhausted *retry.ExhaustedError
+	assert.ErrorAs(t, err, &exhausted)
+	assert.Equal(t, 3, exhausted.Attempts)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, clock.sleeps, 2)
+}
+
+func testPolicy_ContextDeadline(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	policy := retry.NewPolicy(
+		retry.Config{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: time.Second},
+		retry.WithClock(clock),
+		retry.WithRandom(func() float64 { return 0.99 }),
+	)
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(1500*time.Millisecond))
+	defer cancel()
+
+	// WHEN
+	calls, err := run(policy, ctx, []error{errTransient, errTransient, errTransient})
+
+	// THEN
+	assert.ErrorIs(t, err, retry.ErrExhausted)
+	assert.Equal(t, 2, calls)
+	assert.Len(t, clock.sleeps, 1)
+}
+
+// --- Helper Functions ---
+
+// run executes an operation failing with errs in order and succeeding afterwards
+func run(policy *retry.Policy, ctx context.Context, errs []error) (int, error) {
+	calls := 0
+	err := policy.Do(ctx, func(context.Context) error {
+		calls++
+		if calls <= len(errs) {
+			return errs[calls-1]
+		}
+		return nil
+	}, func(err error) bool { return errors.Is(err, errTransient) })
+
+	return calls, err
+}
+
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func newClock() *fakeClock {
+	return &fakeClock{now: time.Now()}
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(_ context.Context, d time.Duration) error {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
@@ -0,0 +1,285 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/retry"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+type (
+	// SplitRequest charges a single payment to several wallets, one leg per wallet
+	SplitRequest struct {
+		PaymentID     string
+		CorrelationID string
+		Legs          []Leg
+		// MerchantCategory and UserTier select the fee rule of every leg
+		MerchantCategory string
+		UserTier         string
+	}
+
+	Leg struct {
+		UserID domain.UserID
+		Amount domain.Amount
+	}
+)
+
+// Total is the amount of the whole payment
+func (r SplitRequest) Total() domain.Amount {
+	var total domain.Amount
+	for _, leg := range r.Legs {
+		total += leg.Amount
+	}
+	return total
+}
+
+// leg is the debit of a single wallet, as audited and recorded in its history
+func (r SplitRequest) leg(i int) Request {
+	return Request{
+		PaymentID:        r.PaymentID,
+		UserID:           r.Legs[i].UserID,
+		Amount:           r.Legs[i].Amount,
+		CorrelationID:    r.CorrelationID,
+		MerchantCategory: r.MerchantCategory,
+		UserTier:         r.UserTier,
+	}
+}
+
+// charges prices every leg on its own, in the wallet currency. The fee of a leg is debited from
+// its wallet on top of its amount
+func (h *UseCaseHandler) charges(req SplitRequest) []charge {
+	charges := make([]charge, 0, len(req.Legs))
+	for i, leg := range req.Legs {
+		charges = append(charges, charge{amount: leg.Amount, fee: h.fee(req.leg(i), leg.Amount)})
+	}
+	return charges
+}
+
+func totalFee(charges []charge) domain.Amount {
+	var fee domain.Amount
+	for _, c := range charges {
+		fee += c.fee
+	}
+	return fee
+}
+
+// HandleSplit debits every leg of the payment or none of them. The wallets are written with a
+// single UpdateAll, so a lock conflict on any of them retries the whole payment, and a single
+// BalanceDebited event listing every leg is published. Every leg is charged its own fee, credited
+// to the revenue account with the same write, which therefore cannot be one of the payers
+func (h *UseCaseHandler) HandleSplit(ctx context.Context, req SplitRequest) error {
+	tracer := otel.Tracer("wallet-service.application")
+	ctx, span := tracer.Start(ctx, "UseCase.HandleSplitDebit")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("tenant.id", string(domain.TenantFrom(ctx))),
+		attribute.String("payment.id", req.PaymentID),
+		attribute.Int("split.legs", len(req.Legs)),
+		attribute.Float64("debit.amount", float64(req.Total())),
+	)
+
+	slog.InfoContext(ctx, "Handling split debit request", "paymentId", req.PaymentID, "legs", len(req.Legs))
+
+	charges := h.charges(req)
+
+	err := h.admit(ctx, req.Total(), "")
+	if err == nil && h.fees != nil {
+		for _, leg := range req.Legs {
+			if leg.UserID == h.revenueAccount {
+				err = domain.NewRevenueAccountDebitError(string(leg.UserID))
+				break
+			}
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Debit rejected")
+		slog.WarnContext(ctx, "split debit rejected by the tenant", "paymentId", req.PaymentID, "error", err)
+		h.auditLegs(ctx, req, charges, ports.AuditOutcomeRejected, 0, nil, nil, err)
+		return err
+	}
+
+	var read, wallets []domain.Wallet
+	var revenue domain.Wallet
+	attempt := 0
+
+	err = h.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		attempt++
+
+		var err error
+		read, wallets, revenue, err = h.debitAll(ctx, req, charges, attempt)
+		return err
+	}, isTransient)
+
+	if errors.Is(err, retry.ErrExhausted) && errors.Is(err, repository.ErrVersionMismatch) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Transaction failed after max retries")
+		slog.ErrorContext(ctx, "split transaction failed after max retries", "error", err, "paymentId", req.PaymentID)
+		err = domain.NewMaxRetriesError(req.PaymentID, err)
+		h.auditLegs(ctx, req, charges, ports.AuditOutcomeRetriesExhausted, attempt, read, nil, err)
+		return err
+	}
+	if domain.IsInsufficientFunds(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Debit failed")
+		h.auditLegs(ctx, req, charges, ports.AuditOutcomeInsufficientFunds, attempt, read, read, err)
+		return err
+	}
+	if domain.IsPaymentApplied(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Payment already applied")
+		h.auditLegs(ctx, req, charges, ports.AuditOutcomeAlreadyApplied, attempt, read, read, err)
+		if pubErr := h.republishSplit(context.WithoutCancel(ctx), req, read, charges); pubErr != nil {
+			span.RecordError(pubErr)
+			return pubErr
+		}
+		return err
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Debit failed")
+		h.auditLegs(ctx, req, charges, ports.AuditOutcomeFailed, attempt, read, nil, err)
+		return err
+	}
+
+	fee := totalFee(charges)
+	span.SetAttributes(attribute.Float64("debit.fee", float64(fee)))
+
+	slog.InfoContext(ctx, "Debited split payment", "paymentId", req.PaymentID, "attempts", attempt, "fee", fee)
+
+	// committed, see Handle
+	ctx = context.WithoutCancel(ctx)
+
+	for i, wallet := range wallets {
+		h.recordTransaction(ctx, req.leg(i), domain.TransactionDebit, wallet, charges[i])
+	}
+	if fee > 0 {
+		h.recordTransaction(ctx, Request{PaymentID: req.PaymentID, CorrelationID: req.CorrelationID}, domain.TransactionCredit, revenue, charge{amount: fee})
+	}
+
+	err = h.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return h.eventProcessor.Publish(ctx, toSplitDebitEventRequest(domain.TenantFrom(ctx), wallets, req, charges))
+	}, domain.IsRetryable)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "Publish event failed")
+		slog.ErrorContext(ctx, "error publishing event after successful split debit", "error", err)
+		err = domain.NewPublishMessageError(req.PaymentID, err)
+		h.auditLegs(ctx, req, charges, ports.AuditOutcomePublishFailed, attempt, read, wallets, err)
+		return err
+	}
+
+	h.auditLegs(ctx, req, charges, ports.AuditOutcomeDebited, attempt, read, wallets, nil)
+
+	slog.InfoContext(ctx, "Finished split request", "paymentId", req.PaymentID)
+	return nil
+}
+
+// debitAll reads every wallet, debits its leg and fee and writes them all at once, together with
+// the credit of the fees. It returns the wallets read so far and, once every leg fits, the debited
+// ones and the revenue account as credited
+func (h *UseCaseHandler) debitAll(ctx context.Context, req SplitRequest, charges []charge, attempt int) ([]domain.Wallet, []domain.Wallet, domain.Wallet, error) {
+	tracer := otel.Tracer("wallet-service.application")
+
+	read := make([]domain.Wallet, 0, len(req.Legs))
+	for _, leg := range req.Legs {
+		readCtx, readSpan := tracer.Start(ctx, "Repository.Get")
+		wallet, err := h.walletRepo.Get(readCtx, leg.UserID)
+		readSpan.End()
+
+		if err != nil {
+			slog.ErrorContext(ctx, "Error getting funds for user", "userID", leg.UserID, "attempt", attempt, "error", err)
+			return read, nil, domain.Wallet{}, domain.NewGetFundsError(string(leg.UserID), err)
+		}
+		read = append(read, wallet)
+	}
+
+	// the legs are applied together, see debit
+	if _, ok := h.recordedDebit(ctx, req.PaymentID, req.Legs[0].UserID); ok {
+		slog.WarnContext(ctx, "payment already recorded", "paymentId", req.PaymentID)
+		return read, nil, domain.Wallet{}, domain.NewPaymentAppliedError(req.PaymentID, req.PaymentID, repository.ErrPaymentApplied)
+	}
+
+	wallets := slices.Clone(read)
+	for i := range wallets {
+		if err := wallets[i].Debit(charges[i].total()); err != nil {
+			slog.ErrorContext(ctx, "Error debiting amount from wallet", "amount", req.Legs[i].Amount, "fee", charges[i].fee, "userID", req.Legs[i].UserID, "error", err)
+			return read, nil, domain.Wallet{}, err
+		}
+		wallets[i].Payment = req.PaymentID
+	}
+
+	var revenue domain.Wallet
+	var err error
+
+	updateCtx, updateSpan := tracer.Start(ctx, "Repository.UpdateAll")
+	if fee := totalFee(charges); fee > 0 {
+		revenue, err = h.walletRepo.UpdateAllAndCredit(updateCtx, wallets, ports.Credit{UserID: h.revenueAccount, Amount: fee})
+	} else {
+		err = h.walletRepo.UpdateAll(updateCtx, wallets)
+	}
+	updateSpan.End()
+
+	// Optimistic blocking
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		slog.WarnContext(ctx, "version mismatch detected", "attempt", attempt, "paymentId", req.PaymentID)
+		return read, wallets, revenue, err
+	}
+	if errors.Is(err, repository.ErrPaymentApplied) {
+		slog.WarnContext(ctx, "payment already applied", "paymentId", req.PaymentID)
+		return read, wallets, revenue, domain.NewPaymentAppliedError(req.PaymentID, req.PaymentID, err)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "repository error on update", "error", err, "attempt", attempt, "paymentId", req.PaymentID)
+		return read, wallets, revenue, domain.NewDebitFundsError(req.PaymentID, err)
+	}
+
+	return read, wallets, revenue, nil
+}
+
+// auditLegs writes a record per leg. Legs whose wallet was not read, or not debited, get no balance
+func (h *UseCaseHandler) auditLegs(ctx context.Context, req SplitRequest, charges []charge, outcome ports.AuditOutcome, attempts int, before, after []domain.Wallet, err error) {
+	for i := range req.Legs {
+		h.audit(ctx, newAuditRecord(req.leg(i), charges[i], outcome, attempts, walletAt(before, i), walletAt(after, i)), err)
+	}
+}
+
+func walletAt(wallets []domain.Wallet, i int) *domain.Amount {
+	if i >= len(wallets) {
+		return nil
+	}
+	return balanceIn(wallets[i], "")
+}
+
+// toSplitDebitEventRequest debits every leg with its fee, Principal is what the payment is charged
+func toSplitDebitEventRequest(tenant domain.TenantID, wallets []domain.Wallet, req SplitRequest, charges []charge) ports.BalanceDebitedRequest {
+	fee := totalFee(charges)
+	legs := make([]ports.DebitedLeg, 0, len(wallets))
+	for i, wallet := range wallets {
+		legs = append(legs, ports.DebitedLeg{
+			UserID:        wallet.UserID,
+			AmountDebited: charges[i].total(),
+			AmountLeft:    wallet.Amount,
+		})
+	}
+
+	return ports.BalanceDebitedRequest{
+		TenantID:      tenant,
+		PaymentID:     req.PaymentID,
+		AmountDebited: req.Total() + fee,
+		Principal:     req.Total(),
+		Fee:           fee,
+		EventName:     domain.BalanceDebitedEventName,
+		CorrelationID: req.CorrelationID,
+		Legs:          legs,
+	}
+}
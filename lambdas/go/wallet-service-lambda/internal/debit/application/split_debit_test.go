@@ -0,0 +1,262 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/ports/mocks"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitDebit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should debit every leg and publish a single event", testSplit_Success)
+	t.Run("should debit no leg when one of them lacks funds", testSplit_InsufficientFunds)
+	t.Run("should retry the whole payment on version mismatch", testSplit_VersionMismatchRetried)
+	t.Run("should audit and record every leg", testSplit_AuditAndTransactions)
+	t.Run("should publish a committed split payment after the deadline passes", testSplit_DeadlineAfterCommit)
+	t.Run("should charge the fee of every leg and credit the revenue account", testSplit_Fees)
+	t.Run("should reject a split payment paid by the revenue account", testSplit_RevenueAccountPayer)
+}
+
+func testSplit_Success(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := splitRequest()
+
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-1")).Return(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1}, nil).Once()
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-2")).Return(domain.Wallet{UserID: "user-2", Amount: 50, Version: 3}, nil).Once()
+	repoMock.EXPECT().UpdateAll(mock.Anything, []domain.Wallet{
+		{UserID: "user-1", Amount: 70, Version: 1, Payment: "pay-1"},
+		{UserID: "user-2", Amount: 30, Version: 3, Payment: "pay-1"},
+	}).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, ports.BalanceDebitedRequest{
+		PaymentID:     "pay-1",
+		AmountDebited: 50,
+		Principal:     50,
+		EventName:     domain.BalanceDebitedEventName,
+		CorrelationID: "corr-1",
+		Legs: []ports.DebitedLeg{
+			{UserID: "user-1", AmountDebited: 30, AmountLeft: 70},
+			{UserID: "user-2", AmountDebited: 20, AmountLeft: 30},
+		},
+	}).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock)
+
+	// WHEN
+	err := useCase.HandleSplit(context.Background(), req)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testSplit_InsufficientFunds(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{UserID: "user-2", Amount: 10, Version: 1},
+	)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock)
+
+	// WHEN
+	err := useCase.HandleSplit(context.Background(), splitRequest())
+
+	// THEN
+	assert.True(t, domain.IsInsufficientFunds(err))
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "user-1", Amount: 100, Version: 1},
+		{UserID: "user-2", Amount: 10, Version: 1},
+	}, repo.Wallets())
+	busMock.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+func testSplit_VersionMismatchRetried(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-1")).Return(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1}, nil).Once()
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-2")).Return(domain.Wallet{UserID: "user-2", Amount: 50, Version: 1}, nil).Once()
+	repoMock.EXPECT().UpdateAll(mock.Anything, mock.Anything).Return(repository.ErrVersionMismatch).Once()
+
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-1")).Return(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1}, nil).Once()
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-2")).Return(domain.Wallet{UserID: "user-2", Amount: 40, Version: 2}, nil).Once()
+	repoMock.EXPECT().UpdateAll(mock.Anything, mock.MatchedBy(func(wallets []domain.Wallet) bool {
+		return wallets[1].Amount == 20 && wallets[1].Version == 2
+	})).Return(nil).Once()
+
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock)
+
+	// WHEN
+	err := useCase.HandleSplit(context.Background(), splitRequest())
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testSplit_AuditAndTransactions(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{UserID: "user-2", Amount: 50, Version: 1},
+	)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	transactions := repository.NewInMemoryTransactionRepository()
+
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+	auditMock.EXPECT().Append(mock.Anything, mock.MatchedBy(func(r ports.AuditRecord) bool {
+		return r.Outcome == ports.AuditOutcomeDebited && r.UserID == "user-1" && *r.BalanceBefore == 100 && *r.BalanceAfter == 70
+	})).Return(nil).Once()
+	auditMock.EXPECT().Append(mock.Anything, mock.MatchedBy(func(r ports.AuditRecord) bool {
+		return r.Outcome == ports.AuditOutcomeDebited && r.UserID == "user-2" && *r.BalanceBefore == 50 && *r.BalanceAfter == 30
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithAuditTrail(auditMock, "wallet-service"),
+		application.WithTransactionRepository(transactions),
+	)
+
+	// WHEN
+	err := useCase.HandleSplit(context.Background(), splitRequest())
+
+	// THEN
+	require.NoError(t, err)
+	recorded := transactions.Transactions()
+	require.Len(t, recorded, 2)
+	assert.Equal(t, domain.UserID("user-1"), recorded[0].UserID)
+	assert.Equal(t, domain.Amount(30), recorded[0].Amount)
+	assert.Equal(t, domain.UserID("user-2"), recorded[1].UserID)
+	assert.Equal(t, domain.Amount(30), recorded[1].BalanceAfter)
+	assert.Equal(t, "pay-1", recorded[1].PaymentID)
+}
+
+func testSplit_DeadlineAfterCommit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-1")).Return(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1}, nil).Once()
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-2")).Return(domain.Wallet{UserID: "user-2", Amount: 50, Version: 3}, nil).Once()
+	repoMock.EXPECT().UpdateAll(mock.Anything, mock.Anything).Run(func(context.Context, []domain.Wallet) {
+		<-ctx.Done()
+	}).Return(nil).Once()
+	busMock.EXPECT().Publish(mock.MatchedBy(isLive), mock.Anything).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock)
+
+	// WHEN
+	err := useCase.HandleSplit(ctx, splitRequest())
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testSplit_Fees(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{UserID: "user-2", Amount: 50, Version: 1},
+		domain.Wallet{UserID: "revenue", Amount: 0, Version: 1},
+	)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	transactions := repository.NewInMemoryTransactionRepository()
+
+	busMock.EXPECT().Publish(mock.Anything, ports.BalanceDebitedRequest{
+		PaymentID:     "pay-1",
+		AmountDebited: 52,
+		Principal:     50,
+		Fee:           2,
+		EventName:     domain.BalanceDebitedEventName,
+		CorrelationID: "corr-1",
+		Legs: []ports.DebitedLeg{
+			{UserID: "user-1", AmountDebited: 31, AmountLeft: 69},
+			{UserID: "user-2", AmountDebited: 21, AmountLeft: 29},
+		},
+	}).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithFeeSchedule(schedule, "revenue"),
+		application.WithTransactionRepository(transactions),
+	)
+
+	// WHEN
+	err := useCase.HandleSplit(context.Background(), splitRequest())
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "revenue", Amount: 2, Version: 2},
+		{UserID: "user-1", Amount: 69, Version: 2},
+		{UserID: "user-2", Amount: 29, Version: 2},
+	}, repo.Wallets())
+	recorded := transactions.Transactions()
+	require.Len(t, recorded, 3)
+	assert.Equal(t, domain.Amount(31), recorded[0].Amount)
+	assert.Equal(t, domain.Amount(21), recorded[1].Amount)
+	assert.Equal(t, domain.TransactionCredit, recorded[2].Type)
+	assert.Equal(t, domain.UserID("revenue"), recorded[2].UserID)
+	assert.Equal(t, domain.Amount(2), recorded[2].Amount)
+}
+
+func testSplit_RevenueAccountPayer(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	req := splitRequest()
+	req.Legs[1].UserID = "revenue"
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithFeeSchedule(schedule, "revenue"))
+
+	// WHEN
+	err := useCase.HandleSplit(context.Background(), req)
+
+	// THEN
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "4009", domainErr.Code)
+	repoMock.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	busMock.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything)
+}
+
+// --- Helper Functions ---
+
+func splitRequest() application.SplitRequest {
+	return application.SplitRequest{
+		PaymentID:     "pay-1",
+		CorrelationID: "corr-1",
+		Legs: []application.Leg{
+			{UserID: "user-1", Amount: 30},
+			{UserID: "user-2", Amount: 20},
+		},
+	}
+}
@@ -0,0 +1,134 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// walletBalance selects the movements of the wallet currency balance, the one statements cover
+var walletBalance = []domain.Currency{""}
+
+type (
+	// Statement covers the movements of the wallet currency balance booked in [From, To), oldest first
+	Statement struct {
+		UserID         domain.UserID
+		From           time.Time
+		To             time.Time
+		OpeningBalance domain.Amount
+		ClosingBalance domain.Amount
+		Movements      []domain.Transaction
+		GeneratedAt    time.Time
+	}
+
+	StatementUseCase struct {
+		history    ports.TransactionHistory
+		walletRepo ports.WalletRepository
+		now        func() time.Time
+	}
+
+	StatementOption func(*StatementUseCase)
+)
+
+// WithStatementClock replaces the clock used to stamp the statements
+func WithStatementClock(now func() time.Time) StatementOption {
+	return func(u *StatementUseCase) { u.now = now }
+}
+
+// Generate builds the statement of a wallet for a period. The balances come from the transaction
+// history: the movements of the period, otherwise the closest transaction after or before it.
+// The stored balance is only used for wallets without any transaction
+func (u *StatementUseCase) Generate(ctx context.Context, userID domain.UserID, from, to time.Time) (Statement, error) {
+	if userID == "" {
+		return Statement{}, fmt.Errorf("%w: user_id is missing", ErrInvalidQuery)
+	}
+	if !from.Before(to) {
+		return Statement{}, fmt.Errorf("%w: from must be before to", ErrInvalidQuery)
+	}
+
+	movements, err := u.all(ctx, ports.TransactionQuery{UserID: userID, From: from, To: to, Currencies: walletBalance})
+	if err != nil {
+		return Statement{}, err
+	}
+
+	statement := Statement{UserID: userID, From: from, To: to, Movements: movements, GeneratedAt: u.now().UTC()}
+	if len(movements) > 0 {
+		statement.OpeningBalance = balanceBefore(movements[0])
+		statement.ClosingBalance = movements[len(movements)-1].BalanceAfter
+		return statement, nil
+	}
+
+	balance, err := u.balanceAt(ctx, userID, from, to)
+	if err != nil {
+		return Statement{}, err
+	}
+	statement.OpeningBalance, statement.ClosingBalance = balance, balance
+
+	return statement, nil
+}
+
+// balanceAt is the balance of a period without movements
+func (u *StatementUseCase) balanceAt(ctx context.Context, userID domain.UserID, from, to time.Time) (domain.Amount, error) {
+	later, err := u.all(ctx, ports.TransactionQuery{UserID: userID, From: to, Currencies: walletBalance})
+	if err != nil {
+		return 0, err
+	}
+	if len(later) > 0 {
+		return balanceBefore(later[0]), nil
+	}
+
+	earlier, err := u.history.History(ctx, ports.TransactionQuery{UserID: userID, To: from, Currencies: walletBalance, Limit: 1})
+	if err != nil {
+		return 0, err
+	}
+	if len(earlier.Transactions) > 0 {
+		return earlier.Transactions[0].BalanceAfter, nil
+	}
+
+	wallet, err := u.walletRepo.Get(ctx, userID)
+	if err != nil {
+		return 0, domain.NewGetFundsError(string(userID), err)
+	}
+	return wallet.Amount, nil
+}
+
+// all walks every page of the query and returns the transactions oldest first
+func (u *StatementUseCase) all(ctx context.Context, query ports.TransactionQuery) ([]domain.Transaction, error) {
+	query.Limit = maxPageSize
+
+	var transactions []domain.Transaction
+	for {
+		page, err := u.history.History(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, page.Transactions...)
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	slices.Reverse(transactions)
+	return transactions, nil
+}
+
+func balanceBefore(tx domain.Transaction) domain.Amount {
+	if tx.Type == domain.TransactionCredit {
+		return tx.BalanceAfter - tx.Amount
+	}
+	return tx.BalanceAfter + tx.Amount
+}
+
+func NewStatementUseCase(history ports.TransactionHistory, repo ports.WalletRepository, opts ...StatementOption) *StatementUseCase {
+	u := &StatementUseCase{history: history, walletRepo: repo, now: time.Now}
+	for _, opt := range opts {
+		opt(u)
+	}
+
+	return u
+}
@@ -0,0 +1,158 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	january  = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	february = time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestStatementUseCase(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should take the balances from the movements of the period", testStatement_Movements)
+	t.Run("should take the balance before the next movement for a quiet period", testStatement_LaterMovement)
+	t.Run("should take the balance after the previous movement for a quiet period", testStatement_EarlierMovement)
+	t.Run("should take the stored balance for a wallet without history", testStatement_NoHistory)
+	t.Run("should reject an empty period", testStatement_InvalidPeriod)
+	t.Run("should leave out the movements of other currency balances", testStatement_OtherCurrencies)
+}
+
+func testStatement_Movements(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newStatementUseCase(t,
+		movement("tx-0", domain.TransactionDebit, 10, 90, january.AddDate(0, 0, -5)),
+		movement("tx-1", domain.TransactionDebit, 30, 60, january.AddDate(0, 0, 3)),
+		movement("tx-2", domain.TransactionCredit, 15, 75, january.AddDate(0, 0, 9)),
+		movement("tx-3", domain.TransactionDebit, 5, 70, february),
+	)
+
+	// WHEN
+	s, err := useCase.Generate(context.Background(), "user-1", january, february)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(90), s.OpeningBalance)
+	assert.Equal(t, domain.Amount(75), s.ClosingBalance)
+	require.Len(t, s.Movements, 2)
+	assert.Equal(t, "tx-1", s.Movements[0].ID)
+	assert.Equal(t, "tx-2", s.Movements[1].ID)
+	assert.Equal(t, february.Add(time.Hour), s.GeneratedAt)
+}
+
+func testStatement_LaterMovement(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newStatementUseCase(t,
+		movement("tx-1", domain.TransactionCredit, 20, 120, february.AddDate(0, 0, 2)),
+		movement("tx-2", domain.TransactionDebit, 20, 100, february.AddDate(0, 0, 3)),
+	)
+
+	// WHEN
+	s, err := useCase.Generate(context.Background(), "user-1", january, february)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(100), s.OpeningBalance)
+	assert.Equal(t, domain.Amount(100), s.ClosingBalance)
+	assert.Empty(t, s.Movements)
+}
+
+func testStatement_EarlierMovement(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newStatementUseCase(t, movement("tx-1", domain.TransactionDebit, 20, 80, january.AddDate(0, 0, -1)))
+
+	// WHEN
+	s, err := useCase.Generate(context.Background(), "user-1", january, february)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(80), s.OpeningBalance)
+	assert.Equal(t, domain.Amount(80), s.ClosingBalance)
+}
+
+func testStatement_NoHistory(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newStatementUseCase(t)
+
+	// WHEN
+	s, err := useCase.Generate(context.Background(), "user-1", january, february)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(50), s.OpeningBalance)
+	assert.Equal(t, domain.Amount(50), s.ClosingBalance)
+}
+
+func testStatement_InvalidPeriod(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newStatementUseCase(t)
+
+	// WHEN
+	_, err := useCase.Generate(context.Background(), "user-1", february, january)
+
+	// THEN
+	assert.ErrorIs(t, err, application.ErrInvalidQuery)
+}
+
+func testStatement_OtherCurrencies(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	inUSD := movement("tx-2", domain.TransactionDebit, 5, 15, january.AddDate(0, 0, 4))
+	inUSD.Currency = "USD"
+	useCase := newStatementUseCase(t,
+		movement("tx-0", domain.TransactionDebit, 10, 90, january.AddDate(0, 0, -5)),
+		movement("tx-1", domain.TransactionDebit, 30, 60, january.AddDate(0, 0, 3)),
+		inUSD,
+	)
+
+	// WHEN
+	s, err := useCase.Generate(context.Background(), "user-1", january, february)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(90), s.OpeningBalance)
+	assert.Equal(t, domain.Amount(60), s.ClosingBalance)
+	require.Len(t, s.Movements, 1)
+	assert.Equal(t, "tx-1", s.Movements[0].ID)
+}
+
+// --- Helper Functions ---
+
+func newStatementUseCase(t *testing.T, transactions ...domain.Transaction) *application.StatementUseCase {
+	t.Helper()
+
+	history := repository.NewInMemoryTransactionRepository()
+	for _, tx := range transactions {
+		require.NoError(t, history.Append(context.Background(), tx))
+	}
+	wallets := repository.NewInMemoryWalletRepositoryWith(domain.Wallet{UserID: "user-1", Amount: 50, Version: 1})
+
+	return application.NewStatementUseCase(history, wallets, application.WithStatementClock(func() time.Time {
+		return february.Add(time.Hour)
+	}))
+}
+
+func movement(id string, txType domain.TransactionType, amount, balanceAfter domain.Amount, at time.Time) domain.Transaction {
+	return domain.Transaction{ID: id, UserID: "user-1", Type: txType, Amount: amount, BalanceAfter: balanceAfter, Timestamp: at}
+}
@@ -0,0 +1,33 @@
+package application
+
+import (
+	"context"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// WithTenants hosts the tenants of the registry. Requests are served in the tenant of their
+// context, which has to be one of them. Without it only the empty tenant is hosted. Payments
+// without currency are admitted in the one set by WithWalletCurrency
+func WithTenants(tenants domain.Tenants) Option {
+	return func(h *UseCaseHandler) { h.tenants = tenants }
+}
+
+// admit rejects the payments of amount in currency, empty for the wallet one, that the tenant of
+// ctx does not host or accept
+func (h *UseCaseHandler) admit(ctx context.Context, amount domain.Amount, currency domain.Currency) error {
+	id := domain.TenantFrom(ctx)
+	tenant, ok := h.tenants.Get(id)
+	if !ok {
+		return domain.NewUnknownTenantError(id)
+	}
+	return tenant.Admit(amount, h.currencyCode(h.balanceCurrency(currency)))
+}
+
+// fallbackOf is the fallback of the tenant of ctx, the one of the deployment when it sets none
+func (h *UseCaseHandler) fallbackOf(ctx context.Context) []domain.Currency {
+	if tenant, ok := h.tenants.Get(domain.TenantFrom(ctx)); ok && len(tenant.FallbackCurrencies) > 0 {
+		return tenant.FallbackCurrencies
+	}
+	return h.fallback
+}
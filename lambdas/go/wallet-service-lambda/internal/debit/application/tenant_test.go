@@ -0,0 +1,206 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/ports/mocks"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var registry = domain.Tenants{
+	"acme":   {ID: "acme", Currencies: []domain.Currency{"EUR", "USD"}, FallbackCurrencies: []domain.Currency{"USD"}, MaxDebit: 100},
+	"globex": {ID: "globex"},
+}
+
+func TestUseCaseHandlerTenants(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should debit only the wallet of the tenant of the context", testTenants_Isolation)
+	t.Run("should reject a payment over the limit of the tenant", testTenants_DebitLimit)
+	t.Run("should reject a currency the tenant does not accept", testTenants_CurrencyNotAllowed)
+	t.Run("should reject the requests of a tenant not hosted", testTenants_UnknownTenant)
+	t.Run("should fall back to the currencies of the tenant", testTenants_Fallback)
+	t.Run("should reject a split payment over the limit of the tenant", testTenants_SplitLimit)
+}
+
+func testTenants_Isolation(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(
+		domain.Wallet{TenantID: "acme", UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{TenantID: "globex", UserID: "user-1", Amount: 100, Version: 1},
+	)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	ctx := domain.WithTenant(context.Background(), "globex")
+
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.TenantID == "globex" && req.AmountLeft == 70
+	})).Return(nil).Once()
+	auditMock.EXPECT().Append(mock.Anything, mock.MatchedBy(func(record ports.AuditRecord) bool {
+		return record.TenantID == "globex" && record.Outcome == ports.AuditOutcomeDebited
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithTenants(registry),
+		application.WithWalletCurrency("EUR"),
+		application.WithAuditTrail(auditMock, "wallet-service"),
+	)
+
+	// WHEN
+	err := useCase.Handle(ctx, application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 30, CorrelationID: "corr-1"})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Wallet{
+		{TenantID: "acme", UserID: "user-1", Amount: 100, Version: 1},
+		{TenantID: "globex", UserID: "user-1", Amount: 70, Version: 2},
+	}, repo.Wallets())
+}
+
+func testTenants_DebitLimit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	auditMock := mocks.NewMockAuditTrail(t)
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	auditMock.EXPECT().Append(mock.Anything, ports.AuditRecord{
+		TenantID:      "acme",
+		Outcome:       ports.AuditOutcomeRejected,
+		PaymentID:     "pay-1",
+		CorrelationID: "corr-1",
+		UserID:        "user-1",
+		Amount:        150,
+		ErrorCode:     "4006",
+		Reason:        "debit limit exceeded error",
+		Actor:         "wallet-service",
+	}).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock,
+		application.WithTenants(registry),
+		application.WithWalletCurrency("EUR"),
+		application.WithAuditTrail(auditMock, "wallet-service"),
+	)
+
+	// WHEN
+	err := useCase.Handle(ctx, application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 150, CorrelationID: "corr-1"})
+
+	// THEN
+	assertErrorCode(t, err, "4006")
+	repoMock.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func testTenants_CurrencyNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock,
+		application.WithTenants(registry),
+		application.WithWalletCurrency("EUR"),
+	)
+
+	// WHEN
+	err := useCase.Handle(ctx, application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 10, Currency: "GBP", CorrelationID: "corr-1"})
+
+	// THEN
+	assertErrorCode(t, err, "4005")
+	repoMock.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func testTenants_UnknownTenant(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock, application.WithTenants(registry))
+	req := application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 10, CorrelationID: "corr-1"}
+
+	// WHEN
+	unknownErr := useCase.Handle(domain.WithTenant(context.Background(), "initech"), req)
+	missingErr := useCase.Handle(context.Background(), req)
+
+	// THEN
+	assertErrorCode(t, unknownErr, "4004")
+	assertErrorCode(t, missingErr, "4004")
+	repoMock.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func testTenants_Fallback(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	wallet := multiCurrencyWallet(5, 50)
+	wallet.TenantID = "acme"
+	repo := repository.NewInMemoryWalletRepositoryWith(wallet)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	rates := mocks.NewMockRateProvider(t)
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	rates.EXPECT().Rate(mock.Anything, domain.Currency("EUR"), domain.Currency("USD")).
+		Return(domain.Rate{ID: "eur-usd-1", From: "EUR", To: "USD", Value: 1.1}, nil).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(req ports.BalanceDebitedRequest) bool {
+		return req.TenantID == "acme" && req.Currency == "USD"
+	})).Return(nil).Once()
+
+	useCase := application.NewDebitBalanceUseCaseHandler(repo, busMock,
+		application.WithExchangeRates(rates, fxPolicy),
+		application.WithWalletCurrency("EUR"),
+		application.WithTenants(registry),
+	)
+
+	// WHEN
+	err := useCase.Handle(ctx, application.Request{PaymentID: "pay-1", UserID: "user-1", Amount: 20, CorrelationID: "corr-1"})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(5), repo.Wallets()[0].Amount)
+}
+
+func testTenants_SplitLimit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	busMock := mocks.NewMockEventBusProcessor(t)
+	useCase := application.NewDebitBalanceUseCaseHandler(repoMock, busMock,
+		application.WithTenants(registry),
+		application.WithWalletCurrency("EUR"),
+	)
+
+	// WHEN
+	err := useCase.HandleSplit(domain.WithTenant(context.Background(), "acme"), application.SplitRequest{
+		PaymentID:     "pay-1",
+		CorrelationID: "corr-1",
+		Legs:          []application.Leg{{UserID: "user-1", Amount: 60}, {UserID: "user-2", Amount: 60}},
+	})
+
+	// THEN
+	assertErrorCode(t, err, "4006")
+	repoMock.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+// --- Helper Functions ---
+
+func assertErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, code, domainErr.Code)
+}
@@ -1,19 +1,50 @@
 package domain
 
+import "errors"
+
+const (
+	insufficientFundsCode = "4001"
+	paymentAppliedCode    = "4010"
+)
+
 type Error struct {
-	Message  string
-	Code     string
-	Cause    error
-	Metadata map[string]any
+	Message   string
+	Code      string
+	Cause     error
+	Metadata  map[string]any
+	Retryable bool
 }
 
 func (e *Error) Error() string { return e.Message }
 func (e *Error) Unwrap() error { return e.Cause }
 
+// IsRetryable reports whether any domain error in err's chain is marked as retryable
+func IsRetryable(err error) bool {
+	for err != nil {
+		if e, ok := err.(*Error); ok && e.Retryable {
+			return true
+		}
+		err = errors.Unwrap(err)
+	}
+	return false
+}
+
+// IsInsufficientFunds reports whether err was caused by a wallet without enough balance
+func IsInsufficientFunds(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == insufficientFundsCode
+}
+
+// IsPaymentApplied reports whether err rejected a payment already applied to the wallet
+func IsPaymentApplied(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == paymentAppliedCode
+}
+
 func NewInsufficientFundsError(id string, available, requested float64) error {
 	return &Error{
 		Message: "insufficient funds error",
-		Code:    "4001",
+		Code:    insufficientFundsCode,
 		Metadata: map[string]any{
 			"id":               id,
 			"availableBalance": available,
@@ -56,3 +87,98 @@ func NewPublishMessageError(id string, e error) error {
 		Metadata: map[string]any{"id": id},
 	}
 }
+
+func NewDependencyUnavailableError(dependency string, e error) error {
+	return &Error{
+		Message:   "dependency unavailable error",
+		Code:      "5004",
+		Cause:     e,
+		Metadata:  map[string]any{"dependency": dependency},
+		Retryable: true,
+	}
+}
+
+// NewExchangeRateError reports a payment that could not be converted into the wallet currency
+func NewExchangeRateError(from, to Currency, e error) error {
+	return &Error{
+		Message:  "exchange rate error",
+		Code:     "5005",
+		Cause:    e,
+		Metadata: map[string]any{"from": string(from), "to": string(to)},
+	}
+}
+
+// NewMandateNotActiveError rejects changes to a cancelled or completed mandate
+func NewMandateNotActiveError(id string, status MandateStatus) error {
+	return &Error{
+		Message:  "mandate not active error",
+		Code:     "4003",
+		Metadata: map[string]any{"id": id, "status": string(status)},
+	}
+}
+
+// NewUnknownTenantError rejects a request scoped to a tenant not hosted by the deployment
+func NewUnknownTenantError(tenant TenantID) error {
+	return &Error{
+		Message:  "unknown tenant error",
+		Code:     "4004",
+		Metadata: map[string]any{"tenant": string(tenant)},
+	}
+}
+
+// NewCurrencyNotAllowedError rejects a payment in a currency its tenant does not accept
+func NewCurrencyNotAllowedError(tenant TenantID, currency Currency) error {
+	return &Error{
+		Message:  "currency not allowed error",
+		Code:     "4005",
+		Metadata: map[string]any{"tenant": string(tenant), "currency": string(currency)},
+	}
+}
+
+// NewDebitLimitError rejects a payment over the limit of its tenant
+func NewDebitLimitError(tenant TenantID, limit, requested float64) error {
+	return &Error{
+		Message:  "debit limit exceeded error",
+		Code:     "4006",
+		Metadata: map[string]any{"tenant": string(tenant), "limit": limit, "requestedAmount": requested},
+	}
+}
+
+// NewInvalidSignatureError rejects an event whose signature does not verify with the key it names
+func NewInvalidSignatureError(keyID string, e error) error {
+	return &Error{
+		Message:  "invalid signature error",
+		Code:     "4007",
+		Cause:    e,
+		Metadata: map[string]any{"keyId": keyID},
+	}
+}
+
+// NewRevenueAccountDebitError rejects a payment charged with fees from the account receiving them
+func NewRevenueAccountDebitError(id string) error {
+	return &Error{
+		Message:  "revenue account debit error",
+		Code:     "4009",
+		Metadata: map[string]any{"id": id},
+	}
+}
+
+// NewPaymentAppliedError rejects a payment the wallet already applied, a redelivery or a retry
+// of a run whose outcome was lost
+func NewPaymentAppliedError(id, paymentID string, e error) error {
+	return &Error{
+		Message:  "payment already applied error",
+		Code:     paymentAppliedCode,
+		Cause:    e,
+		Metadata: map[string]any{"id": id, "paymentId": paymentID},
+	}
+}
+
+// NewSourceNotAllowedError rejects an event of a type its producer is not allowed to send
+func NewSourceNotAllowedError(source, eventType string) error {
+	return &Error{
+		Message:  "source not allowed error",
+		Code:     "4008",
+		Metadata: map[string]any{"source": source, "eventType": eventType},
+	}
+}
@@ -2,12 +2,33 @@ package events
 
 import "github.com/payment-processor/internal/debit/domain"
 
-type BalanceDebitedPayload struct {
+type DebitedLeg struct {
 	UserID        domain.UserID `json:"userId"`
 	AmountDebited domain.Amount `json:"amountDebited"`
 	AmountLeft    domain.Amount `json:"amountLeft"`
 }
 
+// BalanceDebitedPayload breaks AmountDebited down into the Principal of the payment and the Fee
+// charged on top of it. A split payment has no UserID nor AmountLeft, AmountDebited is the total
+// and every wallet debited is listed in Legs. A payment charged in another currency carries the
+// amount as sent, its currency and the rate that converted it into Currency. Multi-currency
+// wallets carry every balance they hold
+type BalanceDebitedPayload struct {
+	PaymentID        string                            `json:"paymentId"`
+	UserID           domain.UserID                     `json:"userId"`
+	AmountDebited    domain.Amount                     `json:"amountDebited"`
+	Principal        domain.Amount                     `json:"principal"`
+	Fee              domain.Amount                     `json:"fee"`
+	AmountLeft       domain.Amount                     `json:"amountLeft"`
+	Legs             []DebitedLeg                      `json:"legs,omitempty"`
+	OriginalAmount   domain.Amount                     `json:"originalAmount,omitempty"`
+	OriginalCurrency domain.Currency                   `json:"originalCurrency,omitempty"`
+	Currency         domain.Currency                   `json:"currency,omitempty"`
+	RateID           string                            `json:"rateId,omitempty"`
+	Rate             float64                           `json:"rate,omitempty"`
+	Balances         map[domain.Currency]domain.Amount `json:"balances,omitempty"`
+}
+
 type BalanceDebitedEvent struct {
 	Header  EventHeader           `json:"header"`
 	Payload BalanceDebitedPayload `json:"payload"`
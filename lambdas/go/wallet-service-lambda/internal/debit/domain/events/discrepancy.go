@@ -0,0 +1,24 @@
+package events
+
+import "github.com/payment-processor/internal/debit/domain"
+
+type DiscrepantTransaction struct {
+	PaymentID     string        `json:"paymentId"`
+	CorrelationID string        `json:"correlationId"`
+	AmountDebited domain.Amount `json:"amountDebited"`
+	AmountLeft    domain.Amount `json:"amountLeft"`
+}
+
+type BalanceDiscrepancyPayload struct {
+	UserID          domain.UserID           `json:"userId"`
+	Currency        domain.Currency         `json:"currency,omitempty"`
+	ExpectedBalance domain.Amount           `json:"expectedBalance"`
+	StoredBalance   *domain.Amount          `json:"storedBalance"`
+	Reason          string                  `json:"reason"`
+	Transactions    []DiscrepantTransaction `json:"transactions"`
+}
+
+type BalanceDiscrepancyDetectedEvent struct {
+	Header  EventHeader               `json:"header"`
+	Payload BalanceDiscrepancyPayload `json:"payload"`
+}
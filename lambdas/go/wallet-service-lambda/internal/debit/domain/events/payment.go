@@ -6,19 +6,36 @@ import (
 	"github.com/payment-processor/internal/debit/domain"
 )
 
+// EventHeader carries the TenantID owning the wallets of the event, empty for single tenant deployments.
+// Source names the producer, checked against the allowed sources of the event type when signed
 type EventHeader struct {
-	EventID       string    `json:"event_id"`
-	CorrelationID string    `json:"correlation_id"`
-	EventType     string    `json:"event_type"`
-	Timestamp     time.Time `json:"timestamp"`
-	Version       string    `json:"version"`
+	EventID       string          `json:"event_id"`
+	CorrelationID string          `json:"correlation_id"`
+	EventType     string          `json:"event_type"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Version       string          `json:"version"`
+	TenantID      domain.TenantID `json:"tenant_id,omitempty"`
+	Source        string          `json:"source,omitempty"`
 }
 
+// PayerLeg is the share of a split payment charged to a single wallet
+type PayerLeg struct {
+	UserID domain.UserID `json:"user_id"`
+	Amount domain.Amount `json:"amount"`
+}
+
+// PaymentInitPayload debits Amount from UserID. A split payment lists its Payers instead and
+// leaves UserID and Amount empty, every leg is debited or none is. MerchantCategory and UserTier
+// select the fee rule of the payment. Currency is the one of Amount, the wallet currency when empty
 type PaymentInitPayload struct {
-	PaymentID     string        `json:"payment_id"`
-	TransactionID string        `json:"transaction_id"`
-	UserID        domain.UserID `json:"user_id"`
-	Amount        domain.Amount `json:"amount"`
+	PaymentID        string          `json:"payment_id"`
+	TransactionID    string          `json:"transaction_id"`
+	UserID           domain.UserID   `json:"user_id"`
+	Amount           domain.Amount   `json:"amount"`
+	Payers           []PayerLeg      `json:"payers,omitempty"`
+	MerchantCategory string          `json:"merchant_category,omitempty"`
+	UserTier         string          `json:"user_tier,omitempty"`
+	Currency         domain.Currency `json:"currency,omitempty"`
 }
 
 type PaymentInitEvent struct {
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+)
+
+type (
+	// FeeTier prices the amounts up to UpTo, a zero UpTo leaves the tier open ended
+	FeeTier struct {
+		UpTo       Amount
+		Fixed      Amount
+		Percentage float64
+	}
+
+	// FeeRule prices a single payment as Fixed plus Percentage of the amount. When Tiers are set the
+	// first tier covering the amount replaces Fixed and Percentage. Min and Max bound the fee, a
+	// zero Max leaves it uncapped
+	FeeRule struct {
+		Fixed      Amount
+		Percentage float64
+		Tiers      []FeeTier
+		Min        Amount
+		Max        Amount
+	}
+
+	// FeeSchedule picks the rule of a payment: the one of the user tier, then the one of the
+	// merchant category, then Default
+	FeeSchedule struct {
+		Default            FeeRule
+		MerchantCategories map[string]FeeRule
+		UserTiers          map[string]FeeRule
+	}
+)
+
+// Fee is charged on top of amount, rounded to cents
+func (s FeeSchedule) Fee(amount Amount, merchantCategory, userTier string) Amount {
+	return s.rule(merchantCategory, userTier).Fee(amount)
+}
+
+func (s FeeSchedule) rule(merchantCategory, userTier string) FeeRule {
+	if rule, ok := s.UserTiers[userTier]; ok && userTier != "" {
+		return rule
+	}
+	if rule, ok := s.MerchantCategories[merchantCategory]; ok && merchantCategory != "" {
+		return rule
+	}
+	return s.Default
+}
+
+// Validate rejects the rules that would price a payment in a surprising way
+func (s FeeSchedule) Validate() error {
+	errs := []error{s.Default.validate("default")}
+	for _, category := range slices.Sorted(maps.Keys(s.MerchantCategories)) {
+		errs = append(errs, s.MerchantCategories[category].validate("merchant category "+category))
+	}
+	for _, tier := range slices.Sorted(maps.Keys(s.UserTiers)) {
+		errs = append(errs, s.UserTiers[tier].validate("user tier "+tier))
+	}
+	return errors.Join(errs...)
+}
+
+// Fee is rounded to cents after applying Min and Max
+func (r FeeRule) Fee(amount Amount) Amount {
+	fixed, percentage := r.Fixed, r.Percentage
+	for _, tier := range r.Tiers {
+		if tier.UpTo == 0 || amount <= tier.UpTo {
+			fixed, percentage = tier.Fixed, tier.Percentage
+			break
+		}
+	}
+
+	fee := fixed + amount*Amount(percentage)/100
+	if fee < r.Min {
+		fee = r.Min
+	}
+	if r.Max > 0 && fee > r.Max {
+		fee = r.Max
+	}
+
+	return Amount(math.Round(float64(fee)*100) / 100)
+}
+
+func (r FeeRule) validate(name string) error {
+	var errs []error
+
+	if r.Fixed < 0 || r.Percentage < 0 || r.Min < 0 || r.Max < 0 {
+		errs = append(errs, errors.New("amounts and percentages must not be negative"))
+	}
+	if r.Max > 0 && r.Min > r.Max {
+		errs = append(errs, errors.New("min must not be greater than max"))
+	}
+
+	var previous Amount
+	for i, tier := range r.Tiers {
+		if tier.Fixed < 0 || tier.Percentage < 0 || tier.UpTo < 0 {
+			errs = append(errs, fmt.Errorf("tier %d: amounts and percentages must not be negative", i))
+		}
+		if tier.UpTo == 0 && i < len(r.Tiers)-1 {
+			errs = append(errs, fmt.Errorf("tier %d: only the last tier can be open ended", i))
+		}
+		if tier.UpTo != 0 && tier.UpTo <= previous {
+			errs = append(errs, fmt.Errorf("tier %d: tiers must be sorted by up_to", i))
+		}
+		previous = tier.UpTo
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("fee rule %s: %w", name, err)
+	}
+	return nil
+}
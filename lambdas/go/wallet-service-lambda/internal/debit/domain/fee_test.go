@@ -0,0 +1,107 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeSchedule(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should add the fixed and percentage parts", testFee_FixedAndPercentage)
+	t.Run("should bound the fee with min and max", testFee_Caps)
+	t.Run("should price with the tier covering the amount", testFee_Tiers)
+	t.Run("should prefer the user tier over the merchant category", testFee_RuleSelection)
+	t.Run("should reject inconsistent rules", testFee_Validate)
+}
+
+func testFee_FixedAndPercentage(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	rule := domain.FeeRule{Fixed: 0.3, Percentage: 2.9}
+
+	// WHEN
+	fee := rule.Fee(100)
+
+	// THEN
+	assert.InDelta(t, 3.2, float64(fee), 1e-9)
+}
+
+func testFee_Caps(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	rule := domain.FeeRule{Percentage: 1, Min: 0.5, Max: 5}
+
+	// WHEN
+	low, mid, high := rule.Fee(10), rule.Fee(200), rule.Fee(1000)
+
+	// THEN
+	assert.InDelta(t, 0.5, float64(low), 1e-9)
+	assert.InDelta(t, 2, float64(mid), 1e-9)
+	assert.InDelta(t, 5, float64(high), 1e-9)
+}
+
+func testFee_Tiers(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	rule := domain.FeeRule{Fixed: 99, Tiers: []domain.FeeTier{
+		{UpTo: 50, Fixed: 1},
+		{UpTo: 500, Percentage: 1},
+		{Percentage: 0.5},
+	}}
+
+	// WHEN
+	small, medium, large := rule.Fee(50), rule.Fee(300), rule.Fee(1000)
+
+	// THEN
+	assert.InDelta(t, 1, float64(small), 1e-9)
+	assert.InDelta(t, 3, float64(medium), 1e-9)
+	assert.InDelta(t, 5, float64(large), 1e-9)
+}
+
+func testFee_RuleSelection(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	schedule := domain.FeeSchedule{
+		Default:            domain.FeeRule{Fixed: 1},
+		MerchantCategories: map[string]domain.FeeRule{"travel": {Fixed: 2}},
+		UserTiers:          map[string]domain.FeeRule{"premium": {}},
+	}
+
+	// WHEN
+	byDefault := schedule.Fee(10, "groceries", "standard")
+	byCategory := schedule.Fee(10, "travel", "standard")
+	byTier := schedule.Fee(10, "travel", "premium")
+
+	// THEN
+	assert.Equal(t, domain.Amount(1), byDefault)
+	assert.Equal(t, domain.Amount(2), byCategory)
+	assert.Equal(t, domain.Amount(0), byTier)
+}
+
+func testFee_Validate(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	schedule := domain.FeeSchedule{
+		Default: domain.FeeRule{Min: 5, Max: 1},
+		MerchantCategories: map[string]domain.FeeRule{"travel": {Tiers: []domain.FeeTier{
+			{Fixed: 1},
+			{UpTo: 100, Fixed: 2},
+		}}},
+	}
+
+	// WHEN
+	err := schedule.Validate()
+
+	// THEN
+	assert.ErrorContains(t, err, "fee rule default: min must not be greater than max")
+	assert.ErrorContains(t, err, "fee rule merchant category travel: tier 0: only the last tier can be open ended")
+	assert.NoError(t, domain.FeeSchedule{Default: domain.FeeRule{Percentage: 1, Max: 3}}.Validate())
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Currency is an ISO 4217 code
+type Currency string
+
+// Rounding tells how a converted amount is brought to cents
+type Rounding string
+
+const (
+	RoundHalfUp   Rounding = "half_up"
+	RoundHalfEven Rounding = "half_even"
+	RoundDown     Rounding = "down"
+	RoundUp       Rounding = "up"
+)
+
+type (
+	// Rate converts an amount of From into Value times that amount of To. ID identifies the quote
+	// and a zero ExpiresAt never expires
+	Rate struct {
+		ID        string
+		From      Currency
+		To        Currency
+		Value     float64
+		ExpiresAt time.Time
+	}
+
+	// FXPolicy prices a conversion: SpreadPercent is added to the quoted rate and the result is
+	// rounded to cents with Rounding. A quote is locked for LockFor, or until it expires if sooner
+	FXPolicy struct {
+		SpreadPercent float64
+		Rounding      Rounding
+		LockFor       time.Duration
+	}
+
+	// Conversion is the Original amount charged as Converted, Rate being the quote with the spread
+	Conversion struct {
+		RateID    string
+		Rate      float64
+		From      Currency
+		To        Currency
+		Original  Amount
+		Converted Amount
+	}
+)
+
+// Expired reports whether the quote can no longer be applied at now
+func (r Rate) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// Convert prices amount, expressed in rate.From, in rate.To
+func (p FXPolicy) Convert(amount Amount, rate Rate) Conversion {
+	return p.ConvertAt(amount, Conversion{
+		RateID: rate.ID,
+		Rate:   rate.Value * (1 + p.SpreadPercent/100),
+		From:   rate.From,
+		To:     rate.To,
+	})
+}
+
+// ConvertAt prices amount at the rate of a conversion already applied, its spread included
+func (p FXPolicy) ConvertAt(amount Amount, applied Conversion) Conversion {
+	applied.Original = amount
+	applied.Converted = Amount(p.Rounding.round(float64(amount) * applied.Rate))
+	return applied
+}
+
+// LockUntil is the instant a quote taken at now stops being reused
+func (p FXPolicy) LockUntil(rate Rate, now time.Time) time.Time {
+	until := now.Add(p.LockFor)
+	if !rate.ExpiresAt.IsZero() && rate.ExpiresAt.Before(until) {
+		return rate.ExpiresAt
+	}
+	return until
+}
+
+func (p FXPolicy) Validate() error {
+	var errs []error
+
+	if p.SpreadPercent < 0 {
+		errs = append(errs, errors.New("spread must not be negative"))
+	}
+	if p.LockFor < 0 {
+		errs = append(errs, errors.New("rate lock must not be negative"))
+	}
+	switch p.Rounding {
+	case RoundHalfUp, RoundHalfEven, RoundDown, RoundUp:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported rounding %q", p.Rounding))
+	}
+
+	return errors.Join(errs...)
+}
+
+// round works on cents. The product is first cut to a millionth of a cent, so 0.1*3 is not
+// rounded up to 0.31 because of its binary representation
+func (r Rounding) round(v float64) float64 {
+	cents := math.Round(v*100*1e6) / 1e6
+
+	switch r {
+	case RoundHalfEven:
+		cents = math.RoundToEven(cents)
+	case RoundDown:
+		cents = math.Floor(cents)
+	case RoundUp:
+		cents = math.Ceil(cents)
+	default:
+		cents = math.Round(cents)
+	}
+
+	return cents / 100
+}
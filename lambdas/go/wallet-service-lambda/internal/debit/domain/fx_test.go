@@ -0,0 +1,100 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFXPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should convert with the spread on top of the rate", testFX_Spread)
+	t.Run("should round to cents with every mode", testFX_Rounding)
+	t.Run("should lock a quote until it expires", testFX_LockUntil)
+	t.Run("should reject inconsistent policies", testFX_Validate)
+}
+
+func testFX_Spread(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	policy := domain.FXPolicy{SpreadPercent: 2, Rounding: domain.RoundHalfUp}
+	rate := domain.Rate{ID: "q-1", From: "USD", To: "EUR", Value: 0.9}
+
+	// WHEN
+	conversion := policy.Convert(100, rate)
+
+	// THEN
+	assert.Equal(t, "q-1", conversion.RateID)
+	assert.Equal(t, domain.Currency("USD"), conversion.From)
+	assert.Equal(t, domain.Currency("EUR"), conversion.To)
+	assert.Equal(t, domain.Amount(100), conversion.Original)
+	assert.InDelta(t, 0.918, conversion.Rate, 1e-9)
+	assert.InDelta(t, 91.8, float64(conversion.Converted), 1e-9)
+}
+
+func testFX_Rounding(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	rate := domain.Rate{Value: 1}
+	tests := map[domain.Rounding][]float64{
+		// 10.005, 10.015, 10.001, 0.1*3
+		domain.RoundHalfUp:   {10.01, 10.02, 10, 0.3},
+		domain.RoundHalfEven: {10, 10.02, 10, 0.3},
+		domain.RoundDown:     {10, 10.01, 10, 0.3},
+		domain.RoundUp:       {10.01, 10.02, 10.01, 0.3},
+	}
+
+	for rounding, expected := range tests {
+		policy := domain.FXPolicy{Rounding: rounding}
+
+		// WHEN
+		got := []float64{
+			float64(policy.Convert(10.005, rate).Converted),
+			float64(policy.Convert(10.015, rate).Converted),
+			float64(policy.Convert(10.001, rate).Converted),
+			float64(policy.Convert(0.1*3, rate).Converted),
+		}
+
+		// THEN
+		assert.InDeltaSlice(t, expected, got, 1e-9, string(rounding))
+	}
+}
+
+func testFX_LockUntil(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	policy := domain.FXPolicy{LockFor: 10 * time.Minute}
+
+	// WHEN
+	open := policy.LockUntil(domain.Rate{}, now)
+	expiring := policy.LockUntil(domain.Rate{ExpiresAt: now.Add(time.Minute)}, now)
+
+	// THEN
+	assert.Equal(t, now.Add(10*time.Minute), open)
+	assert.Equal(t, now.Add(time.Minute), expiring)
+	assert.True(t, domain.Rate{ExpiresAt: now}.Expired(now))
+	assert.False(t, domain.Rate{}.Expired(now))
+}
+
+func testFX_Validate(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	policy := domain.FXPolicy{SpreadPercent: -1, Rounding: "nearest", LockFor: -time.Second}
+
+	// WHEN
+	err := policy.Validate()
+
+	// THEN
+	assert.ErrorContains(t, err, "spread must not be negative")
+	assert.ErrorContains(t, err, `unsupported rounding "nearest"`)
+	assert.ErrorContains(t, err, "rate lock must not be negative")
+	assert.NoError(t, domain.FXPolicy{Rounding: domain.RoundHalfEven}.Validate())
+}
@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type (
+	// Cadence is how often a mandate falls due
+	Cadence string
+
+	MandateStatus string
+)
+
+const (
+	CadenceDaily   Cadence = "daily"
+	CadenceWeekly  Cadence = "weekly"
+	CadenceMonthly Cadence = "monthly"
+)
+
+const (
+	MandateActive    MandateStatus = "active"
+	MandateCancelled MandateStatus = "cancelled"
+	// MandateCompleted mandates reached their End or their MaxAmount
+	MandateCompleted MandateStatus = "completed"
+)
+
+type (
+	// MandateRetry tries a cycle declined for insufficient funds, or failed on any other error, up
+	// to Attempts more times, Interval apart, before moving past it
+	MandateRetry struct {
+		Attempts int
+		Interval time.Duration
+	}
+
+	// Mandate authorises debiting Amount from the wallet of UserID every Cadence from Start. A zero
+	// End never ends and a zero MaxAmount never caps the Total debited. Cycle is the next cycle to
+	// debit, Attempts the times it was declined or failed and RetryAt when it is tried again. TenantID is set
+	// by the repositories, as in Wallet, and scopes the debits of the mandate
+	Mandate struct {
+		ID               string
+		TenantID         TenantID
+		UserID           UserID
+		Amount           Amount
+		Currency         Currency
+		MerchantCategory string
+		Cadence          Cadence
+		Start            time.Time
+		End              time.Time
+		MaxAmount        Amount
+		Retry            MandateRetry
+		Status           MandateStatus
+		CancelledAt      time.Time
+		Cycle            int
+		Attempts         int
+		RetryAt          time.Time
+		Total            Amount
+		Version          int
+	}
+)
+
+// DueAt is when cycle falls due. Monthly cycles keep the day of Start, or the last day of shorter months
+func (m Mandate) DueAt(cycle int) time.Time {
+	switch m.Cadence {
+	case CadenceDaily:
+		return m.Start.AddDate(0, 0, cycle)
+	case CadenceWeekly:
+		return m.Start.AddDate(0, 0, 7*cycle)
+	default:
+		return addMonths(m.Start, cycle)
+	}
+}
+
+// PaymentID is the idempotency key of cycle, the same on every run that debits it
+func (m Mandate) PaymentID(cycle int) string {
+	return fmt.Sprintf("mandate:%s:%d", m.ID, cycle)
+}
+
+// Due reports whether the current cycle of an active mandate can be debited at now
+func (m Mandate) Due(now time.Time) bool {
+	if m.Status != MandateActive || m.finished() {
+		return false
+	}
+	return !m.DueAt(m.Cycle).After(now) && !m.RetryAt.After(now)
+}
+
+// Settle moves past the current cycle once debited
+func (m *Mandate) Settle() {
+	m.Total += m.Amount
+	m.next()
+}
+
+// Decline counts a debit of the current cycle rejected for insufficient funds. It reports whether
+// the cycle is tried again, otherwise the cycle is skipped
+func (m *Mandate) Decline(now time.Time) bool {
+	if m.Postpone(now) {
+		return true
+	}
+	m.Skip()
+	return false
+}
+
+// Postpone counts a failed attempt of the current cycle and schedules the next one. It reports
+// whether any attempt was left, the caller settles or skips the cycle otherwise
+func (m *Mandate) Postpone(now time.Time) bool {
+	m.Attempts++
+	if m.Attempts <= m.Retry.Attempts {
+		m.RetryAt = now.Add(m.Retry.Interval)
+		return true
+	}
+	return false
+}
+
+// Skip moves past the current cycle without debiting it
+func (m *Mandate) Skip() {
+	m.next()
+}
+
+// Cancel stops an active mandate, no cycle is debited afterwards
+func (m *Mandate) Cancel(at time.Time) error {
+	if m.Status != MandateActive {
+		return NewMandateNotActiveError(m.ID, m.Status)
+	}
+	m.Status = MandateCancelled
+	m.CancelledAt = at
+	return nil
+}
+
+// Complete closes an active mandate without cycles left, it reports whether it did
+func (m *Mandate) Complete() bool {
+	if m.Status != MandateActive || !m.finished() {
+		return false
+	}
+	m.Status = MandateCompleted
+	return true
+}
+
+func (m *Mandate) next() {
+	m.Cycle++
+	m.Attempts = 0
+	m.RetryAt = time.Time{}
+}
+
+// finished is true once the current cycle falls after End or would debit over MaxAmount
+func (m Mandate) finished() bool {
+	if !m.End.IsZero() && m.DueAt(m.Cycle).After(m.End) {
+		return true
+	}
+	return m.MaxAmount > 0 && m.Total+m.Amount > m.MaxAmount
+}
+
+// Validate rejects the mandates that could never be debited as expected
+func (m Mandate) Validate() error {
+	var errs []error
+
+	if m.ID == "" {
+		errs = append(errs, errors.New("id is missing"))
+	}
+	if m.UserID == "" {
+		errs = append(errs, errors.New("user_id is missing"))
+	}
+	if m.Amount <= 0 {
+		errs = append(errs, errors.New("amount must be positive"))
+	}
+	switch m.Cadence {
+	case CadenceDaily, CadenceWeekly, CadenceMonthly:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported cadence %q", m.Cadence))
+	}
+	switch m.Status {
+	case MandateActive, MandateCancelled, MandateCompleted:
+	default:
+		errs = append(errs, fmt.Errorf("unsupported status %q", m.Status))
+	}
+	if m.Start.IsZero() {
+		errs = append(errs, errors.New("start is missing"))
+	}
+	if !m.End.IsZero() && m.End.Before(m.Start) {
+		errs = append(errs, errors.New("end must not be before start"))
+	}
+	if m.MaxAmount < 0 || (m.MaxAmount > 0 && m.MaxAmount < m.Amount) {
+		errs = append(errs, errors.New("max_amount must cover at least one cycle"))
+	}
+	if m.Retry.Attempts < 0 || m.Retry.Interval < 0 {
+		errs = append(errs, errors.New("retry must not be negative"))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("mandate %s: %w", m.ID, err)
+	}
+	return nil
+}
+
+func addMonths(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
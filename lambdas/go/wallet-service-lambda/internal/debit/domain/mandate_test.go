@@ -0,0 +1,199 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mandateStart = time.Date(2026, 1, 31, 9, 0, 0, 0, time.UTC)
+
+func TestMandate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should keep the day of start in shorter months", testMandate_MonthlyDueAt)
+	t.Run("should fall due on every cadence", testMandate_Cadences)
+	t.Run("should derive the same payment id for a cycle", testMandate_PaymentID)
+	t.Run("should retry a declined cycle before skipping it", testMandate_Decline)
+	t.Run("should postpone a failed cycle without moving past it", testMandate_Postpone)
+	t.Run("should complete once the max amount is reached", testMandate_MaxAmount)
+	t.Run("should complete after the end", testMandate_End)
+	t.Run("should not debit a cancelled mandate", testMandate_Cancel)
+	t.Run("should reject inconsistent mandates", testMandate_Validate)
+}
+
+func testMandate_MonthlyDueAt(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	m := monthly()
+
+	// WHEN
+	due := []time.Time{m.DueAt(0), m.DueAt(1), m.DueAt(2), m.DueAt(13)}
+
+	// THEN
+	assert.Equal(t, []time.Time{
+		mandateStart,
+		time.Date(2026, 2, 28, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 31, 9, 0, 0, 0, time.UTC),
+		time.Date(2027, 2, 28, 9, 0, 0, 0, time.UTC),
+	}, due)
+}
+
+func testMandate_Cadences(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	daily, weekly := monthly(), monthly()
+	daily.Cadence, weekly.Cadence = domain.CadenceDaily, domain.CadenceWeekly
+
+	// WHEN
+	nextDay, nextWeek := daily.DueAt(1), weekly.DueAt(2)
+
+	// THEN
+	assert.Equal(t, mandateStart.AddDate(0, 0, 1), nextDay)
+	assert.Equal(t, mandateStart.AddDate(0, 0, 14), nextWeek)
+}
+
+func testMandate_PaymentID(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	m := monthly()
+
+	// WHEN
+	first, again, next := m.PaymentID(3), m.PaymentID(3), m.PaymentID(4)
+
+	// THEN
+	assert.Equal(t, "mandate:m-1:3", first)
+	assert.Equal(t, first, again)
+	assert.NotEqual(t, first, next)
+}
+
+func testMandate_Decline(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	m := monthly()
+	m.Retry = domain.MandateRetry{Attempts: 1, Interval: 24 * time.Hour}
+	now := mandateStart
+
+	// WHEN
+	retried := m.Decline(now)
+	dueBeforeRetry := m.Due(now.Add(time.Hour))
+	dueAtRetry := m.Due(now.Add(24 * time.Hour))
+	skipped := !m.Decline(now.Add(24 * time.Hour))
+
+	// THEN
+	assert.True(t, retried)
+	assert.False(t, dueBeforeRetry)
+	assert.True(t, dueAtRetry)
+	assert.True(t, skipped)
+	assert.Equal(t, 1, m.Cycle)
+	assert.Zero(t, m.Attempts)
+	assert.Zero(t, m.Total)
+}
+
+func testMandate_Postpone(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	m := monthly()
+	m.Retry = domain.MandateRetry{Attempts: 1, Interval: time.Hour}
+	now := mandateStart
+
+	// WHEN
+	postponed := m.Postpone(now)
+	exhausted := !m.Postpone(now.Add(time.Hour))
+
+	// THEN
+	assert.True(t, postponed)
+	assert.True(t, exhausted)
+	assert.Equal(t, 0, m.Cycle)
+	assert.Equal(t, 2, m.Attempts)
+}
+
+func testMandate_MaxAmount(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	m := monthly()
+	m.MaxAmount = 25
+
+	// WHEN
+	m.Settle()
+	m.Settle()
+
+	// THEN
+	assert.Equal(t, domain.Amount(20), m.Total)
+	assert.False(t, m.Due(mandateStart.AddDate(1, 0, 0)))
+	assert.True(t, m.Complete())
+	assert.Equal(t, domain.MandateCompleted, m.Status)
+}
+
+func testMandate_End(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	m := monthly()
+	m.End = mandateStart.AddDate(0, 1, 0)
+	m.Settle()
+
+	// WHEN
+	m.Settle()
+
+	// THEN
+	assert.False(t, m.Due(mandateStart.AddDate(1, 0, 0)))
+	assert.True(t, m.Complete())
+}
+
+func testMandate_Cancel(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	m := monthly()
+
+	// WHEN
+	first := m.Cancel(mandateStart)
+	second := m.Cancel(mandateStart)
+
+	// THEN
+	require.NoError(t, first)
+	assert.ErrorContains(t, second, "mandate not active error")
+	assert.False(t, m.Due(mandateStart.AddDate(1, 0, 0)))
+	assert.False(t, m.Complete())
+}
+
+func testMandate_Validate(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	m := domain.Mandate{ID: "m-1", Amount: 10, Cadence: "yearly", MaxAmount: 5, Start: mandateStart, End: mandateStart.Add(-time.Hour)}
+
+	// WHEN
+	err := m.Validate()
+
+	// THEN
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "user_id is missing")
+	assert.ErrorContains(t, err, `unsupported cadence "yearly"`)
+	assert.ErrorContains(t, err, "end must not be before start")
+	assert.ErrorContains(t, err, "max_amount must cover at least one cycle")
+	assert.NoError(t, monthly().Validate())
+}
+
+// --- Helper Functions ---
+
+func monthly() domain.Mandate {
+	return domain.Mandate{
+		ID:      "m-1",
+		UserID:  "user-1",
+		Amount:  10,
+		Cadence: domain.CadenceMonthly,
+		Start:   mandateStart,
+		Status:  domain.MandateActive,
+	}
+}
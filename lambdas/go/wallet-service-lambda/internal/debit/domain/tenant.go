@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+type (
+	// TenantID names one of the brands hosted by the deployment, empty for single tenant deployments
+	TenantID string
+
+	// Tenant holds the settings of a brand. Empty Currencies accept every currency, empty
+	// FallbackCurrencies keep the ones of the deployment and a zero MaxDebit never limits a payment
+	Tenant struct {
+		ID                 TenantID
+		Currencies         []Currency
+		FallbackCurrencies []Currency
+		MaxDebit           Amount
+	}
+
+	// Tenants is the registry of the hosted brands. An empty registry hosts only the empty tenant,
+	// as single tenant deployments do
+	Tenants map[TenantID]Tenant
+
+	tenantKey struct{}
+)
+
+// WithTenant scopes ctx to tenant, the repositories only reach the data of the tenant of the context
+func WithTenant(ctx context.Context, tenant TenantID) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom is the tenant of ctx, empty when it was never scoped
+func TenantFrom(ctx context.Context) TenantID {
+	tenant, _ := ctx.Value(tenantKey{}).(TenantID)
+	return tenant
+}
+
+// Get returns the settings of a hosted tenant
+func (t Tenants) Get(id TenantID) (Tenant, bool) {
+	if len(t) == 0 {
+		return Tenant{}, id == ""
+	}
+	tenant, ok := t[id]
+	return tenant, ok && id != ""
+}
+
+// Admit rejects the payments of amount in currency the tenant does not accept
+func (t Tenant) Admit(amount Amount, currency Currency) error {
+	if len(t.Currencies) > 0 && !slices.Contains(t.Currencies, currency) {
+		return NewCurrencyNotAllowedError(t.ID, currency)
+	}
+	if t.MaxDebit > 0 && amount > t.MaxDebit {
+		return NewDebitLimitError(t.ID, float64(t.MaxDebit), float64(amount))
+	}
+	return nil
+}
+
+// Validate rejects the settings that could never admit a payment
+func (t Tenant) Validate() error {
+	var errs []error
+
+	if t.ID == "" {
+		errs = append(errs, errors.New("id is missing"))
+	}
+	if t.MaxDebit < 0 {
+		errs = append(errs, errors.New("max_debit must not be negative"))
+	}
+	for _, currency := range slices.Concat(t.Currencies, t.FallbackCurrencies) {
+		if currency == "" {
+			errs = append(errs, errors.New("currencies must not be empty"))
+			break
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("tenant %s: %w", t.ID, err)
+	}
+	return nil
+}
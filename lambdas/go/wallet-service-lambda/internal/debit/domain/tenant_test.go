@@ -0,0 +1,109 @@
+package domain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenant(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should carry the tenant in the context", testTenant_Context)
+	t.Run("should host only the empty tenant without registry", testTenant_EmptyRegistry)
+	t.Run("should host only the tenants of the registry", testTenant_Registry)
+	t.Run("should admit the payments within the settings", testTenant_Admit)
+	t.Run("should reject inconsistent tenants", testTenant_Validate)
+}
+
+func testTenant_Context(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	ctx := domain.WithTenant(context.Background(), "acme")
+
+	// WHEN
+	tenant := domain.TenantFrom(ctx)
+
+	// THEN
+	assert.Equal(t, domain.TenantID("acme"), tenant)
+	assert.Empty(t, domain.TenantFrom(context.Background()))
+}
+
+func testTenant_EmptyRegistry(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var registry domain.Tenants
+
+	// WHEN
+	_, empty := registry.Get("")
+	_, acme := registry.Get("acme")
+
+	// THEN
+	assert.True(t, empty)
+	assert.False(t, acme)
+}
+
+func testTenant_Registry(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	registry := domain.Tenants{"acme": {ID: "acme", MaxDebit: 10}}
+
+	// WHEN
+	tenant, acme := registry.Get("acme")
+	_, empty := registry.Get("")
+	_, globex := registry.Get("globex")
+
+	// THEN
+	assert.True(t, acme)
+	assert.Equal(t, domain.Amount(10), tenant.MaxDebit)
+	assert.False(t, empty)
+	assert.False(t, globex)
+}
+
+func testTenant_Admit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	tenant := domain.Tenant{ID: "acme", Currencies: []domain.Currency{"EUR"}, MaxDebit: 100}
+
+	// WHEN
+	admitted := tenant.Admit(100, "EUR")
+	overLimit := tenant.Admit(100.01, "EUR")
+	otherCurrency := tenant.Admit(1, "USD")
+	unrestricted := domain.Tenant{ID: "globex"}.Admit(1e6, "JPY")
+
+	// THEN
+	assert.NoError(t, admitted)
+	assert.ErrorContains(t, overLimit, "debit limit exceeded")
+	assert.ErrorContains(t, otherCurrency, "currency not allowed")
+	assert.NoError(t, unrestricted)
+}
+
+func testTenant_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]domain.Tenant{
+		"missing id":     {MaxDebit: 1},
+		"negative limit": {ID: "acme", MaxDebit: -1},
+		"empty currency": {ID: "acme", FallbackCurrencies: []domain.Currency{""}},
+	}
+
+	for name, tenant := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			err := tenant.Validate()
+
+			// THEN
+			assert.Error(t, err)
+		})
+	}
+
+	assert.NoError(t, domain.Tenant{ID: "acme", Currencies: []domain.Currency{"EUR"}}.Validate())
+}
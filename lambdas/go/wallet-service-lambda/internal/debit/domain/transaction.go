@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"strings"
+	"time"
+)
+
+// TransactionType tells the direction of a movement of a wallet
+type TransactionType string
+
+const (
+	TransactionDebit  TransactionType = "debit"
+	TransactionCredit TransactionType = "credit"
+)
+
+// Transaction is a movement applied to the balance in Currency of a wallet, empty for the wallet
+// currency. BalanceAfter is the balance it left. TenantID is set by the repositories, as in Wallet.
+// Fee is the part of a debit charged on top of the payment. The debit of a converted payment keeps
+// its quote: the amount and currency as sent and the rate, spread included, that converted them
+type Transaction struct {
+	ID            string
+	TenantID      TenantID
+	PaymentID     string
+	CorrelationID string
+	UserID        UserID
+	Type          TransactionType
+	Amount        Amount
+	Currency      Currency
+	BalanceAfter  Amount
+	Timestamp     time.Time
+	Fee           Amount
+
+	OriginalAmount   Amount
+	OriginalCurrency Currency
+	RateID           string
+	Rate             float64
+}
+
+// Principal is the payment debited, without its fee
+func (t Transaction) Principal() Amount {
+	return Amount(math.Round(float64(t.Amount-t.Fee)*100) / 100)
+}
+
+// TransactionID identifies the movement of type a payment applies to the wallet of user in tenant.
+// The same payment always gets the same id, so recording it again is detected as a duplicate
+func TransactionID(tenant TenantID, paymentID string, user UserID, txType TransactionType) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{string(tenant), paymentID, string(user), string(txType)}, "\n")))
+	return hex.EncodeToString(sum[:16])
+}
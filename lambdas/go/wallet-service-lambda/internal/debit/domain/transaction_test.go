@@ -0,0 +1,51 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransactionID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should derive the same id for the same movement", testTransactionID_Stable)
+	t.Run("should tell apart the movements of a payment", testTransactionID_Distinct)
+}
+
+func testTransactionID_Stable(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	first := domain.TransactionID("acme", "pay-1", "user-1", domain.TransactionDebit)
+	second := domain.TransactionID("acme", "pay-1", "user-1", domain.TransactionDebit)
+
+	// THEN
+	assert.NotEmpty(t, first)
+	assert.Equal(t, first, second)
+}
+
+func testTransactionID_Distinct(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	id := domain.TransactionID("acme", "pay-1", "user-1", domain.TransactionDebit)
+
+	tests := map[string]string{
+		"other tenant":  domain.TransactionID("globex", "pay-1", "user-1", domain.TransactionDebit),
+		"other payment": domain.TransactionID("acme", "pay-2", "user-1", domain.TransactionDebit),
+		"other user":    domain.TransactionID("acme", "pay-1", "user-2", domain.TransactionDebit),
+		"other type":    domain.TransactionID("acme", "pay-1", "user-1", domain.TransactionCredit),
+		"shifted field": domain.TransactionID("acme", "pay-1\nuser-1", "", domain.TransactionDebit),
+	}
+
+	for name, other := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// THEN
+			assert.NotEqual(t, id, other)
+		})
+	}
+}
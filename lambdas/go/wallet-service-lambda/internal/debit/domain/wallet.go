@@ -1,7 +1,10 @@
 package domain
 
+import "maps"
+
 var (
-	BalanceDebitedEventName Event = "BalanceDebited"
+	BalanceDebitedEventName             Event = "BalanceDebited"
+	BalanceDiscrepancyDetectedEventName Event = "BalanceDiscrepancyDetected"
 )
 
 type (
@@ -10,10 +13,19 @@ type (
 	Amount float64
 )
 
+// Wallet holds Amount in the wallet currency and, for multi-currency wallets, a balance per other
+// currency in Balances. Balances never holds the wallet currency. Every balance shares Version.
+// TenantID is set by the repositories, from the tenant of the context the wallet is stored with.
+// Payment is the id of the payment an update applies, empty for updates of no payment: the
+// repositories keep it in the same write and reject a later update of the wallet with the same
+// one, so a payment is applied once. Wallets read from a repository have none
 type Wallet struct {
-	UserID  UserID
-	Amount  Amount
-	Version int
+	TenantID TenantID
+	UserID   UserID
+	Amount   Amount
+	Balances map[Currency]Amount
+	Version  int
+	Payment  string
 }
 
 func (w *Wallet) CanWithdraw(amountToWithdraw Amount) bool {
@@ -27,3 +39,53 @@ func (w *Wallet) Debit(amountToDebit Amount) error {
 	w.Amount -= amountToDebit
 	return nil
 }
+
+func (w *Wallet) Credit(amountToCredit Amount) {
+	w.Amount += amountToCredit
+}
+
+// Holds reports whether the wallet has a balance in currency, an empty currency being the wallet one
+func (w *Wallet) Holds(currency Currency) bool {
+	if currency == "" {
+		return true
+	}
+	_, ok := w.Balances[currency]
+	return ok
+}
+
+// Balance returns the balance in currency, an empty currency being the wallet one
+func (w *Wallet) Balance(currency Currency) Amount {
+	if currency == "" {
+		return w.Amount
+	}
+	return w.Balances[currency]
+}
+
+// DebitIn debits the balance in currency. Balances is copied before being changed, so a wallet
+// read from a repository can be debited without touching the stored one
+func (w *Wallet) DebitIn(currency Currency, amountToDebit Amount) error {
+	if currency == "" {
+		return w.Debit(amountToDebit)
+	}
+	if w.Balance(currency) < amountToDebit {
+		return NewInsufficientFundsError(string(w.UserID), float64(w.Balance(currency)), float64(amountToDebit))
+	}
+	w.setBalance(currency, w.Balance(currency)-amountToDebit)
+	return nil
+}
+
+// CreditIn credits the balance in currency, opening it when the wallet does not hold it yet
+func (w *Wallet) CreditIn(currency Currency, amountToCredit Amount) {
+	if currency == "" {
+		w.Credit(amountToCredit)
+		return
+	}
+	w.setBalance(currency, w.Balance(currency)+amountToCredit)
+}
+
+func (w *Wallet) setBalance(currency Currency, amount Amount) {
+	balances := make(map[Currency]Amount, len(w.Balances)+1)
+	maps.Copy(balances, w.Balances)
+	balances[currency] = amount
+	w.Balances = balances
+}
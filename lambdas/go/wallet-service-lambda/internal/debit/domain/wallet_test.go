@@ -0,0 +1,81 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalletBalances(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should debit the balance of the currency", testWallet_DebitIn)
+	t.Run("should reject a debit over the balance of the currency", testWallet_DebitInInsufficient)
+	t.Run("should not change the balances of the wallet it was copied from", testWallet_CopyOnWrite)
+	t.Run("should open a balance on the first credit", testWallet_CreditIn)
+}
+
+func testWallet_DebitIn(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	wallet := domain.Wallet{UserID: "user-1", Amount: 10, Balances: map[domain.Currency]domain.Amount{"USD": 50}}
+
+	// WHEN
+	err := wallet.DebitIn("USD", 20)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(30), wallet.Balance("USD"))
+	assert.Equal(t, domain.Amount(10), wallet.Balance(""))
+}
+
+func testWallet_DebitInInsufficient(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	wallet := domain.Wallet{UserID: "user-1", Amount: 100, Balances: map[domain.Currency]domain.Amount{"USD": 5}}
+
+	// WHEN
+	held, missing := wallet.DebitIn("USD", 20), wallet.DebitIn("GBP", 1)
+
+	// THEN
+	assert.True(t, domain.IsInsufficientFunds(held))
+	assert.True(t, domain.IsInsufficientFunds(missing))
+	assert.False(t, wallet.Holds("GBP"))
+	assert.Equal(t, domain.Amount(5), wallet.Balance("USD"))
+}
+
+func testWallet_CopyOnWrite(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	read := domain.Wallet{UserID: "user-1", Balances: map[domain.Currency]domain.Amount{"USD": 50}}
+	debited := read
+
+	// WHEN
+	err := debited.DebitIn("USD", 20)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(50), read.Balance("USD"))
+	assert.Equal(t, domain.Amount(30), debited.Balance("USD"))
+}
+
+func testWallet_CreditIn(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	wallet := domain.Wallet{UserID: "revenue"}
+
+	// WHEN
+	wallet.CreditIn("USD", 2.5)
+	wallet.CreditIn("", 1)
+
+	// THEN
+	assert.True(t, wallet.Holds("USD"))
+	assert.Equal(t, domain.Amount(2.5), wallet.Balance("USD"))
+	assert.Equal(t, domain.Amount(1), wallet.Amount)
+}
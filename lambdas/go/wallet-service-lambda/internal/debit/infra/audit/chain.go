@@ -0,0 +1,270 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// GenesisHash is the previous hash of the first entry of a chain
+var GenesisHash = hex.EncodeToString(make([]byte, sha256.Size))
+
+// ErrTampered is returned by Verify when an entry was modified, removed, reordered or inserted
+var ErrTampered = errors.New("audit trail tampered")
+
+// maxLineSize bounds a single entry when reading the trail back
+const maxLineSize = 1 << 20
+
+type (
+	// Entry is a line of the trail. Hash covers the sequence, the previous hash and the exact bytes
+	// of the record, so changing any of them breaks the link with the next entry
+	Entry struct {
+		Sequence uint64          `json:"seq"`
+		PrevHash string          `json:"prev_hash"`
+		Hash     string          `json:"hash"`
+		Record   json.RawMessage `json:"record"`
+	}
+
+	// Record is the stored form of a ports.AuditRecord
+	Record struct {
+		RecordedAt    time.Time       `json:"recorded_at"`
+		Outcome       string          `json:"outcome"`
+		TenantID      domain.TenantID `json:"tenant_id,omitempty"`
+		PaymentID     string          `json:"payment_id,omitempty"`
+		CorrelationID string          `json:"correlation_id,omitempty"`
+		UserID        domain.UserID   `json:"user_id"`
+		Amount        domain.Amount   `json:"amount"`
+		Fee           domain.Amount   `json:"fee,omitempty"`
+		Currency      domain.Currency `json:"currency,omitempty"`
+		BalanceBefore *domain.Amount  `json:"balance_before"`
+		BalanceAfter  *domain.Amount  `json:"balance_after"`
+		ErrorCode     string          `json:"error_code,omitempty"`
+		Reason        string          `json:"reason,omitempty"`
+		Actor         string          `json:"actor"`
+		Attempts      int             `json:"attempts"`
+	}
+
+	// Head identifies the last entry of a chain. Keeping it outside the trail, in a log or a
+	// metric, makes the removal of the last entries detectable too
+	Head struct {
+		Sequence uint64
+		Hash     string
+	}
+
+	// Trail appends hash-chained entries as JSON lines
+	Trail struct {
+		mu     sync.Mutex
+		w      io.Writer
+		sync   func() error
+		closer io.Closer
+		head   Head
+		now    func() time.Time
+	}
+
+	Option func(*Trail)
+)
+
+// WithNow replaces the clock used to stamp the records
+func WithNow(now func() time.Time) Option {
+	return func(t *Trail) { t.now = now }
+}
+
+// Append chains the record after the current head
+func (t *Trail) Append(_ context.Context, record ports.AuditRecord) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	payload, err := json.Marshal(toRecord(record, t.now()))
+	if err != nil {
+		return err
+	}
+
+	entry := Entry{Sequence: t.head.Sequence + 1, PrevHash: t.head.Hash, Record: payload}
+	entry.Hash = hash(entry)
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err = t.w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append audit entry: %w", err)
+	}
+	if t.sync != nil {
+		if err = t.sync(); err != nil {
+			return fmt.Errorf("failed to sync audit trail: %w", err)
+		}
+	}
+
+	t.head = Head{Sequence: entry.Sequence, Hash: entry.Hash}
+	return nil
+}
+
+// Head returns the last appended entry
+func (t *Trail) Head() Head {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.head
+}
+
+// Close releases the file opened by OpenFile
+func (t *Trail) Close() error {
+	if t.closer == nil {
+		return nil
+	}
+	return t.closer.Close()
+}
+
+// Verify walks the whole trail checking every hash and link and returns its head. An empty
+// trail returns the genesis head
+func Verify(r io.Reader) (Head, error) {
+	head := Head{Hash: GenesisHash}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return head, fmt.Errorf("%w: line %d: %v", ErrTampered, line, err)
+		}
+		if entry.Sequence != head.Sequence+1 {
+			return head, fmt.Errorf("%w: line %d: sequence %d follows %d", ErrTampered, line, entry.Sequence, head.Sequence)
+		}
+		if entry.PrevHash != head.Hash {
+			return head, fmt.Errorf("%w: line %d: previous hash does not match entry %d", ErrTampered, line, head.Sequence)
+		}
+		if hash(entry) != entry.Hash {
+			return head, fmt.Errorf("%w: line %d: hash does not match its content", ErrTampered, line)
+		}
+
+		head = Head{Sequence: entry.Sequence, Hash: entry.Hash}
+	}
+	if err := scanner.Err(); err != nil {
+		return head, err
+	}
+
+	return head, nil
+}
+
+// cutTornTail truncates the file after its last newline
+func cutTornTail(f *os.File) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	valid, err := completeSize(f, info.Size())
+	if err != nil || valid == info.Size() {
+		return err
+	}
+
+	slog.Warn("discarding torn audit trail tail", "path", f.Name(), "bytes", info.Size()-valid)
+	if err = f.Truncate(valid); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// completeSize is the size of the file up to its last newline, reading it backwards
+func completeSize(f *os.File, size int64) (int64, error) {
+	buf := make([]byte, 4096)
+	for end := size; end > 0; {
+		start := max(end-int64(len(buf)), 0)
+		n, err := f.ReadAt(buf[:end-start], start)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(buf[:n], '\n'); i >= 0 {
+			return start + int64(i) + 1, nil
+		}
+		end = start
+	}
+	return 0, nil
+}
+
+func hash(entry Entry) string {
+	h := sha256.New()
+	h.Write([]byte(strconv.FormatUint(entry.Sequence, 10)))
+	h.Write([]byte{'\n'})
+	h.Write([]byte(entry.PrevHash))
+	h.Write([]byte{'\n'})
+	h.Write(entry.Record)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func toRecord(record ports.AuditRecord, at time.Time) Record {
+	return Record{
+		RecordedAt:    at.UTC(),
+		Outcome:       string(record.Outcome),
+		TenantID:      record.TenantID,
+		PaymentID:     record.PaymentID,
+		CorrelationID: record.CorrelationID,
+		UserID:        record.UserID,
+		Amount:        record.Amount,
+		Fee:           record.Fee,
+		Currency:      record.Currency,
+		BalanceBefore: record.BalanceBefore,
+		BalanceAfter:  record.BalanceAfter,
+		ErrorCode:     record.ErrorCode,
+		Reason:        record.Reason,
+		Actor:         record.Actor,
+		Attempts:      record.Attempts,
+	}
+}
+
+// NewTrail starts a new chain on w
+func NewTrail(w io.Writer, opts ...Option) *Trail {
+	t := &Trail{w: w, head: Head{Hash: GenesisHash}, now: time.Now}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// OpenFile continues the chain stored at path, creating it when missing. The existing entries are
+// verified first: appending after a tampered entry would hide it behind valid links. A last line
+// without newline, left by a crash in the middle of an Append, is cut off: it was never reported
+// as written. Complete lines that do not verify still fail
+func OpenFile(path string, opts ...Option) (*Trail, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cutTornTail(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open audit trail %s: %w", path, err)
+	}
+
+	head, err := Verify(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open audit trail %s: %w", path, err)
+	}
+
+	t := NewTrail(f, opts...)
+	t.head = head
+	t.sync = f.Sync
+	t.closer = f
+
+	return t, nil
+}
@@ -0,0 +1,228 @@
+package audit_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrail(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should verify an untouched trail", testTrail_Verify)
+	t.Run("should verify an empty trail", testTrail_VerifyEmpty)
+	t.Run("should detect a modified record", testTrail_Modified)
+	t.Run("should detect a deleted entry", testTrail_Deleted)
+	t.Run("should detect reordered entries", testTrail_Reordered)
+	t.Run("should detect a truncated tail against the head", testTrail_TruncatedTail)
+	t.Run("should continue the chain of an existing file", testTrail_OpenFileContinues)
+	t.Run("should refuse to continue a tampered file", testTrail_OpenFileTampered)
+	t.Run("should cut a last line torn at any byte", testTrail_OpenFileTornTail)
+}
+
+func testTrail_Verify(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var buf bytes.Buffer
+	trail := newTrail(t, &buf, 3)
+
+	// WHEN
+	head, err := audit.Verify(&buf)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, trail.Head(), head)
+	assert.Equal(t, uint64(3), head.Sequence)
+}
+
+func testTrail_VerifyEmpty(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	head, err := audit.Verify(strings.NewReader(""))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, audit.Head{Hash: audit.GenesisHash}, head)
+}
+
+func testTrail_Modified(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var buf bytes.Buffer
+	newTrail(t, &buf, 3)
+	tampered := strings.Replace(buf.String(), `"amount":2`, `"amount":0.2`, 1)
+	require.NotEqual(t, buf.String(), tampered)
+
+	// WHEN
+	_, err := audit.Verify(strings.NewReader(tampered))
+
+	// THEN
+	assert.ErrorIs(t, err, audit.ErrTampered)
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func testTrail_Deleted(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var buf bytes.Buffer
+	newTrail(t, &buf, 3)
+	lines := lines(buf.String())
+
+	// WHEN
+	_, err := audit.Verify(strings.NewReader(lines[0] + lines[2]))
+
+	// THEN
+	assert.ErrorIs(t, err, audit.ErrTampered)
+}
+
+func testTrail_Reordered(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var buf bytes.Buffer
+	newTrail(t, &buf, 3)
+	lines := lines(buf.String())
+
+	// WHEN
+	_, err := audit.Verify(strings.NewReader(lines[0] + lines[2] + lines[1]))
+
+	// THEN
+	assert.ErrorIs(t, err, audit.ErrTampered)
+}
+
+func testTrail_TruncatedTail(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var buf bytes.Buffer
+	trail := newTrail(t, &buf, 3)
+	lines := lines(buf.String())
+
+	// WHEN
+	head, err := audit.Verify(strings.NewReader(lines[0] + lines[1]))
+
+	// THEN
+	require.NoError(t, err)
+	assert.NotEqual(t, trail.Head(), head)
+}
+
+func testTrail_OpenFileContinues(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	first, err := audit.OpenFile(path)
+	require.NoError(t, err)
+	require.NoError(t, first.Append(context.Background(), record(1)))
+	require.NoError(t, first.Close())
+
+	// WHEN
+	second, err := audit.OpenFile(path)
+	require.NoError(t, err)
+	require.NoError(t, second.Append(context.Background(), record(2)))
+	require.NoError(t, second.Close())
+
+	// THEN
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	head, err := audit.Verify(f)
+	require.NoError(t, err)
+	assert.Equal(t, second.Head(), head)
+	assert.Equal(t, uint64(2), head.Sequence)
+}
+
+func testTrail_OpenFileTampered(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var buf bytes.Buffer
+	newTrail(t, &buf, 2)
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	tampered := strings.Replace(buf.String(), `"user_id":"user-1"`, `"user_id":"user-9"`, 1)
+	require.NoError(t, os.WriteFile(path, []byte(tampered), 0o600))
+
+	// WHEN
+	_, err := audit.OpenFile(path)
+
+	// THEN
+	assert.ErrorIs(t, err, audit.ErrTampered)
+}
+
+func testTrail_OpenFileTornTail(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var buf bytes.Buffer
+	newTrail(t, &buf, 3)
+	data := buf.Bytes()
+	lastLine := bytes.LastIndexByte(data[:len(data)-1], '\n') + 1
+	complete, err := audit.Verify(bytes.NewReader(data[:lastLine]))
+	require.NoError(t, err)
+
+	for cut := lastLine + 1; cut < len(data); cut++ {
+		path := filepath.Join(t.TempDir(), "audit.jsonl")
+		require.NoError(t, os.WriteFile(path, data[:cut], 0o600))
+
+		// WHEN
+		trail, err := audit.OpenFile(path)
+
+		// THEN
+		require.NoError(t, err, "cut at byte %d", cut)
+		assert.Equal(t, complete, trail.Head())
+		require.NoError(t, trail.Append(context.Background(), record(3)))
+		require.NoError(t, trail.Close())
+
+		stored, err := os.ReadFile(path)
+		require.NoError(t, err)
+		head, err := audit.Verify(bytes.NewReader(stored))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), head.Sequence)
+	}
+}
+
+// --- Helper Functions ---
+
+func newTrail(t *testing.T, buf *bytes.Buffer, n int) *audit.Trail {
+	t.Helper()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	trail := audit.NewTrail(buf, audit.WithNow(func() time.Time { return now }))
+	for i := range n {
+		require.NoError(t, trail.Append(context.Background(), record(i+1)))
+	}
+	return trail
+}
+
+func record(i int) ports.AuditRecord {
+	before := domain.Amount(100)
+	after := before - domain.Amount(i)
+	return ports.AuditRecord{
+		Outcome:       ports.AuditOutcomeDebited,
+		UserID:        "user-1",
+		Amount:        domain.Amount(i),
+		BalanceBefore: &before,
+		BalanceAfter:  &after,
+		Actor:         "wallet-service",
+		Attempts:      1,
+	}
+}
+
+func lines(s string) []string {
+	return strings.SplitAfter(s, "\n")
+}
@@ -16,34 +16,102 @@ import (
 type ConsoleEventBus struct{}
 
 func (b *ConsoleEventBus) Publish(ctx context.Context, req ports.BalanceDebitedRequest) error {
-	event := events.BalanceDebitedEvent{
+	event := toBalanceDebitedEvent(req)
+
+	eventJSON, err := json.MarshalIndent(event, "", "  ")
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal event to JSON", "error", err)
+		return err
+	}
+
+	slog.InfoContext(ctx, "--- EVENT PUBLISHED ---", "event", string(eventJSON))
+
+	// real implementation would be like:
+	// _, err := b.eventBridgeClient.PutEvents(...)
+	// return err
+
+	return nil
+}
+
+func (b *ConsoleEventBus) PublishDiscrepancy(ctx context.Context, req ports.BalanceDiscrepancyRequest) error {
+	eventJSON, err := json.MarshalIndent(toBalanceDiscrepancyEvent(req), "", "  ")
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to marshal event to JSON", "error", err)
+		return err
+	}
+
+	slog.WarnContext(ctx, "--- EVENT PUBLISHED ---", "event", string(eventJSON))
+	return nil
+}
+
+func toBalanceDebitedEvent(req ports.BalanceDebitedRequest) events.BalanceDebitedEvent {
+	return events.BalanceDebitedEvent{
 		Header: events.EventHeader{
 			EventID:       uuid.NewString(),
 			EventType:     string(req.EventName),
 			Timestamp:     time.Now().UTC(),
 			Version:       "1",
 			CorrelationID: req.CorrelationID,
+			TenantID:      req.TenantID,
 		},
 		Payload: events.BalanceDebitedPayload{
-			UserID:        req.UserID,
-			AmountDebited: req.AmountDebited,
-			AmountLeft:    req.AmountLeft,
+			PaymentID:        req.PaymentID,
+			UserID:           req.UserID,
+			AmountDebited:    req.AmountDebited,
+			Principal:        req.Principal,
+			Fee:              req.Fee,
+			AmountLeft:       req.AmountLeft,
+			Legs:             toDebitedLegs(req.Legs),
+			OriginalAmount:   req.OriginalAmount,
+			OriginalCurrency: req.OriginalCurrency,
+			Currency:         req.Currency,
+			RateID:           req.RateID,
+			Rate:             req.Rate,
+			Balances:         req.Balances,
 		},
 	}
+}
 
-	eventJSON, err := json.MarshalIndent(event, "", "  ")
-	if err != nil {
-		slog.ErrorContext(ctx, "failed to marshal event to JSON", "error", err)
-		return err
+func toDebitedLegs(legs []ports.DebitedLeg) []events.DebitedLeg {
+	if len(legs) == 0 {
+		return nil
 	}
 
-	slog.InfoContext(ctx, "--- EVENT PUBLISHED ---", "event", string(eventJSON))
+	result := make([]events.DebitedLeg, 0, len(legs))
+	for _, leg := range legs {
+		result = append(result, events.DebitedLeg{UserID: leg.UserID, AmountDebited: leg.AmountDebited, AmountLeft: leg.AmountLeft})
+	}
+	return result
+}
 
-	// real implementation would be like:
-	// _, err := b.eventBridgeClient.PutEvents(...)
-	// return err
+func toBalanceDiscrepancyEvent(req ports.BalanceDiscrepancyRequest) events.BalanceDiscrepancyDetectedEvent {
+	transactions := make([]events.DiscrepantTransaction, 0, len(req.Transactions))
+	for _, tx := range req.Transactions {
+		transactions = append(transactions, events.DiscrepantTransaction{
+			PaymentID:     tx.PaymentID,
+			CorrelationID: tx.CorrelationID,
+			AmountDebited: tx.AmountDebited,
+			AmountLeft:    tx.AmountLeft,
+		})
+	}
 
-	return nil
+	return events.BalanceDiscrepancyDetectedEvent{
+		Header: events.EventHeader{
+			EventID:   uuid.NewString(),
+			EventType: string(req.EventName),
+			Timestamp: time.Now().UTC(),
+			Version:   "1",
+			TenantID:  req.TenantID,
+		},
+		Payload: events.BalanceDiscrepancyPayload{
+			UserID:          req.UserID,
+			Currency:        req.Currency,
+			ExpectedBalance: req.ExpectedBalance,
+			StoredBalance:   req.StoredBalance,
+			Reason:          req.Reason,
+			Transactions:    transactions,
+		},
+	}
 }
 
 func NewConsoleEventBus() *ConsoleEventBus {
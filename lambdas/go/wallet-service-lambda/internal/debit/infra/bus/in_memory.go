@@ -0,0 +1,69 @@
+package bus
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain/events"
+)
+
+// InMemoryEventBus is a mock implementation of port EventBusProcessor.
+// Keeps every published event in memory so it can be inspected later
+type InMemoryEventBus struct {
+	mu            sync.Mutex
+	events        []events.BalanceDebitedEvent
+	discrepancies []events.BalanceDiscrepancyDetectedEvent
+}
+
+func (b *InMemoryEventBus) Publish(ctx context.Context, req ports.BalanceDebitedRequest) error {
+	event := toBalanceDebitedEvent(req)
+
+	b.mu.Lock()
+	b.events = append(b.events, event)
+	b.mu.Unlock()
+
+	slog.InfoContext(ctx, "event captured", "eventId", event.Header.EventID, "eventType", event.Header.EventType)
+	return nil
+}
+
+func (b *InMemoryEventBus) PublishDiscrepancy(ctx context.Context, req ports.BalanceDiscrepancyRequest) error {
+	event := toBalanceDiscrepancyEvent(req)
+
+	b.mu.Lock()
+	b.discrepancies = append(b.discrepancies, event)
+	b.mu.Unlock()
+
+	slog.InfoContext(ctx, "event captured", "eventId", event.Header.EventID, "eventType", event.Header.EventType)
+	return nil
+}
+
+// Events returns a copy of the events published so far
+func (b *InMemoryEventBus) Events() []events.BalanceDebitedEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]events.BalanceDebitedEvent{}, b.events...)
+}
+
+// Discrepancies returns a copy of the discrepancy events published so far
+func (b *InMemoryEventBus) Discrepancies() []events.BalanceDiscrepancyDetectedEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]events.BalanceDiscrepancyDetectedEvent{}, b.discrepancies...)
+}
+
+// Reset discards every captured event
+func (b *InMemoryEventBus) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.events = nil
+	b.discrepancies = nil
+}
+
+func NewInMemoryEventBus() *InMemoryEventBus {
+	return &InMemoryEventBus{}
+}
@@ -0,0 +1,94 @@
+package fees
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+var ErrInvalidSchedule = errors.New("invalid fee schedule")
+
+type (
+	// ScheduleRecord is the file representation of a domain.FeeSchedule
+	ScheduleRecord struct {
+		Default            RuleRecord            `json:"default"`
+		MerchantCategories map[string]RuleRecord `json:"merchant_categories"`
+		UserTiers          map[string]RuleRecord `json:"user_tiers"`
+	}
+
+	RuleRecord struct {
+		Fixed      domain.Amount `json:"fixed"`
+		Percentage float64       `json:"percentage"`
+		Tiers      []TierRecord  `json:"tiers"`
+		Min        domain.Amount `json:"min"`
+		Max        domain.Amount `json:"max"`
+	}
+
+	TierRecord struct {
+		UpTo       domain.Amount `json:"up_to"`
+		Fixed      domain.Amount `json:"fixed"`
+		Percentage float64       `json:"percentage"`
+	}
+)
+
+// LoadScheduleFile reads the JSON schedule stored at path
+func LoadScheduleFile(path string) (domain.FeeSchedule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return domain.FeeSchedule{}, err
+	}
+	defer f.Close()
+
+	return LoadSchedule(f)
+}
+
+// LoadSchedule reads a JSON schedule. Unknown fields are rejected so a misspelled rule is not
+// silently charged as zero
+func LoadSchedule(r io.Reader) (domain.FeeSchedule, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var record ScheduleRecord
+	if err := dec.Decode(&record); err != nil {
+		return domain.FeeSchedule{}, fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+	}
+
+	schedule := record.toDomain()
+	if err := schedule.Validate(); err != nil {
+		return domain.FeeSchedule{}, fmt.Errorf("%w: %w", ErrInvalidSchedule, err)
+	}
+
+	return schedule, nil
+}
+
+func (r ScheduleRecord) toDomain() domain.FeeSchedule {
+	return domain.FeeSchedule{
+		Default:            r.Default.toDomain(),
+		MerchantCategories: toRules(r.MerchantCategories),
+		UserTiers:          toRules(r.UserTiers),
+	}
+}
+
+func (r RuleRecord) toDomain() domain.FeeRule {
+	rule := domain.FeeRule{Fixed: r.Fixed, Percentage: r.Percentage, Min: r.Min, Max: r.Max}
+	for _, tier := range r.Tiers {
+		rule.Tiers = append(rule.Tiers, domain.FeeTier{UpTo: tier.UpTo, Fixed: tier.Fixed, Percentage: tier.Percentage})
+	}
+	return rule
+}
+
+func toRules(records map[string]RuleRecord) map[string]domain.FeeRule {
+	if len(records) == 0 {
+		return nil
+	}
+
+	rules := make(map[string]domain.FeeRule, len(records))
+	for name, record := range records {
+		rules[name] = record.toDomain()
+	}
+	return rules
+}
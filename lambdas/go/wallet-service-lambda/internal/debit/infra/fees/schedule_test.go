@@ -0,0 +1,86 @@
+package fees_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/fees"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scheduleJSON = `{
+	"default": {"fixed": 0.25, "percentage": 1.5, "min": 0.5, "max": 20},
+	"merchant_categories": {
+		"travel": {"tiers": [{"up_to": 100, "fixed": 1}, {"percentage": 2}]}
+	},
+	"user_tiers": {"premium": {}}
+}`
+
+func TestLoadSchedule(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should load every rule of a schedule", testLoadSchedule_Success)
+	t.Run("should load a schedule file", testLoadSchedule_File)
+	t.Run("should reject invalid schedules", testLoadSchedule_Invalid)
+}
+
+func testLoadSchedule_Success(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	schedule, err := fees.LoadSchedule(strings.NewReader(scheduleJSON))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.FeeSchedule{
+		Default: domain.FeeRule{Fixed: 0.25, Percentage: 1.5, Min: 0.5, Max: 20},
+		MerchantCategories: map[string]domain.FeeRule{
+			"travel": {Tiers: []domain.FeeTier{{UpTo: 100, Fixed: 1}, {Percentage: 2}}},
+		},
+		UserTiers: map[string]domain.FeeRule{"premium": {}},
+	}, schedule)
+}
+
+func testLoadSchedule_File(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "fees.json")
+	require.NoError(t, os.WriteFile(path, []byte(scheduleJSON), 0o600))
+
+	// WHEN
+	schedule, err := fees.LoadScheduleFile(path)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(1), schedule.Fee(50, "travel", ""))
+	assert.Equal(t, domain.Amount(0), schedule.Fee(50, "travel", "premium"))
+}
+
+func testLoadSchedule_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"malformed":     `{"default":`,
+		"unknown field": `{"default": {"percent": 2}}`,
+		"negative fee":  `{"default": {"fixed": -1}}`,
+		"min over max":  `{"user_tiers": {"basic": {"min": 5, "max": 1}}}`,
+		"unsorted tier": `{"default": {"tiers": [{"up_to": 100}, {"up_to": 50}]}}`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			_, err := fees.LoadSchedule(strings.NewReader(input))
+
+			// THEN
+			assert.ErrorIs(t, err, fees.ErrInvalidSchedule)
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+var (
+	ErrRateNotFound = errors.New("exchange rate not found")
+	ErrInvalidRates = errors.New("invalid rates file")
+)
+
+type (
+	// RateRecord is the file representation of a domain.Rate, a missing expires_at never expires
+	RateRecord struct {
+		ID        string          `json:"id"`
+		From      domain.Currency `json:"from"`
+		To        domain.Currency `json:"to"`
+		Rate      float64         `json:"rate"`
+		ExpiresAt time.Time       `json:"expires_at,omitzero"`
+	}
+
+	pair struct{ from, to domain.Currency }
+
+	// StaticRateProvider quotes a fixed set of rates, for local runs and tests. Only the pairs
+	// listed are quoted, the inverse of a pair is not derived
+	StaticRateProvider struct {
+		rates map[pair]domain.Rate
+	}
+)
+
+func NewStaticRateProvider(rates ...domain.Rate) *StaticRateProvider {
+	p := &StaticRateProvider{rates: make(map[pair]domain.Rate, len(rates))}
+	for _, rate := range rates {
+		p.rates[pair{rate.From, rate.To}] = rate
+	}
+	return p
+}
+
+func (p *StaticRateProvider) Rate(_ context.Context, from, to domain.Currency) (domain.Rate, error) {
+	rate, ok := p.rates[pair{from, to}]
+	if !ok {
+		return domain.Rate{}, fmt.Errorf("%w: %s to %s", ErrRateNotFound, from, to)
+	}
+	return rate, nil
+}
+
+// LoadRatesFile builds a provider quoting the rates of the JSON file at path
+func LoadRatesFile(path string) (*StaticRateProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	rates, err := LoadRates(f)
+	if err != nil {
+		return nil, err
+	}
+	return NewStaticRateProvider(rates...), nil
+}
+
+// LoadRates reads a JSON array of rates. Every rate needs an id and a positive value, and a pair
+// can only be listed once
+func LoadRates(r io.Reader) ([]domain.Rate, error) {
+	var records []RateRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRates, err)
+	}
+
+	var errs []error
+	seen := make(map[pair]bool, len(records))
+	rates := make([]domain.Rate, 0, len(records))
+
+	for i, record := range records {
+		key := pair{record.From, record.To}
+		switch {
+		case record.ID == "":
+			errs = append(errs, fmt.Errorf("rate %d: id is missing", i))
+		case record.From == "" || record.To == "":
+			errs = append(errs, fmt.Errorf("rate %s: from and to are required", record.ID))
+		case record.Rate <= 0:
+			errs = append(errs, fmt.Errorf("rate %s: must be positive", record.ID))
+		case seen[key]:
+			errs = append(errs, fmt.Errorf("rate %s: %s to %s is listed twice", record.ID, record.From, record.To))
+		}
+		seen[key] = true
+
+		rates = append(rates, domain.Rate{ID: record.ID, From: record.From, To: record.To, Value: record.Rate, ExpiresAt: record.ExpiresAt})
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRates, err)
+	}
+	return rates, nil
+}
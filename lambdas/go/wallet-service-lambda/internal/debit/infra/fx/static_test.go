@@ -0,0 +1,86 @@
+package fx_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/fx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ratesJSON = `[
+	{"id": "usd-eur-1", "from": "USD", "to": "EUR", "rate": 0.91},
+	{"id": "gbp-eur-1", "from": "GBP", "to": "EUR", "rate": 1.17, "expires_at": "2026-03-01T12:00:00Z"}
+]`
+
+func TestStaticRateProvider(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should quote the rates of the file", testStatic_Quote)
+	t.Run("should not quote unknown pairs", testStatic_NotFound)
+	t.Run("should reject invalid files", testStatic_Invalid)
+}
+
+func testStatic_Quote(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(ratesJSON), 0o600))
+
+	provider, err := fx.LoadRatesFile(path)
+	require.NoError(t, err)
+
+	// WHEN
+	usd, usdErr := provider.Rate(context.Background(), "USD", "EUR")
+	gbp, gbpErr := provider.Rate(context.Background(), "GBP", "EUR")
+
+	// THEN
+	require.NoError(t, usdErr)
+	require.NoError(t, gbpErr)
+	assert.Equal(t, domain.Rate{ID: "usd-eur-1", From: "USD", To: "EUR", Value: 0.91}, usd)
+	assert.Equal(t, time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), gbp.ExpiresAt)
+}
+
+func testStatic_NotFound(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	provider := fx.NewStaticRateProvider(domain.Rate{ID: "usd-eur-1", From: "USD", To: "EUR", Value: 0.91})
+
+	// WHEN
+	_, err := provider.Rate(context.Background(), "EUR", "USD")
+
+	// THEN
+	assert.ErrorIs(t, err, fx.ErrRateNotFound)
+}
+
+func testStatic_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"malformed":     `[{"id":`,
+		"missing id":    `[{"from": "USD", "to": "EUR", "rate": 1}]`,
+		"missing pair":  `[{"id": "r", "from": "USD", "rate": 1}]`,
+		"zero rate":     `[{"id": "r", "from": "USD", "to": "EUR"}]`,
+		"repeated pair": `[{"id": "a", "from": "USD", "to": "EUR", "rate": 1}, {"id": "b", "from": "USD", "to": "EUR", "rate": 2}]`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			_, err := fx.LoadRates(strings.NewReader(input))
+
+			// THEN
+			assert.ErrorIs(t, err, fx.ErrInvalidRates)
+		})
+	}
+}
@@ -4,56 +4,347 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/domain"
 	events2 "github.com/payment-processor/internal/debit/domain/events"
 )
 
 var ErrValidation = errors.New("event validation failed")
 
+// defaultWorkers matches the default batch size of an SQS event source
+const defaultWorkers = 10
+
+// messageGroupIDAttribute is set by SQS FIFO queues, producers use the user id as group
+const messageGroupIDAttribute = "MessageGroupId"
+
+// Ordering decides how the records of a batch are scheduled
+type Ordering int
+
+const (
+	// OrderingSequential processes the whole batch one record at a time in arrival order
+	OrderingSequential Ordering = iota
+	// OrderingPerUser processes the records of a user in arrival order while different users
+	// run in parallel, so debits on the same wallet never race each other
+	OrderingPerUser
+)
+
 type UseCase interface {
 	Handle(ctx context.Context, req application.Request) error
+	// HandleSplit debits every payer of a split payment or none of them
+	HandleSplit(ctx context.Context, req application.SplitRequest) error
+	// Drop records an invalid request that is discarded without retries
+	Drop(ctx context.Context, req application.Request, reason error)
+	// Reject records a request of an event that could not be authenticated
+	Reject(ctx context.Context, req application.Request, reason error)
+}
+
+// Verifier authenticates the producer of an event. Retryable domain errors leave the message to be
+// redelivered, any other error rejects it
+type Verifier interface {
+	Verify(ctx context.Context, message events.SQSMessage, header events2.EventHeader) error
 }
 
 type SQSHandler struct {
-	useCase UseCase
+	useCase       UseCase
+	ordering      Ordering
+	workers       int
+	recordTimeout time.Duration
+	safetyMargin  time.Duration
+	tenants       domain.Tenants
+	verifier      Verifier
+}
+
+type Option func(*SQSHandler)
+
+func WithOrdering(ordering Ordering) Option {
+	return func(h *SQSHandler) { h.ordering = ordering }
+}
+
+// WithWorkers bounds how many groups of records are processed at the same time. It has no
+// effect with OrderingSequential, where the whole batch is a single group
+func WithWorkers(n int) Option {
+	return func(h *SQSHandler) { h.workers = max(n, 1) }
+}
+
+// WithRecordTimeout cancels the processing of a single record after d, zero means no limit
+func WithRecordTimeout(d time.Duration) Option {
+	return func(h *SQSHandler) { h.recordTimeout = d }
+}
+
+// WithSafetyMargin stops taking new records once the invocation deadline is closer than d and
+// cancels the records in flight, leaving d to return the response before the Lambda is killed
+func WithSafetyMargin(d time.Duration) Option {
+	return func(h *SQSHandler) { h.safetyMargin = d }
+}
+
+// WithTenants accepts the events of the tenants of the registry only. Without it only events
+// without tenant are accepted
+func WithTenants(tenants domain.Tenants) Option {
+	return func(h *SQSHandler) { h.tenants = tenants }
 }
 
-func (h *SQSHandler) Handle(ctx context.Context, sqsEvent events.SQSEvent) error {
-	for _, message := range sqsEvent.Records {
-		if err := h.processMessage(ctx, message); err != nil {
+// WithVerifier rejects, without retries, the events the verifier does not authenticate and the
+// bodies that are not events at all. They are checked before anything else in their body is
+// trusted, the tenant included, so their rejections are audited without tenant
+func WithVerifier(verifier Verifier) Option {
+	return func(h *SQSHandler) { h.verifier = verifier }
+}
+
+// Handle reports the records that were not processed as batch item failures, so SQS only
+// redelivers those. Requires ReportBatchItemFailures on the event source mapping
+func (h *SQSHandler) Handle(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error) {
+	ctx, cancel := h.withSafetyMargin(ctx)
+	defer cancel()
+
+	groups := h.groups(sqsEvent.Records)
+	failed := make([][]string, len(groups))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(h.workers, len(groups)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				failed[i] = h.processGroup(ctx, groups[i])
+			}
+		}()
+	}
+	for i := range groups {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return toResponse(sqsEvent.Records, failed), nil
+}
+
+// groups splits the batch in the units of work that keep their own order
+func (h *SQSHandler) groups(messages []events.SQSMessage) [][]events.SQSMessage {
+	if len(messages) == 0 {
+		return nil
+	}
+	if h.ordering == OrderingPerUser {
+		return groupByUser(messages)
+	}
+	return [][]events.SQSMessage{messages}
+}
+
+// processGroup returns the ids of the records left unprocessed. After a failure the rest of the
+// group is not attempted, so a later debit is never applied before an earlier one
+func (h *SQSHandler) processGroup(ctx context.Context, group []events.SQSMessage) []string {
+	for i, message := range group {
+		if ctx.Err() != nil {
+			slog.WarnContext(ctx, "not enough time left, records returned to the queue untouched",
+				"messageId", message.MessageId,
+				"pending", len(group)-i,
+			)
+			return messageIDs(group[i:])
+		}
+
+		if err := h.processRecord(ctx, message); err != nil {
 			slog.ErrorContext(
 				ctx,
-				"error processing message, batch will be retried",
+				"error processing message, record and the rest of its group will be retried",
 				"messageId", message.MessageId,
+				"pending", len(group)-i-1,
 				"error", err,
 			)
-			return err
+			return messageIDs(group[i:])
 		}
 	}
 
 	return nil
 }
 
+// withSafetyMargin moves the deadline of the invocation earlier by the safety margin. Contexts
+// without deadline, as in local runs, are left as they are
+func (h *SQSHandler) withSafetyMargin(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || h.safetyMargin <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithDeadline(ctx, deadline.Add(-h.safetyMargin))
+}
+
+func (h *SQSHandler) processRecord(ctx context.Context, message events.SQSMessage) error {
+	var cancel context.CancelFunc
+	if h.recordTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, h.recordTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	return h.processMessage(ctx, message)
+}
+
+// toResponse lists the failures in the order of the batch
+func toResponse(messages []events.SQSMessage, failed [][]string) events.SQSEventResponse {
+	failedIDs := make(map[string]bool)
+	for _, ids := range failed {
+		for _, id := range ids {
+			failedIDs[id] = true
+		}
+	}
+
+	response := events.SQSEventResponse{BatchItemFailures: []events.SQSBatchItemFailure{}}
+	for _, message := range messages {
+		if failedIDs[message.MessageId] {
+			response.BatchItemFailures = append(response.BatchItemFailures, events.SQSBatchItemFailure{ItemIdentifier: message.MessageId})
+		}
+	}
+
+	return response
+}
+
+func messageIDs(messages []events.SQSMessage) []string {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.MessageId)
+	}
+	return ids
+}
+
+// groupByUser splits the batch keeping the arrival order inside every group and the order
+// of the groups by their first record. Records sharing any key end in the same group, so a split
+// payment joins the records of every one of its payers and no wallet is handled by two workers
+func groupByUser(messages []events.SQSMessage) [][]events.SQSMessage {
+	parent := make([]int, len(messages))
+	owner := make(map[string]int)
+	for i, message := range messages {
+		parent[i] = i
+		for _, key := range groupKeys(message) {
+			if j, ok := owner[key]; ok {
+				union(parent, i, j)
+				continue
+			}
+			owner[key] = i
+		}
+	}
+
+	var groups [][]events.SQSMessage
+	index := make(map[int]int)
+	for i, message := range messages {
+		root := find(parent, i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], message)
+	}
+
+	return groups
+}
+
+// find returns the representative of the group of i, flattening the path on the way
+func find(parent []int, i int) int {
+	for parent[i] != i {
+		parent[i] = parent[parent[i]]
+		i = parent[i]
+	}
+	return i
+}
+
+// union merges the groups of a and b
+func union(parent []int, a, b int) {
+	if ra, rb := find(parent, a), find(parent, b); ra != rb {
+		parent[rb] = ra
+	}
+}
+
+// groupKeys are the FIFO message group when present and the tenant and user id of every wallet
+// the payload debits: its user or the payers of a split. Undecodable bodies get a group of their
+// own, they fail on their own anyway
+func groupKeys(message events.SQSMessage) []string {
+	var keys []string
+	if group := message.Attributes[messageGroupIDAttribute]; group != "" {
+		keys = append(keys, "group:"+group)
+	}
+
+	var event events2.PaymentInitEvent
+	if err := json.Unmarshal([]byte(message.Body), &event); err != nil {
+		return append(keys, "message:"+message.MessageId)
+	}
+
+	wallet := func(userID domain.UserID) string {
+		return "user:" + string(event.Header.TenantID) + "/" + string(userID)
+	}
+	if event.Payload.UserID != "" {
+		keys = append(keys, wallet(event.Payload.UserID))
+	}
+	for _, payer := range event.Payload.Payers {
+		keys = append(keys, wallet(payer.UserID))
+	}
+
+	if len(keys) == 0 {
+		return []string{"message:" + message.MessageId}
+	}
+	return keys
+}
+
 func (h *SQSHandler) processMessage(ctx context.Context, message events.SQSMessage) error {
 	slog.InfoContext(ctx, "Processing SQS message", "messageId", message.MessageId)
 
 	var event events2.PaymentInitEvent
 	if err := json.Unmarshal([]byte(message.Body), &event); err != nil {
 		slog.ErrorContext(ctx, "failed to unmarshal message body", "error", err, "body", message.Body)
+		if h.verifier != nil {
+			h.useCase.Reject(ctx, application.Request{}, errors.Join(ErrValidation, fmt.Errorf("body is not an event: %w", err)))
+			return nil
+		}
 		return err
 	}
 
 	logger := slog.With("correlationId", event.Header.CorrelationID)
 
+	if h.verifier != nil {
+		if err := h.verifier.Verify(ctx, message, event.Header); err != nil {
+			if domain.IsRetryable(err) {
+				logger.ErrorContext(ctx, "failed to verify event", "error", err)
+				return err
+			}
+			logger.ErrorContext(ctx, "event rejected", "error", err, "source", event.Header.Source)
+			h.useCase.Reject(ctx, toUseCaseRequest(event.Payload, event.Header.CorrelationID), err)
+			return nil
+		}
+	}
+
+	ctx = domain.WithTenant(ctx, event.Header.TenantID)
+
+	if _, ok := h.tenants.Get(event.Header.TenantID); !ok {
+		err := errors.Join(ErrValidation, fmt.Errorf("tenant %q is not hosted", event.Header.TenantID))
+		logger.ErrorContext(ctx, "event validation failed", "error", err)
+		h.useCase.Drop(ctx, toUseCaseRequest(event.Payload, event.Header.CorrelationID), err)
+		return nil
+	}
+
+	if len(event.Payload.Payers) > 0 {
+		return h.processSplit(ctx, logger, message, event)
+	}
+
+	req := toUseCaseRequest(event.Payload, event.Header.CorrelationID)
+
 	if err := h.validate(event); err != nil {
 		logger.ErrorContext(ctx, "event validation failed", "error", err)
+		h.useCase.Drop(ctx, req, err)
 		return nil
 	}
 
-	if err := h.useCase.Handle(ctx, toUseCaseRequest(event.Payload, event.Header.CorrelationID)); err != nil {
+	if err := h.useCase.Handle(ctx, req); err != nil {
+		if domain.IsPaymentApplied(err) {
+			logger.WarnContext(ctx, "payment already applied, message discarded", "paymentId", req.PaymentID)
+			return nil
+		}
 		logger.ErrorContext(ctx, "use case failed to handle request", "error", err)
 		return err
 	}
@@ -62,6 +353,26 @@ func (h *SQSHandler) processMessage(ctx context.Context, message events.SQSMessa
 	return nil
 }
 
+func (h *SQSHandler) processSplit(ctx context.Context, logger *slog.Logger, message events.SQSMessage, event events2.PaymentInitEvent) error {
+	if err := h.validateSplit(event); err != nil {
+		logger.ErrorContext(ctx, "event validation failed", "error", err)
+		h.useCase.Drop(ctx, toUseCaseRequest(event.Payload, event.Header.CorrelationID), err)
+		return nil
+	}
+
+	if err := h.useCase.HandleSplit(ctx, toSplitRequest(event.Payload, event.Header.CorrelationID)); err != nil {
+		if domain.IsPaymentApplied(err) {
+			logger.WarnContext(ctx, "payment already applied, message discarded", "paymentId", event.Payload.PaymentID)
+			return nil
+		}
+		logger.ErrorContext(ctx, "use case failed to handle split request", "error", err)
+		return err
+	}
+
+	logger.InfoContext(ctx, "Successfully processed message", "messageId", message.MessageId)
+	return nil
+}
+
 func (h *SQSHandler) validate(event events2.PaymentInitEvent) error {
 	if event.Header.CorrelationID == "" {
 		return errors.Join(ErrValidation, errors.New("correlation_id is missing"))
@@ -72,18 +383,97 @@ func (h *SQSHandler) validate(event events2.PaymentInitEvent) error {
 	if event.Payload.Amount <= 0 {
 		return errors.Join(ErrValidation, errors.New("amount must be positive"))
 	}
+	if event.Payload.Currency != "" && !isCurrencyCode(event.Payload.Currency) {
+		return errors.Join(ErrValidation, fmt.Errorf("currency %q is not an ISO 4217 code", event.Payload.Currency))
+	}
+
+	return nil
+}
+
+func isCurrencyCode(currency domain.Currency) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, c := range currency {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// MaxSplitPayers bounds the wallets written by a split payment, keeping its single log record
+// of the file repository well below the size of a record
+const MaxSplitPayers = 50
+
+// validateSplit rejects a payer listed twice, both legs would read the same wallet version, and a
+// split of a single payer, which is a plain payment
+func (h *SQSHandler) validateSplit(event events2.PaymentInitEvent) error {
+	if event.Header.CorrelationID == "" {
+		return errors.Join(ErrValidation, errors.New("correlation_id is missing"))
+	}
+	if event.Payload.UserID != "" || event.Payload.Amount != 0 {
+		return errors.Join(ErrValidation, errors.New("split payments carry the amounts in payers only"))
+	}
+	if event.Payload.Currency != "" {
+		return errors.Join(ErrValidation, errors.New("split payments are charged in the wallet currency"))
+	}
+	if len(event.Payload.Payers) < 2 {
+		return errors.Join(ErrValidation, errors.New("split payments have at least 2 payers"))
+	}
+	if len(event.Payload.Payers) > MaxSplitPayers {
+		return errors.Join(ErrValidation, fmt.Errorf("split payments have at most %d payers, got %d", MaxSplitPayers, len(event.Payload.Payers)))
+	}
+
+	seen := make(map[domain.UserID]bool, len(event.Payload.Payers))
+	for _, payer := range event.Payload.Payers {
+		if payer.UserID == "" {
+			return errors.Join(ErrValidation, errors.New("payer user_id is missing"))
+		}
+		if payer.Amount <= 0 {
+			return errors.Join(ErrValidation, fmt.Errorf("amount of payer %s must be positive", payer.UserID))
+		}
+		if seen[payer.UserID] {
+			return errors.Join(ErrValidation, fmt.Errorf("payer %s is listed twice", payer.UserID))
+		}
+		seen[payer.UserID] = true
+	}
 
 	return nil
 }
 
+func toSplitRequest(eventPayload events2.PaymentInitPayload, id string) application.SplitRequest {
+	legs := make([]application.Leg, 0, len(eventPayload.Payers))
+	for _, payer := range eventPayload.Payers {
+		legs = append(legs, application.Leg{UserID: payer.UserID, Amount: payer.Amount})
+	}
+
+	return application.SplitRequest{
+		PaymentID:        eventPayload.PaymentID,
+		CorrelationID:    id,
+		Legs:             legs,
+		MerchantCategory: eventPayload.MerchantCategory,
+		UserTier:         eventPayload.UserTier,
+	}
+}
+
 func toUseCaseRequest(eventPayload events2.PaymentInitPayload, id string) application.Request {
 	return application.Request{
-		UserID:        eventPayload.UserID,
-		Amount:        eventPayload.Amount,
-		CorrelationID: id,
+		PaymentID:        eventPayload.PaymentID,
+		UserID:           eventPayload.UserID,
+		Amount:           eventPayload.Amount,
+		CorrelationID:    id,
+		MerchantCategory: eventPayload.MerchantCategory,
+		UserTier:         eventPayload.UserTier,
+		Currency:         eventPayload.Currency,
 	}
 }
 
-func NewSQSHandler(uc UseCase) *SQSHandler {
-	return &SQSHandler{useCase: uc}
+func NewSQSHandler(uc UseCase, opts ...Option) *SQSHandler {
+	h := &SQSHandler{useCase: uc, workers: defaultWorkers}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
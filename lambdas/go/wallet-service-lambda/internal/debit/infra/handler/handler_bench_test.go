@@ -0,0 +1,162 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/bus"
+	"github.com/payment-processor/internal/debit/infra/handler"
+	"github.com/payment-processor/internal/debit/infra/repository"
+)
+
+const (
+	benchUsers        = 5
+	benchRecords      = 50
+	benchStoreLatency = 200 * time.Microsecond
+)
+
+// BenchmarkSQSHandler compares a batch of debits spread over a few users:
+//   - sequential is the current loop
+//   - concurrent sends every record on its own, like parallel lambda invocations without ordering
+//   - per_user groups the batch by user
+func BenchmarkSQSHandler(b *testing.B) {
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	b.Run("sequential", func(b *testing.B) {
+		benchmarkHandler(b, func(h *handler.SQSHandler, sqsEvent events.SQSEvent) int {
+			return handle(h, sqsEvent)
+		})
+	})
+
+	b.Run("concurrent", func(b *testing.B) {
+		benchmarkHandler(b, func(h *handler.SQSHandler, sqsEvent events.SQSEvent) int {
+			var wg sync.WaitGroup
+			var failed atomic.Int64
+			for _, message := range sqsEvent.Records {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					failed.Add(int64(handle(h, events.SQSEvent{Records: []events.SQSMessage{message}})))
+				}()
+			}
+			wg.Wait()
+			return int(failed.Load())
+		})
+	})
+
+	b.Run("per_user", func(b *testing.B) {
+		benchmarkHandler(b, func(h *handler.SQSHandler, sqsEvent events.SQSEvent) int {
+			return handle(h, sqsEvent)
+		}, handler.WithOrdering(handler.OrderingPerUser))
+	})
+}
+
+func benchmarkHandler(b *testing.B, run func(*handler.SQSHandler, events.SQSEvent) int, opts ...handler.Option) {
+	sqsEvent := benchBatch(b)
+
+	var conflicts, failed int64
+	b.ResetTimer()
+	for range b.N {
+		b.StopTimer()
+		repo := &slowRepository{next: repository.NewInMemoryWalletRepositoryWith(benchWallets()...)}
+		useCase := application.NewDebitBalanceUseCaseHandler(repo, bus.NewInMemoryEventBus())
+		h := handler.NewSQSHandler(useCase, opts...)
+		b.StartTimer()
+
+		failed += int64(run(h, sqsEvent))
+		conflicts += repo.conflicts.Load()
+	}
+
+	b.ReportMetric(float64(conflicts)/float64(b.N), "conflicts/op")
+	b.ReportMetric(float64(failed)/float64(b.N), "failed/op")
+	b.ReportMetric(float64(benchRecords*b.N)/b.Elapsed().Seconds(), "records/s")
+}
+
+// handle returns how many records were reported back as failures
+func handle(h *handler.SQSHandler, sqsEvent events.SQSEvent) int {
+	response, err := h.Handle(context.Background(), sqsEvent)
+	if err != nil {
+		return len(sqsEvent.Records)
+	}
+	return len(response.BatchItemFailures)
+}
+
+func benchBatch(b *testing.B) events.SQSEvent {
+	b.Helper()
+
+	var sqsEvent events.SQSEvent
+	for i := range benchRecords {
+		userID := domain.UserID(fmt.Sprintf("user-%d", i%benchUsers))
+		message := createSQSEvent(b, userID, 1, fmt.Sprintf("corr-%d", i)).Records[0]
+		message.MessageId = fmt.Sprintf("msg-%d", i)
+		sqsEvent.Records = append(sqsEvent.Records, message)
+	}
+
+	return sqsEvent
+}
+
+func benchWallets() []domain.Wallet {
+	wallets := make([]domain.Wallet, 0, benchUsers)
+	for i := range benchUsers {
+		wallets = append(wallets, domain.Wallet{UserID: domain.UserID(fmt.Sprintf("user-%d", i)), Amount: 1000, Version: 1})
+	}
+	return wallets
+}
+
+// slowRepository adds the latency of a remote store and counts lock conflicts
+type slowRepository struct {
+	next      *repository.InMemoryWalletRepository
+	conflicts atomic.Int64
+}
+
+func (r *slowRepository) Get(ctx context.Context, userID domain.UserID) (domain.Wallet, error) {
+	time.Sleep(benchStoreLatency)
+	return r.next.Get(ctx, userID)
+}
+
+func (r *slowRepository) Update(ctx context.Context, wallet domain.Wallet) error {
+	time.Sleep(benchStoreLatency)
+	err := r.next.Update(ctx, wallet)
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		r.conflicts.Add(1)
+	}
+	return err
+}
+
+func (r *slowRepository) UpdateAndCredit(ctx context.Context, wallet domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	time.Sleep(benchStoreLatency)
+	credited, err := r.next.UpdateAndCredit(ctx, wallet, credit)
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		r.conflicts.Add(1)
+	}
+	return credited, err
+}
+
+func (r *slowRepository) UpdateAllAndCredit(ctx context.Context, wallets []domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	time.Sleep(benchStoreLatency)
+	credited, err := r.next.UpdateAllAndCredit(ctx, wallets, credit)
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		r.conflicts.Add(1)
+	}
+	return credited, err
+}
+
+func (r *slowRepository) UpdateAll(ctx context.Context, wallets []domain.Wallet) error {
+	time.Sleep(benchStoreLatency)
+	err := r.next.UpdateAll(ctx, wallets)
+	if errors.Is(err, repository.ErrVersionMismatch) {
+		r.conflicts.Add(1)
+	}
+	return err
+}
@@ -4,7 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/payment-processor/internal/debit/application"
@@ -20,9 +25,33 @@ func TestSQSHandler(t *testing.T) {
 	t.Parallel()
 
 	t.Run("should process message successfully", testHandlerSuccessfully)
+	t.Run("should pass the pricing criteria to the use case", testHandlerPricingCriteria)
+	t.Run("should drop an event with an invalid currency", testHandlerInvalidCurrency)
+	t.Run("should hand the request to the use case in the tenant of the event", testHandlerTenant)
+	t.Run("should drop an event of a tenant not hosted", testHandlerUnknownTenant)
+	t.Run("should drop an event without tenant when tenants are hosted", testHandlerMissingTenant)
+	t.Run("should hand an authenticated event to the use case", testHandlerVerified)
+	t.Run("should reject an event the verifier does not authenticate", testHandlerUnauthenticated)
+	t.Run("should reject an unauthenticated event outside the tenant it claims", testHandlerUnauthenticatedTenant)
+	t.Run("should reject an undecodable body when events are verified", testHandlerUnauthenticatedBody)
+	t.Run("should retry an event when the verifier is unavailable", testHandlerVerifierUnavailable)
 	t.Run("should return error when message body is invalid json", testHandlerUnmarshalError)
-	t.Run("should not return error when event validation fails", testHandlerValidationError)
+	t.Run("should drop the request when event validation fails", testHandlerValidationError)
 	t.Run("should return error when use case fails", testHandlerUseCaseError)
+	t.Run("should discard a payment already applied", testHandlerPaymentApplied)
+	t.Run("should hand a split payment to the split use case", testHandlerSplit)
+	t.Run("should drop a split payment listing a payer twice", testHandlerSplitDuplicatedPayer)
+	t.Run("should drop a split payment with too many payers", testHandlerSplitTooManyPayers)
+	t.Run("should drop a split payment with a single payer", testHandlerSplitSinglePayer)
+	t.Run("should keep each user ordered while users run in parallel", testHandlerPerUserOrdering)
+	t.Run("should stop only the group of a failed record", testHandlerPerUserFailure)
+	t.Run("should group by the FIFO message group", testHandlerPerUserMessageGroup)
+	t.Run("should group a split payment with the records of its payers", testHandlerPerUserSplit)
+	t.Run("should report the rest of the batch after a failure in sequential mode", testHandlerSequentialFailure)
+	t.Run("should not run more groups than workers", testHandlerWorkers)
+	t.Run("should cancel a record exceeding its timeout", testHandlerRecordTimeout)
+	t.Run("should not start records inside the safety margin", testHandlerSafetyMargin)
+	t.Run("should cancel records in flight when the safety margin is reached", testHandlerSafetyMarginInFlight)
 }
 
 func testHandlerSuccessfully(t *testing.T) {
@@ -44,10 +73,248 @@ func testHandlerSuccessfully(t *testing.T) {
 	h := handler.NewSQSHandler(useCaseMock)
 
 	// WHEN
-	err := h.Handle(context.Background(), sqsEvent)
+	response, err := h.Handle(context.Background(), sqsEvent)
 
 	// THEN
 	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+}
+
+func testHandlerPricingCriteria(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+
+	body := `{"header":{"correlation_id":"corr-1"},"payload":{"user_id":"user-1","amount":10,"merchant_category":"travel","user_tier":"premium","currency":"USD"}}`
+	sqsEvent := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "msg-1", Body: body}}}
+
+	useCaseMock.EXPECT().Handle(mock.Anything, application.Request{
+		UserID:           "user-1",
+		Amount:           10,
+		CorrelationID:    "corr-1",
+		MerchantCategory: "travel",
+		UserTier:         "premium",
+		Currency:         "USD",
+	}).Return(nil).Once()
+
+	h := handler.NewSQSHandler(useCaseMock)
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+}
+
+func testHandlerInvalidCurrency(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+
+	body := `{"header":{"correlation_id":"corr-1"},"payload":{"user_id":"user-1","amount":10,"currency":"usd"}}`
+	sqsEvent := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "msg-1", Body: body}}}
+
+	useCaseMock.EXPECT().Drop(mock.Anything, mock.Anything, mock.MatchedBy(func(err error) bool {
+		return errors.Is(err, handler.ErrValidation)
+	})).Once()
+
+	h := handler.NewSQSHandler(useCaseMock)
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	useCaseMock.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
+}
+
+func testHandlerTenant(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+
+	body := `{"header":{"correlation_id":"corr-1","tenant_id":"acme"},"payload":{"user_id":"user-1","amount":10}}`
+	sqsEvent := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "msg-1", Body: body}}}
+
+	useCaseMock.EXPECT().Handle(mock.MatchedBy(func(ctx context.Context) bool {
+		return domain.TenantFrom(ctx) == "acme"
+	}), application.Request{UserID: "user-1", Amount: 10, CorrelationID: "corr-1"}).Return(nil).Once()
+
+	h := handler.NewSQSHandler(useCaseMock, handler.WithTenants(domain.Tenants{"acme": {ID: "acme"}}))
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+}
+
+func testHandlerUnknownTenant(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+
+	body := `{"header":{"correlation_id":"corr-1","tenant_id":"globex"},"payload":{"user_id":"user-1","amount":10}}`
+	sqsEvent := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "msg-1", Body: body}}}
+
+	useCaseMock.EXPECT().Drop(mock.Anything, mock.Anything, mock.MatchedBy(func(err error) bool {
+		return errors.Is(err, handler.ErrValidation)
+	})).Once()
+
+	h := handler.NewSQSHandler(useCaseMock, handler.WithTenants(domain.Tenants{"acme": {ID: "acme"}}))
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	useCaseMock.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
+}
+
+func testHandlerVerified(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	verifierMock := mocks.NewMockVerifier(t)
+	sqsEvent := createSQSEvent(t, "user-1", 10, "corr-1")
+
+	verifierMock.EXPECT().Verify(mock.Anything, sqsEvent.Records[0], _events.EventHeader{CorrelationID: "corr-1"}).Return(nil).Once()
+	useCaseMock.EXPECT().Handle(mock.Anything, application.Request{UserID: "user-1", Amount: 10, CorrelationID: "corr-1"}).Return(nil).Once()
+
+	h := handler.NewSQSHandler(useCaseMock, handler.WithVerifier(verifierMock))
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+}
+
+func testHandlerUnauthenticated(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	verifierMock := mocks.NewMockVerifier(t)
+	sqsEvent := createSQSEvent(t, "user-1", 10, "corr-1")
+	verifyErr := domain.NewInvalidSignatureError("key-1", errors.New("signature does not match the body"))
+
+	verifierMock.EXPECT().Verify(mock.Anything, mock.Anything, mock.Anything).Return(verifyErr).Once()
+	useCaseMock.EXPECT().Reject(mock.Anything, application.Request{UserID: "user-1", Amount: 10, CorrelationID: "corr-1"}, verifyErr).Once()
+
+	h := handler.NewSQSHandler(useCaseMock, handler.WithVerifier(verifierMock))
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	useCaseMock.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
+}
+
+func testHandlerUnauthenticatedTenant(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	verifierMock := mocks.NewMockVerifier(t)
+	body := `{"header":{"correlation_id":"corr-1","tenant_id":"acme"},"payload":{"user_id":"user-1","amount":10}}`
+	sqsEvent := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "msg-1", Body: body}}}
+	verifyErr := domain.NewInvalidSignatureError("key-1", errors.New("signature does not match the body"))
+	withoutTenant := mock.MatchedBy(func(ctx context.Context) bool { return domain.TenantFrom(ctx) == "" })
+
+	verifierMock.EXPECT().Verify(withoutTenant, mock.Anything, mock.Anything).Return(verifyErr).Once()
+	useCaseMock.EXPECT().Reject(withoutTenant, mock.Anything, verifyErr).Once()
+
+	h := handler.NewSQSHandler(useCaseMock, handler.WithVerifier(verifierMock), handler.WithTenants(domain.Tenants{"acme": {ID: "acme"}}))
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+}
+
+func testHandlerUnauthenticatedBody(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	verifierMock := mocks.NewMockVerifier(t)
+	sqsEvent := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "bad-message", Body: "this is not json"}}}
+
+	useCaseMock.EXPECT().Reject(mock.Anything, application.Request{}, mock.MatchedBy(func(err error) bool {
+		return errors.Is(err, handler.ErrValidation)
+	})).Once()
+
+	h := handler.NewSQSHandler(useCaseMock, handler.WithVerifier(verifierMock))
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	verifierMock.AssertNotCalled(t, "Verify", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func testHandlerVerifierUnavailable(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	verifierMock := mocks.NewMockVerifier(t)
+	sqsEvent := createSQSEvent(t, "user-1", 10, "corr-1")
+
+	verifierMock.EXPECT().Verify(mock.Anything, mock.Anything, mock.Anything).
+		Return(domain.NewDependencyUnavailableError("signing keys", errors.New("timeout"))).Once()
+
+	h := handler.NewSQSHandler(useCaseMock, handler.WithVerifier(verifierMock))
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []events.SQSBatchItemFailure{{ItemIdentifier: "test-message-id"}}, response.BatchItemFailures)
+	useCaseMock.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
+	useCaseMock.AssertNotCalled(t, "Reject", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func testHandlerMissingTenant(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+
+	body := `{"header":{"correlation_id":"corr-1"},"payload":{"payers":[{"user_id":"user-1","amount":5},{"user_id":"user-2","amount":5}]}}`
+	sqsEvent := events.SQSEvent{Records: []events.SQSMessage{{MessageId: "msg-1", Body: body}}}
+
+	useCaseMock.EXPECT().Drop(mock.Anything, mock.Anything, mock.MatchedBy(func(err error) bool {
+		return errors.Is(err, handler.ErrValidation)
+	})).Once()
+
+	h := handler.NewSQSHandler(useCaseMock, handler.WithTenants(domain.Tenants{"acme": {ID: "acme"}}))
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	useCaseMock.AssertNotCalled(t, "HandleSplit", mock.Anything, mock.Anything)
 }
 
 func testHandlerUnmarshalError(t *testing.T) {
@@ -57,15 +324,16 @@ func testHandlerUnmarshalError(t *testing.T) {
 	useCaseMock := mocks.NewMockUseCase(t)
 
 	sqsEvent := events.SQSEvent{
-		Records: []events.SQSMessage{{Body: "this is not json"}},
+		Records: []events.SQSMessage{{MessageId: "bad-message", Body: "this is not json"}},
 	}
 	h := handler.NewSQSHandler(useCaseMock)
 
 	// WHEN
-	err := h.Handle(context.Background(), sqsEvent)
+	response, err := h.Handle(context.Background(), sqsEvent)
 
 	// THEN
-	assert.Error(t, err)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bad-message"}, failedIDs(response))
 }
 
 func testHandlerValidationError(t *testing.T) {
@@ -76,11 +344,16 @@ func testHandlerValidationError(t *testing.T) {
 	sqsEvent := createSQSEvent(t, "", 50.5, "corr-id-abc")
 	h := handler.NewSQSHandler(useCaseMock)
 
+	useCaseMock.EXPECT().Drop(mock.Anything, application.Request{Amount: 50.5, CorrelationID: "corr-id-abc"}, mock.MatchedBy(func(err error) bool {
+		return errors.Is(err, handler.ErrValidation)
+	})).Once()
+
 	// WHEN
-	err := h.Handle(context.Background(), sqsEvent)
+	response, err := h.Handle(context.Background(), sqsEvent)
 
 	// THEN
 	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
 	useCaseMock.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
 }
 
@@ -102,17 +375,447 @@ func testHandlerUseCaseError(t *testing.T) {
 	h := handler.NewSQSHandler(useCaseMock)
 
 	// WHEN
-	err := h.Handle(context.Background(), sqsEvent)
+	response, err := h.Handle(context.Background(), sqsEvent)
 
 	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test-message-id"}, failedIDs(response))
+}
 
-	assert.Error(t, err)
-	assert.Equal(t, expectedError, err)
+func testHandlerPaymentApplied(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	sqsEvent := createSQSEvent(t, "user-123", 50.5, "corr-id-abc")
+
+	useCaseMock.EXPECT().Handle(mock.Anything, mock.Anything).Return(domain.NewPaymentAppliedError("user-123", "pay-1", nil)).Once()
+
+	h := handler.NewSQSHandler(useCaseMock)
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+}
+
+func testHandlerSplit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	sqsEvent := createSplitSQSEvent(t, "pay-1", "corr-id-abc", _events.PayerLeg{UserID: "user-1", Amount: 10}, _events.PayerLeg{UserID: "user-2", Amount: 20})
+	h := handler.NewSQSHandler(useCaseMock)
+
+	useCaseMock.EXPECT().HandleSplit(mock.Anything, application.SplitRequest{
+		PaymentID:     "pay-1",
+		CorrelationID: "corr-id-abc",
+		Legs:          []application.Leg{{UserID: "user-1", Amount: 10}, {UserID: "user-2", Amount: 20}},
+	}).Return(nil).Once()
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	useCaseMock.AssertNotCalled(t, "Handle", mock.Anything, mock.Anything)
+}
+
+func testHandlerSplitDuplicatedPayer(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	sqsEvent := createSplitSQSEvent(t, "pay-1", "corr-id-abc", _events.PayerLeg{UserID: "user-1", Amount: 10}, _events.PayerLeg{UserID: "user-1", Amount: 20})
+	h := handler.NewSQSHandler(useCaseMock)
+
+	useCaseMock.EXPECT().Drop(mock.Anything, application.Request{PaymentID: "pay-1", CorrelationID: "corr-id-abc"}, mock.MatchedBy(func(err error) bool {
+		return errors.Is(err, handler.ErrValidation)
+	})).Once()
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	useCaseMock.AssertNotCalled(t, "HandleSplit", mock.Anything, mock.Anything)
+}
+
+func testHandlerSplitTooManyPayers(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	payers := make([]_events.PayerLeg, 0, handler.MaxSplitPayers+1)
+	for i := range handler.MaxSplitPayers + 1 {
+		payers = append(payers, _events.PayerLeg{UserID: domain.UserID(fmt.Sprintf("user-%d", i)), Amount: 1})
+	}
+	sqsEvent := createSplitSQSEvent(t, "pay-1", "corr-id-abc", payers...)
+	h := handler.NewSQSHandler(useCaseMock)
+
+	useCaseMock.EXPECT().Drop(mock.Anything, application.Request{PaymentID: "pay-1", CorrelationID: "corr-id-abc"}, mock.MatchedBy(func(err error) bool {
+		return errors.Is(err, handler.ErrValidation)
+	})).Once()
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	useCaseMock.AssertNotCalled(t, "HandleSplit", mock.Anything, mock.Anything)
+}
+
+func testHandlerSplitSinglePayer(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCaseMock := mocks.NewMockUseCase(t)
+	sqsEvent := createSplitSQSEvent(t, "pay-1", "corr-id-abc", _events.PayerLeg{UserID: "user-1", Amount: 10})
+	h := handler.NewSQSHandler(useCaseMock)
+
+	useCaseMock.EXPECT().Drop(mock.Anything, application.Request{PaymentID: "pay-1", CorrelationID: "corr-id-abc"}, mock.MatchedBy(func(err error) bool {
+		return errors.Is(err, handler.ErrValidation)
+	})).Once()
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	useCaseMock.AssertNotCalled(t, "HandleSplit", mock.Anything, mock.Anything)
+}
+
+func testHandlerPerUserOrdering(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newRecordingUseCase()
+	// user-a can't finish until user-b started, which deadlocks a sequential loop
+	useCase.blockUntilStarted["user-a"] = "user-b"
+	h := handler.NewSQSHandler(useCase, handler.WithOrdering(handler.OrderingPerUser))
+	sqsEvent := batch(t,
+		record{"m1", "user-a", 1}, record{"m2", "user-b", 2}, record{"m3", "user-a", 3}, record{"m4", "user-b", 4},
+	)
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	assert.Equal(t, []domain.Amount{1, 3}, useCase.amounts("user-a"))
+	assert.Equal(t, []domain.Amount{2, 4}, useCase.amounts("user-b"))
+}
+
+func testHandlerPerUserFailure(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newRecordingUseCase()
+	useCase.failOn[2] = errors.New("insufficient funds")
+	h := handler.NewSQSHandler(useCase, handler.WithOrdering(handler.OrderingPerUser))
+	sqsEvent := batch(t,
+		record{"m1", "user-a", 1}, record{"m2", "user-a", 2}, record{"m3", "user-a", 3}, record{"m4", "user-b", 4},
+	)
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m3"}, failedIDs(response), "the failed record and the rest of its group")
+	assert.Equal(t, []domain.Amount{1, 2}, useCase.amounts("user-a"))
+	assert.Equal(t, []domain.Amount{4}, useCase.amounts("user-b"))
+}
+
+func testHandlerPerUserMessageGroup(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newRecordingUseCase()
+	useCase.failOn[1] = errors.New("boom")
+	h := handler.NewSQSHandler(useCase, handler.WithOrdering(handler.OrderingPerUser))
+	sqsEvent := batch(t, record{"m1", "user-a", 1}, record{"m2", "user-b", 2})
+	for i := range sqsEvent.Records {
+		sqsEvent.Records[i].Attributes = map[string]string{"MessageGroupId": "group-1"}
+	}
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m1", "m2"}, failedIDs(response))
+	assert.Empty(t, useCase.amounts("user-b"), "records sharing a message group run in order")
+}
+
+func testHandlerPerUserSplit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newRecordingUseCase()
+	useCase.failOn[2] = errors.New("boom")
+	h := handler.NewSQSHandler(useCase, handler.WithOrdering(handler.OrderingPerUser))
+
+	split := createSplitSQSEvent(t, "pay-split", "corr-split", _events.PayerLeg{UserID: "user-a", Amount: 2}, _events.PayerLeg{UserID: "user-b", Amount: 3}).Records[0]
+	split.MessageId = "m2"
+	sqsEvent := batch(t, record{"m1", "user-a", 1}, record{"m3", "user-b", 4}, record{"m4", "user-c", 5})
+	sqsEvent.Records = slices.Insert(sqsEvent.Records, 1, split)
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m3"}, failedIDs(response), "user-b waits behind the split it shares")
+	assert.Equal(t, []domain.Amount{1}, useCase.amounts("user-a"))
+	assert.Empty(t, useCase.amounts("user-b"))
+	assert.Equal(t, []domain.Amount{5}, useCase.amounts("user-c"))
+}
+
+func testHandlerSequentialFailure(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newRecordingUseCase()
+	useCase.failOn[2] = errors.New("boom")
+	h := handler.NewSQSHandler(useCase)
+	sqsEvent := batch(t, record{"m1", "user-a", 1}, record{"m2", "user-b", 2}, record{"m3", "user-c", 3})
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m3"}, failedIDs(response))
+	assert.Empty(t, useCase.amounts("user-c"))
+}
+
+func testHandlerWorkers(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newRecordingUseCase()
+	useCase.delay = 20 * time.Millisecond
+	h := handler.NewSQSHandler(useCase, handler.WithOrdering(handler.OrderingPerUser), handler.WithWorkers(2))
+	sqsEvent := batch(t,
+		record{"m1", "user-a", 1}, record{"m2", "user-b", 2}, record{"m3", "user-c", 3},
+		record{"m4", "user-d", 4}, record{"m5", "user-e", 5},
+	)
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+	assert.Equal(t, int64(2), useCase.maxInFlight.Load())
+}
+
+func testHandlerRecordTimeout(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newRecordingUseCase()
+	useCase.waitForCancel[1] = true
+	h := handler.NewSQSHandler(useCase,
+		handler.WithOrdering(handler.OrderingPerUser),
+		handler.WithRecordTimeout(10*time.Millisecond),
+	)
+	sqsEvent := batch(t, record{"m1", "user-a", 1}, record{"m2", "user-b", 2})
+
+	// WHEN
+	response, err := h.Handle(context.Background(), sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m1"}, failedIDs(response))
+	assert.Equal(t, []domain.Amount{2}, useCase.amounts("user-b"))
+}
+
+func testHandlerSafetyMargin(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newRecordingUseCase()
+	h := handler.NewSQSHandler(useCase, handler.WithSafetyMargin(2*time.Second))
+	sqsEvent := batch(t, record{"m1", "user-a", 1}, record{"m2", "user-b", 2})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// WHEN
+	response, err := h.Handle(ctx, sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m1", "m2"}, failedIDs(response))
+	assert.Empty(t, useCase.amounts("user-a"))
+	assert.Empty(t, useCase.amounts("user-b"))
+}
+
+func testHandlerSafetyMarginInFlight(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	useCase := newRecordingUseCase()
+	useCase.waitForCancel[2] = true
+	h := handler.NewSQSHandler(useCase, handler.WithSafetyMargin(100*time.Millisecond))
+	sqsEvent := batch(t, record{"m1", "user-a", 1}, record{"m2", "user-b", 2}, record{"m3", "user-c", 3})
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	// WHEN
+	response, err := h.Handle(ctx, sqsEvent)
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m3"}, failedIDs(response))
+	assert.Equal(t, []domain.Amount{1}, useCase.amounts("user-a"))
+	assert.Empty(t, useCase.amounts("user-c"))
+	assert.NoError(t, ctx.Err(), "the response is ready before the invocation deadline")
 }
 
 // --- Helper Functions ---
 
-func createSQSEvent(t *testing.T, userID domain.UserID, amount domain.Amount, corrID string) events.SQSEvent {
+func failedIDs(response events.SQSEventResponse) []string {
+	ids := []string{}
+	for _, failure := range response.BatchItemFailures {
+		ids = append(ids, failure.ItemIdentifier)
+	}
+	return ids
+}
+
+type record struct {
+	messageID string
+	userID    domain.UserID
+	amount    domain.Amount
+}
+
+func batch(t *testing.T, records ...record) events.SQSEvent {
+	t.Helper()
+
+	var sqsEvent events.SQSEvent
+	for _, r := range records {
+		message := createSQSEvent(t, r.userID, r.amount, "corr-"+r.messageID).Records[0]
+		message.MessageId = r.messageID
+		sqsEvent.Records = append(sqsEvent.Records, message)
+	}
+
+	return sqsEvent
+}
+
+// recordingUseCase keeps the requests handled per user
+type recordingUseCase struct {
+	mu                sync.Mutex
+	handled           map[domain.UserID][]domain.Amount
+	started           map[domain.UserID]chan struct{}
+	blockUntilStarted map[domain.UserID]domain.UserID
+	failOn            map[domain.Amount]error
+	waitForCancel     map[domain.Amount]bool
+	delay             time.Duration
+	inFlight          atomic.Int64
+	maxInFlight       atomic.Int64
+}
+
+func newRecordingUseCase() *recordingUseCase {
+	return &recordingUseCase{
+		handled:           make(map[domain.UserID][]domain.Amount),
+		started:           make(map[domain.UserID]chan struct{}),
+		blockUntilStarted: make(map[domain.UserID]domain.UserID),
+		failOn:            make(map[domain.Amount]error),
+		waitForCancel:     make(map[domain.Amount]bool),
+	}
+}
+
+func (u *recordingUseCase) Handle(ctx context.Context, req application.Request) error {
+	u.markStarted(req.UserID)
+
+	inFlight := u.inFlight.Add(1)
+	defer u.inFlight.Add(-1)
+	for peak := u.maxInFlight.Load(); inFlight > peak && !u.maxInFlight.CompareAndSwap(peak, inFlight); {
+		peak = u.maxInFlight.Load()
+	}
+	time.Sleep(u.delay)
+
+	if u.waitForCancel[req.Amount] {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	if other, ok := u.blockUntilStarted[req.UserID]; ok {
+		select {
+		case <-u.startedCh(other):
+		case <-time.After(time.Second):
+			return errors.New("users were not processed in parallel")
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.handled[req.UserID] = append(u.handled[req.UserID], req.Amount)
+
+	return u.failOn[req.Amount]
+}
+
+func (u *recordingUseCase) Drop(context.Context, application.Request, error) {}
+
+func (u *recordingUseCase) Reject(context.Context, application.Request, error) {}
+
+// HandleSplit fails when any leg amount is in failOn, otherwise records every leg
+func (u *recordingUseCase) HandleSplit(_ context.Context, req application.SplitRequest) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for _, leg := range req.Legs {
+		if err := u.failOn[leg.Amount]; err != nil {
+			return err
+		}
+	}
+	for _, leg := range req.Legs {
+		u.handled[leg.UserID] = append(u.handled[leg.UserID], leg.Amount)
+	}
+	return nil
+}
+
+func (u *recordingUseCase) markStarted(userID domain.UserID) {
+	ch := u.startedCh(userID)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	select {
+	case <-ch:
+	default:
+		close(ch)
+	}
+}
+
+// startedCh is closed by the first request of the user
+func (u *recordingUseCase) startedCh(userID domain.UserID) chan struct{} {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	ch, ok := u.started[userID]
+	if !ok {
+		ch = make(chan struct{})
+		u.started[userID] = ch
+	}
+	return ch
+}
+
+func (u *recordingUseCase) amounts(userID domain.UserID) []domain.Amount {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.handled[userID]
+}
+
+func createSQSEvent(t testing.TB, userID domain.UserID, amount domain.Amount, corrID string) events.SQSEvent {
 	t.Helper()
 
 	eventPayload := _events.PaymentInitPayload{
@@ -138,3 +841,19 @@ func createSQSEvent(t *testing.T, userID domain.UserID, amount domain.Amount, co
 		},
 	}
 }
+
+func createSplitSQSEvent(t testing.TB, paymentID, corrID string, payers ..._events.PayerLeg) events.SQSEvent {
+	t.Helper()
+
+	event := _events.PaymentInitEvent{
+		Header:  _events.EventHeader{CorrelationID: corrID},
+		Payload: _events.PaymentInitPayload{PaymentID: paymentID, Payers: payers},
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+
+	return events.SQSEvent{Records: []events.SQSMessage{{MessageId: "test-message-id", Body: string(body)}}}
+}
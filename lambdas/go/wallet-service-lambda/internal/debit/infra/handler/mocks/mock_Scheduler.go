@@ -0,0 +1,106 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockScheduler creates a new instance of MockScheduler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockScheduler(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockScheduler {
+	mock := &MockScheduler{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockScheduler is an autogenerated mock type for the Scheduler type
+type MockScheduler struct {
+	mock.Mock
+}
+
+type MockScheduler_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockScheduler) EXPECT() *MockScheduler_Expecter {
+	return &MockScheduler_Expecter{mock: &_m.Mock}
+}
+
+// Run provides a mock function for the type MockScheduler
+func (_mock *MockScheduler) Run(ctx context.Context, at time.Time) (application.SchedulerReport, error) {
+	ret := _mock.Called(ctx, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Run")
+	}
+
+	var r0 application.SchedulerReport
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) (application.SchedulerReport, error)); ok {
+		return returnFunc(ctx, at)
+	}
+	if returnFunc, ok := ret.Get(0).(func(context.Context, time.Time) application.SchedulerReport); ok {
+		r0 = returnFunc(ctx, at)
+	} else {
+		r0 = ret.Get(0).(application.SchedulerReport)
+	}
+	if returnFunc, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = returnFunc(ctx, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockScheduler_Run_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Run'
+type MockScheduler_Run_Call struct {
+	*mock.Call
+}
+
+// Run is a helper method to define mock.On call
+//   - ctx context.Context
+//   - at time.Time
+func (_e *MockScheduler_Expecter) Run(ctx interface{}, at interface{}) *MockScheduler_Run_Call {
+	return &MockScheduler_Run_Call{Call: _e.mock.On("Run", ctx, at)}
+}
+
+func (_c *MockScheduler_Run_Call) Run(run func(ctx context.Context, at time.Time)) *MockScheduler_Run_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 time.Time
+		if args[1] != nil {
+			arg1 = args[1].(time.Time)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockScheduler_Run_Call) Return(schedulerReport application.SchedulerReport, err error) *MockScheduler_Run_Call {
+	_c.Call.Return(schedulerReport, err)
+	return _c
+}
+
+func (_c *MockScheduler_Run_Call) RunAndReturn(run func(ctx context.Context, at time.Time) (application.SchedulerReport, error)) *MockScheduler_Run_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -38,6 +38,58 @@ func (_m *MockUseCase) EXPECT() *MockUseCase_Expecter {
 	return &MockUseCase_Expecter{mock: &_m.Mock}
 }
 
+// Drop provides a mock function for the type MockUseCase
+func (_mock *MockUseCase) Drop(ctx context.Context, req application.Request, reason error) {
+	_mock.Called(ctx, req, reason)
+	return
+}
+
+// MockUseCase_Drop_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Drop'
+type MockUseCase_Drop_Call struct {
+	*mock.Call
+}
+
+// Drop is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req application.Request
+//   - reason error
+func (_e *MockUseCase_Expecter) Drop(ctx interface{}, req interface{}, reason interface{}) *MockUseCase_Drop_Call {
+	return &MockUseCase_Drop_Call{Call: _e.mock.On("Drop", ctx, req, reason)}
+}
+
+func (_c *MockUseCase_Drop_Call) Run(run func(ctx context.Context, req application.Request, reason error)) *MockUseCase_Drop_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 application.Request
+		if args[1] != nil {
+			arg1 = args[1].(application.Request)
+		}
+		var arg2 error
+		if args[2] != nil {
+			arg2 = args[2].(error)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUseCase_Drop_Call) Return() *MockUseCase_Drop_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockUseCase_Drop_Call) RunAndReturn(run func(ctx context.Context, req application.Request, reason error)) *MockUseCase_Drop_Call {
+	_c.Run(run)
+	return _c
+}
+
 // Handle provides a mock function for the type MockUseCase
 func (_mock *MockUseCase) Handle(ctx context.Context, req application.Request) error {
 	ret := _mock.Called(ctx, req)
@@ -94,3 +146,112 @@ func (_c *MockUseCase_Handle_Call) RunAndReturn(run func(ctx context.Context, re
 	_c.Call.Return(run)
 	return _c
 }
+
+// HandleSplit provides a mock function for the type MockUseCase
+func (_mock *MockUseCase) HandleSplit(ctx context.Context, req application.SplitRequest) error {
+	ret := _mock.Called(ctx, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HandleSplit")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, application.SplitRequest) error); ok {
+		r0 = returnFunc(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockUseCase_HandleSplit_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HandleSplit'
+type MockUseCase_HandleSplit_Call struct {
+	*mock.Call
+}
+
+// HandleSplit is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req application.SplitRequest
+func (_e *MockUseCase_Expecter) HandleSplit(ctx interface{}, req interface{}) *MockUseCase_HandleSplit_Call {
+	return &MockUseCase_HandleSplit_Call{Call: _e.mock.On("HandleSplit", ctx, req)}
+}
+
+func (_c *MockUseCase_HandleSplit_Call) Run(run func(ctx context.Context, req application.SplitRequest)) *MockUseCase_HandleSplit_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 application.SplitRequest
+		if args[1] != nil {
+			arg1 = args[1].(application.SplitRequest)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUseCase_HandleSplit_Call) Return(err error) *MockUseCase_HandleSplit_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockUseCase_HandleSplit_Call) RunAndReturn(run func(ctx context.Context, req application.SplitRequest) error) *MockUseCase_HandleSplit_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Reject provides a mock function for the type MockUseCase
+func (_mock *MockUseCase) Reject(ctx context.Context, req application.Request, reason error) {
+	_mock.Called(ctx, req, reason)
+	return
+}
+
+// MockUseCase_Reject_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Reject'
+type MockUseCase_Reject_Call struct {
+	*mock.Call
+}
+
+// Reject is a helper method to define mock.On call
+//   - ctx context.Context
+//   - req application.Request
+//   - reason error
+func (_e *MockUseCase_Expecter) Reject(ctx interface{}, req interface{}, reason interface{}) *MockUseCase_Reject_Call {
+	return &MockUseCase_Reject_Call{Call: _e.mock.On("Reject", ctx, req, reason)}
+}
+
+func (_c *MockUseCase_Reject_Call) Run(run func(ctx context.Context, req application.Request, reason error)) *MockUseCase_Reject_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 application.Request
+		if args[1] != nil {
+			arg1 = args[1].(application.Request)
+		}
+		var arg2 error
+		if args[2] != nil {
+			arg2 = args[2].(error)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockUseCase_Reject_Call) Return() *MockUseCase_Reject_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *MockUseCase_Reject_Call) RunAndReturn(run func(ctx context.Context, req application.Request, reason error)) *MockUseCase_Reject_Call {
+	_c.Run(run)
+	return _c
+}
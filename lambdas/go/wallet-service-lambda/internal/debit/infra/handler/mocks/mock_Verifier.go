@@ -0,0 +1,103 @@
+// Code generated by mockery; DO NOT EDIT.
+// github.com/vektra/mockery
+// template: testify
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/aws/aws-lambda-go/events"
+	events0 "github.com/payment-processor/internal/debit/domain/events"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// NewMockVerifier creates a new instance of MockVerifier. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMockVerifier(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *MockVerifier {
+	mock := &MockVerifier{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
+
+// MockVerifier is an autogenerated mock type for the Verifier type
+type MockVerifier struct {
+	mock.Mock
+}
+
+type MockVerifier_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *MockVerifier) EXPECT() *MockVerifier_Expecter {
+	return &MockVerifier_Expecter{mock: &_m.Mock}
+}
+
+// Verify provides a mock function for the type MockVerifier
+func (_mock *MockVerifier) Verify(ctx context.Context, message events.SQSMessage, header events0.EventHeader) error {
+	ret := _mock.Called(ctx, message, header)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Verify")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, events.SQSMessage, events0.EventHeader) error); ok {
+		r0 = returnFunc(ctx, message, header)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockVerifier_Verify_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Verify'
+type MockVerifier_Verify_Call struct {
+	*mock.Call
+}
+
+// Verify is a helper method to define mock.On call
+//   - ctx context.Context
+//   - message events.SQSMessage
+//   - header events0.EventHeader
+func (_e *MockVerifier_Expecter) Verify(ctx interface{}, message interface{}, header interface{}) *MockVerifier_Verify_Call {
+	return &MockVerifier_Verify_Call{Call: _e.mock.On("Verify", ctx, message, header)}
+}
+
+func (_c *MockVerifier_Verify_Call) Run(run func(ctx context.Context, message events.SQSMessage, header events0.EventHeader)) *MockVerifier_Verify_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 events.SQSMessage
+		if args[1] != nil {
+			arg1 = args[1].(events.SQSMessage)
+		}
+		var arg2 events0.EventHeader
+		if args[2] != nil {
+			arg2 = args[2].(events0.EventHeader)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockVerifier_Verify_Call) Return(err error) *MockVerifier_Verify_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockVerifier_Verify_Call) RunAndReturn(run func(ctx context.Context, message events.SQSMessage, header events0.EventHeader) error) *MockVerifier_Verify_Call {
+	_c.Call.Return(run)
+	return _c
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-processor/internal/debit/application"
+)
+
+type Scheduler interface {
+	// Run debits the cycles of the mandates due at the given time
+	Run(ctx context.Context, at time.Time) (application.SchedulerReport, error)
+}
+
+// SchedulerHandler runs the scheduler on every tick of an EventBridge schedule
+type SchedulerHandler struct {
+	scheduler Scheduler
+	now       func() time.Time
+}
+
+// Handle debits the cycles due at the time of the event, so a delayed invocation does not debit
+// cycles that fell due after it was scheduled. Declined and failed cycles are left to later ticks
+// and only the mandates that could not be saved fail the invocation
+func (h *SchedulerHandler) Handle(ctx context.Context, event events.CloudWatchEvent) error {
+	at := event.Time
+	if at.IsZero() {
+		at = h.now()
+	}
+
+	report, err := h.scheduler.Run(ctx, at.UTC())
+
+	outcomes := make(map[application.CycleOutcome]int)
+	for _, cycle := range report.Cycles {
+		outcomes[cycle.Outcome]++
+	}
+	slog.InfoContext(ctx, "Scheduler run finished",
+		"eventId", event.ID,
+		"at", at,
+		"mandates", report.Mandates,
+		"debited", outcomes[application.CycleDebited],
+		"alreadyDebited", outcomes[application.CycleAlreadyDebited],
+		"retrying", outcomes[application.CycleRetrying],
+		"skipped", outcomes[application.CycleSkipped],
+		"failed", outcomes[application.CycleFailed],
+	)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "scheduler run failed", "eventId", event.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+func NewSchedulerHandler(scheduler Scheduler) *SchedulerHandler {
+	return &SchedulerHandler{scheduler: scheduler, now: time.Now}
+}
@@ -0,0 +1,101 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/infra/handler"
+	"github.com/payment-processor/internal/debit/infra/handler/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSchedulerHandler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should run the scheduler at the time of the event", testSchedulerEventTime)
+	t.Run("should run the scheduler now for events without time", testSchedulerNow)
+	t.Run("should not fail on declined cycles", testSchedulerDeclined)
+	t.Run("should fail when a mandate could not be saved", testSchedulerError)
+}
+
+func testSchedulerEventTime(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	schedulerMock := mocks.NewMockScheduler(t)
+	tick := time.Date(2026, 3, 1, 6, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	schedulerMock.EXPECT().Run(mock.Anything, tick.UTC()).Return(application.SchedulerReport{Mandates: 1}, nil).Once()
+
+	h := handler.NewSchedulerHandler(schedulerMock)
+
+	// WHEN
+	err := h.Handle(context.Background(), events.CloudWatchEvent{ID: "tick-1", Time: tick})
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testSchedulerNow(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	schedulerMock := mocks.NewMockScheduler(t)
+	before := time.Now()
+
+	schedulerMock.EXPECT().Run(mock.Anything, mock.MatchedBy(func(at time.Time) bool {
+		return !at.Before(before.Truncate(time.Second)) && at.Location() == time.UTC
+	})).Return(application.SchedulerReport{}, nil).Once()
+
+	h := handler.NewSchedulerHandler(schedulerMock)
+
+	// WHEN
+	err := h.Handle(context.Background(), events.CloudWatchEvent{ID: "manual"})
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testSchedulerDeclined(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	schedulerMock := mocks.NewMockScheduler(t)
+	report := application.SchedulerReport{Mandates: 2, Cycles: []application.CycleResult{
+		{MandateID: "sub-1", PaymentID: "mandate:sub-1:0", Outcome: application.CycleDebited},
+		{MandateID: "sub-2", PaymentID: "mandate:sub-2:0", Outcome: application.CycleRetrying, Err: errors.New("insufficient funds")},
+	}}
+
+	schedulerMock.EXPECT().Run(mock.Anything, mock.Anything).Return(report, nil).Once()
+
+	h := handler.NewSchedulerHandler(schedulerMock)
+
+	// WHEN
+	err := h.Handle(context.Background(), events.CloudWatchEvent{ID: "tick-1", Time: time.Now()})
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testSchedulerError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	schedulerMock := mocks.NewMockScheduler(t)
+	saveErr := errors.New("failed to save mandate sub-1")
+
+	schedulerMock.EXPECT().Run(mock.Anything, mock.Anything).Return(application.SchedulerReport{Mandates: 1}, saveErr).Once()
+
+	h := handler.NewSchedulerHandler(schedulerMock)
+
+	// WHEN
+	err := h.Handle(context.Background(), events.CloudWatchEvent{ID: "tick-1", Time: time.Now()})
+
+	// THEN
+	assert.ErrorIs(t, err, saveErr)
+}
@@ -0,0 +1,216 @@
+package recorder
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/google/uuid"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+type Kind string
+
+const (
+	KindInvocation      Kind = "invocation"
+	KindRepositoryGet   Kind = "repository.get"
+	KindRepositoryWrite Kind = "repository.update"
+	KindEventPublished  Kind = "event.published"
+	KindResult          Kind = "invocation.result"
+)
+
+type invocationKey struct{}
+
+// Entry is a single line of a JSONL capture
+type Entry struct {
+	Kind         Kind                         `json:"kind"`
+	InvocationID string                       `json:"invocation_id"`
+	Timestamp    time.Time                    `json:"timestamp"`
+	Input        *events.SQSEvent             `json:"input,omitempty"`
+	TenantID     domain.TenantID              `json:"tenant_id,omitempty"`
+	UserID       domain.UserID                `json:"user_id,omitempty"`
+	Wallet       *WalletRecord                `json:"wallet,omitempty"`
+	Event        *ports.BalanceDebitedRequest `json:"event,omitempty"`
+	Error        string                       `json:"error,omitempty"`
+	Failures     []string                     `json:"batch_item_failures,omitempty"`
+}
+
+type WalletRecord struct {
+	TenantID domain.TenantID                   `json:"tenant_id,omitempty"`
+	UserID   domain.UserID                     `json:"user_id"`
+	Amount   domain.Amount                     `json:"amount"`
+	Balances map[domain.Currency]domain.Amount `json:"balances,omitempty"`
+	Version  int                               `json:"version"`
+}
+
+func (w WalletRecord) toDomain() domain.Wallet {
+	return domain.Wallet{TenantID: w.TenantID, UserID: w.UserID, Amount: w.Amount, Balances: maps.Clone(w.Balances), Version: w.Version}
+}
+
+func toWalletRecord(wallet domain.Wallet) *WalletRecord {
+	return &WalletRecord{TenantID: wallet.TenantID, UserID: wallet.UserID, Amount: wallet.Amount, Balances: maps.Clone(wallet.Balances), Version: wallet.Version}
+}
+
+type Handler interface {
+	Handle(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error)
+}
+
+// Recorder writes every invocation, repository access and published event to a JSONL capture.
+// A failure writing the capture never fails the invocation being recorded
+type Recorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	now func() time.Time
+}
+
+func (r *Recorder) record(ctx context.Context, entry Entry) {
+	entry.InvocationID = invocationID(ctx)
+	entry.TenantID = domain.TenantFrom(ctx)
+	entry.Timestamp = r.now().UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.enc.Encode(entry); err != nil {
+		slog.WarnContext(ctx, "failed to write capture entry", "kind", entry.Kind, "error", err)
+	}
+}
+
+// Handler wraps h so that every invocation gets its own id and its input and outcome are recorded
+func (r *Recorder) Handler(h Handler) Handler {
+	return &recordingHandler{next: h, recorder: r}
+}
+
+// Repository wraps repo so that every read and write is recorded
+func (r *Recorder) Repository(repo ports.WalletRepository) ports.WalletRepository {
+	return &recordingRepository{next: repo, recorder: r}
+}
+
+// EventBus wraps bus so that every published event is recorded
+func (r *Recorder) EventBus(bus ports.EventBusProcessor) ports.EventBusProcessor {
+	return &recordingEventBus{next: bus, recorder: r}
+}
+
+type recordingHandler struct {
+	next     Handler
+	recorder *Recorder
+}
+
+func (h *recordingHandler) Handle(ctx context.Context, sqsEvent events.SQSEvent) (events.SQSEventResponse, error) {
+	ctx = context.WithValue(ctx, invocationKey{}, uuid.NewString())
+
+	h.recorder.record(ctx, Entry{Kind: KindInvocation, Input: &sqsEvent})
+	response, err := h.next.Handle(ctx, sqsEvent)
+	h.recorder.record(ctx, Entry{Kind: KindResult, Error: errorString(err), Failures: failedIDs(response)})
+
+	return response, err
+}
+
+type recordingRepository struct {
+	next     ports.WalletRepository
+	recorder *Recorder
+}
+
+func (r *recordingRepository) Get(ctx context.Context, userID domain.UserID) (domain.Wallet, error) {
+	wallet, err := r.next.Get(ctx, userID)
+
+	entry := Entry{Kind: KindRepositoryGet, UserID: userID, Error: errorString(err)}
+	if err == nil {
+		entry.Wallet = toWalletRecord(wallet)
+	}
+	r.recorder.record(ctx, entry)
+
+	return wallet, err
+}
+
+func (r *recordingRepository) Update(ctx context.Context, wallet domain.Wallet) error {
+	err := r.next.Update(ctx, wallet)
+	r.recorder.record(ctx, Entry{Kind: KindRepositoryWrite, UserID: wallet.UserID, Wallet: toWalletRecord(wallet), Error: errorString(err)})
+
+	return err
+}
+
+// UpdateAll records a write per wallet, all of them with the outcome of the batch
+func (r *recordingRepository) UpdateAll(ctx context.Context, wallets []domain.Wallet) error {
+	err := r.next.UpdateAll(ctx, wallets)
+	for _, wallet := range wallets {
+		r.recorder.record(ctx, Entry{Kind: KindRepositoryWrite, UserID: wallet.UserID, Wallet: toWalletRecord(wallet), Error: errorString(err)})
+	}
+
+	return err
+}
+
+// UpdateAndCredit records the write of the updated wallet and, when it succeeds, the credited
+// wallet as written. The handler never reads the credited wallet, so its state before the credit
+// is recorded as a read for a replay to seed it
+func (r *recordingRepository) UpdateAndCredit(ctx context.Context, wallet domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	credited, err := r.next.UpdateAndCredit(ctx, wallet, credit)
+	r.recordCredit(ctx, []domain.Wallet{wallet}, credit, credited, err)
+
+	return credited, err
+}
+
+// UpdateAllAndCredit records the batch as UpdateAll does and the credit as UpdateAndCredit does
+func (r *recordingRepository) UpdateAllAndCredit(ctx context.Context, wallets []domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	credited, err := r.next.UpdateAllAndCredit(ctx, wallets, credit)
+	r.recordCredit(ctx, wallets, credit, credited, err)
+
+	return credited, err
+}
+
+func (r *recordingRepository) recordCredit(ctx context.Context, wallets []domain.Wallet, credit ports.Credit, credited domain.Wallet, err error) {
+	if err == nil {
+		before := credited
+		before.CreditIn(credit.Currency, -credit.Amount)
+		before.Version--
+		r.recorder.record(ctx, Entry{Kind: KindRepositoryGet, UserID: credit.UserID, Wallet: toWalletRecord(before)})
+	}
+	for _, wallet := range wallets {
+		r.recorder.record(ctx, Entry{Kind: KindRepositoryWrite, UserID: wallet.UserID, Wallet: toWalletRecord(wallet), Error: errorString(err)})
+	}
+	if err == nil {
+		r.recorder.record(ctx, Entry{Kind: KindRepositoryWrite, UserID: credited.UserID, Wallet: toWalletRecord(credited)})
+	}
+}
+
+type recordingEventBus struct {
+	next     ports.EventBusProcessor
+	recorder *Recorder
+}
+
+func (b *recordingEventBus) Publish(ctx context.Context, req ports.BalanceDebitedRequest) error {
+	err := b.next.Publish(ctx, req)
+	b.recorder.record(ctx, Entry{Kind: KindEventPublished, UserID: req.UserID, Event: &req, Error: errorString(err)})
+
+	return err
+}
+
+func invocationID(ctx context.Context) string {
+	id, _ := ctx.Value(invocationKey{}).(string)
+	return id
+}
+
+func failedIDs(response events.SQSEventResponse) []string {
+	var ids []string
+	for _, failure := range response.BatchItemFailures {
+		ids = append(ids, failure.ItemIdentifier)
+	}
+	return ids
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{enc: json.NewEncoder(w), now: time.Now}
+}
@@ -0,0 +1,204 @@
+package recorder_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	_events "github.com/payment-processor/internal/debit/domain/events"
+	"github.com/payment-processor/internal/debit/infra/bus"
+	"github.com/payment-processor/internal/debit/infra/handler"
+	"github.com/payment-processor/internal/debit/infra/recorder"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should record input, repository access and published events", testRecorderCapture)
+	t.Run("should replay a capture without differences", testRecorderReplayMatches)
+	t.Run("should replay a failed invocation without differences", testRecorderReplayError)
+	t.Run("should report differences against a modified capture", testRecorderReplayDiff)
+	t.Run("should report a difference in any field of the event", testRecorderReplayDiffAnyField)
+}
+
+func testRecorderCapture(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var capture bytes.Buffer
+	h := buildRecordedHandler(recorder.NewRecorder(&capture), domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+
+	// WHEN
+	response, err := h.Handle(context.Background(), createSQSEvent(t, "user-1", 30))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Empty(t, response.BatchItemFailures)
+
+	invocations, err := recorder.ReadCapture(&capture)
+	require.NoError(t, err)
+	require.Len(t, invocations, 1)
+
+	inv := invocations[0]
+	assert.NotEmpty(t, inv.ID)
+	assert.Len(t, inv.Input.Records, 1)
+	assert.Equal(t, []domain.Wallet{{UserID: "user-1", Amount: 100, Version: 1}}, inv.InitialState())
+	require.Len(t, inv.Writes, 1)
+	assert.Equal(t, domain.Amount(70), inv.Writes[0].Wallet.Amount)
+	require.Len(t, inv.Events, 1)
+	assert.Equal(t, domain.Amount(70), inv.Events[0].AmountLeft)
+	assert.Empty(t, inv.Error)
+	assert.Empty(t, inv.Failures)
+}
+
+func testRecorderReplayMatches(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var capture bytes.Buffer
+	h := buildRecordedHandler(recorder.NewRecorder(&capture), domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	handle(t, h, createSQSEvent(t, "user-1", 30))
+	handle(t, h, createSQSEvent(t, "user-1", 20))
+
+	invocations, err := recorder.ReadCapture(&capture)
+	require.NoError(t, err)
+	require.Len(t, invocations, 2)
+
+	for _, inv := range invocations {
+		// WHEN
+		result, err := recorder.Replay(context.Background(), inv, buildHandler)
+		require.NoError(t, err)
+
+		// THEN
+		assert.True(t, result.Matches(), result.Diffs)
+		assert.Len(t, result.Produced, 1)
+	}
+}
+
+func testRecorderReplayError(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var capture bytes.Buffer
+	h := buildRecordedHandler(recorder.NewRecorder(&capture), domain.Wallet{UserID: "user-1", Amount: 10, Version: 1})
+	response := handle(t, h, createSQSEvent(t, "user-1", 30))
+	require.Len(t, response.BatchItemFailures, 1)
+
+	invocations, err := recorder.ReadCapture(&capture)
+	require.NoError(t, err)
+	require.Len(t, invocations, 1)
+
+	// WHEN
+	result, err := recorder.Replay(context.Background(), invocations[0], buildHandler)
+	require.NoError(t, err)
+
+	// THEN
+	assert.True(t, result.Matches(), result.Diffs)
+	assert.Equal(t, []string{"msg-1"}, result.ProducedFailures)
+}
+
+func testRecorderReplayDiff(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var capture bytes.Buffer
+	h := buildRecordedHandler(recorder.NewRecorder(&capture), domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	handle(t, h, createSQSEvent(t, "user-1", 30))
+
+	tampered := strings.ReplaceAll(capture.String(), `"AmountLeft":70`, `"AmountLeft":75`)
+	invocations, err := recorder.ReadCapture(strings.NewReader(tampered))
+	require.NoError(t, err)
+
+	// WHEN
+	result, err := recorder.Replay(context.Background(), invocations[0], buildHandler)
+	require.NoError(t, err)
+
+	// THEN
+	assert.False(t, result.Matches())
+	assert.Equal(t, []string{"event[0].AmountLeft: recorded 75, produced 70"}, result.Diffs)
+}
+
+func testRecorderReplayDiffAnyField(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		recorded string
+		tampered string
+		diff     string
+	}{
+		"payment id": {recorded: `"PaymentID":"pay-1"`, tampered: `"PaymentID":"pay-2"`, diff: `event[0].PaymentID: recorded "pay-2", produced "pay-1"`},
+		"rate":       {recorded: `"Rate":0`, tampered: `"Rate":1.1`, diff: `event[0].Rate: recorded 1.1, produced 0`},
+		"balances":   {recorded: `"Balances":null`, tampered: `"Balances":{"USD":5}`, diff: `event[0].Balances: recorded {"USD":5}, produced null`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			var capture bytes.Buffer
+			h := buildRecordedHandler(recorder.NewRecorder(&capture), domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+			handle(t, h, createPaymentSQSEvent(t, "pay-1", "user-1", 30))
+
+			require.Contains(t, capture.String(), tt.recorded)
+			tampered := strings.ReplaceAll(capture.String(), tt.recorded, tt.tampered)
+			invocations, err := recorder.ReadCapture(strings.NewReader(tampered))
+			require.NoError(t, err)
+
+			// WHEN
+			result, err := recorder.Replay(context.Background(), invocations[0], buildHandler)
+			require.NoError(t, err)
+
+			// THEN
+			assert.Equal(t, []string{tt.diff}, result.Diffs)
+		})
+	}
+}
+
+// --- Helper Functions ---
+
+func buildHandler(repo ports.WalletRepository, eventBus ports.EventBusProcessor) (recorder.Handler, error) {
+	return handler.NewSQSHandler(application.NewDebitBalanceUseCaseHandler(repo, eventBus)), nil
+}
+
+func buildRecordedHandler(rec *recorder.Recorder, wallets ...domain.Wallet) recorder.Handler {
+	repo := rec.Repository(repository.NewInMemoryWalletRepositoryWith(wallets...))
+	eventBus := rec.EventBus(bus.NewInMemoryEventBus())
+
+	return rec.Handler(handler.NewSQSHandler(application.NewDebitBalanceUseCaseHandler(repo, eventBus)))
+}
+
+func handle(t *testing.T, h recorder.Handler, sqsEvent events.SQSEvent) events.SQSEventResponse {
+	t.Helper()
+
+	response, err := h.Handle(context.Background(), sqsEvent)
+	require.NoError(t, err)
+	return response
+}
+
+func createSQSEvent(t *testing.T, userID domain.UserID, amount domain.Amount) events.SQSEvent {
+	t.Helper()
+
+	return createPaymentSQSEvent(t, "", userID, amount)
+}
+
+func createPaymentSQSEvent(t *testing.T, paymentID string, userID domain.UserID, amount domain.Amount) events.SQSEvent {
+	t.Helper()
+
+	body, err := json.Marshal(_events.PaymentInitEvent{
+		Header:  _events.EventHeader{CorrelationID: "corr-id"},
+		Payload: _events.PaymentInitPayload{PaymentID: paymentID, UserID: userID, Amount: amount},
+	})
+	require.NoError(t, err)
+
+	return events.SQSEvent{Records: []events.SQSMessage{{MessageId: "msg-1", Body: string(body)}}}
+}
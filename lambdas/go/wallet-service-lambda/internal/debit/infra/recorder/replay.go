@@ -0,0 +1,244 @@
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+)
+
+const maxEntrySize = 10 * 1024 * 1024
+
+// Invocation groups every entry recorded for a single handler invocation
+type Invocation struct {
+	ID     string
+	Input  events.SQSEvent
+	Reads  []Entry
+	Writes []Entry
+	Events []ports.BalanceDebitedRequest
+	Error  string
+	// Failures are the message ids reported back to SQS as batch item failures
+	Failures []string
+}
+
+// InitialState returns the wallets as they were first read during the invocation, each one in
+// the tenant it was read in
+func (i Invocation) InitialState() []domain.Wallet {
+	type walletKey struct {
+		tenant domain.TenantID
+		user   domain.UserID
+	}
+
+	seen := make(map[walletKey]bool)
+	var wallets []domain.Wallet
+
+	for _, read := range i.Reads {
+		key := walletKey{tenant: read.TenantID, user: read.UserID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if read.Wallet != nil {
+			wallet := read.Wallet.toDomain()
+			wallet.TenantID = read.TenantID
+			wallets = append(wallets, wallet)
+		}
+	}
+
+	return wallets
+}
+
+// ReadCapture parses a JSONL capture and groups its entries by invocation, keeping the recorded order
+func ReadCapture(r io.Reader) ([]Invocation, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEntrySize)
+
+	var invocations []*Invocation
+	byID := make(map[string]*Invocation)
+
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("capture line %d: %w", line, err)
+		}
+
+		inv, ok := byID[entry.InvocationID]
+		if !ok {
+			inv = &Invocation{ID: entry.InvocationID}
+			byID[entry.InvocationID] = inv
+			invocations = append(invocations, inv)
+		}
+
+		switch entry.Kind {
+		case KindInvocation:
+			if entry.Input != nil {
+				inv.Input = *entry.Input
+			}
+		case KindRepositoryGet:
+			inv.Reads = append(inv.Reads, entry)
+		case KindRepositoryWrite:
+			inv.Writes = append(inv.Writes, entry)
+		case KindEventPublished:
+			if entry.Event != nil && entry.Error == "" {
+				inv.Events = append(inv.Events, *entry.Event)
+			}
+		case KindResult:
+			inv.Error = entry.Error
+			inv.Failures = entry.Failures
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]Invocation, 0, len(invocations))
+	for _, inv := range invocations {
+		result = append(result, *inv)
+	}
+
+	return result, nil
+}
+
+// HandlerFactory builds the handler under replay on top of the given adapters
+type HandlerFactory func(ports.WalletRepository, ports.EventBusProcessor) (Handler, error)
+
+type ReplayResult struct {
+	InvocationID     string
+	Expected         []ports.BalanceDebitedRequest
+	Produced         []ports.BalanceDebitedRequest
+	ExpectedError    string
+	ProducedError    string
+	ExpectedFailures []string
+	ProducedFailures []string
+	Diffs            []string
+}
+
+func (r ReplayResult) Matches() bool { return len(r.Diffs) == 0 }
+
+// Replay feeds the recorded input back through a fresh handler seeded with the recorded
+// repository state and compares the produced events against the recorded ones. It only fails
+// when the handler cannot be built
+func Replay(ctx context.Context, inv Invocation, build HandlerFactory) (ReplayResult, error) {
+	repo := repository.NewInMemoryWalletRepositoryWith(inv.InitialState()...)
+	bus := &captureBus{}
+
+	h, err := build(repo, bus)
+	if err != nil {
+		return ReplayResult{}, err
+	}
+	response, err := h.Handle(ctx, inv.Input)
+
+	result := ReplayResult{
+		InvocationID:     inv.ID,
+		Expected:         byUser(inv.Events),
+		Produced:         byUser(bus.requests),
+		ExpectedError:    inv.Error,
+		ProducedError:    errorString(err),
+		ExpectedFailures: inv.Failures,
+		ProducedFailures: failedIDs(response),
+	}
+	result.Diffs = diff(result)
+
+	return result, nil
+}
+
+func diff(r ReplayResult) []string {
+	var diffs []string
+
+	if r.ExpectedError != r.ProducedError {
+		diffs = append(diffs, fmt.Sprintf("error: recorded %q, produced %q", r.ExpectedError, r.ProducedError))
+	}
+
+	if !slices.Equal(r.ExpectedFailures, r.ProducedFailures) {
+		diffs = append(diffs, fmt.Sprintf("batch item failures: recorded %v, produced %v", r.ExpectedFailures, r.ProducedFailures))
+	}
+
+	if len(r.Expected) != len(r.Produced) {
+		diffs = append(diffs, fmt.Sprintf("events: recorded %d, produced %d", len(r.Expected), len(r.Produced)))
+	}
+
+	for i := 0; i < min(len(r.Expected), len(r.Produced)); i++ {
+		diffs = append(diffs, diffEvent(i, r.Expected[i], r.Produced[i])...)
+	}
+
+	return diffs
+}
+
+// diffEvent compares every field of the events as they are written to the capture, so a field
+// added to the event is compared without changes here
+func diffEvent(i int, expected, produced ports.BalanceDebitedRequest) []string {
+	recorded, err := fields(expected)
+	if err != nil {
+		return []string{fmt.Sprintf("event[%d]: %v", i, err)}
+	}
+	replayed, err := fields(produced)
+	if err != nil {
+		return []string{fmt.Sprintf("event[%d]: %v", i, err)}
+	}
+
+	names := slices.Sorted(maps.Keys(recorded))
+	for name := range replayed {
+		if _, ok := recorded[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	var diffs []string
+	for _, name := range names {
+		if !bytes.Equal(recorded[name], replayed[name]) {
+			diffs = append(diffs, fmt.Sprintf("event[%d].%s: recorded %s, produced %s", i, name, recorded[name], replayed[name]))
+		}
+	}
+	return diffs
+}
+
+func fields(req ports.BalanceDebitedRequest) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+// byUser orders the events by user keeping the order of each user, users may be processed
+// concurrently so only the order within a user is deterministic
+func byUser(requests []ports.BalanceDebitedRequest) []ports.BalanceDebitedRequest {
+	sorted := slices.Clone(requests)
+	slices.SortStableFunc(sorted, func(a, b ports.BalanceDebitedRequest) int {
+		return strings.Compare(string(a.UserID), string(b.UserID))
+	})
+	return sorted
+}
+
+type captureBus struct {
+	mu       sync.Mutex
+	requests []ports.BalanceDebitedRequest
+}
+
+func (b *captureBus) Publish(_ context.Context, req ports.BalanceDebitedRequest) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.requests = append(b.requests, req)
+	return nil
+}
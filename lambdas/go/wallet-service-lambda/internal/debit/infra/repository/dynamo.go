@@ -3,66 +3,214 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
 	"sync"
 
+	"github.com/payment-processor/internal/debit/application/ports"
 	"github.com/payment-processor/internal/debit/domain"
 )
 
 var (
 	ErrWalletNotFound  = errors.New("wallet not found")
 	ErrVersionMismatch = errors.New("optimistic lock failed: version mismatch")
+	// ErrDuplicateWallet rejects a batch updating the same wallet twice
+	ErrDuplicateWallet = errors.New("wallet updated twice in the same batch")
+	// ErrPaymentApplied rejects an update carrying a payment the wallet already applied
+	ErrPaymentApplied = errors.New("payment already applied to the wallet")
 )
 
-type InMemoryWalletRepository struct {
-	mu      sync.Mutex
-	wallets map[domain.UserID]domain.Wallet
+type (
+	// InMemoryWalletRepository copies the balances of the wallets going in and out, so callers never
+	// share a map with the stored wallets. Wallets are keyed by the tenant of the context and the
+	// user, a tenant never reaches the wallets of another one
+	InMemoryWalletRepository struct {
+		mu       sync.Mutex
+		wallets  map[walletKey]domain.Wallet
+		payments appliedPayments
+	}
+
+	walletKey struct {
+		tenant domain.TenantID
+		user   domain.UserID
+	}
+
+	// appliedPayments holds the ids of the payments applied to every wallet
+	appliedPayments map[walletKey]map[string]bool
+)
+
+func (p appliedPayments) has(key walletKey, payment string) bool {
+	return payment != "" && p[key][payment]
+}
+
+func (p appliedPayments) add(key walletKey, payment string) {
+	if payment == "" {
+		return
+	}
+	if p[key] == nil {
+		p[key] = make(map[string]bool)
+	}
+	p[key][payment] = true
 }
 
-func (r *InMemoryWalletRepository) Get(_ context.Context, userID domain.UserID) (domain.Wallet, error) {
+func keyOf(ctx context.Context, userID domain.UserID) walletKey {
+	return walletKey{tenant: domain.TenantFrom(ctx), user: userID}
+}
+
+func (r *InMemoryWalletRepository) Get(ctx context.Context, userID domain.UserID) (domain.Wallet, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	wallet, ok := r.wallets[userID]
+	wallet, ok := r.wallets[keyOf(ctx, userID)]
 	if !ok {
 		return domain.Wallet{}, ErrWalletNotFound
 	}
 
+	wallet.Balances = maps.Clone(wallet.Balances)
 	return wallet, nil
 }
 
-func (r *InMemoryWalletRepository) Update(_ context.Context, walletToUpdate domain.Wallet) error {
+// Update is a batch of a single wallet
+func (r *InMemoryWalletRepository) Update(ctx context.Context, walletToUpdate domain.Wallet) error {
+	return r.UpdateAll(ctx, []domain.Wallet{walletToUpdate})
+}
+
+// UpdateAll checks every version before writing any wallet
+func (r *InMemoryWalletRepository) UpdateAll(ctx context.Context, walletsToUpdate []domain.Wallet) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	currentWallet, ok := r.wallets[walletToUpdate.UserID]
-	if !ok {
-		return ErrWalletNotFound
+	if err := checkVersions(ctx, r.wallets, r.payments, walletsToUpdate); err != nil {
+		return err
 	}
 
-	// Optimistic Blocking
-	if currentWallet.Version != walletToUpdate.Version {
-		return ErrVersionMismatch
+	for _, wallet := range walletsToUpdate {
+		wallet.TenantID = domain.TenantFrom(ctx)
+		wallet.Version++
+		r.store(wallet)
 	}
 
-	walletToUpdate.Version++
-	r.wallets[walletToUpdate.UserID] = walletToUpdate
+	return nil
+}
+
+// store keeps a copy of the wallet as written and the payment it applies
+func (r *InMemoryWalletRepository) store(wallet domain.Wallet) {
+	key := walletKey{tenant: wallet.TenantID, user: wallet.UserID}
+	r.payments.add(key, wallet.Payment)
+
+	wallet.Payment = ""
+	wallet.Balances = maps.Clone(wallet.Balances)
+	r.wallets[key] = wallet
+}
+
+// checkVersions runs the optimistic lock of Update over a whole batch and rejects the payments
+// already applied
+func checkVersions(ctx context.Context, current map[walletKey]domain.Wallet, applied appliedPayments, walletsToUpdate []domain.Wallet) error {
+	seen := make(map[domain.UserID]bool, len(walletsToUpdate))
+	for _, wallet := range walletsToUpdate {
+		if seen[wallet.UserID] {
+			return fmt.Errorf("%w: %s", ErrDuplicateWallet, wallet.UserID)
+		}
+		seen[wallet.UserID] = true
+
+		key := keyOf(ctx, wallet.UserID)
+		currentWallet, ok := current[key]
+		if !ok {
+			return ErrWalletNotFound
+		}
+		// Optimistic Blocking
+		if currentWallet.Version != wallet.Version {
+			return ErrVersionMismatch
+		}
+		if applied.has(key, wallet.Payment) {
+			return fmt.Errorf("%w: %s", ErrPaymentApplied, wallet.Payment)
+		}
+	}
 
 	return nil
 }
 
+func (r *InMemoryWalletRepository) UpdateAndCredit(ctx context.Context, walletToUpdate domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	return r.UpdateAllAndCredit(ctx, []domain.Wallet{walletToUpdate}, credit)
+}
+
+func (r *InMemoryWalletRepository) UpdateAllAndCredit(ctx context.Context, walletsToUpdate []domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	written, err := applyCredit(ctx, r.wallets, r.payments, walletsToUpdate, credit)
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+
+	for _, wallet := range written {
+		r.store(wallet)
+	}
+
+	credited := written[len(written)-1]
+	credited.Balances = maps.Clone(credited.Balances)
+	return credited, nil
+}
+
+// applyCredit returns the updated wallets and, last, the credited one as they have to be stored.
+// Only the updated wallets have their version checked, the credit is added to the current balance
+func applyCredit(ctx context.Context, current map[walletKey]domain.Wallet, applied appliedPayments, walletsToUpdate []domain.Wallet, credit ports.Credit) ([]domain.Wallet, error) {
+	for _, wallet := range walletsToUpdate {
+		if credit.UserID == wallet.UserID {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateWallet, credit.UserID)
+		}
+	}
+	if err := checkVersions(ctx, current, applied, walletsToUpdate); err != nil {
+		return nil, err
+	}
+
+	credited, ok := current[keyOf(ctx, credit.UserID)]
+	if !ok {
+		return nil, ErrWalletNotFound
+	}
+	credited.CreditIn(credit.Currency, credit.Amount)
+
+	written := append(slices.Clone(walletsToUpdate), credited)
+	for i := range written {
+		written[i].TenantID = domain.TenantFrom(ctx)
+		written[i].Version++
+		written[i].Balances = maps.Clone(written[i].Balances)
+	}
+	return written, nil
+}
+
+// Wallets returns a snapshot of the stored wallets of every tenant sorted by tenant and user id
+func (r *InMemoryWalletRepository) Wallets() []domain.Wallet {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return sortedWallets(r.wallets)
+}
+
+// NewInMemoryWalletRepositoryWith builds a repository holding only the given wallets, each one in
+// its TenantID
+func NewInMemoryWalletRepositoryWith(wallets ...domain.Wallet) *InMemoryWalletRepository {
+	repo := &InMemoryWalletRepository{wallets: make(map[walletKey]domain.Wallet, len(wallets)), payments: make(appliedPayments)}
+	for _, wallet := range wallets {
+		wallet.Balances = maps.Clone(wallet.Balances)
+		repo.wallets[walletKey{tenant: wallet.TenantID, user: wallet.UserID}] = wallet
+	}
+
+	return repo
+}
+
 func NewInMemoryWalletRepository() *InMemoryWalletRepository {
-	return &InMemoryWalletRepository{
-		wallets: map[domain.UserID]domain.Wallet{
-			"user-123": {
-				UserID:  "user-123",
-				Amount:  100.00,
-				Version: 1, // Versión inicial
-			},
-			"user-456": {
-				UserID:  "user-456",
-				Amount:  50.00,
-				Version: 1,
-			},
+	return NewInMemoryWalletRepositoryWith(
+		domain.Wallet{
+			UserID:  "user-123",
+			Amount:  100.00,
+			Version: 1, // Versión inicial
 		},
-	}
+		domain.Wallet{
+			UserID:  "user-456",
+			Amount:  50.00,
+			Version: 1,
+		},
+	)
 }
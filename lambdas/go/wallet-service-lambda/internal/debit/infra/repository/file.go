@@ -0,0 +1,481 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+const (
+	walFileName      = "wallets.wal"
+	snapshotFileName = "wallets.snapshot.json"
+
+	// every log record is framed as payload length + crc32 of the payload + payload
+	walHeaderSize = 8
+	// maxRecordSize bounds every record, so a larger length read back can only come from a
+	// corrupted header
+	maxRecordSize = 1 << 16
+)
+
+var (
+	ErrRepositoryClosed = errors.New("wallet repository is closed")
+	ErrRecordTooLarge   = errors.New("wallet log record too large")
+)
+
+type (
+	// FileWalletRepository keeps the wallets in memory and persists every update to an append-only
+	// write-ahead log before acknowledging it. The log is periodically folded into a snapshot file.
+	// Wallets are keyed by tenant and user as in InMemoryWalletRepository
+	FileWalletRepository struct {
+		mu           sync.Mutex
+		dir          string
+		wal          *os.File
+		wallets      map[walletKey]domain.Wallet
+		payments     appliedPayments
+		pending      int
+		compactEvery int
+		closed       bool
+	}
+
+	FileOption func(*FileWalletRepository)
+)
+
+// WithCompactEvery folds the log into the snapshot after n updates, zero disables compaction
+func WithCompactEvery(n int) FileOption {
+	return func(r *FileWalletRepository) { r.compactEvery = n }
+}
+
+func (r *FileWalletRepository) Get(ctx context.Context, userID domain.UserID) (domain.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return domain.Wallet{}, ErrRepositoryClosed
+	}
+
+	wallet, ok := r.wallets[keyOf(ctx, userID)]
+	if !ok {
+		return domain.Wallet{}, ErrWalletNotFound
+	}
+
+	wallet.Balances = maps.Clone(wallet.Balances)
+	return wallet, nil
+}
+
+// Update has the same optimistic version check as InMemoryWalletRepository.Update.
+// The new state is visible only after it is synced to the log
+func (r *FileWalletRepository) Update(ctx context.Context, walletToUpdate domain.Wallet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrRepositoryClosed
+	}
+
+	if err := checkVersions(ctx, r.wallets, r.payments, []domain.Wallet{walletToUpdate}); err != nil {
+		return err
+	}
+
+	walletToUpdate.TenantID = domain.TenantFrom(ctx)
+	walletToUpdate.Version++
+	record := toLogRecord(walletToUpdate)
+	if err := r.append(record); err != nil {
+		return err
+	}
+	r.store(record)
+
+	r.compactIfDue()
+	return nil
+}
+
+// toLogRecord keeps the payment applied by the update, so replaying the log restores it
+func toLogRecord(wallet domain.Wallet) WalletRecord {
+	record := toWalletRecord(wallet)
+	record.PaymentID = wallet.Payment
+	return record
+}
+
+func (r *FileWalletRepository) store(record WalletRecord) {
+	r.wallets[record.key()] = record.toDomain()
+	r.payments.add(record.key(), record.PaymentID)
+}
+
+// compactIfDue runs after an update is already durable, a failed compaction only leaves a longer log
+func (r *FileWalletRepository) compactIfDue() {
+	if r.compactEvery == 0 || r.pending < r.compactEvery {
+		return
+	}
+	if err := r.compact(); err != nil {
+		slog.Error("failed to compact wallet log", "dir", r.dir, "error", err)
+	}
+}
+
+// UpdateAll writes the whole batch as a single log record, so a crash never leaves only some of
+// the wallets updated
+func (r *FileWalletRepository) UpdateAll(ctx context.Context, walletsToUpdate []domain.Wallet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrRepositoryClosed
+	}
+
+	if err := checkVersions(ctx, r.wallets, r.payments, walletsToUpdate); err != nil {
+		return err
+	}
+
+	records := make([]WalletRecord, 0, len(walletsToUpdate))
+	for _, wallet := range walletsToUpdate {
+		wallet.TenantID = domain.TenantFrom(ctx)
+		wallet.Version++
+		records = append(records, toLogRecord(wallet))
+	}
+	if err := r.append(records); err != nil {
+		return err
+	}
+	for _, record := range records {
+		r.store(record)
+	}
+
+	r.compactIfDue()
+	return nil
+}
+
+func (r *FileWalletRepository) UpdateAndCredit(ctx context.Context, walletToUpdate domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	return r.UpdateAllAndCredit(ctx, []domain.Wallet{walletToUpdate}, credit)
+}
+
+// UpdateAllAndCredit writes the batch and the credited wallet as a single log record, as UpdateAll does
+func (r *FileWalletRepository) UpdateAllAndCredit(ctx context.Context, walletsToUpdate []domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return domain.Wallet{}, ErrRepositoryClosed
+	}
+
+	written, err := applyCredit(ctx, r.wallets, r.payments, walletsToUpdate, credit)
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+
+	records := make([]WalletRecord, 0, len(written))
+	for _, wallet := range written {
+		records = append(records, toLogRecord(wallet))
+	}
+	if err = r.append(records); err != nil {
+		return domain.Wallet{}, err
+	}
+	for _, record := range records {
+		r.store(record)
+	}
+
+	r.compactIfDue()
+	return records[len(records)-1].toDomain(), nil
+}
+
+// Seed stores the given wallets as they are, each one in its TenantID, overwriting existing ones.
+// Meant for fixtures
+func (r *FileWalletRepository) Seed(wallets ...domain.Wallet) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrRepositoryClosed
+	}
+
+	for _, wallet := range wallets {
+		wallet.Balances = maps.Clone(wallet.Balances)
+		r.wallets[walletKey{tenant: wallet.TenantID, user: wallet.UserID}] = wallet
+	}
+
+	return r.compact()
+}
+
+// Wallets returns a snapshot of the stored wallets of every tenant sorted by tenant and user id
+func (r *FileWalletRepository) Wallets() []domain.Wallet {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return sortedWallets(r.wallets)
+}
+
+// Compact writes the current state to the snapshot file and empties the log
+func (r *FileWalletRepository) Compact() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return ErrRepositoryClosed
+	}
+
+	return r.compact()
+}
+
+func (r *FileWalletRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+
+	return r.wal.Close()
+}
+
+// append frames a single wallet record, or a JSON array of them for a batch. A record over
+// maxRecordSize is rejected, it could not be read back
+func (r *FileWalletRepository) append(record any) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxRecordSize {
+		return fmt.Errorf("%w: %d bytes, at most %d", ErrRecordTooLarge, len(payload), maxRecordSize)
+	}
+
+	frame := make([]byte, walHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+	copy(frame[walHeaderSize:], payload)
+
+	offset, err := r.wal.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	if _, err = r.wal.Write(frame); err == nil {
+		err = r.wal.Sync()
+	}
+	if err != nil {
+		// drop the partial record so later appends don't land behind a torn one
+		_ = r.wal.Truncate(offset)
+		_, _ = r.wal.Seek(offset, io.SeekStart)
+		return fmt.Errorf("failed to append to wallet log: %w", err)
+	}
+
+	r.pending++
+	return nil
+}
+
+// compact replaces the snapshot atomically and only then truncates the log. A crash in between
+// leaves records already contained in the snapshot, which replay skips by version
+func (r *FileWalletRepository) compact() error {
+	snapshot := Snapshot{TakenAt: time.Now().UTC(), Payments: r.payments.records()}
+	for _, wallet := range sortedWallets(r.wallets) {
+		snapshot.Wallets = append(snapshot.Wallets, toWalletRecord(wallet))
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if err = writeFileSync(filepath.Join(r.dir, snapshotFileName), data); err != nil {
+		return fmt.Errorf("failed to write wallet snapshot: %w", err)
+	}
+
+	if err = r.wal.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate wallet log: %w", err)
+	}
+	if _, err = r.wal.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err = r.wal.Sync(); err != nil {
+		return fmt.Errorf("failed to sync wallet log: %w", err)
+	}
+
+	r.pending = 0
+	return nil
+}
+
+// recover loads the snapshot and replays the log on top of it. A torn or corrupted tail, left by
+// a crash in the middle of a write, is cut off so new records are appended after the last good one
+func (r *FileWalletRepository) recover() error {
+	data, err := os.ReadFile(filepath.Join(r.dir, snapshotFileName))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		var snapshot Snapshot
+		if err = json.Unmarshal(data, &snapshot); err != nil {
+			return fmt.Errorf("corrupted wallet snapshot: %w", err)
+		}
+		for _, record := range snapshot.Wallets {
+			r.wallets[record.key()] = record.toDomain()
+		}
+		for _, payment := range snapshot.Payments {
+			r.payments.add(payment.key(), payment.PaymentID)
+		}
+	}
+
+	if _, err = r.wal.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(r.wal)
+	var valid int64
+	for {
+		records, size, err := readWALRecord(reader)
+		if err != nil {
+			break
+		}
+
+		// records older than the snapshot survive a crash during compaction
+		for _, record := range records {
+			if current, ok := r.wallets[record.key()]; !ok || record.Version > current.Version {
+				r.wallets[record.key()] = record.toDomain()
+			}
+			r.payments.add(record.key(), record.PaymentID)
+		}
+		valid += size
+		r.pending++
+	}
+
+	info, err := r.wal.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() > valid {
+		slog.Warn("discarding torn wallet log tail", "dir", r.dir, "bytes", info.Size()-valid)
+		if err = r.wal.Truncate(valid); err != nil {
+			return err
+		}
+		if err = r.wal.Sync(); err != nil {
+			return err
+		}
+	}
+
+	_, err = r.wal.Seek(valid, io.SeekStart)
+	return err
+}
+
+// readWALRecord returns the wallets of a frame, several when it holds a batch
+func readWALRecord(reader io.Reader) ([]WalletRecord, int64, error) {
+	var header [walHeaderSize]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, 0, err
+	}
+
+	size := binary.BigEndian.Uint32(header[0:4])
+	if size > maxRecordSize {
+		return nil, 0, ErrRecordTooLarge
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, errors.New("wallet log record checksum mismatch")
+	}
+
+	var records []WalletRecord
+	if len(payload) > 0 && payload[0] == '[' {
+		if err := json.Unmarshal(payload, &records); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		var record WalletRecord
+		if err := json.Unmarshal(payload, &record); err != nil {
+			return nil, 0, err
+		}
+		records = append(records, record)
+	}
+
+	return records, int64(walHeaderSize + len(payload)), nil
+}
+
+// writeFileSync writes through a synced temp file renamed over path, then syncs the directory
+// so the rename itself survives a crash
+func writeFileSync(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+
+	return dir.Sync()
+}
+
+func sortedWallets(wallets map[walletKey]domain.Wallet) []domain.Wallet {
+	sorted := make([]domain.Wallet, 0, len(wallets))
+	for _, wallet := range wallets {
+		sorted = append(sorted, wallet)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].TenantID != sorted[j].TenantID {
+			return sorted[i].TenantID < sorted[j].TenantID
+		}
+		return sorted[i].UserID < sorted[j].UserID
+	})
+	return sorted
+}
+
+// NewFileWalletRepository opens or creates the store in dir and recovers its state
+func NewFileWalletRepository(dir string, opts ...FileOption) (*FileWalletRepository, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	wal, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &FileWalletRepository{
+		dir:          dir,
+		wal:          wal,
+		wallets:      make(map[walletKey]domain.Wallet),
+		payments:     make(appliedPayments),
+		compactEvery: 1000,
+	}
+	for _, opt := range opts {
+		opt(repo)
+	}
+
+	if err = repo.recover(); err != nil {
+		wal.Close()
+		return nil, fmt.Errorf("failed to recover wallet store in %s: %w", dir, err)
+	}
+
+	return repo, nil
+}
@@ -0,0 +1,452 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWalletRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should persist updates across reopen", testFileRepository_Reopen)
+	t.Run("should reject stale versions", testFileRepository_VersionMismatch)
+	t.Run("should return not found for unknown wallets", testFileRepository_NotFound)
+	t.Run("should keep the state after compaction", testFileRepository_Compaction)
+	t.Run("should recover from a write torn at any byte", testFileRepository_TornWrite)
+	t.Run("should skip log records already folded into the snapshot", testFileRepository_CrashDuringCompaction)
+	t.Run("should fail after close", testFileRepository_Closed)
+	t.Run("should persist a batch across reopen", testFileRepository_UpdateAll)
+	t.Run("should write nothing when a wallet of the batch is stale", testFileRepository_UpdateAllVersionMismatch)
+	t.Run("should recover a batch torn at any byte as not applied", testFileRepository_UpdateAllTornWrite)
+	t.Run("should persist the balance of every currency", testFileRepository_Balances)
+	t.Run("should persist a credit without checking its version", testFileRepository_UpdateAndCredit)
+	t.Run("should reject a payment applied before reopen", testFileRepository_PaymentApplied)
+	t.Run("should keep the wallets of each tenant apart across reopen", testFileRepository_Tenants)
+	t.Run("should reject a record too large to be read back", testFileRepository_RecordTooLarge)
+}
+
+func testFileRepository_Reopen(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	dir := t.TempDir()
+	repo := openSeeded(t, dir, repository.WithCompactEvery(0))
+	debit(t, repo, "user-1", 10)
+	debit(t, repo, "user-1", 5)
+	require.NoError(t, repo.Close())
+
+	// WHEN
+	reopened := open(t, dir)
+
+	// THEN
+	wallet, err := reopened.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Wallet{UserID: "user-1", Amount: 85, Version: 3}, wallet)
+}
+
+func testFileRepository_VersionMismatch(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := openSeeded(t, t.TempDir())
+	stale, err := repo.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	debit(t, repo, "user-1", 10)
+
+	// WHEN
+	err = repo.Update(context.Background(), stale)
+
+	// THEN
+	assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+}
+
+func testFileRepository_NotFound(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := openSeeded(t, t.TempDir())
+
+	// WHEN
+	_, getErr := repo.Get(context.Background(), "user-9")
+	updateErr := repo.Update(context.Background(), domain.Wallet{UserID: "user-9", Version: 1})
+
+	// THEN
+	assert.ErrorIs(t, getErr, repository.ErrWalletNotFound)
+	assert.ErrorIs(t, updateErr, repository.ErrWalletNotFound)
+}
+
+func testFileRepository_Compaction(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	dir := t.TempDir()
+	repo := openSeeded(t, dir, repository.WithCompactEvery(3))
+
+	// WHEN
+	for range 6 {
+		debit(t, repo, "user-1", 1)
+	}
+	require.NoError(t, repo.Close())
+
+	// THEN
+	assert.Zero(t, walSize(t, dir), "the log is emptied by every compaction")
+	wallet, err := open(t, dir).Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Wallet{UserID: "user-1", Amount: 94, Version: 7}, wallet)
+}
+
+func testFileRepository_TornWrite(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	dir := t.TempDir()
+	repo := openSeeded(t, dir, repository.WithCompactEvery(0))
+	debit(t, repo, "user-1", 10)
+	committed := walSize(t, dir)
+	debit(t, repo, "user-1", 20)
+	require.NoError(t, repo.Close())
+	full := walSize(t, dir)
+
+	for cut := committed; cut < full; cut++ {
+		crashed := t.TempDir()
+		copyDir(t, dir, crashed)
+		require.NoError(t, os.Truncate(filepath.Join(crashed, "wallets.wal"), cut))
+
+		// WHEN
+		recovered := open(t, crashed)
+
+		// THEN
+		wallet, err := recovered.Get(context.Background(), "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.Wallet{UserID: "user-1", Amount: 90, Version: 2}, wallet, "cut at %d", cut)
+
+		debit(t, recovered, "user-1", 1)
+		require.NoError(t, recovered.Close())
+		wallet, err = open(t, crashed).Get(context.Background(), "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, domain.Wallet{UserID: "user-1", Amount: 89, Version: 3}, wallet, "cut at %d", cut)
+	}
+}
+
+func testFileRepository_CrashDuringCompaction(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	dir := t.TempDir()
+	repo := openSeeded(t, dir, repository.WithCompactEvery(0))
+	debit(t, repo, "user-1", 10)
+	debit(t, repo, "user-1", 10)
+	require.NoError(t, repo.Close())
+	log, err := os.ReadFile(filepath.Join(dir, "wallets.wal"))
+	require.NoError(t, err)
+
+	// the snapshot was replaced but the log was not truncated yet
+	repo = open(t, dir)
+	debit(t, repo, "user-1", 10)
+	require.NoError(t, repo.Compact())
+	require.NoError(t, repo.Close())
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "wallets.wal"), log, 0o600))
+
+	// WHEN
+	wallet, err := open(t, dir).Get(context.Background(), "user-1")
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Wallet{UserID: "user-1", Amount: 70, Version: 4}, wallet)
+}
+
+func testFileRepository_Closed(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := openSeeded(t, t.TempDir())
+	require.NoError(t, repo.Close())
+
+	// WHEN
+	_, err := repo.Get(context.Background(), "user-1")
+
+	// THEN
+	assert.ErrorIs(t, err, repository.ErrRepositoryClosed)
+}
+
+func testFileRepository_UpdateAll(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	dir := t.TempDir()
+	repo := openSeededPair(t, dir)
+
+	// WHEN
+	err := repo.UpdateAll(context.Background(), []domain.Wallet{
+		{UserID: "user-1", Amount: 70, Version: 1},
+		{UserID: "user-2", Amount: 30, Version: 1},
+	})
+
+	// THEN
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "user-1", Amount: 70, Version: 2},
+		{UserID: "user-2", Amount: 30, Version: 2},
+	}, open(t, dir).Wallets())
+}
+
+func testFileRepository_UpdateAllVersionMismatch(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	dir := t.TempDir()
+	repo := openSeededPair(t, dir)
+	debit(t, repo, "user-2", 10)
+	size := walSize(t, dir)
+
+	// WHEN
+	err := repo.UpdateAll(context.Background(), []domain.Wallet{
+		{UserID: "user-1", Amount: 70, Version: 1},
+		{UserID: "user-2", Amount: 30, Version: 1},
+	})
+
+	// THEN
+	assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+	assert.Equal(t, size, walSize(t, dir))
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "user-1", Amount: 100, Version: 1},
+		{UserID: "user-2", Amount: 40, Version: 2},
+	}, repo.Wallets())
+}
+
+func testFileRepository_UpdateAndCredit(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	dir := t.TempDir()
+	repo := openSeededPair(t, dir)
+	debit(t, repo, "user-2", 10)
+
+	// WHEN
+	credited, err := repo.UpdateAndCredit(context.Background(),
+		domain.Wallet{UserID: "user-1", Amount: 70, Version: 1},
+		ports.Credit{UserID: "user-2", Currency: "USD", Amount: 3},
+	)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Wallet{UserID: "user-2", Amount: 40, Balances: map[domain.Currency]domain.Amount{"USD": 3}, Version: 3}, credited)
+	require.NoError(t, repo.Close())
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "user-1", Amount: 70, Version: 2},
+		{UserID: "user-2", Amount: 40, Balances: map[domain.Currency]domain.Amount{"USD": 3}, Version: 3},
+	}, open(t, dir).Wallets())
+}
+
+func testFileRepository_PaymentApplied(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int{
+		"from the log":      0,
+		"from the snapshot": 1,
+	}
+
+	for name, compactEvery := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			dir := t.TempDir()
+			repo := openSeeded(t, dir, repository.WithCompactEvery(compactEvery))
+			wallet, err := repo.Get(context.Background(), "user-1")
+			require.NoError(t, err)
+			require.NoError(t, wallet.Debit(10))
+			wallet.Payment = "pay-1"
+			require.NoError(t, repo.Update(context.Background(), wallet))
+			require.NoError(t, repo.Close())
+			reopened := open(t, dir)
+
+			// WHEN
+			redelivered, err := reopened.Get(context.Background(), "user-1")
+			require.NoError(t, err)
+			require.NoError(t, redelivered.Debit(10))
+			redelivered.Payment = "pay-1"
+			err = reopened.Update(context.Background(), redelivered)
+
+			// THEN
+			assert.ErrorIs(t, err, repository.ErrPaymentApplied)
+			stored, err := reopened.Get(context.Background(), "user-1")
+			require.NoError(t, err)
+			assert.Equal(t, domain.Wallet{UserID: "user-1", Amount: 90, Version: 2}, stored)
+		})
+	}
+}
+
+func testFileRepository_UpdateAllTornWrite(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	dir := t.TempDir()
+	repo := openSeededPair(t, dir)
+	committed := walSize(t, dir)
+	require.NoError(t, repo.UpdateAll(context.Background(), []domain.Wallet{
+		{UserID: "user-1", Amount: 70, Version: 1},
+		{UserID: "user-2", Amount: 30, Version: 1},
+	}))
+	require.NoError(t, repo.Close())
+	full := walSize(t, dir)
+
+	for cut := committed; cut < full; cut++ {
+		crashed := t.TempDir()
+		copyDir(t, dir, crashed)
+		require.NoError(t, os.Truncate(filepath.Join(crashed, "wallets.wal"), cut))
+
+		// WHEN
+		recovered := open(t, crashed)
+
+		// THEN
+		assert.Equal(t, []domain.Wallet{
+			{UserID: "user-1", Amount: 100, Version: 1},
+			{UserID: "user-2", Amount: 50, Version: 1},
+		}, recovered.Wallets(), "cut at %d", cut)
+	}
+}
+
+func testFileRepository_RecordTooLarge(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	dir := t.TempDir()
+	repo := openSeeded(t, dir, repository.WithCompactEvery(0))
+	size := walSize(t, dir)
+
+	// WHEN
+	err := repo.Update(context.Background(), domain.Wallet{UserID: "user-1", Amount: 70, Version: 1, Payment: strings.Repeat("p", 1<<16)})
+
+	// THEN
+	assert.ErrorIs(t, err, repository.ErrRecordTooLarge)
+	assert.Equal(t, size, walSize(t, dir))
+	require.NoError(t, repo.Close())
+	assert.Equal(t, []domain.Wallet{{UserID: "user-1", Amount: 100, Version: 1}}, open(t, dir).Wallets())
+}
+
+// --- Helper Functions ---
+
+func testFileRepository_Balances(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	dir := t.TempDir()
+	repo := open(t, dir, repository.WithCompactEvery(0))
+	require.NoError(t, repo.Seed(domain.Wallet{UserID: "user-1", Amount: 100, Balances: map[domain.Currency]domain.Amount{"USD": 40}, Version: 1}))
+
+	wallet, err := repo.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.NoError(t, wallet.DebitIn("USD", 15))
+	require.NoError(t, repo.Update(context.Background(), wallet))
+	require.NoError(t, repo.Close())
+
+	// WHEN
+	reopened := open(t, dir)
+
+	// THEN
+	stored, err := reopened.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Wallet{UserID: "user-1", Amount: 100, Balances: map[domain.Currency]domain.Amount{"USD": 25}, Version: 2}, stored)
+}
+
+func testFileRepository_Tenants(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	dir := t.TempDir()
+	repo := open(t, dir, repository.WithCompactEvery(0))
+	require.NoError(t, repo.Seed(
+		domain.Wallet{TenantID: "acme", UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{TenantID: "globex", UserID: "user-1", Amount: 10, Version: 1},
+	))
+	acme := domain.WithTenant(context.Background(), "acme")
+	wallet, err := repo.Get(acme, "user-1")
+	require.NoError(t, err)
+	require.NoError(t, wallet.Debit(30))
+	require.NoError(t, repo.Update(acme, wallet))
+	require.NoError(t, repo.Close())
+
+	// WHEN
+	reopened := open(t, dir)
+
+	// THEN
+	stored, err := reopened.Get(acme, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(70), stored.Amount)
+	other, err := reopened.Get(domain.WithTenant(context.Background(), "globex"), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(10), other.Amount)
+	_, err = reopened.Get(context.Background(), "user-1")
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+}
+
+func open(t *testing.T, dir string, opts ...repository.FileOption) *repository.FileWalletRepository {
+	t.Helper()
+
+	repo, err := repository.NewFileWalletRepository(dir, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = repo.Close() })
+
+	return repo
+}
+
+func openSeeded(t *testing.T, dir string, opts ...repository.FileOption) *repository.FileWalletRepository {
+	t.Helper()
+
+	repo := open(t, dir, opts...)
+	require.NoError(t, repo.Seed(domain.Wallet{UserID: "user-1", Amount: 100, Version: 1}))
+
+	return repo
+}
+
+func openSeededPair(t *testing.T, dir string) *repository.FileWalletRepository {
+	t.Helper()
+
+	repo := open(t, dir, repository.WithCompactEvery(0))
+	require.NoError(t, repo.Seed(
+		domain.Wallet{UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{UserID: "user-2", Amount: 50, Version: 1},
+	))
+
+	return repo
+}
+
+func debit(t *testing.T, repo *repository.FileWalletRepository, userID domain.UserID, amount domain.Amount) {
+	t.Helper()
+
+	wallet, err := repo.Get(context.Background(), userID)
+	require.NoError(t, err)
+	require.NoError(t, wallet.Debit(amount))
+	require.NoError(t, repo.Update(context.Background(), wallet))
+}
+
+func walSize(t *testing.T, dir string) int64 {
+	t.Helper()
+
+	info, err := os.Stat(filepath.Join(dir, "wallets.wal"))
+	require.NoError(t, err)
+	return info.Size()
+}
+
+func copyDir(t *testing.T, src, dst string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(src)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(src, entry.Name()))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dst, entry.Name()), data, 0o600))
+	}
+}
@@ -0,0 +1,318 @@
+package repository
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// Format of a wallet fixture file
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatCSV  Format = "csv"
+)
+
+var ErrInvalidFixture = errors.New("invalid wallet fixture")
+
+// csvHeader is the expected first line of a CSV fixture, version and tenant_id are optional
+var csvHeader = []string{"user_id", "amount", "version", "tenant_id"}
+
+type (
+	// WalletRecord is the file representation of a wallet, shared by fixtures and snapshots.
+	// Balances holds the currencies other than the wallet one and TenantID is empty for the
+	// wallets of single tenant deployments. PaymentID is only set in the log of
+	// FileWalletRepository, on the updates applying a payment
+	WalletRecord struct {
+		TenantID  domain.TenantID                   `json:"tenant_id,omitempty"`
+		UserID    domain.UserID                     `json:"user_id"`
+		Amount    domain.Amount                     `json:"amount"`
+		Balances  map[domain.Currency]domain.Amount `json:"balances,omitempty"`
+		Version   int                               `json:"version"`
+		PaymentID string                            `json:"payment_id,omitempty"`
+	}
+
+	// PaymentRecord is a payment applied to the wallet of UserID
+	PaymentRecord struct {
+		TenantID  domain.TenantID `json:"tenant_id,omitempty"`
+		UserID    domain.UserID   `json:"user_id"`
+		PaymentID string          `json:"payment_id"`
+	}
+
+	// Snapshot is the full state of an in-memory repository. A snapshot file is also a valid JSON
+	// fixture. Payments lists the payments applied to the wallets, fixtures start with none
+	Snapshot struct {
+		TakenAt  time.Time       `json:"taken_at"`
+		Wallets  []WalletRecord  `json:"wallets"`
+		Payments []PaymentRecord `json:"payments,omitempty"`
+	}
+)
+
+func (r PaymentRecord) key() walletKey {
+	return walletKey{tenant: r.TenantID, user: r.UserID}
+}
+
+// records lists the payments sorted by tenant, user and payment id
+func (p appliedPayments) records() []PaymentRecord {
+	var records []PaymentRecord
+	for key, payments := range p {
+		for payment := range payments {
+			records = append(records, PaymentRecord{TenantID: key.tenant, UserID: key.user, PaymentID: payment})
+		}
+	}
+
+	slices.SortFunc(records, func(a, b PaymentRecord) int {
+		return cmp.Or(cmp.Compare(a.TenantID, b.TenantID), cmp.Compare(a.UserID, b.UserID), cmp.Compare(a.PaymentID, b.PaymentID))
+	})
+	return records
+}
+
+func (r WalletRecord) toDomain() domain.Wallet {
+	return domain.Wallet{TenantID: r.TenantID, UserID: r.UserID, Amount: r.Amount, Balances: maps.Clone(r.Balances), Version: r.Version}
+}
+
+func (r WalletRecord) key() walletKey {
+	return walletKey{tenant: r.TenantID, user: r.UserID}
+}
+
+func toWalletRecord(wallet domain.Wallet) WalletRecord {
+	return WalletRecord{TenantID: wallet.TenantID, UserID: wallet.UserID, Amount: wallet.Amount, Balances: maps.Clone(wallet.Balances), Version: wallet.Version}
+}
+
+// FormatFromPath picks the fixture format from the file extension
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".csv":
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("%w: unknown extension for %s, expected .json or .csv", ErrInvalidFixture, path)
+	}
+}
+
+// LoadWalletsFile reads the wallets of a JSON or CSV fixture
+func LoadWalletsFile(path string) ([]domain.Wallet, error) {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadWallets(f, format)
+}
+
+// LoadWallets reads a fixture. JSON fixtures are either an array of wallets or a Snapshot,
+// CSV fixtures start with a user_id,amount[,version[,tenant_id]] header. Wallets without version
+// start at 1
+func LoadWallets(r io.Reader, format Format) ([]domain.Wallet, error) {
+	var (
+		records []WalletRecord
+		err     error
+	)
+
+	switch format {
+	case FormatJSON:
+		records, err = readJSONRecords(r)
+	case FormatCSV:
+		records, err = readCSVRecords(r)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidFixture, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toWallets(records)
+}
+
+// NewInMemoryWalletRepositoryFromFile builds a repository holding the wallets of a fixture
+func NewInMemoryWalletRepositoryFromFile(path string) (*InMemoryWalletRepository, error) {
+	wallets, err := LoadWalletsFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewInMemoryWalletRepositoryWith(wallets...), nil
+}
+
+// Snapshot returns the current state of the repository
+func (r *InMemoryWalletRepository) Snapshot() Snapshot {
+	wallets := r.Wallets()
+
+	snapshot := Snapshot{TakenAt: time.Now().UTC(), Wallets: make([]WalletRecord, 0, len(wallets))}
+	for _, wallet := range wallets {
+		snapshot.Wallets = append(snapshot.Wallets, toWalletRecord(wallet))
+	}
+
+	return snapshot
+}
+
+// WriteSnapshot dumps the current state as indented JSON
+func (r *InMemoryWalletRepository) WriteSnapshot(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Snapshot())
+}
+
+// WriteSnapshotFile dumps the current state to path. The file is replaced atomically so a
+// reader never sees a partial snapshot
+func (r *InMemoryWalletRepository) WriteSnapshotFile(path string) error {
+	return writeFileAtomically(path, r.WriteSnapshot)
+}
+
+func writeFileAtomically(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+func readJSONRecords(r io.Reader) ([]WalletRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []WalletRecord
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var snapshot Snapshot
+		err = json.Unmarshal(trimmed, &snapshot)
+		records = snapshot.Wallets
+	} else {
+		err = json.Unmarshal(trimmed, &records)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFixture, err)
+	}
+
+	return records, nil
+}
+
+func readCSVRecords(r io.Reader) ([]WalletRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFixture, err)
+	}
+	if len(header) < 2 || len(header) > len(csvHeader) || !equalFold(header, csvHeader[:len(header)]) {
+		return nil, fmt.Errorf("%w: header must be %s", ErrInvalidFixture, strings.Join(csvHeader, ","))
+	}
+
+	var records []WalletRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidFixture, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(row) != len(header) {
+			return nil, fmt.Errorf("%w: line %d: expected %d fields, got %d", ErrInvalidFixture, line, len(header), len(row))
+		}
+
+		record := WalletRecord{UserID: domain.UserID(row[0])}
+		amount, err := strconv.ParseFloat(row[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid amount %q", ErrInvalidFixture, line, row[1])
+		}
+		record.Amount = domain.Amount(amount)
+
+		if len(row) > 2 && row[2] != "" {
+			if record.Version, err = strconv.Atoi(row[2]); err != nil {
+				return nil, fmt.Errorf("%w: line %d: invalid version %q", ErrInvalidFixture, line, row[2])
+			}
+		}
+
+		if len(row) > 3 {
+			record.TenantID = domain.TenantID(row[3])
+		}
+
+		records = append(records, record)
+	}
+}
+
+func toWallets(records []WalletRecord) ([]domain.Wallet, error) {
+	seen := make(map[walletKey]bool, len(records))
+	wallets := make([]domain.Wallet, 0, len(records))
+
+	for i, record := range records {
+		switch {
+		case record.UserID == "":
+			return nil, fmt.Errorf("%w: wallet %d has no user_id", ErrInvalidFixture, i+1)
+		case seen[record.key()]:
+			return nil, fmt.Errorf("%w: duplicated user_id %s", ErrInvalidFixture, record.UserID)
+		case record.Amount < 0:
+			return nil, fmt.Errorf("%w: negative amount for %s", ErrInvalidFixture, record.UserID)
+		case record.Version < 0:
+			return nil, fmt.Errorf("%w: negative version for %s", ErrInvalidFixture, record.UserID)
+		case hasNegativeBalance(record):
+			return nil, fmt.Errorf("%w: negative balance for %s", ErrInvalidFixture, record.UserID)
+		}
+		seen[record.key()] = true
+
+		if record.Version == 0 {
+			record.Version = 1
+		}
+		wallets = append(wallets, record.toDomain())
+	}
+
+	return wallets, nil
+}
+
+func hasNegativeBalance(record WalletRecord) bool {
+	for _, amount := range record.Balances {
+		if amount < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func equalFold(a, b []string) bool {
+	for i := range a {
+		if !strings.EqualFold(strings.TrimSpace(a[i]), b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var fixtureWallets = []domain.Wallet{
+	{UserID: "user-1", Amount: 100.5, Version: 3},
+	{UserID: "user-2", Amount: 0, Version: 1},
+	{UserID: "user-3", Amount: 42, Version: 1},
+}
+
+func TestFixtures(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should load a JSON fixture", testFixtures_JSON)
+	t.Run("should load a CSV fixture", testFixtures_CSV)
+	t.Run("should load thousands of wallets", testFixtures_Large)
+	t.Run("should reject invalid fixtures", testFixtures_Invalid)
+	t.Run("should reload a written snapshot with the same state", testFixtures_SnapshotRoundTrip)
+}
+
+func testFixtures_JSON(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	repo, err := repository.NewInMemoryWalletRepositoryFromFile(filepath.Join("testdata", "wallets.json"))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, fixtureWallets, repo.Wallets())
+}
+
+func testFixtures_CSV(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	repo, err := repository.NewInMemoryWalletRepositoryFromFile(filepath.Join("testdata", "wallets.csv"))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, fixtureWallets, repo.Wallets())
+}
+
+func testFixtures_Large(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var sb strings.Builder
+	sb.WriteString("user_id,amount\n")
+	for i := range 5000 {
+		fmt.Fprintf(&sb, "user-%05d,%d\n", i, i)
+	}
+
+	// WHEN
+	wallets, err := repository.LoadWallets(strings.NewReader(sb.String()), repository.FormatCSV)
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, wallets, 5000)
+	assert.Equal(t, domain.Wallet{UserID: "user-04999", Amount: 4999, Version: 1}, wallets[4999])
+}
+
+func testFixtures_Invalid(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		format  repository.Format
+		content string
+		message string
+	}{
+		"bad header":         {repository.FormatCSV, "id,balance\nuser-1,10\n", "header must be"},
+		"bad amount":         {repository.FormatCSV, "user_id,amount\nuser-1,ten\n", `line 2: invalid amount "ten"`},
+		"missing field":      {repository.FormatCSV, "user_id,amount,version\nuser-1,10\n", "line 2: expected 3 fields"},
+		"duplicated user":    {repository.FormatJSON, `[{"user_id":"user-1"},{"user_id":"user-1"}]`, "duplicated user_id user-1"},
+		"negative amount":    {repository.FormatJSON, `[{"user_id":"user-1","amount":-1}]`, "negative amount for user-1"},
+		"missing user":       {repository.FormatJSON, `[{"amount":1}]`, "wallet 1 has no user_id"},
+		"malformed JSON":     {repository.FormatJSON, `[{"user_id":`, "invalid wallet fixture"},
+		"unsupported format": {repository.Format("xml"), "<wallets/>", `unsupported format "xml"`},
+	}
+
+	for name, tc := range cases {
+		// WHEN
+		_, err := repository.LoadWallets(strings.NewReader(tc.content), tc.format)
+
+		// THEN
+		require.ErrorIs(t, err, repository.ErrInvalidFixture, name)
+		assert.ErrorContains(t, err, tc.message, name)
+	}
+}
+
+func testFixtures_SnapshotRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryWalletRepositoryWith(fixtureWallets...)
+	wallet, err := repo.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.NoError(t, wallet.Debit(0.5))
+	require.NoError(t, repo.Update(context.Background(), wallet))
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	// WHEN
+	require.NoError(t, repo.WriteSnapshotFile(path))
+	restored, err := repository.NewInMemoryWalletRepositoryFromFile(path)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, repo.Wallets(), restored.Wallets())
+	assert.Equal(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 4}, restored.Wallets()[0])
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+var (
+	ErrMandateNotFound = errors.New("mandate not found")
+	ErrMandateExists   = errors.New("mandate already exists")
+	ErrInvalidMandates = errors.New("invalid mandates file")
+)
+
+type (
+	// InMemoryMandateRepository stores the mandates with the optimistic lock of the wallets, keyed by
+	// the tenant of the context and the id
+	InMemoryMandateRepository struct {
+		mu       sync.Mutex
+		mandates map[mandateKey]domain.Mandate
+	}
+
+	mandateKey struct {
+		tenant domain.TenantID
+		id     string
+	}
+
+	// MandateRecord is the file representation of a domain.Mandate. A missing status is active and
+	// a missing version is 1, so a seed only needs the terms of the mandates
+	MandateRecord struct {
+		ID               string               `json:"id"`
+		TenantID         domain.TenantID      `json:"tenant_id,omitempty"`
+		UserID           domain.UserID        `json:"user_id"`
+		Amount           domain.Amount        `json:"amount"`
+		Currency         domain.Currency      `json:"currency,omitempty"`
+		MerchantCategory string               `json:"merchant_category,omitempty"`
+		Cadence          domain.Cadence       `json:"cadence"`
+		Start            time.Time            `json:"start"`
+		End              time.Time            `json:"end,omitzero"`
+		MaxAmount        domain.Amount        `json:"max_amount,omitempty"`
+		RetryAttempts    int                  `json:"retry_attempts,omitempty"`
+		RetryInterval    string               `json:"retry_interval,omitempty"`
+		Status           domain.MandateStatus `json:"status,omitempty"`
+		CancelledAt      time.Time            `json:"cancelled_at,omitzero"`
+		Cycle            int                  `json:"cycle,omitempty"`
+		Attempts         int                  `json:"attempts,omitempty"`
+		RetryAt          time.Time            `json:"retry_at,omitzero"`
+		Total            domain.Amount        `json:"total,omitempty"`
+		Version          int                  `json:"version,omitempty"`
+	}
+)
+
+func (r *InMemoryMandateRepository) Get(ctx context.Context, id string) (domain.Mandate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mandate, ok := r.mandates[mandateKey{tenant: domain.TenantFrom(ctx), id: id}]
+	if !ok {
+		return domain.Mandate{}, fmt.Errorf("%w: %s", ErrMandateNotFound, id)
+	}
+	return mandate, nil
+}
+
+// Create stores the mandate at version 1, in the tenant of the context
+func (r *InMemoryMandateRepository) Create(ctx context.Context, mandate domain.Mandate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := mandateKey{tenant: domain.TenantFrom(ctx), id: mandate.ID}
+	if _, ok := r.mandates[key]; ok {
+		return fmt.Errorf("%w: %s", ErrMandateExists, mandate.ID)
+	}
+
+	mandate.TenantID = key.tenant
+	mandate.Version = 1
+	r.mandates[key] = mandate
+	return nil
+}
+
+func (r *InMemoryMandateRepository) Update(ctx context.Context, mandate domain.Mandate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := mandateKey{tenant: domain.TenantFrom(ctx), id: mandate.ID}
+	current, ok := r.mandates[key]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrMandateNotFound, mandate.ID)
+	}
+	if current.Version != mandate.Version {
+		return ErrVersionMismatch
+	}
+
+	mandate.TenantID = key.tenant
+	mandate.Version++
+	r.mandates[key] = mandate
+	return nil
+}
+
+// Active lists the active mandates of every tenant, the scheduler debits each one in its TenantID
+func (r *InMemoryMandateRepository) Active(_ context.Context) ([]domain.Mandate, error) {
+	var active []domain.Mandate
+	for _, mandate := range r.Mandates() {
+		if mandate.Status == domain.MandateActive {
+			active = append(active, mandate)
+		}
+	}
+	return active, nil
+}
+
+// Mandates returns the stored mandates of every tenant sorted by tenant and id
+func (r *InMemoryMandateRepository) Mandates() []domain.Mandate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.SortedFunc(maps.Values(r.mandates), func(a, b domain.Mandate) int {
+		return cmp.Or(cmp.Compare(a.TenantID, b.TenantID), cmp.Compare(a.ID, b.ID))
+	})
+}
+
+// LoadMandates reads a JSON array of mandates, every one of them has to be valid
+func LoadMandates(r io.Reader) ([]domain.Mandate, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var records []MandateRecord
+	if err := dec.Decode(&records); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMandates, err)
+	}
+
+	var errs []error
+	seen := make(map[mandateKey]bool, len(records))
+	mandates := make([]domain.Mandate, 0, len(records))
+	for i, record := range records {
+		mandate, err := record.toDomain()
+		if err == nil {
+			err = mandate.Validate()
+		}
+		key := mandateKey{tenant: mandate.TenantID, id: mandate.ID}
+		if err == nil && seen[key] {
+			err = fmt.Errorf("mandate %s is listed twice", mandate.ID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("mandate %d: %w", i, err))
+			continue
+		}
+		seen[key] = true
+		mandates = append(mandates, mandate)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMandates, err)
+	}
+	return mandates, nil
+}
+
+// LoadMandatesFile reads the mandates of a JSON file, as LoadMandates
+func LoadMandatesFile(path string) ([]domain.Mandate, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadMandates(f)
+}
+
+// NewInMemoryMandateRepositoryFromFile builds a repository holding the mandates of a JSON file
+func NewInMemoryMandateRepositoryFromFile(path string) (*InMemoryMandateRepository, error) {
+	mandates, err := LoadMandatesFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewInMemoryMandateRepositoryWith(mandates...), nil
+}
+
+// NewInMemoryMandateRepositoryWith builds a repository holding only the given mandates, each one in
+// its TenantID
+func NewInMemoryMandateRepositoryWith(mandates ...domain.Mandate) *InMemoryMandateRepository {
+	repo := &InMemoryMandateRepository{mandates: make(map[mandateKey]domain.Mandate, len(mandates))}
+	for _, mandate := range mandates {
+		repo.mandates[mandateKey{tenant: mandate.TenantID, id: mandate.ID}] = mandate
+	}
+	return repo
+}
+
+func NewInMemoryMandateRepository() *InMemoryMandateRepository {
+	return NewInMemoryMandateRepositoryWith()
+}
+
+func toMandateRecord(m domain.Mandate) MandateRecord {
+	record := MandateRecord{
+		ID:               m.ID,
+		TenantID:         m.TenantID,
+		UserID:           m.UserID,
+		Amount:           m.Amount,
+		Currency:         m.Currency,
+		MerchantCategory: m.MerchantCategory,
+		Cadence:          m.Cadence,
+		Start:            m.Start,
+		End:              m.End,
+		MaxAmount:        m.MaxAmount,
+		RetryAttempts:    m.Retry.Attempts,
+		Status:           m.Status,
+		CancelledAt:      m.CancelledAt,
+		Cycle:            m.Cycle,
+		Attempts:         m.Attempts,
+		RetryAt:          m.RetryAt,
+		Total:            m.Total,
+		Version:          m.Version,
+	}
+	if m.Retry.Interval != 0 {
+		record.RetryInterval = m.Retry.Interval.String()
+	}
+	return record
+}
+
+func (r MandateRecord) toDomain() (domain.Mandate, error) {
+	var interval time.Duration
+	if r.RetryInterval != "" {
+		var err error
+		if interval, err = time.ParseDuration(r.RetryInterval); err != nil {
+			return domain.Mandate{}, fmt.Errorf("retry_interval: %w", err)
+		}
+	}
+
+	mandate := domain.Mandate{
+		ID:               r.ID,
+		TenantID:         r.TenantID,
+		UserID:           r.UserID,
+		Amount:           r.Amount,
+		Currency:         r.Currency,
+		MerchantCategory: r.MerchantCategory,
+		Cadence:          r.Cadence,
+		Start:            r.Start,
+		End:              r.End,
+		MaxAmount:        r.MaxAmount,
+		Retry:            domain.MandateRetry{Attempts: r.RetryAttempts, Interval: interval},
+		Status:           cmp.Or(r.Status, domain.MandateActive),
+		CancelledAt:      r.CancelledAt,
+		Cycle:            r.Cycle,
+		Attempts:         r.Attempts,
+		RetryAt:          r.RetryAt,
+		Total:            r.Total,
+		Version:          cmp.Or(r.Version, 1),
+	}
+	return mandate, nil
+}
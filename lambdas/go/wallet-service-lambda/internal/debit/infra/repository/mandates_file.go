@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// FileMandateRepository keeps the mandates in memory and appends every created or updated mandate
+// to a JSONL file, one MandateRecord per line, before acknowledging it. The file is replayed over
+// the seed on open and the last line of a mandate wins, so the cycles debited and the
+// cancellations survive restarts. A torn last line left by a crash is cut on open
+type FileMandateRepository struct {
+	*InMemoryMandateRepository
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Create stores the mandate at version 1, in the tenant of the context
+func (r *FileMandateRepository) Create(ctx context.Context, mandate domain.Mandate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.InMemoryMandateRepository.Get(ctx, mandate.ID); err == nil {
+		return fmt.Errorf("%w: %s", ErrMandateExists, mandate.ID)
+	}
+
+	stored := mandate
+	stored.TenantID = domain.TenantFrom(ctx)
+	stored.Version = 1
+	if err := r.append(stored); err != nil {
+		return err
+	}
+
+	return r.InMemoryMandateRepository.Create(ctx, mandate)
+}
+
+func (r *FileMandateRepository) Update(ctx context.Context, mandate domain.Mandate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, err := r.InMemoryMandateRepository.Get(ctx, mandate.ID)
+	if err != nil {
+		return err
+	}
+	if current.Version != mandate.Version {
+		return ErrVersionMismatch
+	}
+
+	stored := mandate
+	stored.TenantID = domain.TenantFrom(ctx)
+	stored.Version++
+	if err = r.append(stored); err != nil {
+		return err
+	}
+
+	return r.InMemoryMandateRepository.Update(ctx, mandate)
+}
+
+func (r *FileMandateRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+func (r *FileMandateRepository) append(mandate domain.Mandate) error {
+	line, err := json.Marshal(toMandateRecord(mandate))
+	if err != nil {
+		return err
+	}
+	if _, err = r.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return r.file.Sync()
+}
+
+// OpenFileMandateRepository replays the mandates of path over the seed, creating path when missing
+func OpenFileMandateRepository(path string, seed ...domain.Mandate) (*FileMandateRepository, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := loadMandateFile(f, seed)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open mandates %s: %w", path, err)
+	}
+
+	return &FileMandateRepository{InMemoryMandateRepository: repo, file: f}, nil
+}
+
+func loadMandateFile(f *os.File, seed []domain.Mandate) (*InMemoryMandateRepository, error) {
+	content, err := readLines(f)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := NewInMemoryMandateRepositoryWith(seed...)
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.DisallowUnknownFields()
+	for line := 1; ; line++ {
+		var record MandateRecord
+		err = dec.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return repo, nil
+		}
+		var mandate domain.Mandate
+		if err == nil {
+			mandate, err = record.toDomain()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidMandates, line, err)
+		}
+		repo.mandates[mandateKey{tenant: mandate.TenantID, id: mandate.ID}] = mandate
+	}
+}
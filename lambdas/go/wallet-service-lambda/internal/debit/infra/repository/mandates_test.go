@@ -0,0 +1,303 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryMandateRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should create a mandate at version 1 once", testMandates_Create)
+	t.Run("should reject an update on a stale version", testMandates_VersionMismatch)
+	t.Run("should list only the active mandates", testMandates_Active)
+	t.Run("should keep the mandates of each tenant apart", testMandates_Tenants)
+	t.Run("should load the mandates of a file", testMandates_LoadFile)
+	t.Run("should reject an invalid mandates file", testMandates_LoadInvalid)
+}
+
+func testMandates_Create(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryMandateRepository()
+	mandate := newMandate("m-1", domain.MandateActive)
+
+	// WHEN
+	first := repo.Create(context.Background(), mandate)
+	second := repo.Create(context.Background(), mandate)
+
+	// THEN
+	require.NoError(t, first)
+	assert.ErrorIs(t, second, repository.ErrMandateExists)
+	stored, err := repo.Get(context.Background(), "m-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.Version)
+}
+
+func testMandates_VersionMismatch(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryMandateRepository()
+	require.NoError(t, repo.Create(context.Background(), newMandate("m-1", domain.MandateActive)))
+	read, err := repo.Get(context.Background(), "m-1")
+	require.NoError(t, err)
+
+	// WHEN
+	read.Cycle++
+	first := repo.Update(context.Background(), read)
+	stale := repo.Update(context.Background(), read)
+	_, missing := repo.Get(context.Background(), "m-2")
+
+	// THEN
+	require.NoError(t, first)
+	assert.ErrorIs(t, stale, repository.ErrVersionMismatch)
+	assert.ErrorIs(t, missing, repository.ErrMandateNotFound)
+	stored, err := repo.Get(context.Background(), "m-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.Version)
+	assert.Equal(t, 1, stored.Cycle)
+}
+
+func testMandates_Active(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryMandateRepositoryWith(
+		newMandate("m-3", domain.MandateActive),
+		newMandate("m-2", domain.MandateCancelled),
+		newMandate("m-1", domain.MandateActive),
+	)
+
+	// WHEN
+	active, err := repo.Active(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	assert.Equal(t, "m-1", active[0].ID)
+	assert.Equal(t, "m-3", active[1].ID)
+}
+
+func testMandates_Tenants(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryMandateRepository()
+	acme := domain.WithTenant(context.Background(), "acme")
+	globex := domain.WithTenant(context.Background(), "globex")
+
+	// WHEN
+	acmeErr := repo.Create(acme, newMandate("m-1", domain.MandateActive))
+	globexErr := repo.Create(globex, newMandate("m-1", domain.MandateActive))
+
+	// THEN
+	require.NoError(t, acmeErr)
+	require.NoError(t, globexErr)
+	_, err := repo.Get(context.Background(), "m-1")
+	assert.ErrorIs(t, err, repository.ErrMandateNotFound)
+	active, err := repo.Active(context.Background())
+	require.NoError(t, err)
+	tenants := []domain.TenantID{active[0].TenantID, active[1].TenantID}
+	assert.ElementsMatch(t, []domain.TenantID{"acme", "globex"}, tenants)
+}
+
+func testMandates_LoadFile(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := "testdata/mandates.json"
+
+	// WHEN
+	repo, err := repository.NewInMemoryMandateRepositoryFromFile(path)
+
+	// THEN
+	require.NoError(t, err)
+	mandates := repo.Mandates()
+	require.Len(t, mandates, 2)
+	assert.Equal(t, domain.Mandate{
+		ID:        "gym-user-2",
+		UserID:    "user-2",
+		Amount:    9.5,
+		Cadence:   domain.CadenceWeekly,
+		Start:     time.Date(2026, 1, 5, 6, 0, 0, 0, time.UTC),
+		End:       time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+		MaxAmount: 200,
+		Status:    domain.MandateCancelled,
+		Version:   4,
+	}, mandates[0])
+	assert.Equal(t, domain.MandateActive, mandates[1].Status)
+	assert.Equal(t, domain.MandateRetry{Attempts: 2, Interval: 24 * time.Hour}, mandates[1].Retry)
+	assert.Equal(t, 1, mandates[1].Version)
+}
+
+func testMandates_LoadInvalid(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	file := `[
+		{"id": "m-1", "user_id": "user-1", "amount": 10, "cadence": "monthly", "start": "2026-01-01T00:00:00Z", "retry_interval": "daily"},
+		{"id": "m-2", "user_id": "user-1", "amount": 0, "cadence": "hourly", "start": "2026-01-01T00:00:00Z"}
+	]`
+
+	// WHEN
+	_, err := repository.LoadMandates(strings.NewReader(file))
+
+	// THEN
+	require.ErrorIs(t, err, repository.ErrInvalidMandates)
+	assert.ErrorContains(t, err, "retry_interval")
+	assert.ErrorContains(t, err, "amount must be positive")
+	assert.ErrorContains(t, err, `unsupported cadence "hourly"`)
+}
+
+func TestFileMandateRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should keep the mandates across restarts", testFileMandates_Reopen)
+	t.Run("should replay the file over the seed", testFileMandates_Seed)
+	t.Run("should reject a stale version after the restart", testFileMandates_VersionAfterReopen)
+	t.Run("should cut a torn last line on open", testFileMandates_TornTail)
+}
+
+func testFileMandates_Reopen(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "mandates.jsonl")
+	repo, err := repository.OpenFileMandateRepository(path)
+	require.NoError(t, err)
+
+	ctx := domain.WithTenant(context.Background(), "acme")
+	mandate := newMandate("m-1", domain.MandateActive)
+	mandate.Retry = domain.MandateRetry{Attempts: 2, Interval: 12 * time.Hour}
+	require.NoError(t, repo.Create(ctx, mandate))
+	read, err := repo.Get(ctx, "m-1")
+	require.NoError(t, err)
+	read.Settle()
+	require.NoError(t, repo.Update(ctx, read))
+	require.NoError(t, repo.Close())
+
+	// WHEN
+	reopened, err := repository.OpenFileMandateRepository(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	// THEN
+	stored, err := reopened.Get(ctx, "m-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.Cycle)
+	assert.Equal(t, domain.Amount(10), stored.Total)
+	assert.Equal(t, 2, stored.Version)
+	assert.Equal(t, mandate.Retry, stored.Retry)
+	assert.Equal(t, domain.TenantID("acme"), stored.TenantID)
+
+	_, err = reopened.Get(context.Background(), "m-1")
+	assert.ErrorIs(t, err, repository.ErrMandateNotFound)
+}
+
+func testFileMandates_Seed(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "mandates.jsonl")
+	seed := []domain.Mandate{newMandate("m-1", domain.MandateActive), newMandate("m-2", domain.MandateActive)}
+	repo, err := repository.OpenFileMandateRepository(path, seed...)
+	require.NoError(t, err)
+	read, err := repo.Get(context.Background(), "m-1")
+	require.NoError(t, err)
+	require.NoError(t, read.Cancel(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)))
+	require.NoError(t, repo.Update(context.Background(), read))
+	require.NoError(t, repo.Close())
+
+	// WHEN
+	reopened, err := repository.OpenFileMandateRepository(path, seed...)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	// THEN
+	active, err := reopened.Active(context.Background())
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "m-2", active[0].ID)
+}
+
+func testFileMandates_VersionAfterReopen(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "mandates.jsonl")
+	repo, err := repository.OpenFileMandateRepository(path)
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(context.Background(), newMandate("m-1", domain.MandateActive)))
+	stale, err := repo.Get(context.Background(), "m-1")
+	require.NoError(t, err)
+	require.NoError(t, repo.Update(context.Background(), stale))
+	require.NoError(t, repo.Close())
+
+	reopened, err := repository.OpenFileMandateRepository(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	// WHEN
+	err = reopened.Update(context.Background(), stale)
+
+	// THEN
+	assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+}
+
+func testFileMandates_TornTail(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "mandates.jsonl")
+	repo, err := repository.OpenFileMandateRepository(path)
+	require.NoError(t, err)
+	require.NoError(t, repo.Create(context.Background(), newMandate("m-1", domain.MandateActive)))
+	require.NoError(t, repo.Close())
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"m-1","cyc`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// WHEN
+	reopened, err := repository.OpenFileMandateRepository(path)
+	require.NoError(t, err)
+	require.NoError(t, reopened.Create(context.Background(), newMandate("m-2", domain.MandateActive)))
+	require.NoError(t, reopened.Close())
+
+	// THEN
+	again, err := repository.OpenFileMandateRepository(path)
+	require.NoError(t, err)
+	defer again.Close()
+
+	active, err := again.Active(context.Background())
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	assert.Equal(t, 0, active[0].Cycle)
+}
+
+// --- Helper Functions ---
+
+func newMandate(id string, status domain.MandateStatus) domain.Mandate {
+	return domain.Mandate{
+		ID:      id,
+		UserID:  "user-1",
+		Amount:  10,
+		Cadence: domain.CadenceMonthly,
+		Start:   time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Status:  status,
+		Version: 1,
+	}
+}
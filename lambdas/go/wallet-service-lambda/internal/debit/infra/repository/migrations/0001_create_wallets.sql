@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS wallets (
+    user_id    VARCHAR(64)      NOT NULL PRIMARY KEY,
+    amount     DOUBLE PRECISION NOT NULL,
+    version    INTEGER          NOT NULL
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS wallet_balances (
+    user_id    VARCHAR(64)      NOT NULL REFERENCES wallets (user_id),
+    currency   VARCHAR(3)       NOT NULL,
+    amount     DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (user_id, currency)
+);
@@ -0,0 +1,27 @@
+CREATE TABLE wallets_by_tenant (
+    tenant_id  VARCHAR(64)      NOT NULL DEFAULT '',
+    user_id    VARCHAR(64)      NOT NULL,
+    amount     DOUBLE PRECISION NOT NULL,
+    version    INTEGER          NOT NULL,
+    PRIMARY KEY (tenant_id, user_id)
+);
+
+CREATE TABLE wallet_balances_by_tenant (
+    tenant_id  VARCHAR(64)      NOT NULL DEFAULT '',
+    user_id    VARCHAR(64)      NOT NULL,
+    currency   VARCHAR(3)       NOT NULL,
+    amount     DOUBLE PRECISION NOT NULL,
+    PRIMARY KEY (tenant_id, user_id, currency),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES wallets_by_tenant (tenant_id, user_id)
+);
+
+INSERT INTO wallets_by_tenant (tenant_id, user_id, amount, version)
+    SELECT '', user_id, amount, version FROM wallets;
+INSERT INTO wallet_balances_by_tenant (tenant_id, user_id, currency, amount)
+    SELECT '', user_id, currency, amount FROM wallet_balances;
+
+DROP TABLE wallet_balances;
+DROP TABLE wallets;
+
+ALTER TABLE wallets_by_tenant RENAME TO wallets;
+ALTER TABLE wallet_balances_by_tenant RENAME TO wallet_balances;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS applied_payments (
+    tenant_id  VARCHAR(64)  NOT NULL DEFAULT '',
+    user_id    VARCHAR(64)  NOT NULL,
+    payment_id VARCHAR(255) NOT NULL,
+    PRIMARY KEY (tenant_id, user_id, payment_id),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES wallets (tenant_id, user_id)
+);
@@ -0,0 +1,446 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Dialect adapts the queries to the placeholder style of the driver. The upserts use
+// INSERT ... ON CONFLICT, so only SQLite and PostgreSQL are supported; MySQL is not
+type Dialect int
+
+const (
+	// DialectQuestion uses ? placeholders, as SQLite does
+	DialectQuestion Dialect = iota
+	// DialectDollar uses $1, $2... placeholders, as PostgreSQL does
+	DialectDollar
+)
+
+type (
+	// SQLWalletRepository stores the wallets in a relational database through database/sql.
+	// The optimistic lock is enforced by the UPDATE itself, matching on the read version. The
+	// balances in other currencies live in wallet_balances and are rewritten in the same
+	// transaction, under the version of the wallet row, as are the payments applied in
+	// applied_payments. Every table is keyed by the tenant of the context and the user
+	SQLWalletRepository struct {
+		db      *sql.DB
+		dialect Dialect
+	}
+
+	SQLOption func(*SQLWalletRepository)
+
+	// querier is either the database or one of its transactions
+	querier interface {
+		QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+		QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	}
+)
+
+func WithDialect(dialect Dialect) SQLOption {
+	return func(r *SQLWalletRepository) { r.dialect = dialect }
+}
+
+func (r *SQLWalletRepository) Get(ctx context.Context, userID domain.UserID) (domain.Wallet, error) {
+	return r.get(ctx, r.db, userID)
+}
+
+func (r *SQLWalletRepository) get(ctx context.Context, q querier, userID domain.UserID) (domain.Wallet, error) {
+	wallet := domain.Wallet{TenantID: domain.TenantFrom(ctx), UserID: userID}
+
+	err := q.QueryRowContext(ctx,
+		r.rebind("SELECT amount, version FROM wallets WHERE tenant_id = ? AND user_id = ?"), string(wallet.TenantID), string(userID),
+	).Scan(&wallet.Amount, &wallet.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Wallet{}, ErrWalletNotFound
+	}
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+
+	balances, err := r.balances(ctx, q,
+		r.rebind("SELECT tenant_id, user_id, currency, amount FROM wallet_balances WHERE tenant_id = ? AND user_id = ?"),
+		string(wallet.TenantID), string(userID),
+	)
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+	wallet.Balances = balances[walletKey{tenant: wallet.TenantID, user: userID}]
+
+	return wallet, nil
+}
+
+// Update is a batch of a single wallet, the balances have to change along with the version
+func (r *SQLWalletRepository) Update(ctx context.Context, walletToUpdate domain.Wallet) error {
+	return r.UpdateAll(ctx, []domain.Wallet{walletToUpdate})
+}
+
+// UpdateAll runs the updates in a single database transaction, rolled back on the first wallet
+// that is missing or has moved on
+func (r *SQLWalletRepository) UpdateAll(ctx context.Context, walletsToUpdate []domain.Wallet) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	seen := make(map[domain.UserID]bool, len(walletsToUpdate))
+	for _, wallet := range walletsToUpdate {
+		if seen[wallet.UserID] {
+			return fmt.Errorf("%w: %s", ErrDuplicateWallet, wallet.UserID)
+		}
+		seen[wallet.UserID] = true
+
+		if err = r.update(ctx, tx, wallet); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// UpdateAndCredit adds the credit with an UPDATE relative to the stored balance, so it only waits
+// for the row lock of concurrent credits instead of failing the version check
+func (r *SQLWalletRepository) UpdateAndCredit(ctx context.Context, walletToUpdate domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	return r.UpdateAllAndCredit(ctx, []domain.Wallet{walletToUpdate}, credit)
+}
+
+// UpdateAllAndCredit runs the updates of UpdateAll and the credit of UpdateAndCredit in a single
+// database transaction
+func (r *SQLWalletRepository) UpdateAllAndCredit(ctx context.Context, walletsToUpdate []domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	seen := map[domain.UserID]bool{credit.UserID: true}
+	for _, wallet := range walletsToUpdate {
+		if seen[wallet.UserID] {
+			return domain.Wallet{}, fmt.Errorf("%w: %s", ErrDuplicateWallet, wallet.UserID)
+		}
+		seen[wallet.UserID] = true
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+	defer tx.Rollback()
+
+	for _, wallet := range walletsToUpdate {
+		if err = r.update(ctx, tx, wallet); err != nil {
+			return domain.Wallet{}, err
+		}
+	}
+	if err = r.credit(ctx, tx, credit); err != nil {
+		return domain.Wallet{}, err
+	}
+
+	credited, err := r.get(ctx, tx, credit.UserID)
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return domain.Wallet{}, err
+	}
+	return credited, nil
+}
+
+// update writes the wallet in the tenant of ctx when its version is still the read one and it
+// has not applied its payment yet
+func (r *SQLWalletRepository) update(ctx context.Context, tx *sql.Tx, wallet domain.Wallet) error {
+	tenant := domain.TenantFrom(ctx)
+	wallet.TenantID = tenant
+
+	result, err := tx.ExecContext(ctx,
+		r.rebind("UPDATE wallets SET amount = ?, version = version + 1 WHERE tenant_id = ? AND user_id = ? AND version = ?"),
+		float64(wallet.Amount), string(tenant), string(wallet.UserID), wallet.Version,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 1 {
+		if err = r.applyPayment(ctx, tx, wallet); err != nil {
+			return err
+		}
+		return r.writeBalances(ctx, tx, wallet)
+	}
+
+	var exists int
+	err = tx.QueryRowContext(ctx,
+		r.rebind("SELECT COUNT(*) FROM wallets WHERE tenant_id = ? AND user_id = ?"), string(tenant), string(wallet.UserID),
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return ErrWalletNotFound
+	}
+	return ErrVersionMismatch
+}
+
+// applyPayment records the payment of the update, failing when the wallet already has it
+func (r *SQLWalletRepository) applyPayment(ctx context.Context, tx *sql.Tx, wallet domain.Wallet) error {
+	if wallet.Payment == "" {
+		return nil
+	}
+
+	result, err := tx.ExecContext(ctx,
+		r.rebind("INSERT INTO applied_payments (tenant_id, user_id, payment_id) VALUES (?, ?, ?) ON CONFLICT DO NOTHING"),
+		string(wallet.TenantID), string(wallet.UserID), wallet.Payment,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %s", ErrPaymentApplied, wallet.Payment)
+	}
+	return nil
+}
+
+// credit bumps the version of the credited wallet, so the readers holding it still fail their
+// version check, and adds the amount to its balance, opening it when missing
+func (r *SQLWalletRepository) credit(ctx context.Context, tx *sql.Tx, credit ports.Credit) error {
+	tenant := string(domain.TenantFrom(ctx))
+
+	amount := 0.0
+	if credit.Currency == "" {
+		amount = float64(credit.Amount)
+	}
+	result, err := tx.ExecContext(ctx,
+		r.rebind("UPDATE wallets SET amount = amount + ?, version = version + 1 WHERE tenant_id = ? AND user_id = ?"),
+		amount, tenant, string(credit.UserID),
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrWalletNotFound
+	}
+	if credit.Currency == "" {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx,
+		r.rebind(`INSERT INTO wallet_balances (tenant_id, user_id, currency, amount) VALUES (?, ?, ?, ?)
+			ON CONFLICT (tenant_id, user_id, currency) DO UPDATE SET amount = wallet_balances.amount + excluded.amount`),
+		tenant, string(credit.UserID), string(credit.Currency), float64(credit.Amount),
+	)
+	return err
+}
+
+// Seed stores the given wallets as they are, each one in its TenantID, overwriting existing ones.
+// Meant for fixtures
+func (r *SQLWalletRepository) Seed(ctx context.Context, wallets ...domain.Wallet) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := r.rebind(`INSERT INTO wallets (tenant_id, user_id, amount, version) VALUES (?, ?, ?, ?)
+		ON CONFLICT (tenant_id, user_id) DO UPDATE SET amount = excluded.amount, version = excluded.version`)
+	for _, wallet := range wallets {
+		if _, err = tx.ExecContext(ctx, query, string(wallet.TenantID), string(wallet.UserID), float64(wallet.Amount), wallet.Version); err != nil {
+			return fmt.Errorf("failed to seed wallet %s: %w", wallet.UserID, err)
+		}
+		if err = r.writeBalances(ctx, tx, wallet); err != nil {
+			return fmt.Errorf("failed to seed wallet %s: %w", wallet.UserID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Wallets returns the stored wallets of every tenant sorted by tenant and user id
+func (r *SQLWalletRepository) Wallets(ctx context.Context) ([]domain.Wallet, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT tenant_id, user_id, amount, version FROM wallets ORDER BY tenant_id, user_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var wallets []domain.Wallet
+	for rows.Next() {
+		var wallet domain.Wallet
+		if err = rows.Scan(&wallet.TenantID, &wallet.UserID, &wallet.Amount, &wallet.Version); err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	balances, err := r.balances(ctx, r.db, "SELECT tenant_id, user_id, currency, amount FROM wallet_balances")
+	if err != nil {
+		return nil, err
+	}
+	for i := range wallets {
+		wallets[i].Balances = balances[walletKey{tenant: wallets[i].TenantID, user: wallets[i].UserID}]
+	}
+
+	return wallets, nil
+}
+
+// balances groups the rows of wallet_balances selected by query by tenant and user
+func (r *SQLWalletRepository) balances(ctx context.Context, q querier, query string, args ...any) (map[walletKey]map[domain.Currency]domain.Amount, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[walletKey]map[domain.Currency]domain.Amount)
+	for rows.Next() {
+		var (
+			key      walletKey
+			currency domain.Currency
+			amount   domain.Amount
+		)
+		if err = rows.Scan(&key.tenant, &key.user, &currency, &amount); err != nil {
+			return nil, err
+		}
+		if balances[key] == nil {
+			balances[key] = make(map[domain.Currency]domain.Amount)
+		}
+		balances[key][currency] = amount
+	}
+
+	return balances, rows.Err()
+}
+
+// writeBalances replaces the balances of the wallet in other currencies
+func (r *SQLWalletRepository) writeBalances(ctx context.Context, tx *sql.Tx, wallet domain.Wallet) error {
+	_, err := tx.ExecContext(ctx,
+		r.rebind("DELETE FROM wallet_balances WHERE tenant_id = ? AND user_id = ?"), string(wallet.TenantID), string(wallet.UserID),
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, currency := range slices.Sorted(maps.Keys(wallet.Balances)) {
+		_, err = tx.ExecContext(ctx,
+			r.rebind("INSERT INTO wallet_balances (tenant_id, user_id, currency, amount) VALUES (?, ?, ?, ?)"),
+			string(wallet.TenantID), string(wallet.UserID), string(currency), float64(wallet.Balances[currency]),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *SQLWalletRepository) rebind(query string) string {
+	if r.dialect != DialectDollar {
+		return query
+	}
+
+	var sb strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(c)
+	}
+	return sb.String()
+}
+
+// Migrate applies the migrations shipped in this package that are not recorded yet in
+// schema_migrations. Each one runs in its own transaction
+func (r *SQLWalletRepository) Migrate(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    VARCHAR(255) NOT NULL PRIMARY KEY,
+		applied_at VARCHAR(64)  NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err = r.migrate(ctx, name); err != nil {
+			return fmt.Errorf("migration %s failed: %w", path.Base(name), err)
+		}
+	}
+
+	return nil
+}
+
+func (r *SQLWalletRepository) migrate(ctx context.Context, name string) error {
+	version := strings.TrimSuffix(path.Base(name), ".sql")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var applied int
+	err = tx.QueryRowContext(ctx, r.rebind("SELECT COUNT(*) FROM schema_migrations WHERE version = ?"), version).Scan(&applied)
+	if err != nil || applied > 0 {
+		return err
+	}
+
+	script, err := migrations.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, string(script)); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		r.rebind("INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)"),
+		version, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func NewSQLWalletRepository(db *sql.DB, opts ...SQLOption) *SQLWalletRepository {
+	repo := &SQLWalletRepository{db: db}
+	for _, opt := range opts {
+		opt(repo)
+	}
+
+	return repo
+}
@@ -0,0 +1,391 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestSQLWalletRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should apply the migrations only once", testSQLRepository_MigrateTwice)
+	t.Run("should read and update a wallet", testSQLRepository_Update)
+	t.Run("should reject stale versions", testSQLRepository_VersionMismatch)
+	t.Run("should reject a payment already applied", testSQLRepository_PaymentApplied)
+	t.Run("should return not found for unknown wallets", testSQLRepository_NotFound)
+	t.Run("should let a single concurrent update win", testSQLRepository_ConcurrentUpdates)
+	t.Run("should update a batch of wallets", testSQLRepository_UpdateAll)
+	t.Run("should roll back the batch when a wallet is stale", testSQLRepository_UpdateAllRollback)
+	t.Run("should store the balance of every currency", testSQLRepository_Balances)
+	t.Run("should let concurrent updates credit the same wallet", testSQLRepository_ConcurrentCredits)
+	t.Run("should roll back the credit when the update fails", testSQLRepository_UpdateAndCreditRollback)
+	t.Run("should roll back the batch and the credit when a wallet is stale", testSQLRepository_UpdateAllAndCreditRollback)
+	t.Run("should keep the wallets of each tenant apart", testSQLRepository_Tenants)
+}
+
+func testSQLRepository_MigrateTwice(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	db := openDB(t)
+	repo := repository.NewSQLWalletRepository(db)
+	require.NoError(t, repo.Migrate(context.Background()))
+
+	// WHEN
+	err := repo.Migrate(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+	var applied int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&applied))
+	assert.Equal(t, 4, applied)
+}
+
+func testSQLRepository_Update(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newSQLRepository(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	wallet, err := repo.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.NoError(t, wallet.Debit(25.5))
+
+	// WHEN
+	err = repo.Update(context.Background(), wallet)
+
+	// THEN
+	require.NoError(t, err)
+	stored, err := repo.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Wallet{UserID: "user-1", Amount: 74.5, Version: 2}, stored)
+}
+
+func testSQLRepository_VersionMismatch(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newSQLRepository(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 3})
+
+	// WHEN
+	err := repo.Update(context.Background(), domain.Wallet{UserID: "user-1", Amount: 50, Version: 2})
+
+	// THEN
+	assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+	stored, err := repo.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 3}, stored)
+}
+
+func testSQLRepository_PaymentApplied(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newSQLRepository(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	require.NoError(t, repo.Update(context.Background(), domain.Wallet{UserID: "user-1", Amount: 90, Version: 1, Payment: "pay-1"}))
+
+	// WHEN
+	err := repo.Update(context.Background(), domain.Wallet{UserID: "user-1", Amount: 80, Version: 2, Payment: "pay-1"})
+
+	// THEN
+	assert.ErrorIs(t, err, repository.ErrPaymentApplied)
+	stored, err := repo.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Wallet{UserID: "user-1", Amount: 90, Version: 2}, stored)
+}
+
+func testSQLRepository_NotFound(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newSQLRepository(t)
+
+	// WHEN
+	_, getErr := repo.Get(context.Background(), "user-9")
+	updateErr := repo.Update(context.Background(), domain.Wallet{UserID: "user-9", Version: 1})
+
+	// THEN
+	assert.ErrorIs(t, getErr, repository.ErrWalletNotFound)
+	assert.ErrorIs(t, updateErr, repository.ErrWalletNotFound)
+}
+
+func testSQLRepository_ConcurrentUpdates(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newSQLRepository(t, domain.Wallet{UserID: "user-1", Amount: 100, Version: 1})
+	const writers = 8
+
+	// WHEN
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = repo.Update(context.Background(), domain.Wallet{UserID: "user-1", Amount: domain.Amount(i), Version: 1})
+		}()
+	}
+	wg.Wait()
+
+	// THEN
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, repository.ErrVersionMismatch)
+	}
+	assert.Equal(t, 1, succeeded)
+
+	stored, err := repo.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stored.Version)
+}
+
+func testSQLRepository_UpdateAll(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newSQLRepository(t,
+		domain.Wallet{UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{UserID: "user-2", Amount: 50, Version: 4},
+	)
+
+	// WHEN
+	err := repo.UpdateAll(context.Background(), []domain.Wallet{
+		{UserID: "user-1", Amount: 70, Version: 1},
+		{UserID: "user-2", Amount: 30, Version: 4},
+	})
+
+	// THEN
+	require.NoError(t, err)
+	wallets, err := repo.Wallets(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "user-1", Amount: 70, Version: 2},
+		{UserID: "user-2", Amount: 30, Version: 5},
+	}, wallets)
+}
+
+func testSQLRepository_UpdateAllRollback(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	seeded := []domain.Wallet{
+		{UserID: "user-1", Amount: 100, Version: 1},
+		{UserID: "user-2", Amount: 50, Version: 4},
+	}
+	repo := newSQLRepository(t, seeded...)
+
+	// WHEN
+	staleErr := repo.UpdateAll(context.Background(), []domain.Wallet{
+		{UserID: "user-1", Amount: 70, Version: 1},
+		{UserID: "user-2", Amount: 30, Version: 3},
+	})
+	missingErr := repo.UpdateAll(context.Background(), []domain.Wallet{
+		{UserID: "user-1", Amount: 70, Version: 1},
+		{UserID: "user-9", Amount: 30, Version: 1},
+	})
+
+	// THEN
+	assert.ErrorIs(t, staleErr, repository.ErrVersionMismatch)
+	assert.ErrorIs(t, missingErr, repository.ErrWalletNotFound)
+	wallets, err := repo.Wallets(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, seeded, wallets)
+}
+
+func testSQLRepository_ConcurrentCredits(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	const writers = 8
+	wallets := []domain.Wallet{{UserID: "revenue", Amount: 0, Balances: map[domain.Currency]domain.Amount{"USD": 1}, Version: 1}}
+	for i := range writers {
+		wallets = append(wallets, domain.Wallet{UserID: domain.UserID(fmt.Sprintf("user-%d", i)), Amount: 100, Version: 1})
+	}
+	repo := newSQLRepository(t, wallets...)
+
+	// WHEN
+	var wg sync.WaitGroup
+	errs := make([]error, writers)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			payer := domain.Wallet{UserID: domain.UserID(fmt.Sprintf("user-%d", i)), Amount: 90, Version: 1}
+			_, errs[i] = repo.UpdateAndCredit(context.Background(), payer, ports.Credit{UserID: "revenue", Amount: 1})
+			if errs[i] == nil {
+				_, errs[i] = repo.UpdateAndCredit(context.Background(), domain.Wallet{UserID: payer.UserID, Amount: 80, Version: 2},
+					ports.Credit{UserID: "revenue", Currency: "USD", Amount: 2})
+			}
+		}()
+	}
+	wg.Wait()
+
+	// THEN
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	revenue, err := repo.Get(context.Background(), "revenue")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Wallet{UserID: "revenue", Amount: writers, Balances: map[domain.Currency]domain.Amount{"USD": 1 + 2*writers}, Version: 1 + 2*writers}, revenue)
+}
+
+func testSQLRepository_UpdateAndCreditRollback(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	seeded := []domain.Wallet{
+		{UserID: "revenue", Amount: 0, Version: 1},
+		{UserID: "user-1", Amount: 100, Version: 2},
+	}
+	repo := newSQLRepository(t, seeded...)
+	credit := ports.Credit{UserID: "revenue", Amount: 5}
+
+	// WHEN
+	_, staleErr := repo.UpdateAndCredit(context.Background(), domain.Wallet{UserID: "user-1", Amount: 95, Version: 1}, credit)
+	_, missingErr := repo.UpdateAndCredit(context.Background(), domain.Wallet{UserID: "user-1", Amount: 95, Version: 2}, ports.Credit{UserID: "user-9", Amount: 5})
+	_, selfErr := repo.UpdateAndCredit(context.Background(), domain.Wallet{UserID: "revenue", Amount: 0, Version: 1}, credit)
+
+	// THEN
+	assert.ErrorIs(t, staleErr, repository.ErrVersionMismatch)
+	assert.ErrorIs(t, missingErr, repository.ErrWalletNotFound)
+	assert.ErrorIs(t, selfErr, repository.ErrDuplicateWallet)
+	wallets, err := repo.Wallets(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, seeded, wallets)
+}
+
+func testSQLRepository_UpdateAllAndCreditRollback(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	seeded := []domain.Wallet{
+		{UserID: "revenue", Amount: 0, Version: 1},
+		{UserID: "user-1", Amount: 100, Version: 2},
+		{UserID: "user-2", Amount: 50, Version: 1},
+	}
+	repo := newSQLRepository(t, seeded...)
+	credit := ports.Credit{UserID: "revenue", Amount: 2}
+
+	// WHEN
+	_, staleErr := repo.UpdateAllAndCredit(context.Background(), []domain.Wallet{
+		{UserID: "user-1", Amount: 90, Version: 2},
+		{UserID: "user-2", Amount: 40, Version: 9},
+	}, credit)
+	_, selfErr := repo.UpdateAllAndCredit(context.Background(), []domain.Wallet{
+		{UserID: "user-1", Amount: 90, Version: 2},
+		{UserID: "revenue", Amount: 0, Version: 1},
+	}, credit)
+	credited, err := repo.UpdateAllAndCredit(context.Background(), []domain.Wallet{
+		{UserID: "user-1", Amount: 90, Version: 2},
+		{UserID: "user-2", Amount: 40, Version: 1},
+	}, credit)
+
+	// THEN
+	assert.ErrorIs(t, staleErr, repository.ErrVersionMismatch)
+	assert.ErrorIs(t, selfErr, repository.ErrDuplicateWallet)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Wallet{UserID: "revenue", Amount: 2, Version: 2}, credited)
+	wallets, err := repo.Wallets(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "revenue", Amount: 2, Version: 2},
+		{UserID: "user-1", Amount: 90, Version: 3},
+		{UserID: "user-2", Amount: 40, Version: 2},
+	}, wallets)
+}
+
+// --- Helper Functions ---
+
+func testSQLRepository_Balances(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newSQLRepository(t,
+		domain.Wallet{UserID: "user-1", Amount: 100, Balances: map[domain.Currency]domain.Amount{"USD": 40, "GBP": 5}, Version: 1},
+		domain.Wallet{UserID: "user-2", Amount: 10, Version: 1},
+	)
+	wallet, err := repo.Get(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.NoError(t, wallet.DebitIn("USD", 15))
+	wallet.CreditIn("JPY", 300)
+
+	// WHEN
+	err = repo.Update(context.Background(), wallet)
+
+	// THEN
+	require.NoError(t, err)
+	wallets, err := repo.Wallets(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Wallet{
+		{UserID: "user-1", Amount: 100, Balances: map[domain.Currency]domain.Amount{"USD": 25, "GBP": 5, "JPY": 300}, Version: 2},
+		{UserID: "user-2", Amount: 10, Version: 1},
+	}, wallets)
+}
+
+func testSQLRepository_Tenants(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newSQLRepository(t,
+		domain.Wallet{TenantID: "acme", UserID: "user-1", Amount: 100, Version: 1},
+		domain.Wallet{TenantID: "globex", UserID: "user-1", Amount: 10, Version: 1},
+	)
+	acme := domain.WithTenant(context.Background(), "acme")
+	wallet, err := repo.Get(acme, "user-1")
+	require.NoError(t, err)
+	require.NoError(t, wallet.Debit(30))
+
+	// WHEN
+	err = repo.Update(acme, wallet)
+
+	// THEN
+	require.NoError(t, err)
+	globex, err := repo.Get(domain.WithTenant(context.Background(), "globex"), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, domain.Amount(10), globex.Amount)
+	_, err = repo.Get(context.Background(), "user-1")
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+	wallets, err := repo.Wallets(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Wallet{
+		{TenantID: "acme", UserID: "user-1", Amount: 70, Version: 2},
+		{TenantID: "globex", UserID: "user-1", Amount: 10, Version: 1},
+	}, wallets)
+}
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := filepath.Join(t.TempDir(), "wallets.db") + "?_pragma=busy_timeout(5000)"
+	db, err := sql.Open("sqlite", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+func newSQLRepository(t *testing.T, wallets ...domain.Wallet) *repository.SQLWalletRepository {
+	t.Helper()
+
+	repo := repository.NewSQLWalletRepository(openDB(t))
+	require.NoError(t, repo.Migrate(context.Background()))
+	require.NoError(t, repo.Seed(context.Background(), wallets...))
+
+	return repo
+}
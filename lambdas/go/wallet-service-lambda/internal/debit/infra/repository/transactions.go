@@ -0,0 +1,261 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+var (
+	ErrDuplicateTransaction = errors.New("transaction already recorded")
+	ErrTransactionNotFound  = errors.New("transaction not found")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+)
+
+type (
+	// InMemoryTransactionRepository keeps the transactions of every wallet in append order, keyed by
+	// the tenant of the context and the user as the wallets are
+	InMemoryTransactionRepository struct {
+		mu     sync.Mutex
+		seq    uint64
+		ids    map[string]domain.Transaction
+		byUser map[walletKey][]storedTransaction
+	}
+
+	storedTransaction struct {
+		seq uint64
+		tx  domain.Transaction
+	}
+
+	// TransactionRecord is the file representation of a transaction, one JSON object per line
+	TransactionRecord struct {
+		ID            string                 `json:"id"`
+		TenantID      domain.TenantID        `json:"tenant_id,omitempty"`
+		PaymentID     string                 `json:"payment_id"`
+		CorrelationID string                 `json:"correlation_id"`
+		UserID        domain.UserID          `json:"user_id"`
+		Type          domain.TransactionType `json:"type"`
+		Amount        domain.Amount          `json:"amount"`
+		Currency      domain.Currency        `json:"currency,omitempty"`
+		BalanceAfter  domain.Amount          `json:"balance_after"`
+		Timestamp     time.Time              `json:"timestamp"`
+		Fee           domain.Amount          `json:"fee,omitempty"`
+
+		OriginalAmount   domain.Amount   `json:"original_amount,omitempty"`
+		OriginalCurrency domain.Currency `json:"original_currency,omitempty"`
+		RateID           string          `json:"rate_id,omitempty"`
+		Rate             float64         `json:"rate,omitempty"`
+	}
+
+	// cursor points below the last returned transaction. Pages are walked by append order,
+	// so transactions recorded meanwhile never shift a page
+	cursor struct {
+		Before uint64 `json:"before"`
+	}
+)
+
+func (r *InMemoryTransactionRepository) Append(ctx context.Context, tx domain.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.ids[tx.ID]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateTransaction, tx.ID)
+	}
+
+	key := keyOf(ctx, tx.UserID)
+	tx.TenantID = key.tenant
+
+	r.seq++
+	r.ids[tx.ID] = tx
+	r.byUser[key] = append(r.byUser[key], storedTransaction{seq: r.seq, tx: tx})
+
+	return nil
+}
+
+func (r *InMemoryTransactionRepository) contains(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.ids[id]
+	return ok
+}
+
+// Get returns the transaction recorded with id. Ids are derived from the tenant, so they never
+// match a transaction of another tenant
+func (r *InMemoryTransactionRepository) Get(_ context.Context, id string) (domain.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx, ok := r.ids[id]
+	if !ok {
+		return domain.Transaction{}, fmt.Errorf("%w: %s", ErrTransactionNotFound, id)
+	}
+	return tx, nil
+}
+
+// History walks the wallet from the newest transaction backwards. A Limit below 1 returns every
+// matching transaction in a single page
+func (r *InMemoryTransactionRepository) History(ctx context.Context, query ports.TransactionQuery) (ports.TransactionPage, error) {
+	before, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return ports.TransactionPage{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := r.byUser[keyOf(ctx, query.UserID)]
+	page := ports.TransactionPage{Transactions: []domain.Transaction{}}
+
+	var last uint64
+	for i := len(stored) - 1; i >= 0; i-- {
+		s := stored[i]
+		if before != 0 && s.seq >= before {
+			continue
+		}
+		if !matches(s.tx, query) {
+			continue
+		}
+
+		if query.Limit > 0 && len(page.Transactions) == query.Limit {
+			page.NextCursor = encodeCursor(cursor{Before: last})
+			break
+		}
+		page.Transactions = append(page.Transactions, s.tx)
+		last = s.seq
+	}
+
+	return page, nil
+}
+
+// Transactions returns the transactions of every tenant in append order
+func (r *InMemoryTransactionRepository) Transactions() []domain.Transaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var stored []storedTransaction
+	for _, history := range r.byUser {
+		stored = append(stored, history...)
+	}
+	slices.SortFunc(stored, func(a, b storedTransaction) int { return cmp.Compare(a.seq, b.seq) })
+
+	transactions := make([]domain.Transaction, 0, len(stored))
+	for _, s := range stored {
+		transactions = append(transactions, s.tx)
+	}
+	return transactions
+}
+
+// WriteTransactions dumps every transaction as JSON lines, the output can be loaded back with
+// NewInMemoryTransactionRepositoryFromFile
+func (r *InMemoryTransactionRepository) WriteTransactions(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	for _, tx := range r.Transactions() {
+		if err := enc.Encode(TransactionRecord(tx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteTransactionsFile dumps every transaction to path, replacing the file atomically
+func (r *InMemoryTransactionRepository) WriteTransactionsFile(path string) error {
+	return writeFileAtomically(path, r.WriteTransactions)
+}
+
+// LoadTransactions reads the JSON lines written by WriteTransactions
+func LoadTransactions(r io.Reader) ([]domain.Transaction, error) {
+	var transactions []domain.Transaction
+
+	dec := json.NewDecoder(r)
+	for {
+		var record TransactionRecord
+		err := dec.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			return transactions, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %w", len(transactions)+1, err)
+		}
+		transactions = append(transactions, domain.Transaction(record))
+	}
+}
+
+// NewInMemoryTransactionRepositoryFromFile builds a repository holding the transactions of a JSONL
+// file, each one in its TenantID
+func NewInMemoryTransactionRepositoryFromFile(path string) (*InMemoryTransactionRepository, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	transactions, err := LoadTransactions(f)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := NewInMemoryTransactionRepository()
+	for _, tx := range transactions {
+		if err = repo.Append(domain.WithTenant(context.Background(), tx.TenantID), tx); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+func matches(tx domain.Transaction, query ports.TransactionQuery) bool {
+	if !query.From.IsZero() && tx.Timestamp.Before(query.From) {
+		return false
+	}
+	if !query.To.IsZero() && !tx.Timestamp.Before(query.To) {
+		return false
+	}
+	if query.PaymentID != "" && tx.PaymentID != query.PaymentID {
+		return false
+	}
+	if len(query.Currencies) > 0 && !slices.Contains(query.Currencies, tx.Currency) {
+		return false
+	}
+	return len(query.Types) == 0 || slices.Contains(query.Types, tx.Type)
+}
+
+func encodeCursor(c cursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (uint64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	var c cursor
+	if err = json.Unmarshal(raw, &c); err != nil || c.Before == 0 {
+		return 0, ErrInvalidCursor
+	}
+
+	return c.Before, nil
+}
+
+func NewInMemoryTransactionRepository() *InMemoryTransactionRepository {
+	return &InMemoryTransactionRepository{
+		ids:    make(map[string]domain.Transaction),
+		byUser: make(map[walletKey][]storedTransaction),
+	}
+}
@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// FileTransactionRepository keeps the history in memory and appends every transaction to a JSONL
+// file, in the format of WriteTransactions, before acknowledging it. The file is read back on
+// open, so the history survives restarts. A torn last line left by a crash is cut on open
+type FileTransactionRepository struct {
+	*InMemoryTransactionRepository
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func (r *FileTransactionRepository) Append(ctx context.Context, tx domain.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.contains(tx.ID) {
+		return fmt.Errorf("%w: %s", ErrDuplicateTransaction, tx.ID)
+	}
+
+	tx.TenantID = domain.TenantFrom(ctx)
+	line, err := json.Marshal(TransactionRecord(tx))
+	if err != nil {
+		return err
+	}
+	if _, err = r.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err = r.file.Sync(); err != nil {
+		return err
+	}
+
+	return r.InMemoryTransactionRepository.Append(ctx, tx)
+}
+
+func (r *FileTransactionRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.file.Close()
+}
+
+// OpenFileTransactionRepository loads the transactions of path, creating it when missing
+func OpenFileTransactionRepository(path string) (*FileTransactionRepository, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := loadTransactionFile(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open transactions %s: %w", path, err)
+	}
+
+	return &FileTransactionRepository{InMemoryTransactionRepository: repo, file: f}, nil
+}
+
+func loadTransactionFile(f *os.File) (*InMemoryTransactionRepository, error) {
+	content, err := readLines(f)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := LoadTransactions(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	repo := NewInMemoryTransactionRepository()
+	for _, tx := range transactions {
+		if err = repo.Append(domain.WithTenant(context.Background(), tx.TenantID), tx); err != nil {
+			return nil, err
+		}
+	}
+	return repo, nil
+}
+
+// readLines reads the complete lines of a JSONL file, cutting a torn last line left by a crash
+func readLines(f *os.File) ([]byte, error) {
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if complete := bytes.LastIndexByte(content, '\n') + 1; complete < len(content) {
+		slog.Warn("discarding torn tail", "path", f.Name(), "bytes", len(content)-complete)
+		if err = f.Truncate(int64(complete)); err != nil {
+			return nil, err
+		}
+		if err = f.Sync(); err != nil {
+			return nil, err
+		}
+		content = content[:complete]
+	}
+	return content, nil
+}
@@ -0,0 +1,329 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var day = time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+func TestInMemoryTransactionRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should page through the history newest first", testTransactions_Pagination)
+	t.Run("should keep pages stable while new transactions arrive", testTransactions_StablePages)
+	t.Run("should filter by date range and type", testTransactions_Filters)
+	t.Run("should filter by payment", testTransactions_PaymentFilter)
+	t.Run("should only return the transactions of the wallet", testTransactions_OtherWallets)
+	t.Run("should only return the transactions of the tenant", testTransactions_Tenants)
+	t.Run("should reject an invalid cursor", testTransactions_InvalidCursor)
+	t.Run("should reject a duplicated transaction id", testTransactions_Duplicate)
+	t.Run("should load the transactions it writes", testTransactions_RoundTrip)
+	t.Run("should get a transaction by id", testTransactions_Get)
+}
+
+func testTransactions_Pagination(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newTransactionRepository(t, 5)
+	query := ports.TransactionQuery{UserID: "user-1", Limit: 2}
+
+	// WHEN
+	var ids []string
+	pages := 0
+	for {
+		page, err := repo.History(context.Background(), query)
+		require.NoError(t, err)
+		pages++
+		for _, tx := range page.Transactions {
+			ids = append(ids, tx.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+
+	// THEN
+	assert.Equal(t, 3, pages)
+	assert.Equal(t, []string{"tx-5", "tx-4", "tx-3", "tx-2", "tx-1"}, ids)
+}
+
+func testTransactions_StablePages(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newTransactionRepository(t, 3)
+	first, err := repo.History(context.Background(), ports.TransactionQuery{UserID: "user-1", Limit: 2})
+	require.NoError(t, err)
+	require.NoError(t, repo.Append(context.Background(), transaction("tx-new", "user-1", domain.TransactionDebit, day.Add(time.Hour))))
+
+	// WHEN
+	second, err := repo.History(context.Background(), ports.TransactionQuery{UserID: "user-1", Limit: 2, Cursor: first.NextCursor})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx-1"}, transactionIDs(second))
+	assert.Empty(t, second.NextCursor)
+}
+
+func testTransactions_Filters(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := repository.NewInMemoryTransactionRepository()
+	for i, txType := range []domain.TransactionType{domain.TransactionDebit, domain.TransactionCredit, domain.TransactionDebit, domain.TransactionDebit} {
+		tx := transaction(fmt.Sprintf("tx-%d", i+1), "user-1", txType, day.AddDate(0, 0, i))
+		require.NoError(t, repo.Append(context.Background(), tx))
+	}
+
+	// WHEN
+	page, err := repo.History(context.Background(), ports.TransactionQuery{
+		UserID: "user-1",
+		From:   day.AddDate(0, 0, 1),
+		To:     day.AddDate(0, 0, 3),
+		Types:  []domain.TransactionType{domain.TransactionDebit},
+		Limit:  10,
+	})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx-3"}, transactionIDs(page))
+}
+
+func testTransactions_PaymentFilter(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newTransactionRepository(t, 3)
+
+	// WHEN
+	page, err := repo.History(context.Background(), ports.TransactionQuery{UserID: "user-1", PaymentID: "pay-tx-2", Limit: 10})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx-2"}, transactionIDs(page))
+}
+
+func testTransactions_OtherWallets(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newTransactionRepository(t, 2)
+	require.NoError(t, repo.Append(context.Background(), transaction("tx-other", "user-2", domain.TransactionDebit, day)))
+
+	// WHEN
+	page, err := repo.History(context.Background(), ports.TransactionQuery{UserID: "user-2", Limit: 10})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx-other"}, transactionIDs(page))
+}
+
+func testTransactions_Tenants(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newTransactionRepository(t, 2)
+	acme := domain.WithTenant(context.Background(), "acme")
+	require.NoError(t, repo.Append(acme, transaction("tx-acme", "user-1", domain.TransactionDebit, day)))
+
+	// WHEN
+	page, err := repo.History(acme, ports.TransactionQuery{UserID: "user-1", Limit: 10})
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx-acme"}, transactionIDs(page))
+	assert.Equal(t, domain.TenantID("acme"), page.Transactions[0].TenantID)
+}
+
+func testTransactions_InvalidCursor(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newTransactionRepository(t, 2)
+
+	// WHEN
+	_, err := repo.History(context.Background(), ports.TransactionQuery{UserID: "user-1", Limit: 1, Cursor: "not-a-cursor"})
+
+	// THEN
+	assert.ErrorIs(t, err, repository.ErrInvalidCursor)
+}
+
+func testTransactions_Duplicate(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newTransactionRepository(t, 1)
+
+	// WHEN
+	err := repo.Append(context.Background(), transaction("tx-1", "user-1", domain.TransactionDebit, day))
+
+	// THEN
+	assert.ErrorIs(t, err, repository.ErrDuplicateTransaction)
+}
+
+func testTransactions_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newTransactionRepository(t, 3)
+	require.NoError(t, repo.Append(context.Background(), transaction("tx-other", "user-2", domain.TransactionCredit, day)))
+	converted := transaction("tx-converted", "user-2", domain.TransactionDebit, day)
+	converted.OriginalAmount, converted.OriginalCurrency, converted.RateID, converted.Rate = 20, "USD", "usd-eur-1", 0.909
+	require.NoError(t, repo.Append(context.Background(), converted))
+	var buf bytes.Buffer
+	require.NoError(t, repo.WriteTransactions(&buf))
+
+	// WHEN
+	loaded, err := repository.LoadTransactions(&buf)
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, repo.Transactions(), loaded)
+	assert.Equal(t, "tx-other", loaded[3].ID)
+	assert.Equal(t, "usd-eur-1", loaded[4].RateID)
+}
+
+func testTransactions_Get(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repo := newTransactionRepository(t, 2)
+
+	// WHEN
+	found, foundErr := repo.Get(context.Background(), "tx-2")
+	_, missingErr := repo.Get(context.Background(), "tx-9")
+
+	// THEN
+	require.NoError(t, foundErr)
+	assert.Equal(t, "pay-tx-2", found.PaymentID)
+	assert.ErrorIs(t, missingErr, repository.ErrTransactionNotFound)
+}
+
+func TestFileTransactionRepository(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should keep the history across restarts", testFileTransactions_Reopen)
+	t.Run("should reject a transaction recorded before the restart", testFileTransactions_DuplicateAfterReopen)
+	t.Run("should cut a torn last line on open", testFileTransactions_TornTail)
+}
+
+func testFileTransactions_Reopen(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "transactions.jsonl")
+	repo, err := repository.OpenFileTransactionRepository(path)
+	require.NoError(t, err)
+
+	ctx := domain.WithTenant(context.Background(), "acme")
+	require.NoError(t, repo.Append(ctx, transaction("tx-1", "user-1", domain.TransactionDebit, day)))
+	require.NoError(t, repo.Append(ctx, transaction("tx-2", "user-1", domain.TransactionCredit, day.Add(time.Minute))))
+	require.NoError(t, repo.Close())
+
+	// WHEN
+	reopened, err := repository.OpenFileTransactionRepository(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	// THEN
+	page, err := reopened.History(ctx, ports.TransactionQuery{UserID: "user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx-2", "tx-1"}, transactionIDs(page))
+	assert.Equal(t, domain.TenantID("acme"), page.Transactions[0].TenantID)
+
+	other, err := reopened.History(context.Background(), ports.TransactionQuery{UserID: "user-1"})
+	require.NoError(t, err)
+	assert.Empty(t, other.Transactions)
+}
+
+func testFileTransactions_DuplicateAfterReopen(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "transactions.jsonl")
+	repo, err := repository.OpenFileTransactionRepository(path)
+	require.NoError(t, err)
+	require.NoError(t, repo.Append(context.Background(), transaction("tx-1", "user-1", domain.TransactionDebit, day)))
+	require.NoError(t, repo.Close())
+
+	reopened, err := repository.OpenFileTransactionRepository(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	// WHEN
+	err = reopened.Append(context.Background(), transaction("tx-1", "user-1", domain.TransactionDebit, day))
+
+	// THEN
+	assert.ErrorIs(t, err, repository.ErrDuplicateTransaction)
+}
+
+func testFileTransactions_TornTail(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "transactions.jsonl")
+	repo, err := repository.OpenFileTransactionRepository(path)
+	require.NoError(t, err)
+	require.NoError(t, repo.Append(context.Background(), transaction("tx-1", "user-1", domain.TransactionDebit, day)))
+	require.NoError(t, repo.Close())
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"id":"tx-2","user_`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// WHEN
+	reopened, err := repository.OpenFileTransactionRepository(path)
+	require.NoError(t, err)
+	require.NoError(t, reopened.Append(context.Background(), transaction("tx-3", "user-1", domain.TransactionDebit, day.Add(time.Minute))))
+	require.NoError(t, reopened.Close())
+
+	// THEN
+	again, err := repository.OpenFileTransactionRepository(path)
+	require.NoError(t, err)
+	defer again.Close()
+
+	page, err := again.History(context.Background(), ports.TransactionQuery{UserID: "user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tx-3", "tx-1"}, transactionIDs(page))
+}
+
+// --- Helper Functions ---
+
+func newTransactionRepository(t *testing.T, n int) *repository.InMemoryTransactionRepository {
+	t.Helper()
+
+	repo := repository.NewInMemoryTransactionRepository()
+	for i := range n {
+		tx := transaction(fmt.Sprintf("tx-%d", i+1), "user-1", domain.TransactionDebit, day.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, repo.Append(context.Background(), tx))
+	}
+	return repo
+}
+
+func transaction(id string, userID domain.UserID, txType domain.TransactionType, at time.Time) domain.Transaction {
+	return domain.Transaction{ID: id, PaymentID: "pay-" + id, UserID: userID, Type: txType, Amount: 1, BalanceAfter: 10, Timestamp: at}
+}
+
+func transactionIDs(page ports.TransactionPage) []string {
+	ids := make([]string, 0, len(page.Transactions))
+	for _, tx := range page.Transactions {
+		ids = append(ids, tx.ID)
+	}
+	return ids
+}
@@ -0,0 +1,57 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrBulkheadFull = errors.New("bulkhead is full")
+
+// Bulkhead caps the number of concurrent calls to a dependency so a slow one cannot
+// hold every message of the batch
+type Bulkhead struct {
+	slots   chan struct{}
+	maxWait time.Duration
+}
+
+// acquire waits at most maxWait for a free slot
+func (b *Bulkhead) acquire(ctx context.Context) error {
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	if b.maxWait <= 0 {
+		return ErrBulkheadFull
+	}
+
+	timer := time.NewTimer(b.maxWait)
+	defer timer.Stop()
+
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrBulkheadFull
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *Bulkhead) release() {
+	<-b.slots
+}
+
+func (b *Bulkhead) InFlight() int {
+	return len(b.slots)
+}
+
+func NewBulkhead(maxConcurrent int, maxWait time.Duration) *Bulkhead {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+
+	return &Bulkhead{slots: make(chan struct{}, maxConcurrent), maxWait: maxWait}
+}
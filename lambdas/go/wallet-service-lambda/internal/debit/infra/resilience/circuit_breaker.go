@@ -0,0 +1,149 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type State string
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half-open"
+)
+
+type BreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before letting a probe through
+	OpenTimeout time.Duration
+	// SuccessThreshold is the number of consecutive successful probes that closes the circuit
+	SuccessThreshold int
+}
+
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	settings  BreakerSettings
+	state     State
+	failures  int
+	successes int
+	probing   bool
+	openedAt  time.Time
+	now       func() time.Time
+	// generation changes with every state transition, so calls admitted in a previous state
+	// do not count towards the current one
+	generation uint64
+}
+
+func (cb *CircuitBreaker) State() State {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.refresh()
+	return cb.state
+}
+
+// allow reports whether a call may go through and returns the generation it was admitted in.
+// While half-open a single probe is let through at a time
+func (cb *CircuitBreaker) allow() (uint64, error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.refresh()
+
+	switch cb.state {
+	case StateOpen:
+		return 0, ErrCircuitOpen
+	case StateHalfOpen:
+		if cb.probing {
+			return 0, ErrCircuitOpen
+		}
+		cb.probing = true
+	}
+
+	return cb.generation, nil
+}
+
+// record counts the result of a call. Results of calls admitted before the last transition are
+// stale: a call started while closed must not count as the probe nor free the probe slot
+func (cb *CircuitBreaker) record(generation uint64, success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation != cb.generation {
+		return
+	}
+
+	switch cb.state {
+	case StateClosed:
+		if success {
+			cb.failures = 0
+			return
+		}
+		cb.failures++
+		if cb.failures >= cb.settings.FailureThreshold {
+			cb.open()
+		}
+	case StateHalfOpen:
+		cb.probing = false
+		if !success {
+			cb.open()
+			return
+		}
+		cb.successes++
+		if cb.successes >= cb.settings.SuccessThreshold {
+			cb.state = StateClosed
+			cb.failures = 0
+			cb.successes = 0
+			cb.generation++
+		}
+	}
+}
+
+// abandon ends a call without a result, freeing the probe slot it may hold
+func (cb *CircuitBreaker) abandon(generation uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if generation == cb.generation && cb.state == StateHalfOpen {
+		cb.probing = false
+	}
+}
+
+func (cb *CircuitBreaker) refresh() {
+	if cb.state == StateOpen && cb.now().Sub(cb.openedAt) >= cb.settings.OpenTimeout {
+		cb.state = StateHalfOpen
+		cb.successes = 0
+		cb.probing = false
+		cb.generation++
+	}
+}
+
+func (cb *CircuitBreaker) open() {
+	cb.state = StateOpen
+	cb.openedAt = cb.now()
+	cb.failures = 0
+	cb.successes = 0
+	cb.generation++
+}
+
+func NewCircuitBreaker(settings BreakerSettings, now func() time.Time) *CircuitBreaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 5
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 10 * time.Second
+	}
+	if settings.SuccessThreshold <= 0 {
+		settings.SuccessThreshold = 1
+	}
+	if now == nil {
+		now = time.Now
+	}
+
+	return &CircuitBreaker{settings: settings, state: StateClosed, now: now}
+}
@@ -0,0 +1,88 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+)
+
+// IsRepositoryFailure ignores the business outcomes of the repository so they never open the circuit
+func IsRepositoryFailure(err error) bool {
+	return err != nil && !errors.Is(err, repository.ErrVersionMismatch) && !errors.Is(err, repository.ErrWalletNotFound) &&
+		!errors.Is(err, repository.ErrPaymentApplied)
+}
+
+type WalletRepository struct {
+	next   ports.WalletRepository
+	policy *Policy
+}
+
+func (r *WalletRepository) Get(ctx context.Context, userID domain.UserID) (domain.Wallet, error) {
+	var wallet domain.Wallet
+
+	err := r.policy.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		wallet, err = r.next.Get(ctx, userID)
+		return err
+	})
+
+	return wallet, err
+}
+
+func (r *WalletRepository) Update(ctx context.Context, wallet domain.Wallet) error {
+	return r.policy.Execute(ctx, func(ctx context.Context) error {
+		return r.next.Update(ctx, wallet)
+	})
+}
+
+func (r *WalletRepository) UpdateAll(ctx context.Context, wallets []domain.Wallet) error {
+	return r.policy.Execute(ctx, func(ctx context.Context) error {
+		return r.next.UpdateAll(ctx, wallets)
+	})
+}
+
+func (r *WalletRepository) UpdateAndCredit(ctx context.Context, wallet domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	var credited domain.Wallet
+
+	err := r.policy.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		credited, err = r.next.UpdateAndCredit(ctx, wallet, credit)
+		return err
+	})
+
+	return credited, err
+}
+
+func (r *WalletRepository) UpdateAllAndCredit(ctx context.Context, wallets []domain.Wallet, credit ports.Credit) (domain.Wallet, error) {
+	var credited domain.Wallet
+
+	err := r.policy.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		credited, err = r.next.UpdateAllAndCredit(ctx, wallets, credit)
+		return err
+	})
+
+	return credited, err
+}
+
+func NewWalletRepository(next ports.WalletRepository, policy *Policy) *WalletRepository {
+	return &WalletRepository{next: next, policy: policy}
+}
+
+type EventBus struct {
+	next   ports.EventBusProcessor
+	policy *Policy
+}
+
+func (b *EventBus) Publish(ctx context.Context, req ports.BalanceDebitedRequest) error {
+	return b.policy.Execute(ctx, func(ctx context.Context) error {
+		return b.next.Publish(ctx, req)
+	})
+}
+
+func NewEventBus(next ports.EventBusProcessor, policy *Policy) *EventBus {
+	return &EventBus{next: next, policy: policy}
+}
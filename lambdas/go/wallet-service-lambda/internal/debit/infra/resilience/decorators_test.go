@@ -0,0 +1,317 @@
+package resilience_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application/ports"
+	"github.com/payment-processor/internal/debit/application/ports/mocks"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/repository"
+	"github.com/payment-processor/internal/debit/infra/resilience"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var settings = resilience.Settings{
+	Breaker:       resilience.BreakerSettings{FailureThreshold: 2, OpenTimeout: time.Minute, SuccessThreshold: 1},
+	MaxConcurrent: 1,
+	Timeout:       time.Second,
+}
+
+func TestResilienceDecorators(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should fail fast with retryable error once the circuit opens", testDecorator_CircuitOpens)
+	t.Run("should not open the circuit on version mismatch or an applied payment", testDecorator_BusinessErrorsIgnored)
+	t.Run("should close the circuit after a successful probe", testDecorator_HalfOpenRecovery)
+	t.Run("should reject calls when the bulkhead is full", testDecorator_BulkheadFull)
+	t.Run("should return retryable error when the call times out", testDecorator_Timeout)
+	t.Run("should not open the circuit when the caller gives up", testDecorator_CallerCancelled)
+	t.Run("should free the probe of a caller giving up", testDecorator_CallerCancelledProbe)
+	t.Run("should ignore calls admitted before the circuit went half-open", testDecorator_StaleResult)
+}
+
+func testDecorator_CircuitOpens(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	busMock := mocks.NewMockEventBusProcessor(t)
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("eventbridge is down")).Twice()
+
+	policy := resilience.NewPolicy("event-bus", settings, nil, newClock().Now)
+	bus := resilience.NewEventBus(busMock, policy)
+
+	_ = bus.Publish(context.Background(), ports.BalanceDebitedRequest{})
+	_ = bus.Publish(context.Background(), ports.BalanceDebitedRequest{})
+
+	// WHEN
+	err := bus.Publish(context.Background(), ports.BalanceDebitedRequest{})
+
+	// THEN
+	assert.Equal(t, resilience.StateOpen, policy.State())
+	assert.ErrorIs(t, err, resilience.ErrCircuitOpen)
+	assert.True(t, domain.IsRetryable(err))
+
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "5004", domainErr.Code)
+}
+
+func testDecorator_BusinessErrorsIgnored(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	repoMock.EXPECT().Update(mock.Anything, mock.Anything).Return(repository.ErrVersionMismatch).Times(3)
+	repoMock.EXPECT().UpdateAll(mock.Anything, mock.Anything).Return(repository.ErrPaymentApplied).Times(3)
+
+	policy := resilience.NewPolicy("wallet-repository", settings, resilience.IsRepositoryFailure, newClock().Now)
+	repo := resilience.NewWalletRepository(repoMock, policy)
+
+	// WHEN
+	var mismatchErr, appliedErr error
+	for i := 0; i < 3; i++ {
+		mismatchErr = repo.Update(context.Background(), domain.Wallet{UserID: "user-1"})
+		appliedErr = repo.UpdateAll(context.Background(), []domain.Wallet{{UserID: "user-1", Payment: "pay-1"}})
+	}
+
+	// THEN
+	assert.ErrorIs(t, mismatchErr, repository.ErrVersionMismatch)
+	assert.ErrorIs(t, appliedErr, repository.ErrPaymentApplied)
+	assert.False(t, domain.IsRetryable(mismatchErr))
+	assert.Equal(t, resilience.StateClosed, policy.State())
+}
+
+func testDecorator_HalfOpenRecovery(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	repoMock := mocks.NewMockWalletRepository(t)
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-1")).Return(domain.Wallet{}, errors.New("dynamo is down")).Twice()
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("user-1")).Return(domain.Wallet{UserID: "user-1", Amount: 10}, nil).Once()
+
+	policy := resilience.NewPolicy("wallet-repository", settings, resilience.IsRepositoryFailure, clock.Now)
+	repo := resilience.NewWalletRepository(repoMock, policy)
+
+	_, _ = repo.Get(context.Background(), "user-1")
+	_, _ = repo.Get(context.Background(), "user-1")
+	require.Equal(t, resilience.StateOpen, policy.State())
+
+	clock.Advance(time.Minute)
+
+	// WHEN
+	wallet, err := repo.Get(context.Background(), "user-1")
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, domain.Amount(10), wallet.Amount)
+	assert.Equal(t, resilience.StateClosed, policy.State())
+}
+
+func testDecorator_BulkheadFull(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	inCall := make(chan struct{})
+	release := make(chan struct{})
+
+	repoMock := mocks.NewMockWalletRepository(t)
+	repoMock.EXPECT().Get(mock.Anything, mock.Anything).RunAndReturn(func(context.Context, domain.UserID) (domain.Wallet, error) {
+		close(inCall)
+		<-release
+		return domain.Wallet{}, nil
+	}).Once()
+
+	repo := resilience.NewWalletRepository(repoMock, resilience.NewPolicy("wallet-repository", settings, resilience.IsRepositoryFailure, newClock().Now))
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = repo.Get(context.Background(), "user-1")
+	}()
+	<-inCall
+
+	// WHEN
+	_, err := repo.Get(context.Background(), "user-2")
+
+	// THEN
+	assert.ErrorIs(t, err, resilience.ErrBulkheadFull)
+	assert.True(t, domain.IsRetryable(err))
+
+	close(release)
+	wg.Wait()
+}
+
+func testDecorator_Timeout(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	repoMock := mocks.NewMockWalletRepository(t)
+	repoMock.EXPECT().Get(mock.Anything, mock.Anything).RunAndReturn(func(ctx context.Context, _ domain.UserID) (domain.Wallet, error) {
+		<-ctx.Done()
+		return domain.Wallet{}, ctx.Err()
+	}).Once()
+
+	timeoutSettings := settings
+	timeoutSettings.Timeout = 10 * time.Millisecond
+	repo := resilience.NewWalletRepository(repoMock, resilience.NewPolicy("wallet-repository", timeoutSettings, resilience.IsRepositoryFailure, newClock().Now))
+
+	// WHEN
+	_, err := repo.Get(context.Background(), "user-1")
+
+	// THEN
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, domain.IsRetryable(err))
+}
+
+func testDecorator_CallerCancelled(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	busMock := mocks.NewMockEventBusProcessor(t)
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(context.Canceled).Times(3)
+
+	policy := resilience.NewPolicy("event-bus", settings, nil, newClock().Now)
+	bus := resilience.NewEventBus(busMock, policy)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// WHEN
+	var err error
+	for i := 0; i < 3; i++ {
+		err = bus.Publish(ctx, ports.BalanceDebitedRequest{})
+	}
+
+	// THEN
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, resilience.StateClosed, policy.State())
+}
+
+func testDecorator_CallerCancelledProbe(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	busMock := mocks.NewMockEventBusProcessor(t)
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("eventbridge is down")).Twice()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(context.Canceled).Once()
+	busMock.EXPECT().Publish(mock.Anything, mock.Anything).Return(nil).Once()
+
+	policy := resilience.NewPolicy("event-bus", settings, nil, clock.Now)
+	bus := resilience.NewEventBus(busMock, policy)
+
+	_ = bus.Publish(context.Background(), ports.BalanceDebitedRequest{})
+	_ = bus.Publish(context.Background(), ports.BalanceDebitedRequest{})
+	clock.Advance(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = bus.Publish(ctx, ports.BalanceDebitedRequest{})
+	require.Equal(t, resilience.StateHalfOpen, policy.State())
+
+	// WHEN
+	err := bus.Publish(context.Background(), ports.BalanceDebitedRequest{})
+
+	// THEN
+	assert.NoError(t, err)
+	assert.Equal(t, resilience.StateClosed, policy.State())
+}
+
+func testDecorator_StaleResult(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	clock := newClock()
+	stale, probe := newBlockedCall(nil), newBlockedCall(errors.New("dynamo is down"))
+	repoMock := mocks.NewMockWalletRepository(t)
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("stale")).RunAndReturn(stale.get).Once()
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("failing")).Return(domain.Wallet{}, errors.New("dynamo is down")).Once()
+	repoMock.EXPECT().Get(mock.Anything, domain.UserID("probe")).RunAndReturn(probe.get).Once()
+
+	concurrentSettings := settings
+	concurrentSettings.Breaker.FailureThreshold = 1
+	concurrentSettings.MaxConcurrent = 3
+	policy := resilience.NewPolicy("wallet-repository", concurrentSettings, resilience.IsRepositoryFailure, clock.Now)
+	repo := resilience.NewWalletRepository(repoMock, policy)
+
+	stale.start(repo, "stale")
+	_, _ = repo.Get(context.Background(), "failing")
+	clock.Advance(time.Minute)
+	probe.start(repo, "probe")
+
+	// WHEN
+	stale.finish()
+
+	// THEN
+	assert.Equal(t, resilience.StateHalfOpen, policy.State())
+	_, err := repo.Get(context.Background(), "user-1")
+	assert.ErrorIs(t, err, resilience.ErrCircuitOpen)
+
+	probe.finish()
+	assert.Equal(t, resilience.StateOpen, policy.State())
+}
+
+// --- Helper Functions ---
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// blockedCall is a repository call held inside the dependency until finish is called
+type blockedCall struct {
+	err      error
+	entered  chan struct{}
+	released chan struct{}
+	done     chan struct{}
+}
+
+func newBlockedCall(err error) *blockedCall {
+	return &blockedCall{err: err, entered: make(chan struct{}), released: make(chan struct{}), done: make(chan struct{})}
+}
+
+func (c *blockedCall) get(context.Context, domain.UserID) (domain.Wallet, error) {
+	close(c.entered)
+	<-c.released
+	return domain.Wallet{}, c.err
+}
+
+// start calls Get in the background and waits until the call reaches the dependency
+func (c *blockedCall) start(repo ports.WalletRepository, userID domain.UserID) {
+	go func() {
+		defer close(c.done)
+		_, _ = repo.Get(context.Background(), userID)
+	}()
+	<-c.entered
+}
+
+// finish lets the call return and waits until the breaker recorded its result
+func (c *blockedCall) finish() {
+	close(c.released)
+	<-c.done
+}
+
+func newClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,156 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+type Settings struct {
+	Breaker       BreakerSettings
+	MaxConcurrent int
+	// MaxWait is how long a call may wait for a bulkhead slot, zero fails immediately
+	MaxWait time.Duration
+	// Timeout bounds every single call to the dependency
+	Timeout time.Duration
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Breaker:       BreakerSettings{FailureThreshold: 5, OpenTimeout: 10 * time.Second, SuccessThreshold: 1},
+		MaxConcurrent: 10,
+		MaxWait:       100 * time.Millisecond,
+		Timeout:       2 * time.Second,
+	}
+}
+
+// Policy guards the calls to a single dependency with a bulkhead, a circuit breaker and a timeout.
+// Calls rejected by any of them fail fast with a retryable domain error
+type Policy struct {
+	name       string
+	breaker    *CircuitBreaker
+	bulkhead   *Bulkhead
+	timeout    time.Duration
+	isFailure  func(error) bool
+	rejections metric.Int64Counter
+}
+
+func (p *Policy) Execute(ctx context.Context, fn func(context.Context) error) error {
+	if err := p.bulkhead.acquire(ctx); err != nil {
+		p.reject(ctx, "bulkhead_full")
+		return domain.NewDependencyUnavailableError(p.name, err)
+	}
+	defer p.bulkhead.release()
+
+	generation, err := p.breaker.allow()
+	if err != nil {
+		p.reject(ctx, "circuit_open")
+		return domain.NewDependencyUnavailableError(p.name, err)
+	}
+
+	callCtx := ctx
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	err = fn(callCtx)
+	if ctx.Err() != nil {
+		// the caller gave up, whatever the call returned says nothing about the dependency
+		p.breaker.abandon(generation)
+	} else {
+		p.breaker.record(generation, !p.isFailure(err))
+	}
+
+	// only our own timeout is reported as unavailability, a cancelled caller is not
+	if err != nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		p.reject(ctx, "timeout")
+		return domain.NewDependencyUnavailableError(p.name, errors.Join(context.DeadlineExceeded, err))
+	}
+
+	return err
+}
+
+func (p *Policy) State() State { return p.breaker.State() }
+
+// reject counts the rejection in the tenant of the call, the breaker and the bulkhead are shared by
+// every tenant so their gauges are not tagged
+func (p *Policy) reject(ctx context.Context, reason string) {
+	slog.WarnContext(ctx, "call rejected by resilience policy", "dependency", p.name, "reason", reason)
+	p.rejections.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("dependency", p.name),
+		attribute.String("reason", reason),
+		attribute.String("tenant.id", string(domain.TenantFrom(ctx))),
+	))
+}
+
+func (p *Policy) registerMetrics(meter metric.Meter) {
+	var err error
+
+	p.rejections, err = meter.Int64Counter("resilience.rejections",
+		metric.WithDescription("Calls rejected by the bulkhead, the circuit breaker or the timeout"))
+	if err != nil {
+		slog.Warn("failed to create resilience metric", "metric", "resilience.rejections", "error", err)
+		p.rejections = noop.Int64Counter{}
+	}
+
+	attrs := metric.WithAttributes(attribute.String("dependency", p.name))
+
+	_, err = meter.Int64ObservableGauge("resilience.circuit_breaker.state",
+		metric.WithDescription("Circuit breaker state: 0 closed, 1 half-open, 2 open"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(stateValue(p.breaker.State()), attrs)
+			return nil
+		}))
+	if err != nil {
+		slog.Warn("failed to create resilience metric", "metric", "resilience.circuit_breaker.state", "error", err)
+	}
+
+	_, err = meter.Int64ObservableGauge("resilience.bulkhead.in_flight",
+		metric.WithDescription("Calls currently holding a bulkhead slot"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			o.Observe(int64(p.bulkhead.InFlight()), attrs)
+			return nil
+		}))
+	if err != nil {
+		slog.Warn("failed to create resilience metric", "metric", "resilience.bulkhead.in_flight", "error", err)
+	}
+}
+
+func stateValue(s State) int64 {
+	switch s {
+	case StateHalfOpen:
+		return 1
+	case StateOpen:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// NewPolicy builds the policy of the named dependency. isFailure tells which errors count
+// against the circuit breaker; nil counts every error
+func NewPolicy(name string, settings Settings, isFailure func(error) bool, now func() time.Time) *Policy {
+	if isFailure == nil {
+		isFailure = func(err error) bool { return err != nil }
+	}
+
+	p := &Policy{
+		name:      name,
+		breaker:   NewCircuitBreaker(settings.Breaker, now),
+		bulkhead:  NewBulkhead(settings.MaxConcurrent, settings.MaxWait),
+		timeout:   settings.Timeout,
+		isFailure: isFailure,
+	}
+	p.registerMetrics(otel.Meter("wallet-service.resilience"))
+
+	return p
+}
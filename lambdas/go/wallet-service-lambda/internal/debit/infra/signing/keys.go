@@ -0,0 +1,138 @@
+package signing
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrInvalidKeys = errors.New("invalid signing keys")
+
+// minSecretLength is the size of the SHA-256 output, shorter secrets weaken the HMAC
+const minSecretLength = 32
+
+// Keys are the secrets accepted at a given time by key id. Producers move to a new key while the
+// old one is still listed, so both verify until the old one is removed
+type Keys map[string][]byte
+
+// KeyProvider reads the active keys from wherever the secrets are kept. It is called for every
+// event, implementations cache the keys and refresh them to pick up rotations
+type KeyProvider interface {
+	Keys(ctx context.Context) (Keys, error)
+}
+
+// KeyRecord is the file representation of a key, Secret is base64 encoded
+type KeyRecord struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
+// LoadKeysFile reads the JSON keys stored at path
+func LoadKeysFile(path string) (Keys, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadKeys(f)
+}
+
+// LoadKeys reads a JSON array of keys. A key listed twice or with a secret shorter than 32 bytes
+// is rejected
+func LoadKeys(r io.Reader) (Keys, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var records []KeyRecord
+	if err := dec.Decode(&records); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidKeys, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no keys", ErrInvalidKeys)
+	}
+
+	keys := make(Keys, len(records))
+	for _, record := range records {
+		if record.ID == "" {
+			return nil, fmt.Errorf("%w: key id is missing", ErrInvalidKeys)
+		}
+		if _, ok := keys[record.ID]; ok {
+			return nil, fmt.Errorf("%w: key %s is listed twice", ErrInvalidKeys, record.ID)
+		}
+		secret, err := base64.StdEncoding.DecodeString(record.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("%w: secret of key %s: %w", ErrInvalidKeys, record.ID, err)
+		}
+		if len(secret) < minSecretLength {
+			return nil, fmt.Errorf("%w: secret of key %s is shorter than %d bytes", ErrInvalidKeys, record.ID, minSecretLength)
+		}
+		keys[record.ID] = secret
+	}
+
+	return keys, nil
+}
+
+// FileKeyProvider serves the keys of a JSON file, read again once refresh has elapsed so a
+// rotated file is picked up without a deploy
+type FileKeyProvider struct {
+	path    string
+	refresh time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	keys     Keys
+	loadedAt time.Time
+}
+
+type FileKeyProviderOption func(*FileKeyProvider)
+
+// WithKeysClock replaces the clock deciding when the file is read again
+func WithKeysClock(now func() time.Time) FileKeyProviderOption {
+	return func(p *FileKeyProvider) { p.now = now }
+}
+
+// Keys reads the file on the first call and after every refresh. When a refresh fails the keys
+// already loaded are kept until the next one, so a file being rewritten does not reject every event
+func (p *FileKeyProvider) Keys(ctx context.Context) (Keys, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if p.keys != nil && now.Sub(p.loadedAt) < p.refresh {
+		return p.keys, nil
+	}
+
+	keys, err := LoadKeysFile(p.path)
+	if err != nil {
+		if p.keys == nil {
+			return nil, err
+		}
+		slog.WarnContext(ctx, "failed to refresh signing keys, keeping the loaded ones", "error", err)
+		p.loadedAt = now
+		return p.keys, nil
+	}
+
+	p.keys, p.loadedAt = keys, now
+	return keys, nil
+}
+
+// NewFileKeyProvider reads the keys at path lazily, zero refresh reads them once
+func NewFileKeyProvider(path string, refresh time.Duration, opts ...FileKeyProviderOption) *FileKeyProvider {
+	if refresh <= 0 {
+		refresh = math.MaxInt64
+	}
+	p := &FileKeyProvider{path: path, refresh: refresh, now: time.Now}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
@@ -0,0 +1,153 @@
+package signing_test
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/infra/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	currentSecret  = []byte("current-secret-of-at-least-32-bytes")
+	previousSecret = []byte("previous-secret-of-at-least-32-bytes")
+)
+
+func TestLoadKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should load every key of the file", testLoadKeys_Success)
+	t.Run("should reject invalid keys", testLoadKeys_Invalid)
+}
+
+func TestFileKeyProvider(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should pick up a rotated file after the refresh", testFileKeyProvider_Refresh)
+	t.Run("should keep the loaded keys when a refresh fails", testFileKeyProvider_RefreshFailure)
+	t.Run("should fail when the file cannot be read", testFileKeyProvider_Missing)
+}
+
+func testLoadKeys_Success(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	keys, err := signing.LoadKeys(strings.NewReader(keysJSON(t, "2026-09", previousSecret, "2026-10", currentSecret)))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, signing.Keys{"2026-09": previousSecret, "2026-10": currentSecret}, keys)
+}
+
+func testLoadKeys_Invalid(t *testing.T) {
+	t.Parallel()
+
+	secret := base64.StdEncoding.EncodeToString(currentSecret)
+	tests := map[string]string{
+		"malformed":      `[{"id":`,
+		"unknown field":  `[{"id": "k1", "secret": "` + secret + `", "algorithm": "sha1"}]`,
+		"empty":          `[]`,
+		"missing id":     `[{"secret": "` + secret + `"}]`,
+		"not base64":     `[{"id": "k1", "secret": "not base64!"}]`,
+		"short secret":   `[{"id": "k1", "secret": "c2hvcnQ="}]`,
+		"duplicated key": `[{"id": "k1", "secret": "` + secret + `"}, {"id": "k1", "secret": "` + secret + `"}]`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			_, err := signing.LoadKeys(strings.NewReader(input))
+
+			// THEN
+			assert.ErrorIs(t, err, signing.ErrInvalidKeys)
+		})
+	}
+}
+
+func testFileKeyProvider_Refresh(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "keys.json")
+	writeKeys(t, path, keysJSON(t, "2026-09", previousSecret))
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	provider := signing.NewFileKeyProvider(path, time.Minute, signing.WithKeysClock(func() time.Time { return now }))
+
+	_, err := provider.Keys(context.Background())
+	require.NoError(t, err)
+	writeKeys(t, path, keysJSON(t, "2026-09", previousSecret, "2026-10", currentSecret))
+
+	// WHEN
+	cached, cachedErr := provider.Keys(context.Background())
+	now = now.Add(time.Minute)
+	refreshed, refreshedErr := provider.Keys(context.Background())
+
+	// THEN
+	require.NoError(t, cachedErr)
+	require.NoError(t, refreshedErr)
+	assert.Equal(t, signing.Keys{"2026-09": previousSecret}, cached)
+	assert.Equal(t, signing.Keys{"2026-09": previousSecret, "2026-10": currentSecret}, refreshed)
+}
+
+func testFileKeyProvider_RefreshFailure(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "keys.json")
+	writeKeys(t, path, keysJSON(t, "2026-10", currentSecret))
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	provider := signing.NewFileKeyProvider(path, time.Minute, signing.WithKeysClock(func() time.Time { return now }))
+
+	_, err := provider.Keys(context.Background())
+	require.NoError(t, err)
+	writeKeys(t, path, `[{"id":`)
+	now = now.Add(time.Minute)
+
+	// WHEN
+	keys, err := provider.Keys(context.Background())
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, signing.Keys{"2026-10": currentSecret}, keys)
+}
+
+func testFileKeyProvider_Missing(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	provider := signing.NewFileKeyProvider(filepath.Join(t.TempDir(), "keys.json"), time.Minute)
+
+	// WHEN
+	_, err := provider.Keys(context.Background())
+
+	// THEN
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// --- Helper Functions ---
+
+// keysJSON lists the secrets of the pairs of key id and secret
+func keysJSON(t *testing.T, pairs ...any) string {
+	t.Helper()
+
+	records := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		secret := base64.StdEncoding.EncodeToString(pairs[i+1].([]byte))
+		records = append(records, `{"id": "`+pairs[i].(string)+`", "secret": "`+secret+`"}`)
+	}
+	return "[" + strings.Join(records, ", ") + "]"
+}
+
+func writeKeys(t *testing.T, path, content string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
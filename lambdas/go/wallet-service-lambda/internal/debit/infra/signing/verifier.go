@@ -0,0 +1,114 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-processor/internal/debit/domain"
+	events2 "github.com/payment-processor/internal/debit/domain/events"
+)
+
+// Message attributes set by the producers next to the body they sign
+const (
+	SignatureAttribute = "signature"
+	KeyIDAttribute     = "signature_key_id"
+)
+
+// Sources lists by event type the producers allowed to send it, as set in header.source
+type Sources map[string][]string
+
+// Verifier authenticates the events of the queue: the body must be signed with one of the active
+// keys and, when sources are set, come from a producer allowed to send its event type
+type Verifier struct {
+	keys    KeyProvider
+	sources Sources
+}
+
+type VerifierOption func(*Verifier)
+
+// WithSources rejects the events whose producer is not listed for their type. Without it any
+// producer holding a key is accepted
+func WithSources(sources Sources) VerifierOption {
+	return func(v *Verifier) { v.sources = sources }
+}
+
+// Verify rejects the message with a non retryable domain error when it is not authenticated.
+// Failing to read the keys is retryable, the message is not at fault
+func (v *Verifier) Verify(ctx context.Context, message events.SQSMessage, header events2.EventHeader) error {
+	keyID := attribute(message, KeyIDAttribute)
+	signature := attribute(message, SignatureAttribute)
+	if keyID == "" || signature == "" {
+		return domain.NewInvalidSignatureError(keyID, errors.New("signature is missing"))
+	}
+
+	keys, err := v.keys.Keys(ctx)
+	if err != nil {
+		return domain.NewDependencyUnavailableError("signing keys", err)
+	}
+	secret, ok := keys[keyID]
+	if !ok {
+		return domain.NewInvalidSignatureError(keyID, errors.New("key is not active"))
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return domain.NewInvalidSignatureError(keyID, err)
+	}
+	want, err := mac(secret, []byte(message.Body))
+	if err != nil {
+		return domain.NewInvalidSignatureError(keyID, err)
+	}
+	if !hmac.Equal(got, want) {
+		return domain.NewInvalidSignatureError(keyID, errors.New("signature does not match the body"))
+	}
+
+	if v.sources != nil && !slices.Contains(v.sources[header.EventType], header.Source) {
+		return domain.NewSourceNotAllowedError(header.Source, header.EventType)
+	}
+
+	return nil
+}
+
+// Sign is the signature a producer sets in SignatureAttribute: the hex encoded HMAC-SHA256 of
+// the body without insignificant whitespace
+func Sign(secret []byte, body []byte) (string, error) {
+	sum, err := mac(secret, body)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+// mac hashes the canonical body, so reformatting the JSON in transit does not break the signature
+func mac(secret []byte, body []byte) ([]byte, error) {
+	var canonical bytes.Buffer
+	if err := json.Compact(&canonical, body); err != nil {
+		return nil, err
+	}
+
+	h := hmac.New(sha256.New, secret)
+	h.Write(canonical.Bytes())
+	return h.Sum(nil), nil
+}
+
+func attribute(message events.SQSMessage, name string) string {
+	if value := message.MessageAttributes[name].StringValue; value != nil {
+		return *value
+	}
+	return ""
+}
+
+func NewVerifier(keys KeyProvider, opts ...VerifierOption) *Verifier {
+	v := &Verifier{keys: keys}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
+}
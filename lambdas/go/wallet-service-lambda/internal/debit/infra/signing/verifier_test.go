@@ -0,0 +1,181 @@
+package signing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/payment-processor/internal/debit/domain"
+	_events "github.com/payment-processor/internal/debit/domain/events"
+	"github.com/payment-processor/internal/debit/infra/signing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const signedBody = `{"header":{"correlation_id":"corr-1","event_type":"PaymentInit","source":"checkout"},"payload":{"user_id":"user-1","amount":10}}`
+
+var (
+	activeKeys = staticKeys{keys: signing.Keys{"2026-09": previousSecret, "2026-10": currentSecret}}
+	header     = _events.EventHeader{CorrelationID: "corr-1", EventType: "PaymentInit", Source: "checkout"}
+)
+
+func TestVerifier(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should accept a body signed with an active key", testVerifier_Success)
+	t.Run("should accept the previous key during a rotation", testVerifier_PreviousKey)
+	t.Run("should accept a body reformatted in transit", testVerifier_Canonical)
+	t.Run("should reject unauthenticated messages", testVerifier_Invalid)
+	t.Run("should reject a source not allowed for the event type", testVerifier_SourceNotAllowed)
+	t.Run("should report unavailable keys as retryable", testVerifier_KeysUnavailable)
+}
+
+func testVerifier_Success(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	verifier := signing.NewVerifier(activeKeys, signing.WithSources(signing.Sources{"PaymentInit": {"saga", "checkout"}}))
+
+	// WHEN
+	err := verifier.Verify(context.Background(), signedMessage(t, signedBody, "2026-10", currentSecret), header)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testVerifier_PreviousKey(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	verifier := signing.NewVerifier(activeKeys)
+
+	// WHEN
+	err := verifier.Verify(context.Background(), signedMessage(t, signedBody, "2026-09", previousSecret), header)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testVerifier_Canonical(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	verifier := signing.NewVerifier(activeKeys)
+	message := signedMessage(t, signedBody, "2026-10", currentSecret)
+	message.Body = "{\n  \"header\": {\"correlation_id\": \"corr-1\", \"event_type\": \"PaymentInit\", \"source\": \"checkout\"},\n" +
+		"  \"payload\": {\"user_id\": \"user-1\", \"amount\": 10}\n}"
+
+	// WHEN
+	err := verifier.Verify(context.Background(), message, header)
+
+	// THEN
+	assert.NoError(t, err)
+}
+
+func testVerifier_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tampered := signedMessage(t, signedBody, "2026-10", currentSecret)
+	tampered.Body = `{"header":{"correlation_id":"corr-1","event_type":"PaymentInit","source":"checkout"},"payload":{"user_id":"user-1","amount":1000}}`
+	unsigned := signedMessage(t, signedBody, "2026-10", currentSecret)
+	delete(unsigned.MessageAttributes, signing.SignatureAttribute)
+	notHex := signedMessage(t, signedBody, "2026-10", currentSecret)
+	notHex.MessageAttributes[signing.SignatureAttribute] = stringAttribute("not hex")
+
+	tests := map[string]events.SQSMessage{
+		"tampered body":   tampered,
+		"missing":         unsigned,
+		"not hex":         notHex,
+		"wrong secret":    signedMessage(t, signedBody, "2026-10", previousSecret),
+		"retired key":     signedMessage(t, signedBody, "2026-08", currentSecret),
+		"missing key id":  signedMessage(t, signedBody, "", currentSecret),
+		"not a JSON body": signedMessage(t, signedBody[1:], "2026-10", currentSecret),
+	}
+
+	for name, message := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// GIVEN
+			verifier := signing.NewVerifier(activeKeys)
+
+			// WHEN
+			err := verifier.Verify(context.Background(), message, header)
+
+			// THEN
+			assertRejected(t, err, "4007")
+		})
+	}
+}
+
+func testVerifier_SourceNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	verifier := signing.NewVerifier(activeKeys, signing.WithSources(signing.Sources{"PaymentInit": {"saga"}, "Refund": {"checkout"}}))
+
+	// WHEN
+	err := verifier.Verify(context.Background(), signedMessage(t, signedBody, "2026-10", currentSecret), header)
+
+	// THEN
+	assertRejected(t, err, "4008")
+}
+
+func testVerifier_KeysUnavailable(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	verifier := signing.NewVerifier(staticKeys{err: errors.New("secret store unavailable")})
+
+	// WHEN
+	err := verifier.Verify(context.Background(), signedMessage(t, signedBody, "2026-10", currentSecret), header)
+
+	// THEN
+	require.Error(t, err)
+	assert.True(t, domain.IsRetryable(err))
+}
+
+// --- Helper Functions ---
+
+type staticKeys struct {
+	keys signing.Keys
+	err  error
+}
+
+func (s staticKeys) Keys(context.Context) (signing.Keys, error) {
+	return s.keys, s.err
+}
+
+// signedMessage carries body signed with secret under keyID. Bodies that are not JSON are
+// signed raw, as a producer ignoring the canonical form would
+func signedMessage(t *testing.T, body, keyID string, secret []byte) events.SQSMessage {
+	t.Helper()
+
+	signature, err := signing.Sign(secret, []byte(body))
+	if err != nil {
+		signature = "00"
+	}
+
+	return events.SQSMessage{
+		MessageId: "msg-1",
+		Body:      body,
+		MessageAttributes: map[string]events.SQSMessageAttribute{
+			signing.SignatureAttribute: stringAttribute(signature),
+			signing.KeyIDAttribute:     stringAttribute(keyID),
+		},
+	}
+}
+
+func stringAttribute(value string) events.SQSMessageAttribute {
+	return events.SQSMessageAttribute{StringValue: &value, DataType: "String"}
+}
+
+func assertRejected(t *testing.T, err error, code string) {
+	t.Helper()
+
+	var domainErr *domain.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, code, domainErr.Code)
+	assert.False(t, domain.IsRetryable(err))
+}
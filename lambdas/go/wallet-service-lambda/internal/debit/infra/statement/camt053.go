@@ -0,0 +1,184 @@
+package statement
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"math"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+// Camt053Namespace is the BankToCustomerStatement version rendered by WriteCamt053
+const Camt053Namespace = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+
+// notProvided is the ISO 20022 placeholder for a missing end to end id
+const notProvided = "NOTPROVIDED"
+
+type (
+	camtDocument struct {
+		XMLName   xml.Name          `xml:"Document"`
+		Namespace string            `xml:"xmlns,attr"`
+		Statement camtBankStatement `xml:"BkToCstmrStmt"`
+	}
+
+	camtBankStatement struct {
+		MessageID string        `xml:"GrpHdr>MsgId"`
+		CreatedAt string        `xml:"GrpHdr>CreDtTm"`
+		Statement camtStatement `xml:"Stmt"`
+	}
+
+	camtStatement struct {
+		ID        string        `xml:"Id"`
+		CreatedAt string        `xml:"CreDtTm"`
+		From      string        `xml:"FrToDt>FrDtTm"`
+		To        string        `xml:"FrToDt>ToDtTm"`
+		AccountID string        `xml:"Acct>Id>Othr>Id"`
+		Currency  string        `xml:"Acct>Ccy"`
+		Balances  []camtBalance `xml:"Bal"`
+		Summary   camtSummary   `xml:"TxsSummry"`
+		Entries   []camtEntry   `xml:"Ntry"`
+	}
+
+	camtBalance struct {
+		Code      string     `xml:"Tp>CdOrPrtry>Cd"`
+		Amount    camtAmount `xml:"Amt"`
+		Indicator string     `xml:"CdtDbtInd"`
+		Date      string     `xml:"Dt>DtTm"`
+	}
+
+	camtAmount struct {
+		Currency string `xml:"Ccy,attr"`
+		Value    string `xml:",chardata"`
+	}
+
+	camtSummary struct {
+		Entries       int    `xml:"TtlNtries>NbOfNtries"`
+		CreditEntries int    `xml:"TtlCdtNtries>NbOfNtries"`
+		CreditSum     string `xml:"TtlCdtNtries>Sum"`
+		DebitEntries  int    `xml:"TtlDbtNtries>NbOfNtries"`
+		DebitSum      string `xml:"TtlDbtNtries>Sum"`
+	}
+
+	camtEntry struct {
+		Reference   string     `xml:"NtryRef"`
+		Amount      camtAmount `xml:"Amt"`
+		Indicator   string     `xml:"CdtDbtInd"`
+		Status      string     `xml:"Sts>Cd"`
+		BookingDate string     `xml:"BookgDt>DtTm"`
+		ValueDate   string     `xml:"ValDt>DtTm"`
+		BankTxCode  string     `xml:"BkTxCd>Prtry>Cd"`
+		EndToEndID  string     `xml:"NtryDtls>TxDtls>Refs>EndToEndId"`
+		TxID        string     `xml:"NtryDtls>TxDtls>Refs>TxId,omitempty"`
+	}
+)
+
+// WriteCamt053 renders the statement as an ISO 20022 camt.053 document. Wallets have no currency
+// of their own, so it is given by the caller. The message and statement ids are derived from the
+// wallet, the period and the generation time, so the same statement always gets the same ids
+func WriteCamt053(w io.Writer, s application.Statement, currency string) error {
+	id := statementID(s)
+
+	doc := camtDocument{
+		Namespace: Camt053Namespace,
+		Statement: camtBankStatement{
+			MessageID: id,
+			CreatedAt: formatTime(s.GeneratedAt),
+			Statement: camtStatement{
+				ID:        id,
+				CreatedAt: formatTime(s.GeneratedAt),
+				From:      formatTime(s.From),
+				To:        formatTime(s.To),
+				AccountID: string(s.UserID),
+				Currency:  currency,
+				Balances: []camtBalance{
+					toCamtBalance("OPBD", s.OpeningBalance, s.From, currency),
+					toCamtBalance("CLBD", s.ClosingBalance, s.To, currency),
+				},
+				Summary: toCamtSummary(s.Movements),
+			},
+		},
+	}
+	for _, tx := range s.Movements {
+		doc.Statement.Statement.Entries = append(doc.Statement.Statement.Entries, toCamtEntry(tx, currency))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func toCamtBalance(code string, balance domain.Amount, at time.Time, currency string) camtBalance {
+	return camtBalance{
+		Code:      code,
+		Amount:    toCamtAmount(balance, currency),
+		Indicator: indicator(balance >= 0),
+		Date:      formatTime(at),
+	}
+}
+
+func toCamtSummary(movements []domain.Transaction) camtSummary {
+	summary := camtSummary{Entries: len(movements)}
+
+	var credits, debits domain.Amount
+	for _, tx := range movements {
+		if tx.Type == domain.TransactionCredit {
+			summary.CreditEntries++
+			credits += tx.Amount
+			continue
+		}
+		summary.DebitEntries++
+		debits += tx.Amount
+	}
+	summary.CreditSum = formatAmount(credits)
+	summary.DebitSum = formatAmount(debits)
+
+	return summary
+}
+
+func toCamtEntry(tx domain.Transaction, currency string) camtEntry {
+	endToEndID := tx.PaymentID
+	if endToEndID == "" {
+		endToEndID = notProvided
+	}
+
+	return camtEntry{
+		Reference:   tx.ID,
+		Amount:      toCamtAmount(tx.Amount, currency),
+		Indicator:   indicator(tx.Type == domain.TransactionCredit),
+		Status:      "BOOK",
+		BookingDate: formatTime(tx.Timestamp),
+		ValueDate:   formatTime(tx.Timestamp),
+		BankTxCode:  string(tx.Type),
+		EndToEndID:  endToEndID,
+		TxID:        tx.CorrelationID,
+	}
+}
+
+// toCamtAmount writes the absolute value, the sign goes in CdtDbtInd
+func toCamtAmount(a domain.Amount, currency string) camtAmount {
+	return camtAmount{Currency: currency, Value: formatAmount(domain.Amount(math.Abs(float64(a))))}
+}
+
+func indicator(credit bool) string {
+	if credit {
+		return "CRDT"
+	}
+	return "DBIT"
+}
+
+// statementID fits the 35 characters allowed for ISO 20022 identifiers
+func statementID(s application.Statement) string {
+	sum := sha256.Sum256([]byte(string(s.UserID) + "|" + formatTime(s.From) + "|" + formatTime(s.To) + "|" + formatTime(s.GeneratedAt)))
+	return hex.EncodeToString(sum[:16])
+}
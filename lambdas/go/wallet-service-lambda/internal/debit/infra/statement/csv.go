@@ -0,0 +1,57 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+var csvHeader = []string{"booked_at", "type", "transaction_id", "payment_id", "correlation_id", "amount", "balance"}
+
+// WriteCSV renders the statement as a single table: an opening row, one row per movement with
+// debits as negative amounts, and a closing row
+func WriteCSV(w io.Writer, s application.Statement) error {
+	cw := csv.NewWriter(w)
+
+	rows := [][]string{
+		csvHeader,
+		{formatTime(s.From), "opening", "", "", "", "", formatAmount(s.OpeningBalance)},
+	}
+	for _, tx := range s.Movements {
+		rows = append(rows, []string{
+			formatTime(tx.Timestamp),
+			string(tx.Type),
+			tx.ID,
+			tx.PaymentID,
+			tx.CorrelationID,
+			formatAmount(signed(tx)),
+			formatAmount(tx.BalanceAfter),
+		})
+	}
+	rows = append(rows, []string{formatTime(s.To), "closing", "", "", "", "", formatAmount(s.ClosingBalance)})
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func signed(tx domain.Transaction) domain.Amount {
+	if tx.Type == domain.TransactionDebit {
+		return -tx.Amount
+	}
+	return tx.Amount
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatAmount uses two decimals, as the statement formats expect
+func formatAmount(a domain.Amount) string {
+	return strconv.FormatFloat(float64(a), 'f', 2, 64)
+}
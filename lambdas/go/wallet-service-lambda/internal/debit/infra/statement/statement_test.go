@@ -0,0 +1,130 @@
+package statement_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/payment-processor/internal/debit/application"
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/statement"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+var (
+	periodStart = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	periodEnd   = time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestStatement(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should render movements as csv", testStatement_CSV)
+	t.Run("should render movements as camt.053", testStatement_Camt053)
+	t.Run("should render a period without movements as csv", testStatement_EmptyCSV)
+	t.Run("should render a period without movements as camt.053", testStatement_EmptyCamt053)
+}
+
+func testStatement_CSV(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var buf bytes.Buffer
+
+	// WHEN
+	err := statement.WriteCSV(&buf, monthStatement())
+
+	// THEN
+	require.NoError(t, err)
+	assertGolden(t, "statement.csv", buf.Bytes())
+}
+
+func testStatement_Camt053(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var buf bytes.Buffer
+
+	// WHEN
+	err := statement.WriteCamt053(&buf, monthStatement(), "EUR")
+
+	// THEN
+	require.NoError(t, err)
+	assertGolden(t, "statement.camt053.xml", buf.Bytes())
+}
+
+func testStatement_EmptyCSV(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var buf bytes.Buffer
+
+	// WHEN
+	err := statement.WriteCSV(&buf, emptyStatement())
+
+	// THEN
+	require.NoError(t, err)
+	assertGolden(t, "empty.csv", buf.Bytes())
+}
+
+func testStatement_EmptyCamt053(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	var buf bytes.Buffer
+
+	// WHEN
+	err := statement.WriteCamt053(&buf, emptyStatement(), "EUR")
+
+	// THEN
+	require.NoError(t, err)
+	assertGolden(t, "empty.camt053.xml", buf.Bytes())
+}
+
+// --- Helper Functions ---
+
+func monthStatement() application.Statement {
+	return application.Statement{
+		UserID:         "user-1",
+		From:           periodStart,
+		To:             periodEnd,
+		OpeningBalance: 100,
+		ClosingBalance: 84.5,
+		GeneratedAt:    periodEnd.Add(time.Hour),
+		Movements: []domain.Transaction{
+			{ID: "tx-1", PaymentID: "pay-1", CorrelationID: "corr-1", UserID: "user-1", Type: domain.TransactionDebit, Amount: 25.5, BalanceAfter: 74.5, Timestamp: periodStart.Add(36 * time.Hour)},
+			{ID: "tx-2", PaymentID: "pay-2", CorrelationID: "corr-2", UserID: "user-1", Type: domain.TransactionCredit, Amount: 20, BalanceAfter: 94.5, Timestamp: periodStart.AddDate(0, 0, 10)},
+			{ID: "tx-3", UserID: "user-1", Type: domain.TransactionDebit, Amount: 10, BalanceAfter: 84.5, Timestamp: periodStart.AddDate(0, 0, 20)},
+		},
+	}
+}
+
+func emptyStatement() application.Statement {
+	return application.Statement{
+		UserID:         "user-2",
+		From:           periodStart,
+		To:             periodEnd,
+		OpeningBalance: 42,
+		ClosingBalance: 42,
+		GeneratedAt:    periodEnd.Add(time.Hour),
+	}
+}
+
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
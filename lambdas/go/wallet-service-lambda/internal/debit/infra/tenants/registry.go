@@ -0,0 +1,70 @@
+package tenants
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/payment-processor/internal/debit/domain"
+)
+
+var ErrInvalidRegistry = errors.New("invalid tenant registry")
+
+// TenantRecord is the file representation of a domain.Tenant
+type TenantRecord struct {
+	ID                 domain.TenantID   `json:"id"`
+	Currencies         []domain.Currency `json:"currencies"`
+	FallbackCurrencies []domain.Currency `json:"fallback_currencies"`
+	MaxDebit           domain.Amount     `json:"max_debit"`
+}
+
+// LoadRegistryFile reads the JSON registry stored at path
+func LoadRegistryFile(path string) (domain.Tenants, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadRegistry(f)
+}
+
+// LoadRegistry reads a JSON array of tenants. Unknown fields are rejected so a misspelled limit
+// is not silently lifted, and so is a tenant listed twice
+func LoadRegistry(r io.Reader) (domain.Tenants, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var records []TenantRecord
+	if err := dec.Decode(&records); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRegistry, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: no tenants", ErrInvalidRegistry)
+	}
+
+	registry := make(domain.Tenants, len(records))
+	for _, record := range records {
+		tenant := record.toDomain()
+		if err := tenant.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRegistry, err)
+		}
+		if _, ok := registry[tenant.ID]; ok {
+			return nil, fmt.Errorf("%w: tenant %s is listed twice", ErrInvalidRegistry, tenant.ID)
+		}
+		registry[tenant.ID] = tenant
+	}
+
+	return registry, nil
+}
+
+func (r TenantRecord) toDomain() domain.Tenant {
+	return domain.Tenant{
+		ID:                 r.ID,
+		Currencies:         r.Currencies,
+		FallbackCurrencies: r.FallbackCurrencies,
+		MaxDebit:           r.MaxDebit,
+	}
+}
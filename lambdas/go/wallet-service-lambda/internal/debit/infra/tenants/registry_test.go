@@ -0,0 +1,89 @@
+package tenants_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/payment-processor/internal/debit/domain"
+	"github.com/payment-processor/internal/debit/infra/tenants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const registryJSON = `[
+	{"id": "acme", "currencies": ["EUR", "USD"], "fallback_currencies": ["USD"], "max_debit": 500},
+	{"id": "globex"}
+]`
+
+func TestLoadRegistry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("should load every tenant of a registry", testLoadRegistry_Success)
+	t.Run("should load a registry file", testLoadRegistry_File)
+	t.Run("should reject invalid registries", testLoadRegistry_Invalid)
+}
+
+func testLoadRegistry_Success(t *testing.T) {
+	t.Parallel()
+
+	// WHEN
+	registry, err := tenants.LoadRegistry(strings.NewReader(registryJSON))
+
+	// THEN
+	require.NoError(t, err)
+	assert.Equal(t, domain.Tenants{
+		"acme": {
+			ID:                 "acme",
+			Currencies:         []domain.Currency{"EUR", "USD"},
+			FallbackCurrencies: []domain.Currency{"USD"},
+			MaxDebit:           500,
+		},
+		"globex": {ID: "globex"},
+	}, registry)
+}
+
+func testLoadRegistry_File(t *testing.T) {
+	t.Parallel()
+
+	// GIVEN
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	require.NoError(t, os.WriteFile(path, []byte(registryJSON), 0o600))
+
+	// WHEN
+	registry, err := tenants.LoadRegistryFile(path)
+
+	// THEN
+	require.NoError(t, err)
+	_, ok := registry.Get("globex")
+	assert.True(t, ok)
+	_, ok = registry.Get("")
+	assert.False(t, ok)
+}
+
+func testLoadRegistry_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"malformed":         `[{"id":`,
+		"unknown field":     `[{"id": "acme", "max_amount": 10}]`,
+		"empty":             `[]`,
+		"missing id":        `[{"max_debit": 10}]`,
+		"negative limit":    `[{"id": "acme", "max_debit": -1}]`,
+		"empty currency":    `[{"id": "acme", "currencies": [""]}]`,
+		"duplicated tenant": `[{"id": "acme"}, {"id": "acme"}]`,
+	}
+
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// WHEN
+			_, err := tenants.LoadRegistry(strings.NewReader(input))
+
+			// THEN
+			assert.ErrorIs(t, err, tenants.ErrInvalidRegistry)
+		})
+	}
+}